@@ -0,0 +1,657 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+)
+
+// NewDatabaseHttp serves any database instead of Postgres, which is mostly
+// useful to run the server on top of the inmemory store in tests.
+func NewDatabaseHttp(db database.Database) *Http {
+	return &Http{client: &databaseClient{mu: &sync.Mutex{}, db: db}}
+}
+
+// databaseClient adapts a database.Database to the client the handlers use.
+// Requests are served one at a time, which keeps check-then-write operations
+// like create atomic and protects backends that are not safe for concurrent
+// use. The clients of the namespaces share the mutex of the store.
+type databaseClient struct {
+	mu *sync.Mutex
+	db database.Database
+}
+
+func (d *databaseClient) CreatePostgresRow(key, val string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	values, err := d.db.MultiGet([]string{key})
+	if err != nil {
+		return err
+	}
+	if _, ok := values[key]; ok {
+		return fmt.Errorf("%w. Use 'update' to change the value", postgres.ErrConflict)
+	}
+	return d.db.MultiSet(map[string]string{key: val})
+}
+
+func (d *databaseClient) DeletePostgresRow(key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deleted, err := d.db.MultiDelete([]string{key})
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		return postgres.ErrNotFound
+	}
+	return nil
+}
+
+func (d *databaseClient) UpdatePostgresRow(key, value string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	values, err := d.db.MultiGet([]string{key})
+	if err != nil {
+		return err
+	}
+	if _, ok := values[key]; !ok {
+		return postgres.ErrNotFound
+	}
+	return d.db.MultiSet(map[string]string{key: value})
+}
+
+func (d *databaseClient) GetPostgresRow(key string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	values, err := d.db.MultiGet([]string{key})
+	if err != nil {
+		return "", err
+	}
+	value, ok := values[key]
+	if !ok {
+		return "", postgres.ErrNotFound
+	}
+	return value, nil
+}
+
+func (d *databaseClient) ShowPostgresRow() (map[string]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	keys, err := d.db.Keys("")
+	if err != nil {
+		return nil, err
+	}
+	return d.db.MultiGet(keys)
+}
+
+func (d *databaseClient) MultiGetPostgresRows(keys []string) (map[string]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.db.MultiGet(keys)
+}
+
+func (d *databaseClient) MultiSetPostgresRows(pairs map[string]string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.db.MultiSet(pairs)
+}
+
+func (d *databaseClient) MultiDeletePostgresRows(keys []string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.db.MultiDelete(keys)
+}
+
+func (d *databaseClient) KeysPostgresRows(prefix string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.db.Keys(prefix)
+}
+
+func (d *databaseClient) CopyPostgresRows(pairs map[string]string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if loader, ok := d.db.(database.BulkLoader); ok {
+		return loader.BulkLoad(pairs)
+	}
+	return d.db.MultiSet(pairs)
+}
+
+// Reconnect passes new credentials on to backends that use them.
+func (d *databaseClient) Reconnect(username, password string) error {
+	if reconnecter, ok := d.db.(database.Reconnecter); ok {
+		return reconnecter.Reconnect(username, password)
+	}
+	return nil
+}
+
+func (d *databaseClient) StatPostgresRow(key string) (postgres.Metadata, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, ok := d.db.(database.MetadataStore)
+	if !ok {
+		return postgres.Metadata{}, errNotSupported
+	}
+	meta, err := store.Stat(key)
+	if errors.Is(err, database.ErrNotFound) {
+		return postgres.Metadata{}, postgres.ErrNotFound
+	}
+	return postgres.Metadata(meta), err
+}
+
+func (d *databaseClient) SetMetadataPostgresRow(key, contentType string, tags []string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, ok := d.db.(database.MetadataStore)
+	if !ok {
+		return errNotSupported
+	}
+	err := store.SetMetadata(key, contentType, tags)
+	if errors.Is(err, database.ErrNotFound) {
+		return postgres.ErrNotFound
+	}
+	return err
+}
+
+func (d *databaseClient) HistoryPostgresRows(key string, limit int) ([]postgres.Version, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, ok := d.db.(database.HistoryStore)
+	if !ok {
+		return nil, errNotSupported
+	}
+	history, err := store.History(key, limit)
+	if errors.Is(err, database.ErrNotFound) {
+		return nil, postgres.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]postgres.Version, len(history))
+	for i, version := range history {
+		versions[i] = postgres.Version(version)
+	}
+	return versions, nil
+}
+
+func (d *databaseClient) GetAtPostgresRow(key string, at time.Time) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, ok := d.db.(database.HistoryStore)
+	if !ok {
+		return "", errNotSupported
+	}
+	value, err := store.GetAt(key, at)
+	if errors.Is(err, database.ErrNotFound) {
+		return "", postgres.ErrNotFound
+	}
+	return value, err
+}
+
+func (d *databaseClient) UndeletePostgresRow(key string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, ok := d.db.(database.Undeleter)
+	if !ok {
+		return errNotSupported
+	}
+	err := store.Undelete(key)
+	if errors.Is(err, database.ErrNotFound) {
+		return postgres.ErrNotFound
+	}
+	return err
+}
+
+func (d *databaseClient) PurgeTombstonesPostgresRows(before time.Time) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, ok := d.db.(database.TombstonePurger)
+	if !ok {
+		return 0, errNotSupported
+	}
+	return store.PurgeTombstones(before)
+}
+
+func (d *databaseClient) ReadStreamPostgresRow(key string, w io.Writer) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	err := database.ReadValue(d.db, key, w)
+	if errors.Is(err, database.ErrNotFound) {
+		return postgres.ErrNotFound
+	}
+	return err
+}
+
+func (d *databaseClient) WriteStreamPostgresRow(key string, r io.Reader) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return database.WriteValue(d.db, key, r)
+}
+
+func (d *databaseClient) IncrByPostgresRow(key string, delta int64) (int64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	counter, ok := d.db.(database.Counter)
+	if !ok {
+		return 0, errNotSupported
+	}
+	result, err := counter.IncrBy(key, delta)
+	return result, counterError(err)
+}
+
+func (d *databaseClient) IncrByFloatPostgresRow(key string, delta float64) (float64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	counter, ok := d.db.(database.Counter)
+	if !ok {
+		return 0, errNotSupported
+	}
+	result, err := counter.IncrByFloat(key, delta)
+	return result, counterError(err)
+}
+
+// counterError converts the errors of database.Counter to the ones of the
+// postgres client, keeping their message.
+func counterError(err error) error {
+	switch {
+	case errors.Is(err, database.ErrNotNumber):
+		return fmt.Errorf("%w%s", postgres.ErrNotNumber, strings.TrimPrefix(err.Error(), database.ErrNotNumber.Error()))
+	case errors.Is(err, database.ErrOverflow):
+		return postgres.ErrOverflow
+	}
+	return err
+}
+
+// sortedSets returns the database as a database.SortedSetStore, which the
+// caller holds d.mu for.
+func (d *databaseClient) sortedSets() (database.SortedSetStore, error) {
+	store, ok := d.db.(database.SortedSetStore)
+	if !ok {
+		return nil, errNotSupported
+	}
+	return store, nil
+}
+
+// sortedSetError converts the errors of database.SortedSetStore to the ones
+// of the postgres client.
+func sortedSetError(err error) error {
+	if errors.Is(err, database.ErrNotFound) {
+		return postgres.ErrNotFound
+	}
+	return counterError(err)
+}
+
+func (d *databaseClient) ZAddPostgresRow(key, member string, score float64) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.sortedSets()
+	if err != nil {
+		return false, err
+	}
+	added, err := store.ZAdd(key, member, score)
+	return added, sortedSetError(err)
+}
+
+func (d *databaseClient) ZIncrByPostgresRow(key, member string, delta float64) (float64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.sortedSets()
+	if err != nil {
+		return 0, err
+	}
+	score, err := store.ZIncrBy(key, member, delta)
+	return score, sortedSetError(err)
+}
+
+func (d *databaseClient) ZScorePostgresRow(key, member string) (float64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.sortedSets()
+	if err != nil {
+		return 0, err
+	}
+	score, err := store.ZScore(key, member)
+	return score, sortedSetError(err)
+}
+
+func (d *databaseClient) ZRankPostgresRow(key, member string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.sortedSets()
+	if err != nil {
+		return 0, err
+	}
+	rank, err := store.ZRank(key, member)
+	return rank, sortedSetError(err)
+}
+
+func (d *databaseClient) ZRemPostgresRows(key string, members []string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.sortedSets()
+	if err != nil {
+		return 0, err
+	}
+	removed, err := store.ZRem(key, members...)
+	return removed, sortedSetError(err)
+}
+
+func (d *databaseClient) ZCardPostgresRows(key string) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.sortedSets()
+	if err != nil {
+		return 0, err
+	}
+	count, err := store.ZCard(key)
+	return count, sortedSetError(err)
+}
+
+func (d *databaseClient) ZRangePostgresRows(key string, start, stop int) ([]postgres.ScoredMember, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.sortedSets()
+	if err != nil {
+		return nil, err
+	}
+	members, err := store.ZRange(key, start, stop)
+	return scoredMembers(members), sortedSetError(err)
+}
+
+func (d *databaseClient) ZRangeByScorePostgresRows(key string, min, max float64) ([]postgres.ScoredMember, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.sortedSets()
+	if err != nil {
+		return nil, err
+	}
+	members, err := store.ZRangeByScore(key, min, max)
+	return scoredMembers(members), sortedSetError(err)
+}
+
+func scoredMembers(members []database.ScoredMember) []postgres.ScoredMember {
+	if members == nil {
+		return nil
+	}
+	converted := make([]postgres.ScoredMember, len(members))
+	for i, member := range members {
+		converted[i] = postgres.ScoredMember(member)
+	}
+	return converted
+}
+
+// documents returns the database as a database.JSONStore, which the caller
+// holds d.mu for.
+func (d *databaseClient) documents() (database.JSONStore, error) {
+	store, ok := d.db.(database.JSONStore)
+	if !ok {
+		return nil, errNotSupported
+	}
+	return store, nil
+}
+
+// jsonError converts a missing key or path to the error of the postgres
+// client, keeping its message. The other errors of database.JSONStore are
+// already the client's.
+func jsonError(err error) error {
+	if errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("%w%s", postgres.ErrNotFound, strings.TrimPrefix(err.Error(), database.ErrNotFound.Error()))
+	}
+	return err
+}
+
+func (d *databaseClient) JSONGetPostgresRow(key, path string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.documents()
+	if err != nil {
+		return "", err
+	}
+	value, err := store.JSONGet(key, path)
+	return value, jsonError(err)
+}
+
+func (d *databaseClient) JSONSetPostgresRow(key, path, value string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.documents()
+	if err != nil {
+		return err
+	}
+	return jsonError(store.JSONSet(key, path, value))
+}
+
+func (d *databaseClient) JSONMergePostgresRow(key, patch string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.documents()
+	if err != nil {
+		return "", err
+	}
+	document, err := store.JSONMerge(key, patch)
+	return document, jsonError(err)
+}
+
+func (d *databaseClient) JSONPatchPostgresRow(key, patch string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	store, err := d.documents()
+	if err != nil {
+		return "", err
+	}
+	document, err := store.JSONPatch(key, patch)
+	return document, jsonError(err)
+}
+
+// indexer returns the database as a database.Indexer, which the caller
+// holds d.mu for.
+func (d *databaseClient) indexer() (database.Indexer, error) {
+	indexer, ok := d.db.(database.Indexer)
+	if !ok {
+		return nil, errNotSupported
+	}
+	return indexer, nil
+}
+
+// indexError converts the errors of database.Indexer to the ones of the
+// postgres client, keeping their message.
+func indexError(err error) error {
+	switch {
+	case errors.Is(err, database.ErrIndexNotFound):
+		return fmt.Errorf("%w%s", postgres.ErrIndexNotFound, strings.TrimPrefix(err.Error(), database.ErrIndexNotFound.Error()))
+	case errors.Is(err, database.ErrIndexExists):
+		return fmt.Errorf("%w%s", postgres.ErrIndexExists, strings.TrimPrefix(err.Error(), database.ErrIndexExists.Error()))
+	}
+	return err
+}
+
+func (d *databaseClient) CreateIndexPostgresRow(name, path string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	indexer, err := d.indexer()
+	if err != nil {
+		return err
+	}
+	return indexError(indexer.CreateIndex(name, path))
+}
+
+func (d *databaseClient) DropIndexPostgresRow(name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	indexer, err := d.indexer()
+	if err != nil {
+		return err
+	}
+	return indexError(indexer.DropIndex(name))
+}
+
+func (d *databaseClient) IndexesPostgresRows() ([]postgres.Index, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	indexer, err := d.indexer()
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := indexer.Indexes()
+	if err != nil {
+		return nil, err
+	}
+	converted := make([]postgres.Index, len(indexes))
+	for i, index := range indexes {
+		converted[i] = postgres.Index(index)
+	}
+	return converted, nil
+}
+
+func (d *databaseClient) QueryPostgresRows(index, value string) ([]string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	indexer, err := d.indexer()
+	if err != nil {
+		return nil, err
+	}
+	keys, err := indexer.Query(index, value)
+	return keys, indexError(err)
+}
+
+func (d *databaseClient) namespaces() (database.NamespaceStore, error) {
+	namespaces, ok := d.db.(database.NamespaceStore)
+	if !ok {
+		return nil, errNotSupported
+	}
+	return namespaces, nil
+}
+
+// namespaceError converts database.ErrNamespaceNotFound to the error of the
+// postgres client, keeping its message.
+func namespaceError(err error) error {
+	if errors.Is(err, database.ErrNamespaceNotFound) {
+		return fmt.Errorf("%w%s", postgres.ErrNamespaceNotFound, strings.TrimPrefix(err.Error(), database.ErrNamespaceNotFound.Error()))
+	}
+	return err
+}
+
+func (d *databaseClient) Namespace(name string) (postgres.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	namespaces, err := d.namespaces()
+	if err != nil {
+		return nil, err
+	}
+	db, err := namespaces.Namespace(name)
+	if err != nil {
+		return nil, err
+	}
+	return &databaseClient{mu: d.mu, db: db}, nil
+}
+
+func (d *databaseClient) NamespacesPostgresRows() ([]postgres.Namespace, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	namespaces, err := d.namespaces()
+	if err != nil {
+		return nil, err
+	}
+	list, err := namespaces.Namespaces()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]postgres.Namespace, 0, len(list))
+	for _, namespace := range list {
+		result = append(result, postgres.Namespace{Name: namespace.Name, DefaultTTL: namespace.DefaultTTL})
+	}
+	return result, nil
+}
+
+func (d *databaseClient) SetNamespaceTTLPostgresRow(name string, ttl time.Duration) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	namespaces, err := d.namespaces()
+	if err != nil {
+		return err
+	}
+	return namespaces.SetNamespaceTTL(name, ttl)
+}
+
+func (d *databaseClient) DropNamespacePostgresRow(name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	namespaces, err := d.namespaces()
+	if err != nil {
+		return err
+	}
+	return namespaceError(namespaces.DropNamespace(name))
+}
+
+func (d *databaseClient) ExpirePostgresRows(before time.Time) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	expirer, ok := d.db.(database.Expirer)
+	if !ok {
+		return 0, errNotSupported
+	}
+	return expirer.Expire(before)
+}
+
+func (d *databaseClient) memoryStats() (database.MemoryStats, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	reporter, ok := d.db.(database.MemoryReporter)
+	if !ok {
+		return database.MemoryStats{}, errNotSupported
+	}
+	return reporter.MemoryStats(), nil
+}
+
+// ListenPostgresChanges does not take mu, it runs as long as the server.
+func (d *databaseClient) ListenPostgresChanges(ctx context.Context, changed func(key string)) error {
+	listener, ok := d.db.(database.ChangeListener)
+	if !ok {
+		return errNotSupported
+	}
+	return listener.ListenChanges(ctx, changed)
+}
@@ -2,11 +2,21 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"mime"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/cache"
 	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+	"github.com/imsumedhaa/In-memory-database/pkg/jsondoc"
 )
 
 type Response struct {
@@ -18,12 +28,138 @@ type Request struct {
 	Value string `json:"Value"`
 }
 
+// GetRequest reads the current value of Key, or with At the value it had
+// at that time.
+type GetRequest struct {
+	Key string     `json:"Key"`
+	At  *time.Time `json:"At,omitempty"`
+}
+
+type HistoryRequest struct {
+	Key   string `json:"Key"`
+	Limit int    `json:"Limit,omitempty"`
+}
+
+type VersionResponse struct {
+	Version    int64     `json:"Version"`
+	Value      string    `json:"Value"`
+	WrittenAt  time.Time `json:"WrittenAt"`
+	ReplacedAt time.Time `json:"ReplacedAt,omitzero"`
+	Deleted    bool      `json:"Deleted,omitempty"`
+}
+
+type HistoryResponse struct {
+	Key      string            `json:"Key"`
+	Versions []VersionResponse `json:"Versions"`
+}
+
+// IncrRequest adds By, or ByFloat for decimal values, to a counter. An
+// empty body adds 1.
+type IncrRequest struct {
+	By      *int64   `json:"By,omitempty"`
+	ByFloat *float64 `json:"ByFloat,omitempty"`
+}
+
+type IncrResponse struct {
+	Key   string      `json:"Key"`
+	Value json.Number `json:"Value"`
+}
+
+type QueryResponse struct {
+	Index string   `json:"Index"`
+	Value string   `json:"Value"`
+	Keys  []string `json:"Keys"`
+}
+
+// IndexResponse is an index in the /indexes response, and the body of PUT
+// /indexes/{name}, where only Path is read.
+type IndexResponse struct {
+	Name string `json:"Name,omitempty"`
+	Path string `json:"Path"`
+}
+
+type IndexesResponse struct {
+	Indexes []IndexResponse `json:"Indexes"`
+}
+
+type KeysResponse struct {
+	Keys []string `json:"Keys"`
+}
+
+// NamespaceResponse is a namespace in the /ns response, with the number of
+// its keys in the /ns/{namespace}/stats one. DefaultTTL is a duration like
+// "1h30m", left out when the keys do not expire.
+type NamespaceResponse struct {
+	Name       string `json:"Name"`
+	DefaultTTL string `json:"DefaultTTL,omitempty"`
+	Keys       *int   `json:"Keys,omitempty"`
+}
+
+type NamespacesResponse struct {
+	Namespaces []NamespaceResponse `json:"Namespaces"`
+}
+
+// NamespaceRequest is the body of PUT /ns/{namespace}.
+type NamespaceRequest struct {
+	DefaultTTL string `json:"DefaultTTL"`
+}
+
+type MetadataRequest struct {
+	Key         string   `json:"Key"`
+	ContentType string   `json:"ContentType"`
+	Tags        []string `json:"Tags"`
+}
+
+type StatResponse struct {
+	Key         string    `json:"Key"`
+	CreatedAt   time.Time `json:"CreatedAt"`
+	UpdatedAt   time.Time `json:"UpdatedAt"`
+	WrittenAt   time.Time `json:"WrittenAt,omitzero"`
+	ContentType string    `json:"ContentType,omitempty"`
+	Tags        []string  `json:"Tags,omitempty"`
+	Version     int64     `json:"Version,omitempty"`
+}
+
+type BatchOperation struct {
+	Op    string `json:"Op"` // get, set or delete
+	Key   string `json:"Key"`
+	Value string `json:"Value,omitempty"`
+}
+
+type BatchRequest struct {
+	Operations []BatchOperation `json:"Operations"`
+}
+
+type BatchResult struct {
+	Op     string `json:"Op"`
+	Key    string `json:"Key"`
+	Value  string `json:"Value,omitempty"`
+	Status string `json:"Status"` // ok, not found or error
+	Error  string `json:"Error,omitempty"`
+}
+
+type BatchResponse struct {
+	Results []BatchResult `json:"Results"`
+}
+
+const (
+	batchStatusOK       = "ok"
+	batchStatusNotFound = "not found"
+	batchStatusError    = "error"
+)
+
 type Http struct {
 	client postgres.Client
+	// cache is set when client is a cache, see Cache
+	cache *cache.Client
+
+	// Token, when set, must be sent as "Authorization: Bearer <token>" with
+	// every request.
+	Token string
 }
 
-func NewHttp(host,port, username, password, dbname string) (*Http, error) {
-	dbClient, err := postgres.NewClient(host,port, username, password, dbname)
+func NewHttp(cfg postgres.Config) (*Http, error) {
+	dbClient, err := postgres.NewClient(cfg)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect: %w", err)
@@ -49,7 +185,7 @@ func (h *Http) create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.client.CreatePostgresRow(req.Key, req.Value); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to create row: %s", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to create row: %s", err), errorStatus(err))
 		return
 	}
 
@@ -76,7 +212,7 @@ func (h *Http) update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.client.UpdatePostgresRow(req.Key, req.Value); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to update row: %s ", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to update row: %s ", err), errorStatus(err))
 		return
 	}
 
@@ -86,7 +222,7 @@ func (h *Http) update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Http) delete(w http.ResponseWriter, r *http.Request) {
-	
+
 	if r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -102,9 +238,8 @@ func (h *Http) delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-
 	if err := h.client.DeletePostgresRow(req.Key); err != nil {
-		http.Error(w, fmt.Sprintf("Failed to delete row: %s", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to delete row: %s", err), errorStatus(err))
 		return
 	}
 
@@ -119,7 +254,7 @@ func (h *Http) get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req Request
+	var req GetRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, fmt.Sprintf("Invalid get body request: %s", err), http.StatusBadRequest)
 		return
@@ -130,13 +265,25 @@ func (h *Http) get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.At != nil {
+		h.getAt(w, req.Key, *req.At)
+		return
+	}
+
 	value, err := h.client.GetPostgresRow(req.Key)
 
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get the row: %s", err), http.StatusInternalServerError)
+		http.Error(w, fmt.Sprintf("Failed to get the row: %s", err), errorStatus(err))
 		return
 	}
 
+	// The value was read already, so metadata that cannot be read is left out
+	if meta, err := h.client.StatPostgresRow(req.Key); err == nil {
+		if writeMetadataHeaders(w, r, meta) {
+			return
+		}
+	}
+
 	response := map[string]string{
 		"Key":   req.Key,
 		"Value": value,
@@ -147,6 +294,635 @@ func (h *Http) get(w http.ResponseWriter, r *http.Request) {
 
 }
 
+// getAt answers a get of a past value. Its metadata is the one of the
+// current value, so no metadata headers are sent.
+func (h *Http) getAt(w http.ResponseWriter, key string, at time.Time) {
+	value, err := h.client.GetAtPostgresRow(key, at)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get the row: %s", err), errorStatus(err))
+		return
+	}
+
+	response := map[string]string{
+		"Key":   key,
+		"Value": value,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Http) history(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req HistoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid history body request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if req.Key == "" {
+		http.Error(w, "Key cannot be empty", http.StatusBadRequest)
+		return
+	}
+	if req.Limit < 0 {
+		http.Error(w, "Limit cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	versions, err := h.client.HistoryPostgresRows(req.Key, req.Limit)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get the history: %s", err), errorStatus(err))
+		return
+	}
+
+	response := HistoryResponse{Key: req.Key, Versions: make([]VersionResponse, len(versions))}
+	for i, version := range versions {
+		response.Versions[i] = VersionResponse(version)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// restore brings back a key deleted in soft delete mode. The key is part
+// of the path: POST /keys/{key}/restore.
+func (h *Http) restore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := r.PathValue("key")
+	if key == "" {
+		http.Error(w, "Key cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.client.UndeletePostgresRow(key); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to restore the row: %s", err), errorStatus(err))
+		return
+	}
+
+	response := Response{Message: "Row restored succesfully"}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// value reads and writes the raw bytes of a value, for binary values that
+// the JSON endpoints would mangle. GET /keys/{key} answers with an
+// application/octet-stream body and PUT /keys/{key} stores its body as the
+// value, creating or overwriting the key. Large values are streamed both
+// ways instead of being held in memory.
+func (h *Http) value(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if key == "" {
+		http.Error(w, "Key cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.download(w, r, key)
+	case http.MethodPut:
+		if err := h.client.WriteStreamPostgresRow(key, r.Body); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to set the row: %s", err), errorStatus(err))
+			return
+		}
+
+		response := Response{Message: "Row saved succesfully"}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Http) download(w http.ResponseWriter, r *http.Request, key string) {
+	meta, err := h.client.StatPostgresRow(key)
+	if errors.Is(err, postgres.ErrNotFound) {
+		http.Error(w, fmt.Sprintf("Failed to get the row: %s", err), http.StatusNotFound)
+		return
+	}
+	// Metadata that cannot be read is left out, as in get
+	if err == nil && writeMetadataHeaders(w, r, meta) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	body := &bodyWriter{w: w}
+	if err := h.client.ReadStreamPostgresRow(key, body); err != nil {
+		if !body.written {
+			http.Error(w, fmt.Sprintf("Failed to get the row: %s", err), errorStatus(err))
+			return
+		}
+		// The status is sent already, so the connection is dropped for the
+		// client to see the value is incomplete
+		log.Printf("failed to stream %q: %s", key, err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// bodyWriter tells whether anything was written to the response yet.
+type bodyWriter struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+func (b *bodyWriter) Write(p []byte) (int, error) {
+	b.written = true
+	return b.w.Write(p)
+}
+
+// incr atomically adds to the numeric value of a key, which is created
+// when it is missing: POST /keys/{key}/incr.
+func (h *Http) incr(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	key := r.PathValue("key")
+	if key == "" {
+		http.Error(w, "Key cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	var req IncrRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, fmt.Sprintf("Invalid incr body request: %s", err), http.StatusBadRequest)
+		return
+	}
+	if req.By != nil && req.ByFloat != nil {
+		http.Error(w, "By and ByFloat cannot be used together", http.StatusBadRequest)
+		return
+	}
+
+	var value string
+	var err error
+	if req.ByFloat != nil {
+		var result float64
+		result, err = h.client.IncrByFloatPostgresRow(key, *req.ByFloat)
+		value = strconv.FormatFloat(result, 'f', -1, 64)
+	} else {
+		by := int64(1)
+		if req.By != nil {
+			by = *req.By
+		}
+		var result int64
+		result, err = h.client.IncrByPostgresRow(key, by)
+		value = strconv.FormatInt(result, 10)
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to increment the row: %s", err), errorStatus(err))
+		return
+	}
+
+	response := IncrResponse{Key: key, Value: json.Number(value)}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// document reads and changes parts of a JSON value in place. GET
+// /keys/{key}/json?path=$.user answers with the JSON at path, $ by default,
+// and PUT sets it to the JSON body. PATCH applies the body as a merge patch
+// with Content-Type application/merge-patch+json, or as a JSON patch with
+// application/json-patch+json, and answers with the new document.
+func (h *Http) document(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if key == "" {
+		http.Error(w, "Key cannot be empty", http.StatusBadRequest)
+		return
+	}
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		path = "$"
+	}
+	if _, err := jsondoc.ParsePath(path); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body string
+	if r.Method == http.MethodPut || r.Method == http.MethodPatch {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON body: %s", err), http.StatusBadRequest)
+			return
+		}
+		body = string(data)
+		if err := jsondoc.Valid(body); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid JSON body: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var document string
+	var err error
+	action := "get"
+	switch r.Method {
+	case http.MethodGet:
+		document, err = h.client.JSONGetPostgresRow(key, path)
+	case http.MethodPut:
+		if err := h.client.JSONSetPostgresRow(key, path, body); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to set the JSON: %s", err), errorStatus(err))
+			return
+		}
+
+		response := Response{Message: "JSON saved succesfully"}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+		return
+	case http.MethodPatch:
+		if path != "$" {
+			http.Error(w, "A patch applies to the whole document", http.StatusBadRequest)
+			return
+		}
+		action = "patch"
+		contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		switch contentType {
+		case "application/merge-patch+json":
+			document, err = h.client.JSONMergePostgresRow(key, body)
+		case "application/json-patch+json":
+			document, err = h.client.JSONPatchPostgresRow(key, body)
+		default:
+			http.Error(w, "Content-Type must be application/merge-patch+json or application/json-patch+json", http.StatusUnsupportedMediaType)
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to %s the JSON: %s", action, err), errorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, document)
+}
+
+// query finds the keys by the content of their JSON values with a
+// secondary index: GET /query?index=status&value=active.
+func (h *Http) query(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	index, value := r.URL.Query().Get("index"), r.URL.Query().Get("value")
+	if index == "" || !r.URL.Query().Has("value") {
+		http.Error(w, "Index and value are required", http.StatusBadRequest)
+		return
+	}
+
+	keys, err := h.client.QueryPostgresRows(index, value)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to query the index: %s", err), errorStatus(err))
+		return
+	}
+
+	response := QueryResponse{Index: index, Value: value, Keys: keys}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// indexes lists the secondary indexes: GET /indexes.
+func (h *Http) indexes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	indexes, err := h.client.IndexesPostgresRows()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list the indexes: %s", err), errorStatus(err))
+		return
+	}
+
+	response := IndexesResponse{Indexes: make([]IndexResponse, 0, len(indexes))}
+	for _, index := range indexes {
+		response.Indexes = append(response.Indexes, IndexResponse(index))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// index creates a secondary index with PUT /indexes/{name} and a body like
+// {"Path": "$.status"}, and drops it with DELETE.
+func (h *Http) index(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	switch r.Method {
+	case http.MethodPut:
+		var req IndexResponse
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid index body request: %s", err), http.StatusBadRequest)
+			return
+		}
+		if _, err := database.CheckIndex(name, req.Path); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.client.CreateIndexPostgresRow(name, req.Path); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to create the index: %s", err), errorStatus(err))
+			return
+		}
+
+		response := Response{Message: "Index created succesfully"}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	case http.MethodDelete:
+		if err := h.client.DropIndexPostgresRow(name); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to drop the index: %s", err), errorStatus(err))
+			return
+		}
+
+		response := Response{Message: "Index dropped succesfully"}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// keys lists the keys starting with the prefix parameter, or every key:
+// GET /keys?prefix=user:.
+func (h *Http) keys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	keys, err := h.client.KeysPostgresRows(r.URL.Query().Get("prefix"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list the keys: %s", err), errorStatus(err))
+		return
+	}
+
+	response := KeysResponse{Keys: keys}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// inNamespace serves a route of the store for the keys of the namespace in
+// the path, like GET /ns/{namespace}/keys/{key}. The namespace is created
+// by the first write, reads and deletes of a missing one answer 404.
+func (h *Http) inNamespace(handler func(*Http, http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("namespace")
+		if err := database.CheckNamespace(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodDelete:
+			exists, err := h.namespaceExists(name)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Failed to open the namespace: %s", err), errorStatus(err))
+				return
+			}
+			if !exists {
+				http.Error(w, fmt.Sprintf("Failed to open the namespace: %s: %s", postgres.ErrNamespaceNotFound, name), http.StatusNotFound)
+				return
+			}
+		}
+
+		client, err := h.client.Namespace(name)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("Failed to open the namespace: %s", err), errorStatus(err))
+			return
+		}
+		handler(&Http{client: client}, w, r)
+	}
+}
+
+// namespaceExists tells whether the namespace name was created.
+func (h *Http) namespaceExists(name string) (bool, error) {
+	namespaces, err := h.client.NamespacesPostgresRows()
+	if err != nil {
+		return false, err
+	}
+	return slices.ContainsFunc(namespaces, func(namespace postgres.Namespace) bool { return namespace.Name == name }), nil
+}
+
+// namespaces lists the namespaces: GET /ns.
+func (h *Http) namespaces(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespaces, err := h.client.NamespacesPostgresRows()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to list the namespaces: %s", err), errorStatus(err))
+		return
+	}
+
+	response := NamespacesResponse{Namespaces: make([]NamespaceResponse, 0, len(namespaces))}
+	for _, namespace := range namespaces {
+		response.Namespaces = append(response.Namespaces, namespaceResponse(namespace))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func namespaceResponse(namespace postgres.Namespace) NamespaceResponse {
+	response := NamespaceResponse{Name: namespace.Name}
+	if namespace.DefaultTTL > 0 {
+		response.DefaultTTL = namespace.DefaultTTL.String()
+	}
+	return response
+}
+
+// namespace sets the default TTL of a namespace, creating it, with PUT
+// /ns/{namespace} and a body like {"DefaultTTL": "24h"}, and drops it with
+// all its keys with DELETE. An empty or zero DefaultTTL keeps the keys.
+func (h *Http) namespace(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("namespace")
+
+	switch r.Method {
+	case http.MethodPut:
+		var req NamespaceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("Invalid namespace body request: %s", err), http.StatusBadRequest)
+			return
+		}
+		if err := database.CheckNamespace(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var ttl time.Duration
+		if req.DefaultTTL != "" {
+			var err error
+			ttl, err = time.ParseDuration(req.DefaultTTL)
+			if err != nil || ttl < 0 {
+				http.Error(w, fmt.Sprintf("Invalid DefaultTTL %q, expected a duration like 24h", req.DefaultTTL), http.StatusBadRequest)
+				return
+			}
+		}
+		if err := h.client.SetNamespaceTTLPostgresRow(name, ttl); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to set the namespace: %s", err), errorStatus(err))
+			return
+		}
+
+		response := Response{Message: "Namespace saved succesfully"}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	case http.MethodDelete:
+		if err := h.client.DropNamespacePostgresRow(name); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to drop the namespace: %s", err), errorStatus(err))
+			return
+		}
+
+		response := Response{Message: "Namespace dropped succesfully"}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// namespaceStats answers the default TTL and the number of keys of a
+// namespace: GET /ns/{namespace}/stats.
+func (h *Http) namespaceStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := r.PathValue("namespace")
+
+	namespaces, err := h.client.NamespacesPostgresRows()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get the namespace: %s", err), errorStatus(err))
+		return
+	}
+	found := false
+	var response NamespaceResponse
+	for _, namespace := range namespaces {
+		if namespace.Name == name {
+			found = true
+			response = namespaceResponse(namespace)
+		}
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("Failed to get the namespace: %s: %s", postgres.ErrNamespaceNotFound, name), http.StatusNotFound)
+		return
+	}
+
+	client, err := h.client.Namespace(name)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get the namespace: %s", err), errorStatus(err))
+		return
+	}
+	keys, err := client.KeysPostgresRows("")
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to get the namespace: %s", err), errorStatus(err))
+		return
+	}
+	count := len(keys)
+	response.Keys = &count
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Http) stat(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid stat body request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if req.Key == "" {
+		http.Error(w, "Key cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	meta, err := h.client.StatPostgresRow(req.Key)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to stat the row: %s", err), errorStatus(err))
+		return
+	}
+	if writeMetadataHeaders(w, r, meta) {
+		return
+	}
+
+	response := StatResponse{
+		Key:         req.Key,
+		CreatedAt:   meta.CreatedAt,
+		UpdatedAt:   meta.UpdatedAt,
+		WrittenAt:   meta.WrittenAt,
+		ContentType: meta.ContentType,
+		Tags:        meta.Tags,
+		Version:     meta.Version,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Http) metadata(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req MetadataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid metadata body request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if req.Key == "" {
+		http.Error(w, "Key cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.client.SetMetadataPostgresRow(req.Key, req.ContentType, req.Tags); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to set the metadata: %s", err), errorStatus(err))
+		return
+	}
+
+	response := Response{Message: "Metadata updated succesfully"}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeMetadataHeaders sends the metadata of a key as headers: Last-Modified
+// and X-Created-At, X-Value-Content-Type and X-Tags. When the key has not
+// changed since If-Modified-Since it answers 304 and returns true.
+func writeMetadataHeaders(w http.ResponseWriter, r *http.Request, meta postgres.Metadata) bool {
+	if !meta.CreatedAt.IsZero() {
+		w.Header().Set("X-Created-At", meta.CreatedAt.UTC().Format(http.TimeFormat))
+	}
+	if meta.ContentType != "" {
+		w.Header().Set("X-Value-Content-Type", meta.ContentType)
+	}
+	if len(meta.Tags) > 0 {
+		w.Header().Set("X-Tags", strings.Join(meta.Tags, ","))
+	}
+	if meta.UpdatedAt.IsZero() {
+		return false
+	}
+
+	w.Header().Set("Last-Modified", meta.UpdatedAt.UTC().Format(http.TimeFormat))
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	// The header has a resolution of seconds
+	if err == nil && !meta.UpdatedAt.Truncate(time.Second).After(since) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+	return false
+}
+
 func (h *Http) show(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -156,27 +932,329 @@ func (h *Http) show(w http.ResponseWriter, r *http.Request) {
 	store, err := h.client.ShowPostgresRow()
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Failed to show row: %s", err), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(store)
 }
 
-func (h *Http) Run() error {
-	h.routes()
-	log.Println("Server started on http://localhost:8080")
-	err := http.ListenAndServe(":8080", nil)
+func (h *Http) batch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req BatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid batch body request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Operations) == 0 {
+		http.Error(w, "Operations cannot be empty", http.StatusBadRequest)
+		return
+	}
+
+	results := make([]BatchResult, len(req.Operations))
+
+	// Consecutive operations of the same kind go to the database as one call,
+	// which keeps the order of the batch while saving round trips.
+	for start := 0; start < len(req.Operations); {
+		end := start + 1
+		for end < len(req.Operations) && req.Operations[end].Op == req.Operations[start].Op {
+			end++
+		}
+		h.runBatch(req.Operations[start:end], results[start:end])
+		start = end
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(BatchResponse{Results: results})
+}
+
+// runBatch executes a run of operations sharing the same Op and fills in
+// the matching results.
+func (h *Http) runBatch(ops []BatchOperation, results []BatchResult) {
+	var keys []string
+	for i, op := range ops {
+		results[i] = BatchResult{Op: op.Op, Key: op.Key}
+		if op.Key == "" {
+			results[i].Status = batchStatusError
+			results[i].Error = "Key cannot be empty"
+			continue
+		}
+		keys = append(keys, op.Key)
+	}
+
+	fail := func(message string) {
+		for i := range results {
+			if results[i].Status == "" {
+				results[i].Status = batchStatusError
+				results[i].Error = message
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return
+	}
+
+	switch ops[0].Op {
+	case "get":
+		store, err := h.client.MultiGetPostgresRows(keys)
+		if err != nil {
+			fail(fmt.Sprintf("Failed to get the rows: %s", err))
+			return
+		}
+		for i := range results {
+			if results[i].Status != "" {
+				continue
+			}
+			if value, ok := store[results[i].Key]; ok {
+				results[i].Value = value
+				results[i].Status = batchStatusOK
+			} else {
+				results[i].Status = batchStatusNotFound
+			}
+		}
+
+	case "set":
+		pairs := make(map[string]string, len(keys))
+		for _, op := range ops {
+			if op.Key != "" {
+				pairs[op.Key] = op.Value
+			}
+		}
+		if err := h.client.MultiSetPostgresRows(pairs); err != nil {
+			fail(fmt.Sprintf("Failed to set the rows: %s", err))
+			return
+		}
+		for i := range results {
+			if results[i].Status == "" {
+				results[i].Status = batchStatusOK
+			}
+		}
+
+	case "delete":
+		deleted, err := h.client.MultiDeletePostgresRows(keys)
+		if err != nil {
+			fail(fmt.Sprintf("Failed to delete the rows: %s", err))
+			return
+		}
+		gone := make(map[string]bool, len(deleted))
+		for _, key := range deleted {
+			gone[key] = true
+		}
+		for i := range results {
+			if results[i].Status != "" {
+				continue
+			}
+			if gone[results[i].Key] {
+				results[i].Status = batchStatusOK
+			} else {
+				results[i].Status = batchStatusNotFound
+			}
+		}
+
+	default:
+		fail(fmt.Sprintf("Unknown operation %q", ops[0].Op))
+	}
+}
+
+// Reconnect switches the database connection to new credentials.
+func (h *Http) Reconnect(username, password string) error {
+	if err := h.client.Reconnect(username, password); err != nil {
+		return fmt.Errorf("failed to reconnect: %w", err)
+	}
+	return nil
+}
+
+// PurgeTombstones removes for good the keys deleted before the given time
+// in soft delete mode, see database.TombstonePurger.
+func (h *Http) PurgeTombstones(before time.Time) (int, error) {
+	purged, err := h.client.PurgeTombstonesPostgresRows(before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge tombstones: %w", err)
+	}
+	return purged, nil
+}
+
+// ExpireNamespaces deletes the keys of every namespace written longer than
+// its default TTL ago, see database.NamespaceExpirer.
+func (h *Http) ExpireNamespaces(now time.Time) (int, error) {
+	namespaces, err := h.client.NamespacesPostgresRows()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list the namespaces: %w", err)
+	}
+
+	expired := 0
+	for _, namespace := range namespaces {
+		if namespace.DefaultTTL <= 0 {
+			continue
+		}
+		client, err := h.client.Namespace(namespace.Name)
+		if err != nil {
+			return expired, fmt.Errorf("failed to open the namespace %s: %w", namespace.Name, err)
+		}
+		count, err := client.ExpirePostgresRows(now.Add(-namespace.DefaultTTL))
+		expired += count
+		if err != nil {
+			return expired, fmt.Errorf("failed to expire the keys of %s: %w", namespace.Name, err)
+		}
+	}
+	return expired, nil
+}
+
+// memoryReporter is implemented by the clients of the stores that account
+// for the memory of their keys, see metrics.
+type memoryReporter interface {
+	memoryStats() (database.MemoryStats, error)
+}
+
+// metrics answers the keys, memory and evictions of the store, and of the
+// cache in front of it, in the Prometheus text format: GET /metrics.
+func (h *Http) metrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	backend := h.client
+	if h.cache != nil {
+		backend = h.cache.Client
+	}
+	reporter, ok := backend.(memoryReporter)
+	if !ok && h.cache == nil {
+		http.Error(w, fmt.Sprintf("Failed to get the metrics: %s", errNotSupported), http.StatusNotImplemented)
+		return
+	}
+	var stats database.MemoryStats
+	if ok {
+		var err error
+		if stats, err = reporter.memoryStats(); err != nil {
+			http.Error(w, fmt.Sprintf("Failed to get the metrics: %s", err), errorStatus(err))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if ok {
+		writeMetrics(w, []metric{
+			{"kvstore_keys", "gauge", "Number of keys in the store and its namespaces.", int64(stats.Keys)},
+			{"kvstore_memory_bytes", "gauge", "Approximate memory used by the keys.", stats.Bytes},
+			{"kvstore_max_keys", "gauge", "Limit on the number of keys, 0 when unlimited.", int64(stats.MaxKeys)},
+			{"kvstore_max_memory_bytes", "gauge", "Limit on the memory of the keys, 0 when unlimited.", stats.MaxMemory},
+			{"kvstore_rejected_writes_total", "counter", "Writes refused because the store was full.", stats.Rejected},
+		})
+		fmt.Fprintf(w, "# HELP kvstore_evictions_total Keys evicted to stay within the limits.\n# TYPE kvstore_evictions_total counter\nkvstore_evictions_total{policy=%q} %d\n", stats.Policy, stats.Evictions)
+	}
+	if h.cache != nil {
+		cached := h.cache.Stats()
+		writeMetrics(w, []metric{
+			{"kvstore_cache_keys", "gauge", "Number of values and metadata cached.", int64(cached.Keys)},
+			{"kvstore_cache_memory_bytes", "gauge", "Approximate memory used by the cache.", cached.Bytes},
+			{"kvstore_cache_hits_total", "counter", "Keys read from the cache.", cached.Hits},
+			{"kvstore_cache_misses_total", "counter", "Keys read from the backend.", cached.Misses},
+			{"kvstore_cache_evictions_total", "counter", "Values evicted from the cache to stay within its limits.", cached.Evictions},
+		})
+	}
+}
+
+// metric is a single value of GET /metrics.
+type metric struct {
+	name, kind, help string
+	value            int64
+}
+
+func writeMetrics(w io.Writer, metrics []metric) {
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+	}
+}
+
+// Cache puts a cache of the values in front of the backend of the server,
+// see cache.Client. Run the client returned to apply the change
+// notifications of the backend and, in write-behind mode, flush the writes.
+func (h *Http) Cache(settings cache.Settings) (*cache.Client, error) {
+	client, err := cache.NewClient(h.client, settings)
+	if err != nil {
+		return nil, err
+	}
+	h.client, h.cache = client, client
+	return client, nil
+}
+
+// errNotSupported is returned for operations the backend cannot do.
+var errNotSupported = errors.New("not supported by this backend")
+
+// errorStatus maps a client error to its HTTP status: 404 for a missing key,
+// index or namespace, 409 for one that already exists or a JSON patch that does not
+// apply, 422 for a counter that cannot be incremented or a value that is not
+// JSON, 507 for a write refused by a full store, 501 for an operation the
+// backend does not support and 500 for anything else.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, postgres.ErrNotFound), errors.Is(err, postgres.ErrIndexNotFound), errors.Is(err, postgres.ErrNamespaceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, postgres.ErrConflict), errors.Is(err, postgres.ErrIndexExists):
+		return http.StatusConflict
+	case errors.Is(err, postgres.ErrWrongType), errors.Is(err, database.ErrWrongType):
+		return http.StatusConflict
+	case errors.Is(err, postgres.ErrNotNumber), errors.Is(err, postgres.ErrOverflow), errors.Is(err, postgres.ErrNotJSON):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, postgres.ErrPatchFailed):
+		return http.StatusConflict
+	case errors.Is(err, database.ErrMemoryLimit):
+		return http.StatusInsufficientStorage
+	case errors.Is(err, errNotSupported):
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+func (h *Http) Run(addr string) error {
+	log.Printf("Server started on %s", addr)
+	err := http.ListenAndServe(addr, h.Handler())
 	if err != nil {
 		return fmt.Errorf("failed to run server: %w", err)
 	}
 	return nil
 }
 
-func (h *Http) routes() {
-	http.HandleFunc("/create", h.create)
-	http.HandleFunc("/update", h.update)
-	http.HandleFunc("/delete", h.delete)
-	http.HandleFunc("/get", h.get)
-	http.HandleFunc("/show", h.show)
+// Handler returns the routes of the server, so it can be mounted elsewhere
+// or served by httptest.
+func (h *Http) Handler() http.Handler {
+	mux := http.NewServeMux()
+	// Every route on keys is served for the store and, under
+	// /ns/{namespace}, for a namespace
+	for pattern, handler := range keyRoutes {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) { handler(h, w, r) })
+		mux.HandleFunc("/ns/{namespace}"+pattern, h.inNamespace(handler))
+	}
+	mux.HandleFunc("/ns", h.namespaces)
+	mux.HandleFunc("/ns/{namespace}", h.namespace)
+	mux.HandleFunc("/ns/{namespace}/stats", h.namespaceStats)
+	mux.HandleFunc("/metrics", h.metrics)
+	return logRequests(h.authenticate(mux))
 }
 
+// keyRoutes are the routes on the keys of the store, see Handler.
+var keyRoutes = map[string]func(*Http, http.ResponseWriter, *http.Request){
+	"/create":             (*Http).create,
+	"/update":             (*Http).update,
+	"/delete":             (*Http).delete,
+	"/get":                (*Http).get,
+	"/show":               (*Http).show,
+	"/stat":               (*Http).stat,
+	"/metadata":           (*Http).metadata,
+	"/history":            (*Http).history,
+	"/keys":               (*Http).keys,
+	"/keys/{key}":         (*Http).value,
+	"/keys/{key}/restore": (*Http).restore,
+	"/keys/{key}/incr":    (*Http).incr,
+	"/keys/{key}/json":    (*Http).document,
+	"/query":              (*Http).query,
+	"/indexes":            (*Http).indexes,
+	"/indexes/{name}":     (*Http).index,
+	"/batch":              (*Http).batch,
+}
@@ -3,12 +3,20 @@ package api
 import (
 	"bytes"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/inmemory"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/cache"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
 	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestHttp_Create(t *testing.T) {
@@ -54,6 +62,26 @@ func TestHttp_Create(t *testing.T) {
 			expectedCode: http.StatusInternalServerError,
 			expectedBody: "Failed to create row: db error",
 		},
+		{
+			name:        "Create Conflict",
+			method:      http.MethodPost,
+			requestBody: `{"Key":"Hello","Value":"World"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("CreatePostgresRow", "Hello", "World").Return(postgres.ErrConflict).Times(1)
+			},
+			expectedCode: http.StatusConflict,
+			expectedBody: "Failed to create row: key already exists",
+		},
+		{
+			name:        "Create Over Sorted Set",
+			method:      http.MethodPost,
+			requestBody: `{"Key":"Hello","Value":"World"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("CreatePostgresRow", "Hello", "World").Return(fmt.Errorf("%w: Hello holds a sorted set", postgres.ErrWrongType)).Times(1)
+			},
+			expectedCode: http.StatusConflict,
+			expectedBody: "Failed to create row: WRONGTYPE operation against a key holding the wrong kind of value: Hello holds a sorted set",
+		},
 		{
 			name:        "Create Success",
 			method:      http.MethodPost,
@@ -235,12 +263,15 @@ func TestHttp_Update(t *testing.T) {
 
 func TestHttp_Get(t *testing.T) {
 	tests := []struct {
-		name         string
-		method       string
-		requestBody  string
-		mockFunc     func(m *mocks.Client)
-		expectedCode int
-		expectedBody string
+		name        string
+		method      string
+		requestBody string
+		mockFunc    func(m *mocks.Client)
+		// ifModifiedSince is sent as the If-Modified-Since header
+		ifModifiedSince string
+		expectedCode    int
+		expectedBody    string
+		expectedHeader  http.Header
 	}{
 		{
 			name:         "Invalid json",
@@ -276,16 +307,82 @@ func TestHttp_Get(t *testing.T) {
 			expectedCode: http.StatusInternalServerError,
 			expectedBody: "Failed to get the row: db error",
 		},
+		{
+			name:        "Get Not Found",
+			method:      http.MethodGet,
+			requestBody: `{"Key":"Hello"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("GetPostgresRow", "Hello").Return("", postgres.ErrNotFound).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Failed to get the row: key not found",
+		},
 		{
 			name:        "Get Success",
 			method:      http.MethodGet,
 			requestBody: `{"Key":"Hello"}`,
 			mockFunc: func(m *mocks.Client) {
 				m.On("GetPostgresRow", "Hello").Return("World", nil).Times(1)
+				m.On("StatPostgresRow", "Hello").Return(postgres.Metadata{
+					CreatedAt:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+					UpdatedAt:   time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC),
+					ContentType: "text/plain",
+					Tags:        []string{"greeting", "en"},
+				}, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Key":"Hello","Value":"World"}`,
+			expectedHeader: http.Header{
+				"Last-Modified":        {"Mon, 03 Feb 2025 04:05:06 GMT"},
+				"X-Created-At":         {"Thu, 02 Jan 2025 03:04:05 GMT"},
+				"X-Value-Content-Type": {"text/plain"},
+				"X-Tags":               {"greeting,en"},
+			},
+		},
+		{
+			name:            "Get Not Modified",
+			method:          http.MethodGet,
+			requestBody:     `{"Key":"Hello"}`,
+			ifModifiedSince: "Mon, 03 Feb 2025 04:05:06 GMT",
+			mockFunc: func(m *mocks.Client) {
+				m.On("GetPostgresRow", "Hello").Return("World", nil).Times(1)
+				m.On("StatPostgresRow", "Hello").Return(postgres.Metadata{
+					UpdatedAt: time.Date(2025, 2, 3, 4, 5, 6, 500, time.UTC),
+				}, nil).Times(1)
+			},
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:        "Get Without Metadata",
+			method:      http.MethodGet,
+			requestBody: `{"Key":"Hello"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("GetPostgresRow", "Hello").Return("World", nil).Times(1)
+				m.On("StatPostgresRow", "Hello").Return(postgres.Metadata{}, errNotSupported).Times(1)
 			},
 			expectedCode: http.StatusOK,
 			expectedBody: `{"Key":"Hello","Value":"World"}`,
 		},
+		{
+			name:        "Get At",
+			method:      http.MethodGet,
+			requestBody: `{"Key":"Hello","At":"2025-01-02T03:04:05Z"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("GetAtPostgresRow", "Hello", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)).Return("Old World", nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Key":"Hello","Value":"Old World"}`,
+		},
+		{
+			name:        "Get At Not Found",
+			method:      http.MethodGet,
+			requestBody: `{"Key":"Hello","At":"2025-01-02T03:04:05Z"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("GetAtPostgresRow", "Hello", time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)).Return("", postgres.ErrNotFound).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Failed to get the row: key not found",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -295,21 +392,28 @@ func TestHttp_Get(t *testing.T) {
 			handler := &Http{client: mockClient}
 
 			req := httptest.NewRequest(tt.method, "/get", bytes.NewBufferString(tt.requestBody))
+			if tt.ifModifiedSince != "" {
+				req.Header.Set("If-Modified-Since", tt.ifModifiedSince)
+			}
 			rec := httptest.NewRecorder()
 
-			handler.show(rec, req)
+			handler.get(rec, req)
 			assert.Equal(t, rec.Code, tt.expectedCode)
 			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			for name, values := range tt.expectedHeader {
+				assert.Equal(t, values, rec.Header().Values(name), name)
+			}
 
 			mockClient.AssertExpectations(t)
 		})
 	}
 }
 
-func TestHttp_Show(t *testing.T) {
+func TestHttp_Stat(t *testing.T) {
 	tests := []struct {
 		name         string
 		method       string
+		requestBody  string
 		mockFunc     func(m *mocks.Client)
 		expectedCode int
 		expectedBody string
@@ -317,27 +421,309 @@ func TestHttp_Show(t *testing.T) {
 		{
 			name:         "Wrong Http Method",
 			method:       http.MethodPost,
+			requestBody:  `{"Key":"Hello"}`,
 			mockFunc:     func(m *mocks.Client) {},
 			expectedCode: http.StatusMethodNotAllowed,
 			expectedBody: "Method not allowed",
 		},
 		{
-			name:   "Show Failure",
+			name:         "Missing Key",
+			method:       http.MethodGet,
+			requestBody:  `{"Key":""}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Key cannot be empty",
+		},
+		{
+			name:        "Stat Not Found",
+			method:      http.MethodGet,
+			requestBody: `{"Key":"Hello"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "Hello").Return(postgres.Metadata{}, postgres.ErrNotFound).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Failed to stat the row: key not found",
+		},
+		{
+			name:        "Stat Not Supported",
+			method:      http.MethodGet,
+			requestBody: `{"Key":"Hello"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "Hello").Return(postgres.Metadata{}, errNotSupported).Times(1)
+			},
+			expectedCode: http.StatusNotImplemented,
+			expectedBody: "Failed to stat the row: not supported by this backend",
+		},
+		{
+			name:        "Stat Success",
+			method:      http.MethodGet,
+			requestBody: `{"Key":"Hello"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "Hello").Return(postgres.Metadata{
+					CreatedAt:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+					UpdatedAt:   time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC),
+					ContentType: "text/plain",
+					Tags:        []string{"greeting"},
+				}, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Key":"Hello","CreatedAt":"2025-01-02T03:04:05Z","UpdatedAt":"2025-02-03T04:05:06Z","ContentType":"text/plain","Tags":["greeting"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, "/stat", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			handler.stat(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_History(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		requestBody  string
+		mockFunc     func(m *mocks.Client)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Wrong Http Method",
+			method:       http.MethodPost,
+			requestBody:  `{"Key":"Hello"}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "Method not allowed",
+		},
+		{
+			name:         "Missing Key",
+			method:       http.MethodGet,
+			requestBody:  `{"Key":""}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Key cannot be empty",
+		},
+		{
+			name:         "Negative Limit",
+			method:       http.MethodGet,
+			requestBody:  `{"Key":"Hello","Limit":-1}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Limit cannot be negative",
+		},
+		{
+			name:        "History Not Found",
+			method:      http.MethodGet,
+			requestBody: `{"Key":"Hello"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("HistoryPostgresRows", "Hello", 0).Return(nil, postgres.ErrNotFound).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Failed to get the history: key not found",
+		},
+		{
+			name:        "History Success",
+			method:      http.MethodGet,
+			requestBody: `{"Key":"Hello","Limit":2}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("HistoryPostgresRows", "Hello", 2).Return([]postgres.Version{
+					{Version: 2, Value: "World", WrittenAt: time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)},
+					{Version: 1, Value: "Earth", WrittenAt: time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), ReplacedAt: time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)},
+				}, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Key":"Hello","Versions":[{"Version":2,"Value":"World","WrittenAt":"2025-02-03T04:05:06Z"},{"Version":1,"Value":"Earth","WrittenAt":"2025-01-02T03:04:05Z","ReplacedAt":"2025-02-03T04:05:06Z"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, "/history", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			handler.history(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_Restore(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		mockFunc     func(m *mocks.Client)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Wrong Http Method",
+			method:       http.MethodGet,
+			path:         "/keys/Hello/restore",
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "Method not allowed",
+		},
+		{
+			name:   "Restore Not Found",
+			method: http.MethodPost,
+			path:   "/keys/Hello/restore",
+			mockFunc: func(m *mocks.Client) {
+				m.On("UndeletePostgresRow", "Hello").Return(postgres.ErrNotFound).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Failed to restore the row: key not found",
+		},
+		{
+			name:   "Restore Not Supported",
+			method: http.MethodPost,
+			path:   "/keys/Hello/restore",
+			mockFunc: func(m *mocks.Client) {
+				m.On("UndeletePostgresRow", "Hello").Return(errNotSupported).Times(1)
+			},
+			expectedCode: http.StatusNotImplemented,
+			expectedBody: "Failed to restore the row: not supported by this backend",
+		},
+		{
+			name:   "Restore Escaped Key",
+			method: http.MethodPost,
+			path:   "/keys/team%2Fa/restore",
+			mockFunc: func(m *mocks.Client) {
+				m.On("UndeletePostgresRow", "team/a").Return(nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"message":"Row restored succesfully"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			handler.Handler().ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_Value(t *testing.T) {
+	binary := string([]byte{0xff, 0x00, 0xfe})
+	writeValue := func(args mock.Arguments) {
+		io.WriteString(args.Get(1).(io.Writer), binary)
+	}
+
+	tests := []struct {
+		name                string
+		method              string
+		body                string
+		mockFunc            func(m *mocks.Client)
+		expectedCode        int
+		expectedBody        string
+		expectedContentType string
+	}{
+		{
+			name:                "Wrong Http Method",
+			method:              http.MethodPost,
+			mockFunc:            func(m *mocks.Client) {},
+			expectedCode:        http.StatusMethodNotAllowed,
+			expectedBody:        "Method not allowed",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:   "Download Binary Value",
 			method: http.MethodGet,
 			mockFunc: func(m *mocks.Client) {
-				m.On("ShowPostgresRow").Return(nil, errors.New("db error")).Times(1)
+				m.On("StatPostgresRow", "image").Return(postgres.Metadata{ContentType: "image/png"}, nil).Times(1)
+				m.On("ReadStreamPostgresRow", "image", mock.Anything).Run(writeValue).Return(nil).Times(1)
 			},
-			expectedCode: http.StatusInternalServerError,
-			expectedBody: "Failed to show row: db error",
+			expectedCode:        http.StatusOK,
+			expectedBody:        binary,
+			expectedContentType: "application/octet-stream",
 		},
 		{
-			name:   "Show Success",
+			name:   "Download Without Metadata",
 			method: http.MethodGet,
 			mockFunc: func(m *mocks.Client) {
-				m.On("ShowPostgresRow").Return(map[string]string{"Hello": "World"}, nil).Times(1)
+				m.On("StatPostgresRow", "image").Return(postgres.Metadata{}, errNotSupported).Times(1)
+				m.On("ReadStreamPostgresRow", "image", mock.Anything).Run(writeValue).Return(nil).Times(1)
 			},
-			expectedCode: http.StatusOK,
-			expectedBody: `{"Hello":"World"}`,
+			expectedCode:        http.StatusOK,
+			expectedBody:        binary,
+			expectedContentType: "application/octet-stream",
+		},
+		{
+			name:   "Download Not Found",
+			method: http.MethodGet,
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "image").Return(postgres.Metadata{}, postgres.ErrNotFound).Times(1)
+			},
+			expectedCode:        http.StatusNotFound,
+			expectedBody:        "Failed to get the row: key not found",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:   "Download Failure",
+			method: http.MethodGet,
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "image").Return(postgres.Metadata{}, nil).Times(1)
+				m.On("ReadStreamPostgresRow", "image", mock.Anything).Return(errors.New("connection lost")).Times(1)
+			},
+			expectedCode:        http.StatusInternalServerError,
+			expectedBody:        "Failed to get the row: connection lost",
+			expectedContentType: "text/plain; charset=utf-8",
+		},
+		{
+			name:   "Upload Binary Value",
+			method: http.MethodPut,
+			body:   binary,
+			mockFunc: func(m *mocks.Client) {
+				m.On("WriteStreamPostgresRow", "image", mock.Anything).Return(func(key string, r io.Reader) error {
+					value, err := io.ReadAll(r)
+					assert.NoError(t, err)
+					assert.Equal(t, binary, string(value))
+					return nil
+				}).Times(1)
+			},
+			expectedCode:        http.StatusOK,
+			expectedBody:        `{"message":"Row saved succesfully"}`,
+			expectedContentType: "application/json",
+		},
+		{
+			name:   "Upload Failure",
+			method: http.MethodPut,
+			body:   binary,
+			mockFunc: func(m *mocks.Client) {
+				m.On("WriteStreamPostgresRow", "image", mock.Anything).Return(errors.New("disk full")).Times(1)
+			},
+			expectedCode:        http.StatusInternalServerError,
+			expectedBody:        "Failed to set the row: disk full",
+			expectedContentType: "text/plain; charset=utf-8",
 		},
 	}
 	for _, tt := range tests {
@@ -347,14 +733,964 @@ func TestHttp_Show(t *testing.T) {
 
 			handler := &Http{client: mockClient}
 
-			req := httptest.NewRequest(tt.method, "/show", nil)
+			req := httptest.NewRequest(tt.method, "/keys/image", bytes.NewBufferString(tt.body))
 			rec := httptest.NewRecorder()
 
-			handler.show(rec, req)
-			assert.Equal(t, rec.Code, tt.expectedCode)
+			handler.Handler().ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
 			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+			assert.Equal(t, tt.expectedContentType, rec.Header().Get("Content-Type"))
 
 			mockClient.AssertExpectations(t)
 		})
 	}
 }
+
+func TestHttp_ValueInmemory(t *testing.T) {
+	db, err := inmemory.NewInmemory()
+	assert.NoError(t, err)
+	handler := NewDatabaseHttp(db).Handler()
+
+	binary := string([]byte{0x89, 'P', 'N', 'G', 0x00, 0xff})
+	req := httptest.NewRequest(http.MethodPut, "/keys/logo", bytes.NewBufferString(binary))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/keys/logo", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, binary, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/keys/missing", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHttp_Incr(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		body         string
+		mockFunc     func(m *mocks.Client)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Wrong Http Method",
+			method:       http.MethodGet,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "Method not allowed",
+		},
+		{
+			name:         "Invalid Body",
+			method:       http.MethodPost,
+			body:         `{"By": 1.5}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Invalid incr body request",
+		},
+		{
+			name:         "Both Increments",
+			method:       http.MethodPost,
+			body:         `{"By": 1, "ByFloat": 1.5}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "By and ByFloat cannot be used together",
+		},
+		{
+			name:   "Empty Body Adds One",
+			method: http.MethodPost,
+			mockFunc: func(m *mocks.Client) {
+				m.On("IncrByPostgresRow", "hits", int64(1)).Return(int64(42), nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Key":"hits","Value":42}`,
+		},
+		{
+			name:   "Decrement",
+			method: http.MethodPost,
+			body:   `{"By": -3}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("IncrByPostgresRow", "hits", int64(-3)).Return(int64(-3), nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Key":"hits","Value":-3}`,
+		},
+		{
+			name:   "Float Increment",
+			method: http.MethodPost,
+			body:   `{"ByFloat": 0.5}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("IncrByFloatPostgresRow", "hits", 0.5).Return(10.5, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Key":"hits","Value":10.5}`,
+		},
+		{
+			name:   "Not A Number",
+			method: http.MethodPost,
+			mockFunc: func(m *mocks.Client) {
+				m.On("IncrByPostgresRow", "hits", int64(1)).Return(int64(0), postgres.ErrNotNumber).Times(1)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: "Failed to increment the row: value is not a number",
+		},
+		{
+			name:   "Overflow",
+			method: http.MethodPost,
+			mockFunc: func(m *mocks.Client) {
+				m.On("IncrByPostgresRow", "hits", int64(1)).Return(int64(0), postgres.ErrOverflow).Times(1)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: "Failed to increment the row: increment would overflow",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, "/keys/hits/incr", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.Handler().ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_Document(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		contentType  string
+		body         string
+		mockFunc     func(m *mocks.Client)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Wrong Http Method",
+			method:       http.MethodPost,
+			target:       "/keys/user/json",
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "Method not allowed",
+		},
+		{
+			name:         "Invalid Path",
+			method:       http.MethodGet,
+			target:       "/keys/user/json?path=name",
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "invalid JSON path",
+		},
+		{
+			name:   "Get Path",
+			method: http.MethodGet,
+			target: "/keys/user/json?path=$.name",
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONGetPostgresRow", "user", "$.name").Return(`"Alice"`, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"Alice"`,
+		},
+		{
+			name:   "Missing Path",
+			method: http.MethodGet,
+			target: "/keys/user/json?path=$.email",
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONGetPostgresRow", "user", "$.email").Return("", postgres.ErrNotFound).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Failed to get the JSON: key not found",
+		},
+		{
+			name:   "Not JSON",
+			method: http.MethodGet,
+			target: "/keys/user/json",
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONGetPostgresRow", "user", "$").Return("", postgres.ErrNotJSON).Times(1)
+			},
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: "value is not a JSON document",
+		},
+		{
+			name:         "Set Invalid Body",
+			method:       http.MethodPut,
+			target:       "/keys/user/json?path=$.name",
+			body:         "Alice",
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Invalid JSON body",
+		},
+		{
+			name:   "Set Path",
+			method: http.MethodPut,
+			target: "/keys/user/json?path=$.name",
+			body:   `"Bob"`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONSetPostgresRow", "user", "$.name", `"Bob"`).Return(nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"message":"JSON saved succesfully"}`,
+		},
+		{
+			name:         "Patch Without Content Type",
+			method:       http.MethodPatch,
+			target:       "/keys/user/json",
+			body:         `{"name":"Bob"}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusUnsupportedMediaType,
+			expectedBody: "Content-Type must be",
+		},
+		{
+			name:         "Patch A Path",
+			method:       http.MethodPatch,
+			target:       "/keys/user/json?path=$.name",
+			contentType:  "application/merge-patch+json",
+			body:         `{"name":"Bob"}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "A patch applies to the whole document",
+		},
+		{
+			name:        "Merge Patch",
+			method:      http.MethodPatch,
+			target:      "/keys/user/json",
+			contentType: "application/merge-patch+json",
+			body:        `{"name":"Bob"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONMergePostgresRow", "user", `{"name":"Bob"}`).Return(`{"name":"Bob"}`, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"name":"Bob"}`,
+		},
+		{
+			name:        "JSON Patch Failed",
+			method:      http.MethodPatch,
+			target:      "/keys/user/json",
+			contentType: "application/json-patch+json",
+			body:        `[{"op":"remove","path":"/email"}]`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONPatchPostgresRow", "user", `[{"op":"remove","path":"/email"}]`).Return("", postgres.ErrPatchFailed).Times(1)
+			},
+			expectedCode: http.StatusConflict,
+			expectedBody: "Failed to patch the JSON: JSON patch cannot be applied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.Handler().ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_DocumentInmemory(t *testing.T) {
+	db, err := inmemory.NewInmemory()
+	assert.NoError(t, err)
+	handler := NewDatabaseHttp(db).Handler()
+
+	serve := func(method, target, contentType, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", contentType)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPut, "/keys/user/json", "application/json", `{"name":"Alice","tags":["a"]}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(http.MethodPatch, "/keys/user/json", "application/merge-patch+json", `{"status":"active","tags":null}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"name":"Alice","status":"active"}`, rec.Body.String())
+
+	rec = serve(http.MethodPatch, "/keys/user/json", "application/json-patch+json", `[{"op":"test","path":"/name","value":"Bob"}]`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = serve(http.MethodGet, "/keys/user/json?path=$.status", "", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `"active"`, rec.Body.String())
+
+	rec = serve(http.MethodGet, "/keys/user/json?path=$.email", "", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHttp_Query(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		mockFunc     func(m *mocks.Client)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Wrong Http Method",
+			method:       http.MethodPost,
+			target:       "/query?index=status&value=active",
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "Method not allowed",
+		},
+		{
+			name:         "Missing Value",
+			method:       http.MethodGet,
+			target:       "/query?index=status",
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Index and value are required",
+		},
+		{
+			name:   "Missing Index",
+			method: http.MethodGet,
+			target: "/query?index=status&value=active",
+			mockFunc: func(m *mocks.Client) {
+				m.On("QueryPostgresRows", "status", "active").Return(nil, postgres.ErrIndexNotFound).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Failed to query the index: index not found",
+		},
+		{
+			name:   "Query Success",
+			method: http.MethodGet,
+			target: "/query?index=status&value=active",
+			mockFunc: func(m *mocks.Client) {
+				m.On("QueryPostgresRows", "status", "active").Return([]string{"user:1", "user:3"}, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Index":"status","Value":"active","Keys":["user:1","user:3"]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			rec := httptest.NewRecorder()
+
+			handler.Handler().ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_Index(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		mockFunc     func(m *mocks.Client)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Invalid Name",
+			method:       http.MethodPut,
+			target:       "/indexes/Status",
+			body:         `{"Path": "$.status"}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "invalid index name",
+		},
+		{
+			name:   "Taken",
+			method: http.MethodPut,
+			target: "/indexes/status",
+			body:   `{"Path": "$.status"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("CreateIndexPostgresRow", "status", "$.status").Return(postgres.ErrIndexExists).Times(1)
+			},
+			expectedCode: http.StatusConflict,
+			expectedBody: "Failed to create the index: index already exists",
+		},
+		{
+			name:   "Create Success",
+			method: http.MethodPut,
+			target: "/indexes/status",
+			body:   `{"Path": "$.status"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("CreateIndexPostgresRow", "status", "$.status").Return(nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "Index created succesfully",
+		},
+		{
+			name:   "Drop Missing",
+			method: http.MethodDelete,
+			target: "/indexes/status",
+			mockFunc: func(m *mocks.Client) {
+				m.On("DropIndexPostgresRow", "status").Return(postgres.ErrIndexNotFound).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Failed to drop the index: index not found",
+		},
+		{
+			name:   "List",
+			method: http.MethodGet,
+			target: "/indexes",
+			mockFunc: func(m *mocks.Client) {
+				m.On("IndexesPostgresRows").Return([]postgres.Index{{Name: "status", Path: "$.status"}}, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Indexes":[{"Name":"status","Path":"$.status"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.Handler().ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_Metadata(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		requestBody  string
+		mockFunc     func(m *mocks.Client)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Wrong Http Method",
+			method:       http.MethodGet,
+			requestBody:  `{"Key":"Hello"}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "Method not allowed",
+		},
+		{
+			name:        "Metadata Not Found",
+			method:      http.MethodPut,
+			requestBody: `{"Key":"Hello","ContentType":"text/plain"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("SetMetadataPostgresRow", "Hello", "text/plain", []string(nil)).Return(postgres.ErrNotFound).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Failed to set the metadata: key not found",
+		},
+		{
+			name:        "Metadata Success",
+			method:      http.MethodPut,
+			requestBody: `{"Key":"Hello","ContentType":"text/plain","Tags":["greeting","en"]}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("SetMetadataPostgresRow", "Hello", "text/plain", []string{"greeting", "en"}).Return(nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "Metadata updated succesfully",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, "/metadata", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			handler.metadata(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_Show(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		mockFunc     func(m *mocks.Client)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Wrong Http Method",
+			method:       http.MethodPost,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "Method not allowed",
+		},
+		{
+			name:   "Show Failure",
+			method: http.MethodGet,
+			mockFunc: func(m *mocks.Client) {
+				m.On("ShowPostgresRow").Return(nil, errors.New("db error")).Times(1)
+			},
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: "Failed to show row: db error",
+		},
+		{
+			name:   "Show Success",
+			method: http.MethodGet,
+			mockFunc: func(m *mocks.Client) {
+				m.On("ShowPostgresRow").Return(map[string]string{"Hello": "World"}, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Hello":"World"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, "/show", nil)
+			rec := httptest.NewRecorder()
+
+			handler.show(rec, req)
+			assert.Equal(t, rec.Code, tt.expectedCode)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_Batch(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		requestBody  string
+		mockFunc     func(m *mocks.Client)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Wrong Http Method",
+			method:       http.MethodGet,
+			requestBody:  `{"Operations":[{"Op":"get","Key":"Hello"}]}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusMethodNotAllowed,
+			expectedBody: "Method not allowed",
+		},
+		{
+			name:         "Invalid JSON",
+			method:       http.MethodPost,
+			requestBody:  `invalid-json`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Invalid batch body request",
+		},
+		{
+			name:         "No Operations",
+			method:       http.MethodPost,
+			requestBody:  `{"Operations":[]}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Operations cannot be empty",
+		},
+		{
+			name:        "Get Batch",
+			method:      http.MethodPost,
+			requestBody: `{"Operations":[{"Op":"get","Key":"Hello"},{"Op":"get","Key":"Missing"}]}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("MultiGetPostgresRows", []string{"Hello", "Missing"}).Return(map[string]string{"Hello": "World"}, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Results":[{"Op":"get","Key":"Hello","Value":"World","Status":"ok"},{"Op":"get","Key":"Missing","Status":"not found"}]}`,
+		},
+		{
+			name:        "Mixed Batch Keeps Order",
+			method:      http.MethodPost,
+			requestBody: `{"Operations":[{"Op":"set","Key":"a","Value":"1"},{"Op":"set","Key":"b","Value":"2"},{"Op":"delete","Key":"a"},{"Op":"delete","Key":"c"}]}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("MultiSetPostgresRows", map[string]string{"a": "1", "b": "2"}).Return(nil).Times(1)
+				m.On("MultiDeletePostgresRows", []string{"a", "c"}).Return([]string{"a"}, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Results":[{"Op":"set","Key":"a","Status":"ok"},{"Op":"set","Key":"b","Status":"ok"},{"Op":"delete","Key":"a","Status":"ok"},{"Op":"delete","Key":"c","Status":"not found"}]}`,
+		},
+		{
+			name:         "Empty Key And Unknown Op",
+			method:       http.MethodPost,
+			requestBody:  `{"Operations":[{"Op":"get","Key":""},{"Op":"rename","Key":"a"}]}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Results":[{"Op":"get","Key":"","Status":"error","Error":"Key cannot be empty"},{"Op":"rename","Key":"a","Status":"error","Error":"Unknown operation \"rename\""}]}`,
+		},
+		{
+			name:        "Batch Failure - db error",
+			method:      http.MethodPost,
+			requestBody: `{"Operations":[{"Op":"delete","Key":"a"}]}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("MultiDeletePostgresRows", []string{"a"}).Return(nil, errors.New("db error")).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"Status":"error","Error":"Failed to delete the rows: db error"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, "/batch", bytes.NewBufferString(tt.requestBody))
+			rec := httptest.NewRecorder()
+
+			handler.batch(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_Authenticate(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		mockFunc      func(m *mocks.Client)
+		expectedCode  int
+	}{
+		{
+			name:         "No token configured",
+			token:        "",
+			mockFunc:     func(m *mocks.Client) { m.On("ShowPostgresRow").Return(map[string]string{}, nil).Times(1) },
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Missing token",
+			token:        "secret",
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:          "Wrong token",
+			token:         "secret",
+			authorization: "Bearer guess",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedCode:  http.StatusUnauthorized,
+		},
+		{
+			name:          "Valid token",
+			token:         "secret",
+			authorization: "Bearer secret",
+			mockFunc:      func(m *mocks.Client) { m.On("ShowPostgresRow").Return(map[string]string{}, nil).Times(1) },
+			expectedCode:  http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient, Token: tt.token}
+
+			req := httptest.NewRequest(http.MethodGet, "/show", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+
+			handler.Handler().ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+		})
+	}
+}
+
+func TestHttp_Namespace(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		target       string
+		body         string
+		mockFunc     func(m *mocks.Client)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Invalid Name",
+			method:       http.MethodGet,
+			target:       "/ns/Billing/keys/invoice",
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "invalid namespace name",
+		},
+		{
+			name:   "Get In Namespace",
+			method: http.MethodGet,
+			target: "/ns/billing/get",
+			body:   `{"Key": "invoice"}`,
+			mockFunc: func(m *mocks.Client) {
+				namespace := &mocks.Client{}
+				namespace.On("GetPostgresRow", "invoice").Return("paid", nil).Times(1)
+				namespace.On("StatPostgresRow", "invoice").Return(postgres.Metadata{}, nil).Times(1)
+				m.On("NamespacesPostgresRows").Return([]postgres.Namespace{{Name: "billing"}}, nil).Times(1)
+				m.On("Namespace", "billing").Return(namespace, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"Value":"paid"`,
+		},
+		{
+			name:   "Get In Missing Namespace",
+			method: http.MethodGet,
+			target: "/ns/search/get",
+			body:   `{"Key": "invoice"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("NamespacesPostgresRows").Return([]postgres.Namespace{{Name: "billing"}}, nil).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "namespace not found: search",
+		},
+		{
+			name:   "Delete In Missing Namespace",
+			method: http.MethodDelete,
+			target: "/ns/search/delete",
+			body:   `{"Key": "invoice"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("NamespacesPostgresRows").Return([]postgres.Namespace{}, nil).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "namespace not found: search",
+		},
+		{
+			name:   "Create In Missing Namespace",
+			method: http.MethodPost,
+			target: "/ns/search/create",
+			body:   `{"Key": "invoice", "Value": "paid"}`,
+			mockFunc: func(m *mocks.Client) {
+				namespace := &mocks.Client{}
+				namespace.On("CreatePostgresRow", "invoice", "paid").Return(nil).Times(1)
+				m.On("Namespace", "search").Return(namespace, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "succesfully",
+		},
+		{
+			name:         "Invalid TTL",
+			method:       http.MethodPut,
+			target:       "/ns/billing",
+			body:         `{"DefaultTTL": "a day"}`,
+			mockFunc:     func(m *mocks.Client) {},
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "Invalid DefaultTTL",
+		},
+		{
+			name:   "Set TTL",
+			method: http.MethodPut,
+			target: "/ns/billing",
+			body:   `{"DefaultTTL": "24h"}`,
+			mockFunc: func(m *mocks.Client) {
+				m.On("SetNamespaceTTLPostgresRow", "billing", 24*time.Hour).Return(nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: "Namespace saved succesfully",
+		},
+		{
+			name:   "Drop Missing",
+			method: http.MethodDelete,
+			target: "/ns/billing",
+			mockFunc: func(m *mocks.Client) {
+				m.On("DropNamespacePostgresRow", "billing").Return(postgres.ErrNamespaceNotFound).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "Failed to drop the namespace: namespace not found",
+		},
+		{
+			name:   "List",
+			method: http.MethodGet,
+			target: "/ns",
+			mockFunc: func(m *mocks.Client) {
+				m.On("NamespacesPostgresRows").Return([]postgres.Namespace{{Name: "billing", DefaultTTL: time.Hour}, {Name: "search"}}, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Namespaces":[{"Name":"billing","DefaultTTL":"1h0m0s"},{"Name":"search"}]}`,
+		},
+		{
+			name:   "Stats",
+			method: http.MethodGet,
+			target: "/ns/billing/stats",
+			mockFunc: func(m *mocks.Client) {
+				namespace := &mocks.Client{}
+				namespace.On("KeysPostgresRows", "").Return([]string{"invoice:1", "invoice:2"}, nil).Times(1)
+				m.On("NamespacesPostgresRows").Return([]postgres.Namespace{{Name: "billing", DefaultTTL: time.Hour}}, nil).Times(1)
+				m.On("Namespace", "billing").Return(namespace, nil).Times(1)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"Name":"billing","DefaultTTL":"1h0m0s","Keys":2}`,
+		},
+		{
+			name:   "Stats Missing",
+			method: http.MethodGet,
+			target: "/ns/search/stats",
+			mockFunc: func(m *mocks.Client) {
+				m.On("NamespacesPostgresRows").Return([]postgres.Namespace{{Name: "billing"}}, nil).Times(1)
+			},
+			expectedCode: http.StatusNotFound,
+			expectedBody: "namespace not found: search",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			handler := &Http{client: mockClient}
+
+			req := httptest.NewRequest(tt.method, tt.target, bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+
+			handler.Handler().ServeHTTP(rec, req)
+			assert.Equal(t, tt.expectedCode, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.expectedBody)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHttp_NamespaceInmemory(t *testing.T) {
+	db, err := inmemory.NewInmemory()
+	assert.NoError(t, err)
+	server := NewDatabaseHttp(db)
+	handler := server.Handler()
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPut, "/ns/billing/keys/invoice", "paid")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(http.MethodGet, "/ns/billing/keys/invoice", "")
+	assert.Equal(t, "paid", rec.Body.String())
+	rec = serve(http.MethodGet, "/keys/invoice", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code, "the key is only in the namespace")
+
+	rec = serve(http.MethodGet, "/ns/billing/keys", "")
+	assert.Equal(t, `{"Keys":["invoice"]}`+"\n", rec.Body.String())
+	rec = serve(http.MethodPut, "/ns/billing", `{"DefaultTTL": "1h"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(http.MethodGet, "/ns/billing/stats", "")
+	assert.Equal(t, `{"Name":"billing","DefaultTTL":"1h0m0s","Keys":1}`+"\n", rec.Body.String())
+
+	expired, err := server.ExpireNamespaces(time.Now().Add(30 * time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, expired)
+	expired, err = server.ExpireNamespaces(time.Now().Add(2 * time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired, "the key outlived the TTL of its namespace")
+
+	rec = serve(http.MethodDelete, "/ns/billing", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(http.MethodGet, "/ns", "")
+	assert.Equal(t, `{"Namespaces":[]}`+"\n", rec.Body.String())
+}
+
+func TestHttp_Metrics(t *testing.T) {
+	db, err := inmemory.NewInmemory()
+	assert.NoError(t, err)
+	db.SetLimits(database.Limits{MaxKeys: 1})
+	handler := NewDatabaseHttp(db).Handler()
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, bytes.NewBufferString(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodPut, "/keys/first", "1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(http.MethodPut, "/keys/second", "2")
+	assert.Equal(t, http.StatusInsufficientStorage, rec.Code, "the store is full")
+
+	rec = serve(http.MethodGet, "/metrics", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "kvstore_keys 1\n")
+	assert.Contains(t, rec.Body.String(), "kvstore_max_keys 1\n")
+	assert.Contains(t, rec.Body.String(), "kvstore_rejected_writes_total 1\n")
+	assert.Contains(t, rec.Body.String(), `kvstore_evictions_total{policy="reject-writes"} 0`+"\n")
+
+	// The Postgres client does not account for memory
+	mockClient := mocks.NewClient(t)
+	rec = httptest.NewRecorder()
+	(&Http{client: mockClient}).Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
+
+func TestHttp_CacheMetrics(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	mockClient.On("GetPostgresRow", "first").Return("1", nil).Times(1)
+	mockClient.On("StatPostgresRow", "first").Return(postgres.Metadata{Version: 1}, nil).Times(1)
+	server := &Http{client: mockClient}
+	_, err := server.Cache(cache.Settings{Mode: cache.ReadThrough})
+	assert.NoError(t, err)
+	handler := server.Handler()
+
+	// The second get is answered from the cache
+	for range 2 {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/get", bytes.NewBufferString(`{"key": "first"}`)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"Key": "first", "Value": "1"}`, rec.Body.String())
+	}
+
+	// The Postgres client does not account for memory, but the cache does
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "kvstore_cache_keys 2\n")
+	assert.Contains(t, rec.Body.String(), "kvstore_cache_hits_total 1\n")
+	assert.Contains(t, rec.Body.String(), "kvstore_cache_misses_total 1\n")
+	assert.NotContains(t, rec.Body.String(), "kvstore_keys")
+}
@@ -0,0 +1,462 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/inmemory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name           string
+		args           []string
+		output         string
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "Get value",
+			args:           []string{"get", "name"},
+			output:         OutputText,
+			expectedCode:   ExitOK,
+			expectedStdout: "Alice\n",
+		},
+		{
+			name:           "Get missing key",
+			args:           []string{"get", "age"},
+			output:         OutputText,
+			expectedCode:   ExitNotFound,
+			expectedStderr: "key not found: age\n",
+		},
+		{
+			name:           "Get as JSON",
+			args:           []string{"GET", "--output", "json", "name"},
+			output:         OutputText,
+			expectedCode:   ExitOK,
+			expectedStdout: "{\"command\":\"get\",\"status\":\"ok\",\"key\":\"name\",\"value\":\"Alice\"}\n",
+		},
+		{
+			name:           "Missing key as JSON",
+			args:           []string{"get", "age"},
+			output:         OutputJSON,
+			expectedCode:   ExitNotFound,
+			expectedStdout: "{\"command\":\"get\",\"status\":\"not found\",\"key\":\"age\",\"error\":\"key not found\"}\n",
+		},
+		{
+			name:           "Set value",
+			args:           []string{"set", "age", "19"},
+			output:         OutputText,
+			expectedCode:   ExitOK,
+			expectedStdout: "OK\n",
+		},
+		{
+			name:           "Create existing key",
+			args:           []string{"create", "name", "Bob"},
+			output:         OutputText,
+			expectedCode:   ExitError,
+			expectedStderr: "key already exists: name\n",
+		},
+		{
+			name:           "Update missing key",
+			args:           []string{"update", "age", "19"},
+			output:         OutputText,
+			expectedCode:   ExitNotFound,
+			expectedStderr: "key not found: age\n",
+		},
+		{
+			name:           "Delete missing key",
+			args:           []string{"delete", "age"},
+			output:         OutputText,
+			expectedCode:   ExitNotFound,
+			expectedStderr: "key not found: age\n",
+		},
+		{
+			name:           "List with prefix",
+			args:           []string{"list", "--prefix", "user:"},
+			output:         OutputText,
+			expectedCode:   ExitOK,
+			expectedStdout: "user:1\nuser:2\n",
+		},
+		{
+			name:           "List without matches as JSON",
+			args:           []string{"--output=json", "list", "--prefix=order:"},
+			output:         OutputText,
+			expectedCode:   ExitOK,
+			expectedStdout: "{\"command\":\"list\",\"status\":\"ok\",\"keys\":[]}\n",
+		},
+		{
+			name:           "Value looking like the output flag",
+			args:           []string{"set", "age", "--output"},
+			output:         OutputText,
+			expectedCode:   ExitOK,
+			expectedStdout: "OK\n",
+		},
+		{
+			name:           "Output flag after the arguments",
+			args:           []string{"get", "name", "--output", "json"},
+			output:         OutputText,
+			expectedCode:   ExitError,
+			expectedStderr: "usage: get KEY [--at TIME]\n",
+		},
+		{
+			name:           "Show pairs",
+			args:           []string{"show", "--prefix", "user:"},
+			output:         OutputText,
+			expectedCode:   ExitOK,
+			expectedStdout: "user:1=a\nuser:2=b\n",
+		},
+		{
+			name:           "Wrong number of arguments",
+			args:           []string{"set", "age"},
+			output:         OutputText,
+			expectedCode:   ExitError,
+			expectedStderr: "usage: set KEY VALUE\n",
+		},
+		{
+			name:           "Unknown command",
+			args:           []string{"rename", "name"},
+			output:         OutputText,
+			expectedCode:   ExitError,
+			expectedStderr: "unknown command \"rename\"\n",
+		},
+		{
+			name:           "Unknown output",
+			args:           []string{"get", "name"},
+			output:         "yaml",
+			expectedCode:   ExitError,
+			expectedStderr: "unknown output \"yaml\", should be either 'text' or 'json'\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := inmemory.NewInmemory()
+			_ = db.MultiSet(map[string]string{"name": "Alice", "user:1": "a", "user:2": "b"})
+
+			var stdout, stderr bytes.Buffer
+			code := Run(db, tt.args, tt.output, &stdout, &stderr)
+
+			assert.Equal(t, tt.expectedCode, code)
+			assert.Equal(t, tt.expectedStdout, stdout.String())
+			assert.Equal(t, tt.expectedStderr, stderr.String())
+		})
+	}
+}
+
+func TestExecute_WritesThrough(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+
+	result := Execute(db, []string{"create", "name", "Alice"})
+	assert.Equal(t, StatusOK, result.Status)
+
+	result = Execute(db, []string{"update", "name", "Bob"})
+	assert.Equal(t, StatusOK, result.Status)
+
+	result = Execute(db, []string{"get", "name"})
+	assert.Equal(t, "Bob", *result.Value)
+
+	result = Execute(db, []string{"del", "name"})
+	assert.Equal(t, StatusOK, result.Status)
+
+	result = Execute(db, []string{"get", "name"})
+	assert.Equal(t, StatusNotFound, result.Status)
+}
+
+func TestExecute_Metadata(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+	_ = db.MultiSet(map[string]string{"name": "Alice"})
+
+	result := Execute(db, []string{"meta", "name", "--content-type", "text/plain", "user", "admin"})
+	assert.Equal(t, StatusOK, result.Status)
+
+	result = Execute(db, []string{"stat", "name"})
+	assert.Equal(t, StatusOK, result.Status)
+	assert.Equal(t, "text/plain", result.Metadata.ContentType)
+	assert.Equal(t, []string{"user", "admin"}, result.Metadata.Tags)
+	assert.False(t, result.Metadata.UpdatedAt.IsZero())
+
+	var stdout, stderr bytes.Buffer
+	Print(result, &stdout, &stderr)
+	assert.Contains(t, stdout.String(), "content_type=text/plain\ntags=user,admin\n")
+
+	result = Execute(db, []string{"stat", "age"})
+	assert.Equal(t, StatusNotFound, result.Status)
+
+	result = Execute(db, []string{"meta", "--content-type", "text/plain"})
+	assert.Equal(t, "usage: meta KEY [--content-type TYPE] [TAG...]", result.Error)
+}
+
+func TestExecute_History(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+	_ = db.MultiSet(map[string]string{"name": "Alice"})
+	_ = db.MultiSet(map[string]string{"name": "Bob"})
+
+	result := Execute(db, []string{"history", "name", "--limit", "1"})
+	assert.Equal(t, StatusOK, result.Status)
+	assert.Len(t, result.Versions, 1)
+	assert.Equal(t, "Bob", result.Versions[0].Value)
+
+	result = Execute(db, []string{"history", "name"})
+	assert.Equal(t, StatusOK, result.Status)
+	assert.Len(t, result.Versions, 2)
+
+	var stdout, stderr bytes.Buffer
+	Print(result, &stdout, &stderr)
+	assert.Contains(t, stdout.String(), "\tcurrent\tBob\n")
+	assert.Contains(t, stdout.String(), "\treplaced\tAlice\n")
+
+	writtenAt := result.Versions[1].WrittenAt.Format(time.RFC3339Nano)
+	result = Execute(db, []string{"get", "name", "--at", writtenAt})
+	assert.Equal(t, StatusOK, result.Status)
+	assert.Equal(t, "Alice", *result.Value)
+
+	result = Execute(db, []string{"get", "name", "--at", "2000-01-01T00:00:00Z"})
+	assert.Equal(t, StatusNotFound, result.Status)
+
+	result = Execute(db, []string{"get", "name", "--at", "yesterday"})
+	assert.Contains(t, result.Error, `invalid time "yesterday"`)
+
+	result = Execute(db, []string{"history", "age"})
+	assert.Equal(t, StatusNotFound, result.Status)
+
+	result = Execute(db, []string{"history", "name", "--limit", "-1"})
+	assert.Equal(t, "usage: history KEY [--limit N]", result.Error)
+}
+
+func TestExecute_Undelete(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+	_ = db.MultiSet(map[string]string{"name": "Alice"})
+
+	result := Execute(db, []string{"delete", "name"})
+	assert.Equal(t, StatusOK, result.Status)
+	result = Execute(db, []string{"undelete", "name"})
+	assert.Equal(t, StatusNotFound, result.Status, "deletes are permanent by default")
+
+	db.SoftDelete.Enabled = true
+	_ = db.MultiSet(map[string]string{"name": "Bob"})
+	Execute(db, []string{"delete", "name"})
+	result = Execute(db, []string{"get", "name"})
+	assert.Equal(t, StatusNotFound, result.Status)
+
+	result = Execute(db, []string{"UNDELETE", "name"})
+	assert.Equal(t, StatusOK, result.Status)
+	result = Execute(db, []string{"get", "name"})
+	assert.Equal(t, "Bob", *result.Value)
+
+	result = Execute(db, []string{"restore"})
+	assert.Equal(t, "usage: restore KEY", result.Error)
+}
+
+func TestExecute_Keys(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		expectedKeys  []string
+		expectedError string
+	}{
+		{name: "Prefix", pattern: "user:*", expectedKeys: []string{"user:1", "user:10", "user:2"}},
+		{name: "Star matches slashes", pattern: "*/*", expectedKeys: []string{"team/a"}},
+		{name: "Single character", pattern: "user:?", expectedKeys: []string{"user:1", "user:2"}},
+		{name: "Character class", pattern: "user:[!1]", expectedKeys: []string{"user:2"}},
+		{name: "Escaped wildcard", pattern: `odd\*`, expectedKeys: []string{"odd*"}},
+		{name: "Exact key", pattern: "name", expectedKeys: []string{"name"}},
+		{name: "No match", pattern: "order:*", expectedKeys: []string{}},
+		{name: "Invalid pattern", pattern: "user:[", expectedError: `invalid pattern "user:["`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := inmemory.NewInmemory()
+			_ = db.MultiSet(map[string]string{"name": "a", "user:1": "b", "user:2": "c", "user:10": "d", "team/a": "e", "odd*": "f", "oddity": "g"})
+
+			result := Execute(db, []string{"KEYS", tt.pattern})
+
+			assert.Equal(t, tt.expectedError, result.Error)
+			assert.Equal(t, tt.expectedKeys, result.Keys)
+		})
+	}
+}
+
+func TestExecute_UploadDownload(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+	dir := t.TempDir()
+	binary := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "logo.png"), binary, 0644))
+
+	result := Execute(db, []string{"upload", "logo", filepath.Join(dir, "logo.png")})
+	assert.Equal(t, StatusOK, result.Status)
+	result = Execute(db, []string{"get", "logo"})
+	assert.Equal(t, string(binary), *result.Value)
+
+	result = Execute(db, []string{"download", "logo", filepath.Join(dir, "copy.png")})
+	assert.Equal(t, StatusOK, result.Status)
+	data, err := os.ReadFile(filepath.Join(dir, "copy.png"))
+	assert.NoError(t, err)
+	assert.Equal(t, binary, data)
+
+	result = Execute(db, []string{"download", "missing", filepath.Join(dir, "missing.png")})
+	assert.Equal(t, StatusNotFound, result.Status)
+	_, err = os.Stat(filepath.Join(dir, "missing.png"))
+	assert.True(t, os.IsNotExist(err), "the file of a missing key is removed")
+
+	result = Execute(db, []string{"upload", "logo", filepath.Join(dir, "none.png")})
+	assert.Equal(t, StatusError, result.Status)
+
+	result = Execute(db, []string{"download", "logo"})
+	assert.Equal(t, "usage: download KEY FILE", result.Error)
+}
+
+func TestExecute_Counters(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+
+	result := Execute(db, []string{"incr", "hits"})
+	assert.Equal(t, "1", *result.Value)
+	result = Execute(db, []string{"INCRBY", "hits", "10"})
+	assert.Equal(t, "11", *result.Value)
+	result = Execute(db, []string{"decr", "hits"})
+	assert.Equal(t, "10", *result.Value)
+	result = Execute(db, []string{"incrbyfloat", "hits", "0.5"})
+	assert.Equal(t, "10.5", *result.Value)
+
+	result = Execute(db, []string{"incr", "hits"})
+	assert.Equal(t, StatusError, result.Status)
+	assert.Contains(t, result.Error, "value is not a number")
+
+	result = Execute(db, []string{"incrby", "hits", "ten"})
+	assert.Equal(t, `invalid increment "ten", expected an integer`, result.Error)
+	result = Execute(db, []string{"incrby", "hits"})
+	assert.Equal(t, "usage: incrby KEY N", result.Error)
+}
+
+func TestExecute_Types(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+
+	result := Execute(db, []string{"rpush", "queue", "a", "b", "c"})
+	assert.Equal(t, "3", *result.Value)
+	result = Execute(db, []string{"LPOP", "queue"})
+	assert.Equal(t, "a", *result.Value)
+	result = Execute(db, []string{"lrange", "queue", "0", "-1"})
+	assert.Equal(t, []string{"b", "c"}, result.Values)
+	result = Execute(db, []string{"lrange", "queue", "0", "last"})
+	assert.Equal(t, `invalid stop "last", expected an integer`, result.Error)
+
+	Execute(db, []string{"sadd", "a", "x", "y"})
+	Execute(db, []string{"sadd", "b", "y", "z"})
+	result = Execute(db, []string{"sinter", "a", "b"})
+	assert.Equal(t, []string{"y"}, result.Values)
+	result = Execute(db, []string{"sunion", "a", "b"})
+	assert.Equal(t, []string{"x", "y", "z"}, result.Values)
+
+	result = Execute(db, []string{"hset", "user", "name", "Alice"})
+	assert.Equal(t, "1", *result.Value)
+	result = Execute(db, []string{"hgetall", "user"})
+	assert.Equal(t, map[string]string{"name": "Alice"}, result.Pairs)
+	result = Execute(db, []string{"hget", "user", "email"})
+	assert.Equal(t, StatusNotFound, result.Status)
+
+	result = Execute(db, []string{"type", "user"})
+	assert.Equal(t, "hash", *result.Value)
+	result = Execute(db, []string{"get", "user"})
+	assert.Equal(t, StatusNotFound, result.Status, "get only reads strings")
+	result = Execute(db, []string{"sadd", "user", "x"})
+	assert.Equal(t, StatusError, result.Status)
+	assert.Contains(t, result.Error, "WRONGTYPE")
+
+	result = Execute(db, []string{"hset", "user", "name"})
+	assert.Equal(t, "usage: hset KEY FIELD VALUE", result.Error)
+
+	var out bytes.Buffer
+	Print(Execute(db, []string{"smembers", "a"}), &out, &bytes.Buffer{})
+	assert.Equal(t, "x\ny\n", out.String())
+}
+
+func TestExecute_SortedSets(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+
+	result := Execute(db, []string{"zadd", "board", "30", "alice"})
+	assert.Equal(t, "1", *result.Value)
+	Execute(db, []string{"zadd", "board", "10", "bob"})
+	result = Execute(db, []string{"zincrby", "board", "2.5", "bob"})
+	assert.Equal(t, "12.5", *result.Value)
+	result = Execute(db, []string{"zrank", "board", "alice"})
+	assert.Equal(t, "1", *result.Value)
+	result = Execute(db, []string{"zscore", "board", "erin"})
+	assert.Equal(t, StatusNotFound, result.Status)
+
+	result = Execute(db, []string{"zrangebyscore", "board", "-inf", "20"})
+	assert.Equal(t, []database.ScoredMember{{Member: "bob", Score: 12.5}}, result.Members)
+	result = Execute(db, []string{"zadd", "board", "high", "carol"})
+	assert.Equal(t, `invalid score "high", expected a decimal number`, result.Error)
+
+	var out bytes.Buffer
+	Print(Execute(db, []string{"zrange", "board", "0", "-1"}), &out, &bytes.Buffer{})
+	assert.Equal(t, "12.5\tbob\n30\talice\n", out.String())
+}
+
+func TestExecute_JSON(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+
+	result := Execute(db, []string{"json.set", "user", "$", `{"name":"Alice"}`})
+	assert.Equal(t, StatusOK, result.Status)
+	result = Execute(db, []string{"JSON.SET", "user", "$.status", `"active"`})
+	assert.Equal(t, StatusOK, result.Status)
+
+	result = Execute(db, []string{"json.get", "user", "$.status"})
+	assert.Equal(t, `"active"`, *result.Value)
+	result = Execute(db, []string{"json.get", "user", "$.email"})
+	assert.Equal(t, StatusNotFound, result.Status)
+
+	result = Execute(db, []string{"json.merge", "user", `{"status":null}`})
+	assert.Equal(t, `{"name":"Alice"}`, *result.Value)
+	result = Execute(db, []string{"json.patch", "user", `[{"op":"add","path":"/tags","value":["a"]}]`})
+	assert.Equal(t, `{"name":"Alice","tags":["a"]}`, *result.Value)
+	result = Execute(db, []string{"json.patch", "user", `[{"op":"remove","path":"/email"}]`})
+	assert.Equal(t, StatusError, result.Status)
+	assert.Contains(t, result.Error, "JSON patch cannot be applied")
+
+	result = Execute(db, []string{"json.set", "user", "$.name"})
+	assert.Equal(t, "usage: json.set KEY PATH VALUE", result.Error)
+}
+
+func TestExecute_Indexes(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+	_ = db.MultiSet(map[string]string{"user:1": `{"status":"active"}`, "user:2": `{"status":"new"}`})
+
+	result := Execute(db, []string{"createindex", "status", "$.status"})
+	assert.Equal(t, StatusOK, result.Status)
+	result = Execute(db, []string{"indexes"})
+	assert.Equal(t, map[string]string{"status": "$.status"}, result.Pairs)
+
+	result = Execute(db, []string{"query", "status", "active"})
+	assert.Equal(t, []string{"user:1"}, result.Values)
+	result = Execute(db, []string{"query", "email", "x"})
+	assert.Equal(t, StatusNotFound, result.Status)
+
+	result = Execute(db, []string{"dropindex", "status"})
+	assert.Equal(t, StatusOK, result.Status)
+	result = Execute(db, []string{"query", "status"})
+	assert.Equal(t, "usage: query INDEX VALUE", result.Error)
+}
+
+func TestExecute_Namespaces(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+
+	result := Execute(db, []string{"createnamespace", "billing", "24h"})
+	assert.Equal(t, StatusOK, result.Status)
+	result = Execute(db, []string{"createnamespace", "search"})
+	assert.Equal(t, StatusOK, result.Status)
+	result = Execute(db, []string{"createnamespace", "search", "soon"})
+	assert.Equal(t, `invalid TTL "soon", expected a duration like 24h`, result.Error)
+	result = Execute(db, []string{"namespaces"})
+	assert.Equal(t, map[string]string{"billing": "24h0m0s", "search": "0s"}, result.Pairs)
+
+	result = Execute(db, []string{"dropnamespace", "search"})
+	assert.Equal(t, StatusOK, result.Status)
+	result = Execute(db, []string{"dropnamespace", "search"})
+	assert.Equal(t, StatusNotFound, result.Status)
+}
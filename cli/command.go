@@ -0,0 +1,523 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// Statuses reported for every command.
+const (
+	StatusOK       = "ok"
+	StatusNotFound = "not found"
+	StatusError    = "error"
+)
+
+// Exit codes of one-shot commands.
+const (
+	ExitOK       = 0
+	ExitNotFound = 1
+	ExitError    = 2
+)
+
+// Result is the outcome of a single command. Only the fields that make
+// sense for the command are filled in.
+type Result struct {
+	Command  string                  `json:"command"`
+	Status   string                  `json:"status"`
+	Key      string                  `json:"key,omitempty"`
+	Value    *string                 `json:"value,omitempty"`
+	Keys     []string                `json:"keys,omitzero"`
+	Values   []string                `json:"values,omitzero"`
+	Members  []database.ScoredMember `json:"members,omitzero"`
+	Pairs    map[string]string       `json:"pairs,omitzero"`
+	Metadata *database.Metadata      `json:"metadata,omitempty"`
+	Versions []database.Version      `json:"versions,omitzero"`
+	Error    string                  `json:"error,omitempty"`
+}
+
+// ExitCode maps the status of the result to the process exit code.
+func (r Result) ExitCode() int {
+	switch r.Status {
+	case StatusOK:
+		return ExitOK
+	case StatusNotFound:
+		return ExitNotFound
+	}
+	return ExitError
+}
+
+const Usage = `Commands:
+  get KEY [--at TIME] print the value of KEY, or the one it had at an RFC 3339 TIME
+  set KEY VALUE       store VALUE under KEY, creating or overwriting it
+  create KEY VALUE    store VALUE under KEY, failing if KEY exists
+  update KEY VALUE    overwrite the value of an existing KEY
+  delete KEY          remove KEY
+  undelete KEY        restore KEY after a delete, when the backend keeps tombstones
+  list [--prefix P]   list the keys, optionally only those starting with P
+  show [--prefix P]   list the key value pairs
+  keys PATTERN        list the keys matching a glob pattern like user:*
+  stat KEY            print when KEY was created and updated, its content type and tags
+  meta KEY [--content-type T] [TAG...]
+                      set the content type and tags of KEY
+  history KEY [--limit N]
+                      list the previous values of KEY, newest first
+  incr KEY, decr KEY  add 1 to or subtract 1 from the integer value of KEY, 0 when missing
+  incrby KEY N        add the integer N to the value of KEY
+  incrbyfloat KEY N   add the decimal N to the value of KEY
+  upload KEY FILE     store the content of FILE under KEY as is, for binary values
+  download KEY FILE   write the value of KEY to FILE as is
+  type KEY            print the type of KEY: string, list, set, hash, zset or none
+  lpush KEY VALUE..., rpush KEY VALUE...
+                      add values to the head or the tail of the list KEY
+  lpop KEY, rpop KEY  remove and print the first or last element of the list KEY
+  lrange KEY START STOP
+                      print the elements of the list KEY from START to STOP, -1 being the last
+  sadd KEY MEMBER..., srem KEY MEMBER...
+                      add members to or remove them from the set KEY
+  smembers KEY        print the members of the set KEY
+  sinter KEY..., sunion KEY...
+                      print the members found in all or any of the sets
+  hset KEY FIELD VALUE
+                      set a field of the hash KEY
+  hget KEY FIELD      print a field of the hash KEY
+  hdel KEY FIELD...   remove fields from the hash KEY
+  hgetall KEY         print every field of the hash KEY
+  zadd KEY SCORE MEMBER, zincrby KEY DELTA MEMBER
+                      set or add to the score of a member of the sorted set KEY
+  zscore KEY MEMBER, zrank KEY MEMBER
+                      print the score or the rank, from 0 for the lowest score, of a member
+  zrem KEY MEMBER..., zcard KEY
+                      remove members from or count the members of the sorted set KEY
+  zrange KEY START STOP
+                      print the members ranked from START to STOP with their scores
+  zrangebyscore KEY MIN MAX
+                      print the members scored from MIN to MAX, which take -inf and +inf
+  json.get KEY [PATH] print the JSON at PATH, like $.user.name, in the value of KEY, $ by default
+  json.set KEY PATH VALUE
+                      set the JSON at PATH, whose parent must exist; $ sets the whole value
+  json.merge KEY PATCH
+                      apply an RFC 7396 merge patch to the JSON value of KEY
+  json.patch KEY PATCH
+                      apply an RFC 6902 JSON patch, every operation or none, to the value of KEY
+  createindex NAME PATH
+                      index the JSON values by the value at PATH, like $.status
+  dropindex NAME      remove the index NAME
+  indexes             list the indexes with their paths
+  query INDEX VALUE   list the keys whose value at the path of INDEX is VALUE
+  createnamespace NAME [TTL]
+                      create the namespace NAME, or set the TTL, like 24h, of its keys
+  dropnamespace NAME  remove the namespace NAME with all its keys
+  namespaces          list the namespaces with the TTL of their keys`
+
+// Execute runs one command, given as its name followed by its arguments,
+// against db. Commands are case-insensitive.
+func Execute(db database.Database, args []string) Result {
+	if len(args) == 0 {
+		return failure("", "missing command")
+	}
+
+	name := strings.ToLower(args[0])
+	args = args[1:]
+
+	switch name {
+	case "get":
+		flags := flag.NewFlagSet(name, flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		at := flags.String("at", "", "time of the value to read, in RFC 3339")
+		if len(args) == 0 || strings.HasPrefix(args[0], "-") || flags.Parse(args[1:]) != nil || flags.NArg() > 0 {
+			return failure(name, "usage: get KEY [--at TIME]")
+		}
+		if *at == "" {
+			return get(db, args[0])
+		}
+		when, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			return failure(name, fmt.Sprintf("invalid time %q, expected RFC 3339 like 2025-01-02T15:04:05Z", *at))
+		}
+		return getAt(db, args[0], when)
+
+	case "set":
+		if len(args) != 2 {
+			return failure(name, "usage: set KEY VALUE")
+		}
+		return set(db, name, args[0], args[1])
+
+	case "create", "update":
+		if len(args) != 2 {
+			return failure(name, fmt.Sprintf("usage: %s KEY VALUE", name))
+		}
+
+		values, err := db.MultiGet([]string{args[0]})
+		if err != nil {
+			return failure(name, err.Error())
+		}
+		_, exists := values[args[0]]
+
+		if name == "create" && exists {
+			return Result{Command: name, Status: StatusError, Key: args[0], Error: "key already exists"}
+		}
+		if name == "update" && !exists {
+			return Result{Command: name, Status: StatusNotFound, Key: args[0], Error: "key not found"}
+		}
+		return set(db, name, args[0], args[1])
+
+	case "delete", "del":
+		if len(args) != 1 {
+			return failure(name, "usage: delete KEY")
+		}
+		deleted, err := db.MultiDelete([]string{args[0]})
+		if err != nil {
+			return failure(name, err.Error())
+		}
+		if len(deleted) == 0 {
+			return Result{Command: name, Status: StatusNotFound, Key: args[0], Error: "key not found"}
+		}
+		return Result{Command: name, Status: StatusOK, Key: args[0]}
+
+	case "undelete", "restore":
+		if len(args) != 1 {
+			return failure(name, fmt.Sprintf("usage: %s KEY", name))
+		}
+		return undelete(db, name, args[0])
+
+	case "keys":
+		if len(args) != 1 {
+			return failure(name, "usage: keys PATTERN")
+		}
+		return keys(db, args[0])
+
+	case "stat":
+		if len(args) != 1 {
+			return failure(name, "usage: stat KEY")
+		}
+		return stat(db, args[0])
+
+	case "meta":
+		flags := flag.NewFlagSet(name, flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		contentType := flags.String("content-type", "", "content type of the value")
+		if len(args) == 0 || strings.HasPrefix(args[0], "-") || flags.Parse(args[1:]) != nil {
+			return failure(name, "usage: meta KEY [--content-type TYPE] [TAG...]")
+		}
+		return setMetadata(db, args[0], *contentType, flags.Args())
+
+	case "history":
+		flags := flag.NewFlagSet(name, flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		limit := flags.Int("limit", 0, "number of versions to list, 0 for all")
+		if len(args) == 0 || strings.HasPrefix(args[0], "-") || flags.Parse(args[1:]) != nil || flags.NArg() > 0 || *limit < 0 {
+			return failure(name, "usage: history KEY [--limit N]")
+		}
+		return history(db, args[0], *limit)
+
+	case "incr", "decr":
+		if len(args) != 1 {
+			return failure(name, fmt.Sprintf("usage: %s KEY", name))
+		}
+		delta := "1"
+		if name == "decr" {
+			delta = "-1"
+		}
+		return increment(db, name, args[0], delta)
+
+	case "incrby", "incrbyfloat":
+		if len(args) != 2 {
+			return failure(name, fmt.Sprintf("usage: %s KEY N", name))
+		}
+		return increment(db, name, args[0], args[1])
+
+	case "upload", "download":
+		if len(args) != 2 {
+			return failure(name, fmt.Sprintf("usage: %s KEY FILE", name))
+		}
+		if name == "upload" {
+			return upload(db, args[0], args[1])
+		}
+		return download(db, args[0], args[1])
+
+	case "lpush", "rpush", "lpop", "rpop", "lrange",
+		"sadd", "srem", "smembers", "sinter", "sunion",
+		"hset", "hget", "hdel", "hgetall",
+		"zadd", "zincrby", "zscore", "zrank", "zrem", "zcard", "zrange", "zrangebyscore", "type":
+		return composite(db, name, args)
+
+	case "json.get", "json.set", "json.merge", "json.patch":
+		return document(db, name, args)
+
+	case "createindex", "dropindex", "indexes", "query":
+		return indexCommand(db, name, args)
+
+	case "createnamespace", "dropnamespace", "namespaces":
+		return namespaceCommand(db, name, args)
+
+	case "list", "show":
+		flags := flag.NewFlagSet(name, flag.ContinueOnError)
+		flags.SetOutput(io.Discard)
+		prefix := flags.String("prefix", "", "only keys starting with this prefix")
+		if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+			return failure(name, fmt.Sprintf("usage: %s [--prefix PREFIX]", name))
+		}
+
+		keys, err := db.Keys(*prefix)
+		if err != nil {
+			return failure(name, err.Error())
+		}
+		if name == "list" {
+			return Result{Command: name, Status: StatusOK, Keys: keys}
+		}
+
+		pairs, err := db.MultiGet(keys)
+		if err != nil {
+			return failure(name, err.Error())
+		}
+		return Result{Command: name, Status: StatusOK, Pairs: pairs}
+	}
+
+	return failure(name, fmt.Sprintf("unknown command %q", name))
+}
+
+func get(db database.Database, key string) Result {
+	values, err := db.MultiGet([]string{key})
+	if err != nil {
+		return failure("get", err.Error())
+	}
+
+	value, ok := values[key]
+	if !ok {
+		return Result{Command: "get", Status: StatusNotFound, Key: key, Error: "key not found"}
+	}
+	return Result{Command: "get", Status: StatusOK, Key: key, Value: &value}
+}
+
+func getAt(db database.Database, key string, at time.Time) Result {
+	store, ok := db.(database.HistoryStore)
+	if !ok {
+		return failure("get", "the backend does not keep history")
+	}
+
+	value, err := store.GetAt(key, at)
+	if errors.Is(err, database.ErrNotFound) {
+		return Result{Command: "get", Status: StatusNotFound, Key: key, Error: "key not found"}
+	} else if err != nil {
+		return failure("get", err.Error())
+	}
+	return Result{Command: "get", Status: StatusOK, Key: key, Value: &value}
+}
+
+func history(db database.Database, key string, limit int) Result {
+	store, ok := db.(database.HistoryStore)
+	if !ok {
+		return failure("history", "the backend does not keep history")
+	}
+
+	versions, err := store.History(key, limit)
+	if errors.Is(err, database.ErrNotFound) {
+		return Result{Command: "history", Status: StatusNotFound, Key: key, Error: "key not found"}
+	} else if err != nil {
+		return failure("history", err.Error())
+	}
+	return Result{Command: "history", Status: StatusOK, Key: key, Versions: versions}
+}
+
+func undelete(db database.Database, name, key string) Result {
+	store, ok := db.(database.Undeleter)
+	if !ok {
+		return failure(name, "the backend does not keep deleted keys")
+	}
+
+	err := store.Undelete(key)
+	if errors.Is(err, database.ErrNotFound) {
+		return Result{Command: name, Status: StatusNotFound, Key: key, Error: "no deleted key"}
+	} else if err != nil {
+		return failure(name, err.Error())
+	}
+	return Result{Command: name, Status: StatusOK, Key: key}
+}
+
+// increment adds delta, an integer or a decimal for incrbyfloat, to the
+// value of key atomically.
+func increment(db database.Database, name, key, delta string) Result {
+	counter, ok := db.(database.Counter)
+	if !ok {
+		return failure(name, "the backend does not support counters")
+	}
+
+	var value string
+	if name == "incrbyfloat" {
+		by, err := strconv.ParseFloat(delta, 64)
+		if err != nil {
+			return failure(name, fmt.Sprintf("invalid increment %q, expected a decimal number", delta))
+		}
+		result, err := counter.IncrByFloat(key, by)
+		if err != nil {
+			return failure(name, err.Error())
+		}
+		value = strconv.FormatFloat(result, 'f', -1, 64)
+	} else {
+		by, err := strconv.ParseInt(delta, 10, 64)
+		if err != nil {
+			return failure(name, fmt.Sprintf("invalid increment %q, expected an integer", delta))
+		}
+		result, err := counter.IncrBy(key, by)
+		if err != nil {
+			return failure(name, err.Error())
+		}
+		value = strconv.FormatInt(result, 10)
+	}
+	return Result{Command: name, Status: StatusOK, Key: key, Value: &value}
+}
+
+// upload stores a file as a value without going through a string argument,
+// so binary content is kept and large files are streamed by the backends
+// that can.
+func upload(db database.Database, key, path string) Result {
+	file, err := os.Open(path)
+	if err != nil {
+		return failure("upload", err.Error())
+	}
+	defer file.Close()
+
+	if err := database.WriteValue(db, key, file); err != nil {
+		return failure("upload", err.Error())
+	}
+	return Result{Command: "upload", Status: StatusOK, Key: key}
+}
+
+// download writes a value to a file as is. The file is removed again when
+// the value cannot be read.
+func download(db database.Database, key, path string) Result {
+	file, err := os.Create(path)
+	if err != nil {
+		return failure("download", err.Error())
+	}
+
+	err = database.ReadValue(db, key, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	if errors.Is(err, database.ErrNotFound) {
+		return Result{Command: "download", Status: StatusNotFound, Key: key, Error: "key not found"}
+	} else if err != nil {
+		return failure("download", err.Error())
+	}
+	return Result{Command: "download", Status: StatusOK, Key: key}
+}
+
+func stat(db database.Database, key string) Result {
+	store, ok := db.(database.MetadataStore)
+	if !ok {
+		return failure("stat", "the backend does not keep metadata")
+	}
+
+	meta, err := store.Stat(key)
+	if errors.Is(err, database.ErrNotFound) {
+		return Result{Command: "stat", Status: StatusNotFound, Key: key, Error: "key not found"}
+	} else if err != nil {
+		return failure("stat", err.Error())
+	}
+	return Result{Command: "stat", Status: StatusOK, Key: key, Metadata: &meta}
+}
+
+func setMetadata(db database.Database, key, contentType string, tags []string) Result {
+	store, ok := db.(database.MetadataStore)
+	if !ok {
+		return failure("meta", "the backend does not keep metadata")
+	}
+
+	err := store.SetMetadata(key, contentType, tags)
+	if errors.Is(err, database.ErrNotFound) {
+		return Result{Command: "meta", Status: StatusNotFound, Key: key, Error: "key not found"}
+	} else if err != nil {
+		return failure("meta", err.Error())
+	}
+	return Result{Command: "meta", Status: StatusOK, Key: key}
+}
+
+// keys lists the keys matching a glob pattern. Only the part before the
+// first wildcard is sent to the database, the rest is matched here.
+func keys(db database.Database, pattern string) Result {
+	match, err := compileGlob(pattern)
+	if err != nil {
+		return failure("keys", fmt.Sprintf("invalid pattern %q", pattern))
+	}
+
+	prefix := pattern
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		prefix = pattern[:i]
+	}
+
+	candidates, err := db.Keys(prefix)
+	if err != nil {
+		return failure("keys", err.Error())
+	}
+
+	matched := []string{}
+	for _, key := range candidates {
+		if match.MatchString(key) {
+			matched = append(matched, key)
+		}
+	}
+	return Result{Command: "keys", Status: StatusOK, Keys: matched}
+}
+
+// compileGlob turns a Redis style glob into a regular expression: * matches
+// any run of characters (slashes included), ? a single one, [abc] a class
+// and a backslash escapes the next character.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated [")
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			// Byte by byte is fine: QuoteMeta only touches ASCII
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+func set(db database.Database, name, key, value string) Result {
+	if err := db.MultiSet(map[string]string{key: value}); err != nil {
+		return failure(name, err.Error())
+	}
+	return Result{Command: name, Status: StatusOK, Key: key}
+}
+
+func failure(name, message string) Result {
+	return Result{Command: name, Status: StatusError, Error: message}
+}
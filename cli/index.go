@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// indexCommand runs the commands on secondary indexes. indexes returns the
+// index names with their paths as pairs, query the keys found as values.
+func indexCommand(db database.Database, name string, args []string) Result {
+	indexer, ok := db.(database.Indexer)
+	if !ok {
+		return failure(name, "the backend does not support indexes")
+	}
+
+	result := Result{Command: name, Status: StatusOK}
+	var err error
+	switch name {
+	case "createindex":
+		if len(args) != 2 {
+			return failure(name, "usage: createindex NAME PATH")
+		}
+		err = indexer.CreateIndex(args[0], args[1])
+	case "dropindex":
+		if len(args) != 1 {
+			return failure(name, "usage: dropindex NAME")
+		}
+		err = indexer.DropIndex(args[0])
+	case "indexes":
+		if len(args) != 0 {
+			return failure(name, "usage: indexes")
+		}
+		var indexes []database.Index
+		indexes, err = indexer.Indexes()
+		result.Pairs = make(map[string]string, len(indexes))
+		for _, index := range indexes {
+			result.Pairs[index.Name] = index.Path
+		}
+	case "query":
+		if len(args) != 2 {
+			return failure(name, "usage: query INDEX VALUE")
+		}
+		result.Values, err = indexer.Query(args[0], args[1])
+	}
+
+	if errors.Is(err, database.ErrIndexNotFound) {
+		return Result{Command: name, Status: StatusNotFound, Error: err.Error()}
+	} else if err != nil {
+		return failure(name, err.Error())
+	}
+	return result
+}
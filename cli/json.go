@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// document runs the json.* commands, which read and change parts of a JSON
+// value. json.get prints the JSON at a path, json.merge and json.patch the
+// new document.
+func document(db database.Database, name string, args []string) Result {
+	documents, ok := db.(database.JSONStore)
+	if !ok {
+		return failure(name, "the backend does not support JSON documents")
+	}
+
+	var (
+		value string
+		err   error
+	)
+	switch name {
+	case "json.get":
+		if len(args) < 1 || len(args) > 2 {
+			return failure(name, "usage: json.get KEY [PATH]")
+		}
+		path := "$"
+		if len(args) == 2 {
+			path = args[1]
+		}
+		value, err = documents.JSONGet(args[0], path)
+	case "json.set":
+		if len(args) != 3 {
+			return failure(name, "usage: json.set KEY PATH VALUE")
+		}
+		err = documents.JSONSet(args[0], args[1], args[2])
+	case "json.merge", "json.patch":
+		if len(args) != 2 {
+			return failure(name, "usage: "+name+" KEY PATCH")
+		}
+		apply := documents.JSONPatch
+		if name == "json.merge" {
+			apply = documents.JSONMerge
+		}
+		value, err = apply(args[0], args[1])
+	}
+
+	if errors.Is(err, database.ErrNotFound) {
+		return Result{Command: name, Status: StatusNotFound, Key: args[0], Error: err.Error()}
+	} else if err != nil {
+		return failure(name, err.Error())
+	}
+	result := Result{Command: name, Status: StatusOK, Key: args[0]}
+	if name != "json.set" {
+		result.Value = &value
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// namespaceCommand runs the commands on namespaces. namespaces returns the
+// names with their default TTL as pairs, 0s when the keys do not expire.
+func namespaceCommand(db database.Database, name string, args []string) Result {
+	store, ok := db.(database.NamespaceStore)
+	if !ok {
+		return failure(name, "the backend does not support namespaces")
+	}
+
+	result := Result{Command: name, Status: StatusOK}
+	var err error
+	switch name {
+	case "createnamespace":
+		if len(args) < 1 || len(args) > 2 {
+			return failure(name, "usage: createnamespace NAME [TTL]")
+		}
+		var ttl time.Duration
+		if len(args) == 2 {
+			ttl, err = time.ParseDuration(args[1])
+			if err != nil || ttl < 0 {
+				return failure(name, fmt.Sprintf("invalid TTL %q, expected a duration like 24h", args[1]))
+			}
+		}
+		err = store.SetNamespaceTTL(args[0], ttl)
+	case "dropnamespace":
+		if len(args) != 1 {
+			return failure(name, "usage: dropnamespace NAME")
+		}
+		err = store.DropNamespace(args[0])
+	case "namespaces":
+		if len(args) != 0 {
+			return failure(name, "usage: namespaces")
+		}
+		var namespaces []database.Namespace
+		namespaces, err = store.Namespaces()
+		result.Pairs = make(map[string]string, len(namespaces))
+		for _, namespace := range namespaces {
+			result.Pairs[namespace.Name] = namespace.DefaultTTL.String()
+		}
+	}
+
+	if errors.Is(err, database.ErrNamespaceNotFound) {
+		return Result{Command: name, Status: StatusNotFound, Error: err.Error()}
+	} else if err != nil {
+		return failure(name, err.Error())
+	}
+	return result
+}
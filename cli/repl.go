@@ -0,0 +1,170 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// commands offered by tab completion, in the order they are listed.
+var commands = []string{"GET", "SET", "DEL", "KEYS", "CREATE", "UPDATE", "DELETE", "UNDELETE", "LIST", "SHOW", "STAT", "META", "HISTORY", "INCR", "DECR", "INCRBY", "INCRBYFLOAT", "UPLOAD", "DOWNLOAD", "TYPE", "LPUSH", "RPUSH", "LPOP", "RPOP", "LRANGE", "SADD", "SREM", "SMEMBERS", "SINTER", "SUNION", "HSET", "HGET", "HDEL", "HGETALL", "ZADD", "ZINCRBY", "ZSCORE", "ZRANK", "ZREM", "ZCARD", "ZRANGE", "ZRANGEBYSCORE", "JSON.GET", "JSON.SET", "JSON.MERGE", "JSON.PATCH", "CREATEINDEX", "DROPINDEX", "INDEXES", "QUERY", "CREATENAMESPACE", "DROPNAMESPACE", "NAMESPACES", "HELP", "EXIT"}
+
+type REPLConfig struct {
+	Prompt string
+	// HistoryFile keeps the command history between sessions. Empty
+	// disables persistence.
+	HistoryFile string
+
+	// Stdin, Stdout and Stderr default to the process streams.
+	Stdin  io.ReadCloser
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// DefaultHistoryFile is ~/.kvstore_history, or empty when there is no home
+// directory.
+func DefaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kvstore_history")
+}
+
+// RunREPL reads commands like `SET name "Alice Smith"` line by line and runs
+// them against db until EXIT, Ctrl+D or the end of the input. A failing
+// command prints its error and the session carries on.
+func RunREPL(db database.Database, cfg REPLConfig) error {
+	if cfg.Prompt == "" {
+		cfg.Prompt = "> "
+	}
+	if cfg.Stdout == nil {
+		cfg.Stdout = os.Stdout
+	}
+	if cfg.Stderr == nil {
+		cfg.Stderr = os.Stderr
+	}
+
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:            cfg.Prompt,
+		HistoryFile:       cfg.HistoryFile,
+		HistorySearchFold: true,
+		AutoComplete:      &completer{db: db},
+		InterruptPrompt:   "^C",
+		EOFPrompt:         "exit",
+		Stdin:             cfg.Stdin,
+		Stdout:            cfg.Stdout,
+		Stderr:            cfg.Stderr,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start the prompt: %w", err)
+	}
+	defer rl.Close()
+
+	fmt.Fprintln(cfg.Stdout, `Type HELP for the list of commands and EXIT to quit.`)
+
+	for {
+		line, err := rl.Readline()
+		if errors.Is(err, readline.ErrInterrupt) {
+			// Ctrl+C drops the current line, like a shell
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the command: %w", err)
+		}
+
+		args, err := Split(line)
+		if err != nil {
+			fmt.Fprintf(cfg.Stderr, "(error) %v\n", err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		switch strings.ToLower(args[0]) {
+		case "exit", "quit":
+			return nil
+		case "help":
+			fmt.Fprintln(cfg.Stdout, Usage)
+			continue
+		}
+
+		result := Execute(db, args)
+		if result.Status == StatusError {
+			fmt.Fprintf(cfg.Stderr, "(error) %s\n", result.Error)
+			continue
+		}
+		if result.Status == StatusNotFound {
+			fmt.Fprintln(cfg.Stdout, "(nil)")
+			continue
+		}
+		Print(result, cfg.Stdout, cfg.Stderr)
+	}
+}
+
+// completer completes command names in the first word and existing keys in
+// the second one.
+type completer struct {
+	db database.Database
+}
+
+func (c *completer) Do(line []rune, pos int) ([][]rune, int) {
+	before := string(line[:pos])
+
+	words := strings.Fields(before)
+	if len(words) == 0 || !strings.HasSuffix(before, words[len(words)-1]) {
+		// The cursor is after a space: a new, empty word starts here
+		words = append(words, "")
+	}
+	current := words[len(words)-1]
+
+	var candidates []string
+	switch len(words) {
+	case 1:
+		lower := strings.ToLower(current) == current && current != ""
+		for _, command := range commands {
+			if lower {
+				command = strings.ToLower(command)
+			}
+			candidates = append(candidates, command)
+		}
+	case 2:
+		switch strings.ToLower(words[0]) {
+		case "help", "exit", "quit", "list", "show":
+			return nil, 0
+		}
+		keys, err := c.db.Keys(current)
+		if err != nil {
+			return nil, 0
+		}
+		candidates = keys
+	default:
+		return nil, 0
+	}
+
+	// Commands match regardless of case, keys exactly
+	match := strings.HasPrefix
+	if len(words) == 1 {
+		match = func(candidate, prefix string) bool {
+			return strings.HasPrefix(strings.ToUpper(candidate), strings.ToUpper(prefix))
+		}
+	}
+
+	var suggestions [][]rune
+	for _, candidate := range candidates {
+		if match(candidate, current) {
+			suggestions = append(suggestions, []rune(candidate[len(current):]+" "))
+		}
+	}
+	return suggestions, len([]rune(current))
+}
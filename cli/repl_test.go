@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/imsumedhaa/In-memory-database/inmemory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name          string
+		line          string
+		expectedWords []string
+		expectedError string
+	}{
+		{name: "Plain words", line: "SET name  Alice", expectedWords: []string{"SET", "name", "Alice"}},
+		{name: "Double quotes", line: `SET name "Alice Smith"`, expectedWords: []string{"SET", "name", "Alice Smith"}},
+		{name: "Single quotes are literal", line: `SET path 'C:\temp dir'`, expectedWords: []string{"SET", "path", `C:\temp dir`}},
+		{name: "Escaped quote", line: `SET quote "say \"hi\""`, expectedWords: []string{"SET", "quote", `say "hi"`}},
+		{name: "Empty quoted word", line: `SET name ""`, expectedWords: []string{"SET", "name", ""}},
+		{name: "Quotes inside a word", line: `SET na"me x"`, expectedWords: []string{"SET", "name x"}},
+		{name: "Blank line", line: "   ", expectedWords: nil},
+		{name: "Unterminated quote", line: `SET name "Alice`, expectedError: "unterminated quote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			words, err := Split(tt.line)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedWords, words)
+		})
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for _, word := range []string{"plain", "with space", `back\slash`, `"quoted"`, "it's", ""} {
+		words, err := Split("SET " + Quote(word))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"SET", word}, words)
+	}
+}
+
+func TestCompleter(t *testing.T) {
+	tests := []struct {
+		name                string
+		line                string
+		expectedSuggestions []string
+		expectedLength      int
+	}{
+		{name: "Command in upper case", line: "DE", expectedSuggestions: []string{"L ", "LETE ", "CR "}, expectedLength: 2},
+		{name: "Command in lower case", line: "ke", expectedSuggestions: []string{"ys "}, expectedLength: 2},
+		{name: "Namespace commands", line: "createn", expectedSuggestions: []string{"amespace "}, expectedLength: 7},
+		{name: "Key after a command", line: "GET user:", expectedSuggestions: []string{"1 ", "2 "}, expectedLength: 5},
+		{name: "All keys", line: "get ", expectedSuggestions: []string{"name ", "user:1 ", "user:2 "}, expectedLength: 0},
+		{name: "No key completion for LIST", line: "LIST u", expectedSuggestions: nil, expectedLength: 0},
+		{name: "Nothing after the key", line: "SET name ", expectedSuggestions: nil, expectedLength: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := inmemory.NewInmemory()
+			_ = db.MultiSet(map[string]string{"name": "Alice", "user:1": "a", "user:2": "b"})
+
+			c := &completer{db: db}
+			suggestions, length := c.Do([]rune(tt.line), len([]rune(tt.line)))
+
+			var actual []string
+			for _, suggestion := range suggestions {
+				actual = append(actual, string(suggestion))
+			}
+			assert.Equal(t, tt.expectedSuggestions, actual)
+			assert.Equal(t, tt.expectedLength, length)
+		})
+	}
+}
+
+func TestRunREPL(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+	history := filepath.Join(t.TempDir(), "history")
+
+	input := strings.Join([]string{
+		`SET name "Alice Smith"`,
+		`GET name`,
+		`GET missing`,
+		`FROB`,
+		`SET "broken`,
+		`KEYS n*`,
+		`EXIT`,
+		`GET name`,
+	}, "\n") + "\n"
+
+	var stdout, stderr bytes.Buffer
+	err := RunREPL(db, REPLConfig{
+		HistoryFile: history,
+		Stdin:       io.NopCloser(strings.NewReader(input)),
+		Stdout:      &stdout,
+		Stderr:      &stderr,
+	})
+	assert.NoError(t, err)
+
+	// Errors are reported and the session keeps going until EXIT
+	assert.Contains(t, stdout.String(), "OK\nAlice Smith\n(nil)\nname\n")
+	assert.Equal(t, "(error) unknown command \"frob\"\n(error) unterminated quote\n", stderr.String())
+
+	values, _ := db.MultiGet([]string{"name"})
+	assert.Equal(t, map[string]string{"name": "Alice Smith"}, values)
+}
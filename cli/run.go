@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// Output formats.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Run executes a one-shot command like "get mykey" and returns the exit
+// code: 0 when it succeeded, 1 when the key was not found and 2 for any
+// other error. An --output flag before the command or right after it
+// overrides output.
+func Run(db database.Database, args []string, output string, stdout, stderr io.Writer) int {
+	args, output, err := extractOutput(args, output)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return ExitError
+	}
+	if output != OutputText && output != OutputJSON {
+		fmt.Fprintf(stderr, "unknown output %q, should be either 'text' or 'json'\n", output)
+		return ExitError
+	}
+
+	result := Execute(db, args)
+
+	if output == OutputJSON {
+		json.NewEncoder(stdout).Encode(result)
+	} else {
+		Print(result, stdout, stderr)
+	}
+	return result.ExitCode()
+}
+
+// Print writes a result in the plain text format: values, keys and pairs go
+// to stdout, errors to stderr.
+func Print(result Result, stdout, stderr io.Writer) {
+	if result.Status != StatusOK {
+		if result.Key != "" {
+			fmt.Fprintf(stderr, "%s: %s\n", result.Error, result.Key)
+		} else {
+			fmt.Fprintln(stderr, result.Error)
+		}
+		return
+	}
+
+	switch {
+	case result.Value != nil:
+		fmt.Fprintln(stdout, *result.Value)
+	case result.Keys != nil:
+		for _, key := range result.Keys {
+			fmt.Fprintln(stdout, key)
+		}
+	case result.Values != nil:
+		for _, value := range result.Values {
+			fmt.Fprintln(stdout, value)
+		}
+	case result.Members != nil:
+		// The member last as it may contain tabs
+		for _, member := range result.Members {
+			fmt.Fprintf(stdout, "%s\t%s\n", strconv.FormatFloat(member.Score, 'f', -1, 64), member.Member)
+		}
+	case result.Metadata != nil:
+		meta := result.Metadata
+		fmt.Fprintf(stdout, "created_at=%s\n", formatTime(meta.CreatedAt))
+		fmt.Fprintf(stdout, "updated_at=%s\n", formatTime(meta.UpdatedAt))
+		fmt.Fprintf(stdout, "content_type=%s\n", meta.ContentType)
+		fmt.Fprintf(stdout, "tags=%s\n", strings.Join(meta.Tags, ","))
+		fmt.Fprintf(stdout, "version=%d\n", meta.Version)
+	case result.Versions != nil:
+		// One version per line, the value last as it may contain tabs
+		for _, version := range result.Versions {
+			state := "current"
+			if version.Deleted {
+				state = "deleted"
+			} else if !version.ReplacedAt.IsZero() {
+				state = "replaced"
+			}
+			fmt.Fprintf(stdout, "%d\t%s\t%s\t%s\n", version.Version, formatTime(version.WrittenAt), state, version.Value)
+		}
+	case result.Pairs != nil:
+		keys := make([]string, 0, len(result.Pairs))
+		for key := range result.Pairs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(stdout, "%s=%s\n", key, result.Pairs[key])
+		}
+	default:
+		fmt.Fprintln(stdout, "OK")
+	}
+}
+
+// formatTime prints a timestamp in RFC 3339, or nothing for keys written
+// before the backend recorded metadata.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// extractOutput removes the --output flags from args that come before the
+// command or right after it, ahead of its arguments, and returns the
+// remaining arguments and the last output given. The arguments of the
+// command are left alone, so "set key --output" sets key to "--output".
+func extractOutput(args []string, output string) ([]string, string, error) {
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "output" {
+			rest = append(rest, arg)
+			if len(rest) > 1 {
+				rest = append(rest, args[i+1:]...)
+				break
+			}
+			continue
+		}
+
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("flag needs an argument: --output")
+			}
+			i++
+			value = args[i]
+		}
+		output = value
+	}
+	return rest, output, nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+type ScriptOptions struct {
+	// ContinueOnError runs the remaining commands after a failure instead of
+	// stopping at the first one.
+	ContinueOnError bool
+}
+
+// ScriptStep is the outcome of one line of a script.
+type ScriptStep struct {
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+	Result Result `json:"result"`
+}
+
+type ScriptReport struct {
+	Steps  []ScriptStep `json:"steps"`
+	OK     int          `json:"ok"`
+	Failed int          `json:"failed"`
+	// Stopped is set when a failure ended the script early.
+	Stopped bool `json:"stopped"`
+}
+
+// ExitCode is the worst exit code of all steps, so a script exits with 0
+// only when every command succeeded.
+func (r ScriptReport) ExitCode() int {
+	code := ExitOK
+	for _, step := range r.Steps {
+		code = max(code, step.Result.ExitCode())
+	}
+	return code
+}
+
+// RunScript runs one command per line from r against db. Blank lines and
+// lines starting with # are skipped. Anything that isn't a success, a
+// missing key included, counts as a failure.
+func RunScript(db database.Database, r io.Reader, opts ScriptOptions) (ScriptReport, error) {
+	report := ScriptReport{Steps: []ScriptStep{}}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var result Result
+		args, err := Split(text)
+		if err != nil {
+			result = failure("", err.Error())
+		} else {
+			result = Execute(db, args)
+		}
+
+		report.Steps = append(report.Steps, ScriptStep{Line: line, Text: text, Result: result})
+		if result.Status == StatusOK {
+			report.OK++
+			continue
+		}
+
+		report.Failed++
+		if !opts.ContinueOnError {
+			report.Stopped = true
+			break
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return report, fmt.Errorf("failed to read line %d: %w", line+1, err)
+	}
+	return report, nil
+}
+
+// PrintReport writes the report in the given output format, one line per
+// command followed by a summary for text.
+func PrintReport(report ScriptReport, output string, w io.Writer) {
+	if output == OutputJSON {
+		json.NewEncoder(w).Encode(report)
+		return
+	}
+
+	for _, step := range report.Steps {
+		result := step.Result
+		var outcome string
+		switch {
+		case result.Status != StatusOK:
+			outcome = strings.ToUpper(result.Status) + ": " + result.Error
+		case result.Value != nil:
+			outcome = *result.Value
+		case result.Keys != nil:
+			outcome = strings.Join(result.Keys, ", ")
+		case result.Values != nil:
+			outcome = strings.Join(result.Values, ", ")
+		case result.Members != nil:
+			outcome = fmt.Sprintf("%d members", len(result.Members))
+		case result.Pairs != nil:
+			outcome = fmt.Sprintf("%d pairs", len(result.Pairs))
+		default:
+			outcome = "OK"
+		}
+		fmt.Fprintf(w, "line %d: %s -> %s\n", step.Line, step.Text, outcome)
+	}
+
+	fmt.Fprintf(w, "%d commands, %d ok, %d failed", len(report.Steps), report.OK, report.Failed)
+	if report.Stopped {
+		fmt.Fprint(w, ", stopped at the first failure")
+	}
+	fmt.Fprintln(w)
+}
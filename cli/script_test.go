@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/imsumedhaa/In-memory-database/inmemory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunScript(t *testing.T) {
+	script := strings.Join([]string{
+		"# seed data",
+		`SET name "Alice Smith"`,
+		"",
+		"  # indented comment",
+		"UPDATE missing 1",
+		"SET age 19",
+		`SET "broken`,
+		"GET age",
+	}, "\n")
+
+	tests := []struct {
+		name             string
+		continueOnError  bool
+		expectedLines    []int
+		expectedOK       int
+		expectedFailed   int
+		expectedStopped  bool
+		expectedExitCode int
+		expectedStore    map[string]string
+	}{
+		{
+			name:             "Stop on error",
+			continueOnError:  false,
+			expectedLines:    []int{2, 5},
+			expectedOK:       1,
+			expectedFailed:   1,
+			expectedStopped:  true,
+			expectedExitCode: ExitNotFound,
+			expectedStore:    map[string]string{"name": "Alice Smith"},
+		},
+		{
+			name:             "Continue on error",
+			continueOnError:  true,
+			expectedLines:    []int{2, 5, 6, 7, 8},
+			expectedOK:       3,
+			expectedFailed:   2,
+			expectedStopped:  false,
+			expectedExitCode: ExitError,
+			expectedStore:    map[string]string{"name": "Alice Smith", "age": "19"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := inmemory.NewInmemory()
+
+			report, err := RunScript(db, strings.NewReader(script), ScriptOptions{ContinueOnError: tt.continueOnError})
+			assert.NoError(t, err)
+
+			var lines []int
+			for _, step := range report.Steps {
+				lines = append(lines, step.Line)
+			}
+			assert.Equal(t, tt.expectedLines, lines)
+			assert.Equal(t, tt.expectedOK, report.OK)
+			assert.Equal(t, tt.expectedFailed, report.Failed)
+			assert.Equal(t, tt.expectedStopped, report.Stopped)
+			assert.Equal(t, tt.expectedExitCode, report.ExitCode())
+
+			keys, _ := db.Keys("")
+			values, _ := db.MultiGet(keys)
+			assert.Equal(t, tt.expectedStore, values)
+		})
+	}
+}
+
+func TestPrintReport(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+	report, _ := RunScript(db, strings.NewReader("SET name Alice\nGET name\nGET age\n"), ScriptOptions{})
+
+	var out bytes.Buffer
+	PrintReport(report, OutputText, &out)
+
+	assert.Equal(t, "line 1: SET name Alice -> OK\n"+
+		"line 2: GET name -> Alice\n"+
+		"line 3: GET age -> NOT FOUND: key not found\n"+
+		"3 commands, 2 ok, 1 failed, stopped at the first failure\n", out.String())
+
+	out.Reset()
+	PrintReport(report, OutputJSON, &out)
+	assert.Contains(t, out.String(), `{"line":2,"text":"GET name","result":{"command":"get","status":"ok","key":"name","value":"Alice"}}`)
+	assert.Contains(t, out.String(), `"ok":2,"failed":1,"stopped":true}`)
+}
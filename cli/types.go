@@ -0,0 +1,239 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// typeCommands are the commands on lists, sets, hashes and sorted sets,
+// with their usage and the number of arguments they take, -1 meaning any.
+var typeCommands = map[string]struct {
+	usage    string
+	min, max int
+}{
+	"lpush":         {"lpush KEY VALUE...", 2, -1},
+	"rpush":         {"rpush KEY VALUE...", 2, -1},
+	"lpop":          {"lpop KEY", 1, 1},
+	"rpop":          {"rpop KEY", 1, 1},
+	"lrange":        {"lrange KEY START STOP", 3, 3},
+	"sadd":          {"sadd KEY MEMBER...", 2, -1},
+	"srem":          {"srem KEY MEMBER...", 2, -1},
+	"smembers":      {"smembers KEY", 1, 1},
+	"sinter":        {"sinter KEY...", 1, -1},
+	"sunion":        {"sunion KEY...", 1, -1},
+	"hset":          {"hset KEY FIELD VALUE", 3, 3},
+	"hget":          {"hget KEY FIELD", 2, 2},
+	"hdel":          {"hdel KEY FIELD...", 2, -1},
+	"hgetall":       {"hgetall KEY", 1, 1},
+	"zadd":          {"zadd KEY SCORE MEMBER", 3, 3},
+	"zincrby":       {"zincrby KEY DELTA MEMBER", 3, 3},
+	"zscore":        {"zscore KEY MEMBER", 2, 2},
+	"zrank":         {"zrank KEY MEMBER", 2, 2},
+	"zrem":          {"zrem KEY MEMBER...", 2, -1},
+	"zcard":         {"zcard KEY", 1, 1},
+	"zrange":        {"zrange KEY START STOP", 3, 3},
+	"zrangebyscore": {"zrangebyscore KEY MIN MAX", 3, 3},
+	"type":          {"type KEY", 1, 1},
+}
+
+// composite runs one of typeCommands. Counts, scores and single elements
+// are returned as the value, lists and set members as values, hashes as
+// pairs and sorted set members as members.
+func composite(db database.Database, name string, args []string) Result {
+	command := typeCommands[name]
+	if len(args) < command.min || (command.max >= 0 && len(args) > command.max) {
+		return failure(name, "usage: "+command.usage)
+	}
+	key := args[0]
+
+	var (
+		result Result
+		err    error
+	)
+	switch name {
+	case "lpush", "rpush", "lpop", "rpop", "lrange":
+		lists, ok := db.(database.ListStore)
+		if !ok {
+			return failure(name, "the backend does not support lists")
+		}
+		result, err = listCommand(lists, name, key, args[1:])
+	case "sadd", "srem", "smembers", "sinter", "sunion":
+		sets, ok := db.(database.SetStore)
+		if !ok {
+			return failure(name, "the backend does not support sets")
+		}
+		result, err = setCommand(sets, name, args)
+	case "hset", "hget", "hdel", "hgetall":
+		hashes, ok := db.(database.HashStore)
+		if !ok {
+			return failure(name, "the backend does not support hashes")
+		}
+		result, err = hashCommand(hashes, name, key, args[1:])
+	case "zadd", "zincrby", "zscore", "zrank", "zrem", "zcard", "zrange", "zrangebyscore":
+		zsets, ok := db.(database.SortedSetStore)
+		if !ok {
+			return failure(name, "the backend does not support sorted sets")
+		}
+		result, err = sortedSetCommand(zsets, name, key, args[1:])
+	case "type":
+		typer, ok := db.(database.Typer)
+		if !ok {
+			return failure(name, "the backend only holds strings")
+		}
+		var kind string
+		kind, err = typer.Type(key)
+		result.Value = &kind
+	}
+
+	if errors.Is(err, database.ErrNotFound) {
+		return Result{Command: name, Status: StatusNotFound, Key: key, Error: "key not found"}
+	} else if err != nil {
+		return failure(name, err.Error())
+	}
+	result.Command = name
+	result.Status = StatusOK
+	if name != "sinter" && name != "sunion" {
+		result.Key = key
+	}
+	return result
+}
+
+func listCommand(lists database.ListStore, name, key string, args []string) (Result, error) {
+	switch name {
+	case "lpush", "rpush":
+		push := lists.RPush
+		if name == "lpush" {
+			push = lists.LPush
+		}
+		length, err := push(key, args...)
+		return count(length), err
+	case "lpop", "rpop":
+		pop := lists.RPop
+		if name == "lpop" {
+			pop = lists.LPop
+		}
+		value, err := pop(key)
+		return Result{Value: &value}, err
+	}
+
+	start, err := strconv.Atoi(args[0])
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid start %q, expected an integer", args[0])
+	}
+	stop, err := strconv.Atoi(args[1])
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid stop %q, expected an integer", args[1])
+	}
+	values, err := lists.LRange(key, start, stop)
+	return Result{Values: values}, err
+}
+
+func setCommand(sets database.SetStore, name string, args []string) (Result, error) {
+	var (
+		members []string
+		err     error
+	)
+	switch name {
+	case "sadd", "srem":
+		change := sets.SRem
+		if name == "sadd" {
+			change = sets.SAdd
+		}
+		changed, err := change(args[0], args[1:]...)
+		return count(changed), err
+	case "smembers":
+		members, err = sets.SMembers(args[0])
+	case "sinter":
+		members, err = sets.SInter(args...)
+	case "sunion":
+		members, err = sets.SUnion(args...)
+	}
+	return Result{Values: members}, err
+}
+
+func hashCommand(hashes database.HashStore, name, key string, args []string) (Result, error) {
+	switch name {
+	case "hset":
+		created, err := hashes.HSet(key, args[0], args[1])
+		if created {
+			return count(1), err
+		}
+		return count(0), err
+	case "hget":
+		value, err := hashes.HGet(key, args[0])
+		return Result{Value: &value}, err
+	case "hdel":
+		removed, err := hashes.HDel(key, args...)
+		return count(removed), err
+	}
+	pairs, err := hashes.HGetAll(key)
+	return Result{Pairs: pairs}, err
+}
+
+func sortedSetCommand(zsets database.SortedSetStore, name, key string, args []string) (Result, error) {
+	switch name {
+	case "zadd", "zincrby":
+		score, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid score %q, expected a decimal number", args[0])
+		}
+		if name == "zadd" {
+			added, err := zsets.ZAdd(key, args[1], score)
+			if added {
+				return count(1), err
+			}
+			return count(0), err
+		}
+		score, err = zsets.ZIncrBy(key, args[1], score)
+		return formatScore(score), err
+	case "zscore":
+		score, err := zsets.ZScore(key, args[0])
+		return formatScore(score), err
+	case "zrank":
+		rank, err := zsets.ZRank(key, args[0])
+		return count(rank), err
+	case "zrem":
+		removed, err := zsets.ZRem(key, args...)
+		return count(removed), err
+	case "zcard":
+		n, err := zsets.ZCard(key)
+		return count(n), err
+	case "zrange":
+		start, err := strconv.Atoi(args[0])
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid start %q, expected an integer", args[0])
+		}
+		stop, err := strconv.Atoi(args[1])
+		if err != nil {
+			return Result{}, fmt.Errorf("invalid stop %q, expected an integer", args[1])
+		}
+		members, err := zsets.ZRange(key, start, stop)
+		return Result{Members: members}, err
+	}
+
+	min, err := strconv.ParseFloat(args[0], 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid min %q, expected a decimal number", args[0])
+	}
+	max, err := strconv.ParseFloat(args[1], 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid max %q, expected a decimal number", args[1])
+	}
+	members, err := zsets.ZRangeByScore(key, min, max)
+	return Result{Members: members}, err
+}
+
+func formatScore(score float64) Result {
+	value := strconv.FormatFloat(score, 'f', -1, 64)
+	return Result{Value: &value}
+}
+
+// count returns a result with n as the value, like the number of members
+// added by sadd.
+func count(n int) Result {
+	value := strconv.Itoa(n)
+	return Result{Value: &value}
+}
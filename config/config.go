@@ -0,0 +1,587 @@
+// Package config holds the settings of every subcommand. They are layered:
+// built-in defaults, then the config file, then environment variables and
+// finally the command line flags, which the caller applies on top.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/cache"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	Filesystem Filesystem `yaml:"filesystem" toml:"filesystem"`
+	Inmemory   Inmemory   `yaml:"inmemory" toml:"inmemory"`
+	Postgres   Postgres   `yaml:"postgres" toml:"postgres"`
+	Remote     Remote     `yaml:"remote" toml:"remote"`
+	Server     Server     `yaml:"server" toml:"server"`
+	Auth       Auth       `yaml:"auth" toml:"auth"`
+	Logging    Logging    `yaml:"logging" toml:"logging"`
+	History    History    `yaml:"history" toml:"history"`
+	SoftDelete SoftDelete `yaml:"soft_delete" toml:"soft_delete"`
+	Cache      Cache      `yaml:"cache" toml:"cache"`
+}
+
+type Filesystem struct {
+	// Name of the JSON file the keys are stored in.
+	Name string `yaml:"name" toml:"name"`
+}
+
+type Inmemory struct {
+	// Snapshot is the JSON file the keys are saved to and loaded from at
+	// startup. Empty keeps them in memory only.
+	Snapshot string `yaml:"snapshot" toml:"snapshot"`
+	// SnapshotInterval saves the keys periodically instead of after every
+	// write.
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" toml:"snapshot_interval"`
+	// MaxKeys and MaxMemory bound the keys of the store, zero means no
+	// limit. Eviction is what happens to a write once one is reached:
+	// lru, lfu, random, volatile-ttl or reject-writes, the default.
+	MaxKeys   int      `yaml:"max_keys" toml:"max_keys"`
+	MaxMemory ByteSize `yaml:"max_memory" toml:"max_memory"`
+	Eviction  string   `yaml:"eviction" toml:"eviction"`
+}
+
+// ByteSize is a number of bytes written with an optional unit, like 96Mi,
+// see database.ParseSize.
+type ByteSize int64
+
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	size, err := database.ParseSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = ByteSize(size)
+	return nil
+}
+
+type Postgres struct {
+	// DSN is a full connection string or postgres:// URL. The settings
+	// below override the parts of it they set.
+	DSN string `yaml:"dsn" toml:"dsn"`
+
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"` // zero means 5432
+	User     string `yaml:"user" toml:"user"`
+	Password string `yaml:"password" toml:"password"`
+	Name     string `yaml:"name" toml:"name"`
+
+	SSLMode     string `yaml:"sslmode" toml:"sslmode"`
+	SSLRootCert string `yaml:"sslrootcert" toml:"sslrootcert"`
+	SSLCert     string `yaml:"sslcert" toml:"sslcert"`
+	SSLKey      string `yaml:"sslkey" toml:"sslkey"`
+
+	// Schema and Table the keys are stored in; an empty schema uses the
+	// search path of the connection, an empty table means kvstore.
+	Schema string `yaml:"schema" toml:"schema"`
+	Table  string `yaml:"table" toml:"table"`
+
+	ConnectTimeout  time.Duration `yaml:"connect_timeout" toml:"connect_timeout"`
+	MaxOpenConns    int           `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" toml:"conn_max_idle_time"`
+
+	// StreamThreshold is the size in bytes above which values are read and
+	// written in chunks of that size; zero means 1 MiB.
+	StreamThreshold int `yaml:"stream_threshold" toml:"stream_threshold"`
+
+	// UserFile and PasswordFile hold the credentials instead, typically a
+	// mounted Docker or Kubernetes secret. They are read again every
+	// ReloadInterval and a change reconnects with the new credentials.
+	UserFile       string        `yaml:"user_file" toml:"user_file"`
+	PasswordFile   string        `yaml:"password_file" toml:"password_file"`
+	ReloadInterval time.Duration `yaml:"reload_interval" toml:"reload_interval"`
+}
+
+type Remote struct {
+	URL     string            `yaml:"url" toml:"url"`
+	Token   string            `yaml:"token" toml:"token"`
+	Headers map[string]string `yaml:"headers" toml:"headers"`
+	Timeout time.Duration     `yaml:"timeout" toml:"timeout"`
+	Retries int               `yaml:"retries" toml:"retries"`
+}
+
+type Server struct {
+	Addr string `yaml:"addr" toml:"addr"`
+	// Backend the server stores the keys in.
+	Backend string `yaml:"backend" toml:"backend"`
+}
+
+type Auth struct {
+	// Token the server requires as "Authorization: Bearer <token>", and
+	// the remote client sends unless remote.token is set. Empty disables
+	// authentication.
+	Token string `yaml:"token" toml:"token"`
+}
+
+// History bounds the previous values every backend keeps per key. Zero
+// means no limit.
+type History struct {
+	MaxVersions int           `yaml:"max_versions" toml:"max_versions"`
+	MaxAge      time.Duration `yaml:"max_age" toml:"max_age"`
+}
+
+// SoftDelete keeps deleted keys as tombstones that can be restored for
+// GracePeriod, after which they are purged. A zero GracePeriod keeps them.
+type SoftDelete struct {
+	Enabled     bool          `yaml:"enabled" toml:"enabled"`
+	GracePeriod time.Duration `yaml:"grace_period" toml:"grace_period"`
+}
+
+// Cache puts an LRU cache of the values in front of the backend of the
+// server, see cache.Client. An empty Mode disables it.
+type Cache struct {
+	Mode      string   `yaml:"mode" toml:"mode"` // read-through, write-through or write-behind
+	MaxKeys   int      `yaml:"max_keys" toml:"max_keys"`
+	MaxMemory ByteSize `yaml:"max_memory" toml:"max_memory"`
+	// FlushInterval is how often the writes are sent to the backend in
+	// write-behind mode.
+	FlushInterval time.Duration `yaml:"flush_interval" toml:"flush_interval"`
+}
+
+type Logging struct {
+	Level  string `yaml:"level" toml:"level"`   // debug, info, warn or error
+	Format string `yaml:"format" toml:"format"` // text or json
+}
+
+// Backends that can be selected by name.
+var Backends = []string{"filesystem", "inmemory", "postgres", "remote"}
+
+// Default returns the settings used when nothing else is configured.
+func Default() *Config {
+	return &Config{
+		Filesystem: Filesystem{Name: "database.json"},
+		Postgres: Postgres{
+			Table:          postgres.DefaultTable,
+			ConnectTimeout: 10 * time.Second,
+			ReloadInterval: 30 * time.Second,
+		},
+		Remote: Remote{
+			URL:     "http://localhost:8080",
+			Headers: map[string]string{},
+			Timeout: 10 * time.Second,
+			Retries: 2,
+		},
+		Server:     Server{Addr: ":8080", Backend: "postgres"},
+		Logging:    Logging{Level: "info", Format: "text"},
+		History:    History{MaxVersions: database.DefaultRetention.MaxVersions},
+		SoftDelete: SoftDelete{GracePeriod: 7 * 24 * time.Hour},
+		Cache:      Cache{MaxMemory: 32 << 20, FlushInterval: cache.DefaultFlushInterval},
+	}
+}
+
+// Load reads the config file at path, which may be empty for none, and
+// applies the environment variables read through getenv.
+func Load(path string, getenv func(string) string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := decode(path, data, cfg); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		if cfg.Postgres.User != "" && cfg.Postgres.UserFile != "" {
+			return nil, fmt.Errorf("invalid config file %s: set either postgres.user or postgres.user_file, not both", path)
+		}
+		if cfg.Postgres.Password != "" && cfg.Postgres.PasswordFile != "" {
+			return nil, fmt.Errorf("invalid config file %s: set either postgres.password or postgres.password_file, not both", path)
+		}
+	}
+
+	if err := cfg.applyEnv(getenv); err != nil {
+		return nil, err
+	}
+	if err := cfg.readSecrets(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// decode fills cfg from YAML or TOML depending on the file extension.
+// Unknown keys are rejected so typos don't go unnoticed.
+func decode(path string, data []byte, cfg *Config) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		return nil
+
+	case ".toml":
+		meta, err := toml.Decode(string(data), cfg)
+		if err != nil {
+			return err
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("unknown key %q", undecoded[0].String())
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported extension %q, use .yaml, .yml or .toml", filepath.Ext(path))
+}
+
+// env maps the supported environment variables to the setting they
+// override. Every variable can also be given as a path in NAME_FILE; file
+// records where such a path goes when the file is watched for changes, the
+// others are read once.
+var env = []struct {
+	name string
+	set  func(cfg *Config, value string) error
+	file func(cfg *Config, path string)
+}{
+	{"DATABASE_URL", func(cfg *Config, v string) error { cfg.Postgres.DSN = v; return nil }, nil},
+	{"DB_HOST", func(cfg *Config, v string) error { cfg.Postgres.Host = v; return nil }, nil},
+	{"DB_PORT", func(cfg *Config, v string) error { return setInt(&cfg.Postgres.Port, v) }, nil},
+	{
+		"DB_USER",
+		func(cfg *Config, v string) error { cfg.Postgres.User, cfg.Postgres.UserFile = v, ""; return nil },
+		func(cfg *Config, path string) { cfg.Postgres.User, cfg.Postgres.UserFile = "", path },
+	},
+	{
+		"DB_PASSWORD",
+		func(cfg *Config, v string) error {
+			cfg.Postgres.Password, cfg.Postgres.PasswordFile = v, ""
+			return nil
+		},
+		func(cfg *Config, path string) { cfg.Postgres.Password, cfg.Postgres.PasswordFile = "", path },
+	},
+	{"DB_NAME", func(cfg *Config, v string) error { cfg.Postgres.Name = v; return nil }, nil},
+	{"DB_SCHEMA", func(cfg *Config, v string) error { cfg.Postgres.Schema = v; return nil }, nil},
+	{"DB_TABLE", func(cfg *Config, v string) error { cfg.Postgres.Table = v; return nil }, nil},
+	{"DB_SSLMODE", func(cfg *Config, v string) error { cfg.Postgres.SSLMode = v; return nil }, nil},
+	{"DB_SSLROOTCERT", func(cfg *Config, v string) error { cfg.Postgres.SSLRootCert = v; return nil }, nil},
+	{"DB_SSLCERT", func(cfg *Config, v string) error { cfg.Postgres.SSLCert = v; return nil }, nil},
+	{"DB_SSLKEY", func(cfg *Config, v string) error { cfg.Postgres.SSLKey = v; return nil }, nil},
+	{"DB_CONNECT_TIMEOUT", func(cfg *Config, v string) error { return setDuration(&cfg.Postgres.ConnectTimeout, v) }, nil},
+	{"DB_STREAM_THRESHOLD", func(cfg *Config, v string) error { return setInt(&cfg.Postgres.StreamThreshold, v) }, nil},
+	{"KVSTORE_FILE", func(cfg *Config, v string) error { cfg.Filesystem.Name = v; return nil }, nil},
+	{"KVSTORE_INMEMORY_SNAPSHOT", func(cfg *Config, v string) error { cfg.Inmemory.Snapshot = v; return nil }, nil},
+	{"KVSTORE_INMEMORY_SNAPSHOT_INTERVAL", func(cfg *Config, v string) error { return setDuration(&cfg.Inmemory.SnapshotInterval, v) }, nil},
+	{"KVSTORE_INMEMORY_MAX_KEYS", func(cfg *Config, v string) error { return setInt(&cfg.Inmemory.MaxKeys, v) }, nil},
+	{"KVSTORE_INMEMORY_MAX_MEMORY", func(cfg *Config, v string) error { return cfg.Inmemory.MaxMemory.UnmarshalText([]byte(v)) }, nil},
+	{"KVSTORE_INMEMORY_EVICTION", func(cfg *Config, v string) error { cfg.Inmemory.Eviction = v; return nil }, nil},
+	{"KVSTORE_REMOTE_URL", func(cfg *Config, v string) error { cfg.Remote.URL = v; return nil }, nil},
+	{"KVSTORE_REMOTE_TOKEN", func(cfg *Config, v string) error { cfg.Remote.Token = v; return nil }, nil},
+	{"KVSTORE_REMOTE_TIMEOUT", func(cfg *Config, v string) error { return setDuration(&cfg.Remote.Timeout, v) }, nil},
+	{"KVSTORE_REMOTE_RETRIES", func(cfg *Config, v string) error { return setInt(&cfg.Remote.Retries, v) }, nil},
+	{"KVSTORE_SERVER_ADDR", func(cfg *Config, v string) error { cfg.Server.Addr = v; return nil }, nil},
+	{"KVSTORE_SERVER_BACKEND", func(cfg *Config, v string) error { cfg.Server.Backend = v; return nil }, nil},
+	{"KVSTORE_AUTH_TOKEN", func(cfg *Config, v string) error { cfg.Auth.Token = v; return nil }, nil},
+	{"KVSTORE_LOG_LEVEL", func(cfg *Config, v string) error { cfg.Logging.Level = v; return nil }, nil},
+	{"KVSTORE_LOG_FORMAT", func(cfg *Config, v string) error { cfg.Logging.Format = v; return nil }, nil},
+	{"KVSTORE_HISTORY_MAX_VERSIONS", func(cfg *Config, v string) error { return setInt(&cfg.History.MaxVersions, v) }, nil},
+	{"KVSTORE_HISTORY_MAX_AGE", func(cfg *Config, v string) error { return setDuration(&cfg.History.MaxAge, v) }, nil},
+	{"KVSTORE_SOFT_DELETE", func(cfg *Config, v string) error { return setBool(&cfg.SoftDelete.Enabled, v) }, nil},
+	{"KVSTORE_SOFT_DELETE_GRACE_PERIOD", func(cfg *Config, v string) error { return setDuration(&cfg.SoftDelete.GracePeriod, v) }, nil},
+	{"KVSTORE_CACHE_MODE", func(cfg *Config, v string) error { cfg.Cache.Mode = v; return nil }, nil},
+	{"KVSTORE_CACHE_MAX_KEYS", func(cfg *Config, v string) error { return setInt(&cfg.Cache.MaxKeys, v) }, nil},
+	{"KVSTORE_CACHE_MAX_MEMORY", func(cfg *Config, v string) error { return cfg.Cache.MaxMemory.UnmarshalText([]byte(v)) }, nil},
+	{"KVSTORE_CACHE_FLUSH_INTERVAL", func(cfg *Config, v string) error { return setDuration(&cfg.Cache.FlushInterval, v) }, nil},
+}
+
+func (c *Config) applyEnv(getenv func(string) string) error {
+	for _, variable := range env {
+		value := getenv(variable.name)
+
+		if path := getenv(variable.name + "_FILE"); path != "" {
+			if value != "" {
+				return fmt.Errorf("set either %s or %s_FILE, not both", variable.name, variable.name)
+			}
+			if variable.file != nil {
+				variable.file(c, path)
+				continue
+			}
+			secret, err := ReadSecret(path)
+			if err != nil {
+				return fmt.Errorf("failed to read %s_FILE: %w", variable.name, err)
+			}
+			value = secret
+		}
+
+		if value == "" {
+			continue
+		}
+		if err := variable.set(c, value); err != nil {
+			return fmt.Errorf("invalid %s %q: %w", variable.name, value, err)
+		}
+	}
+	return nil
+}
+
+func setInt(field *int, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("must be a whole number")
+	}
+	*field = n
+	return nil
+}
+
+func setBool(field *bool, value string) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return fmt.Errorf("must be true or false")
+	}
+	*field = b
+	return nil
+}
+
+func setDuration(field *time.Duration, value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("must be a duration like 10s")
+	}
+	*field = d
+	return nil
+}
+
+// Validate checks the settings every subcommand needs plus the sections
+// given, which are backend names or "server". Postgres settings are only
+// required when "postgres" is among them. All problems are reported at once.
+func (c *Config) Validate(sections ...string) error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, c.Logging.Level) {
+		fail("logging.level must be one of debug, info, warn or error, got %q", c.Logging.Level)
+	}
+	if !slices.Contains([]string{"text", "json"}, c.Logging.Format) {
+		fail("logging.format must be text or json, got %q", c.Logging.Format)
+	}
+	if c.History.MaxVersions < 0 {
+		fail("history.max_versions cannot be negative, got %d", c.History.MaxVersions)
+	}
+	if c.History.MaxAge < 0 {
+		fail("history.max_age cannot be negative, got %s", c.History.MaxAge)
+	}
+	if c.SoftDelete.GracePeriod < 0 {
+		fail("soft_delete.grace_period cannot be negative, got %s", c.SoftDelete.GracePeriod)
+	}
+	if c.Inmemory.SnapshotInterval < 0 {
+		fail("inmemory.snapshot_interval cannot be negative, got %s", c.Inmemory.SnapshotInterval)
+	}
+	if c.Inmemory.MaxKeys < 0 {
+		fail("inmemory.max_keys cannot be negative, got %d", c.Inmemory.MaxKeys)
+	}
+	if c.Inmemory.MaxMemory < 0 {
+		fail("inmemory.max_memory cannot be negative, got %d", c.Inmemory.MaxMemory)
+	}
+	if c.Inmemory.Eviction != "" && !slices.Contains(database.EvictionPolicies, database.EvictionPolicy(c.Inmemory.Eviction)) {
+		policies := make([]string, len(database.EvictionPolicies))
+		for n, policy := range database.EvictionPolicies {
+			policies[n] = string(policy)
+		}
+		fail("inmemory.eviction must be one of %s, got %q", strings.Join(policies, ", "), c.Inmemory.Eviction)
+	}
+
+	if c.Cache.Mode != "" && !slices.Contains(cache.Modes, cache.Mode(c.Cache.Mode)) {
+		fail("cache.mode must be empty or one of read-through, write-through or write-behind, got %q", c.Cache.Mode)
+	}
+	if c.Cache.MaxKeys < 0 {
+		fail("cache.max_keys cannot be negative, got %d", c.Cache.MaxKeys)
+	}
+	if c.Cache.MaxMemory < 0 {
+		fail("cache.max_memory cannot be negative, got %d", c.Cache.MaxMemory)
+	}
+	if c.Cache.FlushInterval < 0 {
+		fail("cache.flush_interval cannot be negative, got %s", c.Cache.FlushInterval)
+	}
+
+	if slices.Contains(sections, "server") {
+		if c.Server.Addr == "" {
+			fail("server.addr is required (config file or KVSTORE_SERVER_ADDR)")
+		}
+		if slices.Contains(Backends, c.Server.Backend) {
+			sections = append(sections, c.Server.Backend)
+		} else {
+			fail("server.backend must be one of %s, got %q", strings.Join(Backends, ", "), c.Server.Backend)
+		}
+	}
+
+	if slices.Contains(sections, "filesystem") && c.Filesystem.Name == "" {
+		fail("filesystem.name is required (config file, KVSTORE_FILE or --name)")
+	}
+
+	if slices.Contains(sections, "postgres") {
+		pg := c.Postgres
+		// A DSN carries the connection settings itself
+		if pg.DSN == "" {
+			required := []struct{ key, value, variable string }{
+				{"postgres.host", pg.Host, "DB_HOST"},
+				{"postgres.user", pg.User, "DB_USER"},
+				{"postgres.password", pg.Password, "DB_PASSWORD"},
+				{"postgres.name", pg.Name, "DB_NAME"},
+			}
+			for _, setting := range required {
+				if setting.value == "" {
+					fail("%s is required (config file, %s or %s_FILE) unless postgres.dsn is set", setting.key, setting.variable, setting.variable)
+				}
+			}
+		}
+		if pg.Port < 0 || pg.Port > 65535 {
+			fail("postgres.port must be between 1 and 65535, or 0 for 5432, got %d", pg.Port)
+		}
+		// Postgres silently truncates longer names, which could make two
+		// stores share a table
+		for _, name := range []struct{ key, value string }{{"postgres.schema", pg.Schema}, {"postgres.table", pg.Table}} {
+			if len(name.value) > 63 {
+				fail("%s cannot be longer than 63 bytes, got %d", name.key, len(name.value))
+			}
+		}
+		if pg.SSLMode != "" && !slices.Contains(postgres.SSLModes, pg.SSLMode) {
+			fail("postgres.sslmode must be one of %s, got %q", strings.Join(postgres.SSLModes, ", "), pg.SSLMode)
+		}
+		if (pg.SSLCert == "") != (pg.SSLKey == "") {
+			fail("postgres.sslcert and postgres.sslkey must be set together")
+		}
+		for _, file := range []struct{ key, path string }{
+			{"postgres.sslrootcert", pg.SSLRootCert},
+			{"postgres.sslcert", pg.SSLCert},
+			{"postgres.sslkey", pg.SSLKey},
+		} {
+			if file.path == "" {
+				continue
+			}
+			if _, err := os.Stat(file.path); err != nil {
+				fail("%s: %v", file.key, err)
+			}
+		}
+		for _, count := range []struct {
+			key   string
+			value int
+		}{
+			{"postgres.max_open_conns", pg.MaxOpenConns},
+			{"postgres.max_idle_conns", pg.MaxIdleConns},
+			{"postgres.stream_threshold", pg.StreamThreshold},
+		} {
+			if count.value < 0 {
+				fail("%s cannot be negative, got %d", count.key, count.value)
+			}
+		}
+		if pg.MaxOpenConns > 0 && pg.MaxIdleConns > pg.MaxOpenConns {
+			fail("postgres.max_idle_conns (%d) cannot be more than postgres.max_open_conns (%d)", pg.MaxIdleConns, pg.MaxOpenConns)
+		}
+		for _, duration := range []struct {
+			key   string
+			value time.Duration
+		}{
+			{"postgres.connect_timeout", pg.ConnectTimeout},
+			{"postgres.conn_max_lifetime", pg.ConnMaxLifetime},
+			{"postgres.conn_max_idle_time", pg.ConnMaxIdleTime},
+		} {
+			if duration.value < 0 {
+				fail("%s cannot be negative, got %s", duration.key, duration.value)
+			}
+		}
+		if (pg.UserFile != "" || pg.PasswordFile != "") && pg.ReloadInterval <= 0 {
+			fail("postgres.reload_interval must be positive, got %s", pg.ReloadInterval)
+		}
+	}
+
+	if slices.Contains(sections, "remote") {
+		if !strings.HasPrefix(c.Remote.URL, "http://") && !strings.HasPrefix(c.Remote.URL, "https://") {
+			fail("remote.url must start with http:// or https://, got %q", c.Remote.URL)
+		}
+		if c.Remote.Timeout < 0 {
+			fail("remote.timeout cannot be negative, got %s", c.Remote.Timeout)
+		}
+		if c.Remote.Retries < 0 {
+			fail("remote.retries cannot be negative, got %d", c.Remote.Retries)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ClientConfig converts the section to the settings of the postgres client.
+func (p Postgres) ClientConfig() postgres.Config {
+	port := ""
+	if p.Port != 0 {
+		port = strconv.Itoa(p.Port)
+	}
+	return postgres.Config{
+		DSN:             p.DSN,
+		Host:            p.Host,
+		Port:            port,
+		User:            p.User,
+		Password:        p.Password,
+		DBName:          p.Name,
+		SSLMode:         p.SSLMode,
+		SSLRootCert:     p.SSLRootCert,
+		SSLCert:         p.SSLCert,
+		SSLKey:          p.SSLKey,
+		ConnectTimeout:  p.ConnectTimeout,
+		Schema:          p.Schema,
+		Table:           p.Table,
+		MaxOpenConns:    p.MaxOpenConns,
+		MaxIdleConns:    p.MaxIdleConns,
+		ConnMaxLifetime: p.ConnMaxLifetime,
+		ConnMaxIdleTime: p.ConnMaxIdleTime,
+		StreamThreshold: p.StreamThreshold,
+	}
+}
+
+// PostgresConfig is the postgres section converted to the settings of the
+// client, with the history retention.
+func (c *Config) PostgresConfig() postgres.Config {
+	cfg := c.Postgres.ClientConfig()
+	cfg.HistoryMaxVersions = c.History.MaxVersions
+	cfg.HistoryMaxAge = c.History.MaxAge
+	cfg.SoftDelete = c.SoftDelete.Enabled
+	return cfg
+}
+
+// Settings converts the section to the soft delete mode of the backends.
+func (s SoftDelete) Settings() database.SoftDelete {
+	return database.SoftDelete{Enabled: s.Enabled, GracePeriod: s.GracePeriod}
+}
+
+// Settings converts the section to the settings of the cache.
+func (c Cache) Settings() cache.Settings {
+	return cache.Settings{Mode: cache.Mode(c.Mode), MaxKeys: c.MaxKeys, MaxMemory: int64(c.MaxMemory), FlushInterval: c.FlushInterval}
+}
+
+// Limits converts the section to the limits of the inmemory store.
+func (i Inmemory) Limits() database.Limits {
+	return database.Limits{MaxKeys: i.MaxKeys, MaxMemory: int64(i.MaxMemory), Policy: database.EvictionPolicy(i.Eviction)}
+}
+
+// Retention converts the section to the retention of the backends.
+func (h History) Retention() database.Retention {
+	return database.Retention{MaxVersions: h.MaxVersions, MaxAge: h.MaxAge}
+}
+
+// NewLogger builds the logger described by the logging section. It expects
+// a validated config.
+func (l Logging) NewLogger(w io.Writer) *slog.Logger {
+	var level slog.Level
+	level.UnmarshalText([]byte(l.Level))
+
+	opts := &slog.HandlerOptions{Level: level}
+	if l.Format == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// readSecrets fills in the credentials kept in files.
+func (c *Config) readSecrets() error {
+	if c.Postgres.UserFile != "" {
+		user, err := ReadSecret(c.Postgres.UserFile)
+		if err != nil {
+			return fmt.Errorf("failed to read postgres.user_file: %w", err)
+		}
+		c.Postgres.User = user
+	}
+	if c.Postgres.PasswordFile != "" {
+		password, err := ReadSecret(c.Postgres.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read postgres.password_file: %w", err)
+		}
+		c.Postgres.Password = password
+	}
+	return nil
+}
+
+// ReadSecret returns the content of a secret file without the trailing
+// newline most tools write.
+func ReadSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// WatchSecrets reads the postgres credential files again every reload
+// interval until ctx is done, and calls reconnect with the new user and
+// password whenever one of them changed. A failed reconnect is tried again
+// at the next check, so writing the new password to the file before
+// rotating it in the database does no harm.
+func (c *Config) WatchSecrets(ctx context.Context, reconnect func(user, password string) error) {
+	pg := c.Postgres
+	if pg.UserFile == "" && pg.PasswordFile == "" {
+		return
+	}
+
+	ticker := time.NewTicker(pg.ReloadInterval)
+	defer ticker.Stop()
+
+	user, password := pg.User, pg.Password
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		newUser, newPassword := user, password
+		var err error
+		if pg.UserFile != "" {
+			if newUser, err = ReadSecret(pg.UserFile); err != nil {
+				slog.Warn("failed to read the postgres user file", "file", pg.UserFile, "error", err)
+				continue
+			}
+		}
+		if pg.PasswordFile != "" {
+			if newPassword, err = ReadSecret(pg.PasswordFile); err != nil {
+				slog.Warn("failed to read the postgres password file", "file", pg.PasswordFile, "error", err)
+				continue
+			}
+		}
+		if newUser == user && newPassword == password {
+			continue
+		}
+
+		if err := reconnect(newUser, newPassword); err != nil {
+			slog.Error("failed to reconnect to postgres with the new credentials", "error", err)
+			continue
+		}
+		slog.Info("reconnected to postgres with the new credentials")
+		user, password = newUser, newPassword
+	}
+}
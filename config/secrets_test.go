@@ -0,0 +1,142 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad_SecretFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+	passwordFile := write("password", "s3cret\n")
+	userFile := write("user", "app")
+	hostFile := write("host", "db.internal\r\n")
+	configFile := write("config.yaml", "postgres:\n  password_file: "+passwordFile+"\n")
+	conflictFile := write("conflict.yaml", "postgres:\n  password: inline\n  password_file: "+passwordFile+"\n")
+
+	tests := []struct {
+		name          string
+		path          string
+		env           map[string]string
+		expected      Postgres
+		expectedError string
+	}{
+		{
+			name: "Environment files",
+			env:  map[string]string{"DB_PASSWORD_FILE": passwordFile, "DB_USER_FILE": userFile, "DB_HOST_FILE": hostFile},
+			expected: Postgres{
+				Host: "db.internal", Table: "kvstore", ConnectTimeout: 10 * time.Second, User: "app", Password: "s3cret",
+				UserFile: userFile, PasswordFile: passwordFile, ReloadInterval: 30 * time.Second,
+			},
+		},
+		{
+			name: "Config file",
+			path: configFile,
+			expected: Postgres{
+				Table: "kvstore", ConnectTimeout: 10 * time.Second, Password: "s3cret", PasswordFile: passwordFile, ReloadInterval: 30 * time.Second,
+			},
+		},
+		{
+			name: "Environment value replaces the file of the config",
+			path: configFile,
+			env:  map[string]string{"DB_PASSWORD": "from-env"},
+			expected: Postgres{
+				Table: "kvstore", ConnectTimeout: 10 * time.Second, Password: "from-env", ReloadInterval: 30 * time.Second,
+			},
+		},
+		{
+			name:          "Value and file",
+			env:           map[string]string{"DB_PASSWORD": "inline", "DB_PASSWORD_FILE": passwordFile},
+			expectedError: "set either DB_PASSWORD or DB_PASSWORD_FILE, not both",
+		},
+		{
+			name:          "Value and file in the config",
+			path:          conflictFile,
+			expectedError: "set either postgres.password or postgres.password_file, not both",
+		},
+		{
+			name:          "Missing file",
+			env:           map[string]string{"DB_PASSWORD_FILE": filepath.Join(dir, "missing")},
+			expectedError: "failed to read postgres.password_file",
+		},
+		{
+			name:          "Missing file read once",
+			env:           map[string]string{"DB_HOST_FILE": filepath.Join(dir, "missing")},
+			expectedError: "failed to read DB_HOST_FILE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(tt.path, func(name string) string { return tt.env[name] })
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.Postgres)
+		})
+	}
+}
+
+func TestConfig_WatchSecrets(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("old\n"), 0600))
+
+	cfg, err := Load("", func(name string) string {
+		return map[string]string{"DB_USER": "app", "DB_PASSWORD_FILE": passwordFile}[name]
+	})
+	assert.NoError(t, err)
+	cfg.Postgres.ReloadInterval = 5 * time.Millisecond
+
+	var mu sync.Mutex
+	var calls []string
+	fail := true
+	reconnect := func(user, password string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, user+":"+password)
+		if fail {
+			// The database doesn't know the new password yet
+			fail = false
+			return errors.New("password authentication failed")
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		cfg.WatchSecrets(ctx, reconnect)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("new\n"), 0600))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(calls) >= 2
+	}, time.Second, 5*time.Millisecond)
+
+	// Once reconnected the unchanged file doesn't trigger again
+	time.Sleep(30 * time.Millisecond)
+	cancel()
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"app:new", "app:new"}, calls)
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// ValueStreamer is implemented by backends that can move a value through an
+// io.Reader or io.Writer instead of holding all of it in a string, which is
+// how large binary values are uploaded and downloaded.
+type ValueStreamer interface {
+	// ReadValue writes the value of key to w, or returns an error wrapping
+	// ErrNotFound before writing anything when the key does not exist.
+	ReadValue(key string, w io.Writer) error
+	// WriteValue stores everything read from r as the value of key,
+	// creating the key or overwriting it.
+	WriteValue(key string, r io.Reader) error
+}
+
+// ReadValue writes the value of key to w, streaming it when db implements
+// ValueStreamer.
+func ReadValue(db Database, key string, w io.Writer) error {
+	if streamer, ok := db.(ValueStreamer); ok {
+		return streamer.ReadValue(key, w)
+	}
+
+	values, err := db.MultiGet([]string{key})
+	if err != nil {
+		return err
+	}
+	value, ok := values[key]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	_, err = io.WriteString(w, value)
+	return err
+}
+
+// WriteValue stores everything read from r as the value of key, streaming
+// it when db implements ValueStreamer.
+func WriteValue(db Database, key string, r io.Reader) error {
+	if streamer, ok := db.(ValueStreamer); ok {
+		return streamer.WriteValue(key, r)
+	}
+
+	value, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return db.MultiSet(map[string]string{key: string(value)})
+}
+
+// BinaryString is a value that may hold any bytes. It is written to JSON as
+// a plain string when it is valid UTF-8 and as {"base64": "..."} otherwise,
+// since encoding/json would replace the invalid bytes.
+type BinaryString string
+
+type base64Value struct {
+	Base64 string `json:"base64"`
+}
+
+func (s BinaryString) MarshalJSON() ([]byte, error) {
+	if utf8.ValidString(string(s)) {
+		return json.Marshal(string(s))
+	}
+	return json.Marshal(base64Value{Base64: base64.StdEncoding.EncodeToString([]byte(s))})
+}
+
+func (s *BinaryString) UnmarshalJSON(data []byte) error {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return json.Unmarshal(data, (*string)(s))
+	}
+
+	var value base64Value
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(value.Base64)
+	if err != nil {
+		return fmt.Errorf("invalid base64 value: %w", err)
+	}
+	*s = BinaryString(decoded)
+	return nil
+}
+
+func (v Version) MarshalJSON() ([]byte, error) {
+	type version Version
+	return json.Marshal(struct {
+		version
+		Value BinaryString `json:"value"`
+	}{version(v), BinaryString(v.Value)})
+}
+
+func (v *Version) UnmarshalJSON(data []byte) error {
+	type version Version
+	decoded := struct {
+		*version
+		Value BinaryString `json:"value"`
+	}{version: (*version)(v)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	v.Value = string(decoded.Value)
+	return nil
+}
+
+func (t Tombstone) MarshalJSON() ([]byte, error) {
+	type tombstone Tombstone
+	return json.Marshal(struct {
+		tombstone
+		Value BinaryString `json:"value"`
+	}{tombstone(t), BinaryString(t.Value)})
+}
+
+func (t *Tombstone) UnmarshalJSON(data []byte) error {
+	type tombstone Tombstone
+	decoded := struct {
+		*tombstone
+		Value BinaryString `json:"value"`
+	}{tombstone: (*tombstone)(t)}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	t.Value = string(decoded.Value)
+	return nil
+}
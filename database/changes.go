@@ -0,0 +1,13 @@
+package database
+
+import "context"
+
+// ChangeListener is implemented by backends that announce the keys written
+// by any of their clients, like Postgres, so caches in front of them on
+// other servers can drop their copies.
+type ChangeListener interface {
+	// ListenChanges calls changed with every key written until ctx is
+	// done. The empty key means notifications may have been missed and any
+	// key may have changed.
+	ListenChanges(ctx context.Context, changed func(key string)) error
+}
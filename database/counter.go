@@ -0,0 +1,67 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Errors returned, possibly wrapped, by Counter operations.
+var (
+	ErrNotNumber = errors.New("value is not a number")
+	ErrOverflow  = errors.New("increment would overflow")
+)
+
+// Counter is implemented by backends that can change a numeric value in
+// place, atomically, so concurrent clients don't lose increments like they
+// would with a read followed by a write. A missing key counts as 0 and is
+// created.
+type Counter interface {
+	// IncrBy adds delta to the integer value of key and returns the result.
+	IncrBy(key string, delta int64) (int64, error)
+	// IncrByFloat adds delta to the decimal value of key and returns the
+	// result.
+	IncrByFloat(key string, delta float64) (float64, error)
+}
+
+// AddInt adds delta to value, the current value of a key or "" when it is
+// missing, for the backends that implement Counter in Go. It returns the
+// result and the value to store.
+func AddInt(value string, delta int64) (int64, string, error) {
+	var current int64
+	if value != "" {
+		var err error
+		current, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, "", fmt.Errorf("%w: %q is not an integer", ErrNotNumber, value)
+		}
+	}
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, "", ErrOverflow
+	}
+
+	result := current + delta
+	return result, strconv.FormatInt(result, 10), nil
+}
+
+// AddFloat is AddInt for decimal values.
+func AddFloat(value string, delta float64) (float64, string, error) {
+	var current float64
+	if value != "" {
+		var err error
+		current, err = strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(current) || math.IsInf(current, 0) {
+			return 0, "", fmt.Errorf("%w: %q is not a decimal number", ErrNotNumber, value)
+		}
+	}
+	if math.IsNaN(delta) || math.IsInf(delta, 0) {
+		return 0, "", fmt.Errorf("%w: the increment must be finite", ErrNotNumber)
+	}
+
+	result := current + delta
+	if math.IsInf(result, 0) {
+		return 0, "", ErrOverflow
+	}
+	return result, strconv.FormatFloat(result, 'f', -1, 64), nil
+}
@@ -1,5 +1,13 @@
 package database
 
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned, possibly wrapped, by the optional interfaces
+// below when a key does not exist.
+var ErrNotFound = errors.New("key not found")
 
 type Database interface{
 	Create(key, value string) error
@@ -9,4 +17,120 @@ type Database interface{
 	Show() error
 	Exit() error
 
-}
\ No newline at end of file
+	// MultiGet returns the values of the given keys. Keys that do not exist
+	// are left out of the returned map.
+	MultiGet(keys []string) (map[string]string, error)
+	// MultiSet stores every pair, overwriting existing values.
+	MultiSet(pairs map[string]string) error
+	// MultiDelete removes the given keys and returns the ones that existed.
+	MultiDelete(keys []string) ([]string, error)
+	// Keys returns the keys starting with prefix in sorted order. An empty
+	// prefix lists every key.
+	Keys(prefix string) ([]string, error)
+}
+
+// BulkLoader is implemented by backends that have a faster way than MultiSet
+// to load a large number of pairs, like COPY in Postgres.
+type BulkLoader interface {
+	BulkLoad(pairs map[string]string) error
+}
+
+// Reconnecter is implemented by backends that can switch to new credentials
+// without a restart, like Postgres when its password is rotated.
+type Reconnecter interface {
+	Reconnect(username, password string) error
+}
+
+// Metadata is what a backend records about a key besides its value.
+// ContentType and Tags are set by the user, the timestamps and the version
+// by the backend on every write of the value. UpdatedAt also moves when the
+// content type or tags change, WrittenAt only with the value.
+type Metadata struct {
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	WrittenAt   time.Time `json:"written_at,omitzero"`
+	ContentType string    `json:"content_type,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Version     int64     `json:"version,omitempty"`
+}
+
+// ValueWrittenAt returns WrittenAt, or UpdatedAt for the metadata saved
+// before WrittenAt was recorded.
+func (m Metadata) ValueWrittenAt() time.Time {
+	if m.WrittenAt.IsZero() {
+		return m.UpdatedAt
+	}
+	return m.WrittenAt
+}
+
+// MetadataStore is implemented by backends that keep Metadata per key.
+type MetadataStore interface {
+	// Stat returns the metadata of key, or an error wrapping ErrNotFound.
+	Stat(key string) (Metadata, error)
+	// SetMetadata replaces the content type and tags of an existing key and
+	// refreshes its UpdatedAt. The value, its version and WrittenAt are left
+	// alone.
+	SetMetadata(key, contentType string, tags []string) error
+}
+
+// Version is one value a key had. ReplacedAt is zero for the current value
+// and Deleted is set when the value was removed rather than overwritten.
+type Version struct {
+	Version    int64     `json:"version"`
+	Value      string    `json:"value"`
+	WrittenAt  time.Time `json:"written_at"`
+	ReplacedAt time.Time `json:"replaced_at,omitzero"`
+	Deleted    bool      `json:"deleted,omitempty"`
+}
+
+// Retention bounds the previous values kept per key. Zero means no limit.
+type Retention struct {
+	MaxVersions int
+	MaxAge      time.Duration
+}
+
+// DefaultRetention keeps the last ten previous values of every key.
+var DefaultRetention = Retention{MaxVersions: 10}
+
+// HistoryStore is implemented by backends that keep the previous values of
+// a key when it is updated or deleted.
+type HistoryStore interface {
+	// History returns up to limit versions of key, newest first, starting
+	// with the current value when the key exists. A limit of 0 returns all
+	// the versions that are retained.
+	History(key string, limit int) ([]Version, error)
+	// GetAt returns the value key had at the given time, or an error
+	// wrapping ErrNotFound when it did not exist then.
+	GetAt(key string, at time.Time) (string, error)
+}
+
+// SoftDelete makes Delete keep the keys as tombstones, hidden from every
+// read until they are restored with Undelete or purged once GracePeriod
+// has passed. Zero keeps them until purged.
+type SoftDelete struct {
+	Enabled     bool
+	GracePeriod time.Duration
+}
+
+// Tombstone is a key deleted in soft delete mode, with what it had when
+// it was deleted.
+type Tombstone struct {
+	Value     string    `json:"value"`
+	Metadata  Metadata  `json:"metadata"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Undeleter is implemented by backends that can restore a tombstoned key.
+type Undeleter interface {
+	// Undelete restores key with the value and metadata it had when it was
+	// deleted, or returns an error wrapping ErrNotFound when there is no
+	// tombstone for it.
+	Undelete(key string) error
+}
+
+// TombstonePurger is implemented by backends that keep tombstones.
+type TombstonePurger interface {
+	// PurgeTombstones removes for good the keys deleted before the given
+	// time and returns how many there were.
+	PurgeTombstones(before time.Time) (int, error)
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"slices"
+	"time"
+)
+
+// Prune drops the versions that are older than MaxAge or beyond the newest
+// MaxVersions. versions are ordered oldest first.
+func (r Retention) Prune(versions []Version, now time.Time) []Version {
+	if r.MaxAge > 0 {
+		cutoff := now.Add(-r.MaxAge)
+		versions = slices.DeleteFunc(versions, func(v Version) bool { return v.ReplacedAt.Before(cutoff) })
+	}
+	if r.MaxVersions > 0 && len(versions) > r.MaxVersions {
+		versions = versions[len(versions)-r.MaxVersions:]
+	}
+	return versions
+}
+
+// BuildHistory is History for backends that keep the previous values of a
+// key in a list ordered oldest first. current is nil when the key does not
+// exist.
+func BuildHistory(current *Version, past []Version, limit int) []Version {
+	history := make([]Version, 0, len(past)+1)
+	if current != nil {
+		history = append(history, *current)
+	}
+	for i := len(past) - 1; i >= 0; i-- {
+		history = append(history, past[i])
+	}
+	if limit > 0 && len(history) > limit {
+		history = history[:limit]
+	}
+	return history
+}
+
+// ValueAt is GetAt over the same list, reporting whether key existed at
+// the given time.
+func ValueAt(current *Version, past []Version, at time.Time) (string, bool) {
+	if current != nil && !current.WrittenAt.After(at) {
+		return current.Value, true
+	}
+	for i := len(past) - 1; i >= 0; i-- {
+		if !past[i].WrittenAt.After(at) && past[i].ReplacedAt.After(at) {
+			return past[i].Value, true
+		}
+	}
+	return "", false
+}
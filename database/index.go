@@ -0,0 +1,56 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/imsumedhaa/In-memory-database/pkg/jsondoc"
+)
+
+// Errors returned, possibly wrapped, by Indexer operations.
+var (
+	ErrIndexNotFound = errors.New("index not found")
+	ErrIndexExists   = errors.New("index already exists")
+)
+
+// Index is a secondary index of the JSON values of the keys by the value at
+// Path, like $.status.
+type Index struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// Indexer is implemented by backends that can find keys by the content of
+// their JSON values, like every user whose status is active, without
+// reading every value. Indexes are kept up to date by every write. Values
+// that are not JSON, or have nothing or null at the path, are left out.
+type Indexer interface {
+	// CreateIndex indexes the existing values and every later write.
+	// Creating an index again with the same path does nothing, with another
+	// path returns an error wrapping ErrIndexExists.
+	CreateIndex(name, path string) error
+	// DropIndex removes an index, or returns an error wrapping
+	// ErrIndexNotFound.
+	DropIndex(name string) error
+	// Indexes returns the indexes sorted by name.
+	Indexes() ([]Index, error)
+	// Query returns the sorted keys whose value at the path of index is
+	// value. Values are compared as text: a string without its quotes and
+	// anything else as JSON, so active matches {"status": "active"} and 30
+	// matches {"age": 30}.
+	Query(index, value string) ([]string, error)
+}
+
+// indexName is what index names are limited to, so every backend can use
+// them in its identifiers and file names.
+var indexName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// CheckIndex validates the name and the path of an index and returns the
+// steps of the path.
+func CheckIndex(name, path string) ([]string, error) {
+	if !indexName.MatchString(name) {
+		return nil, fmt.Errorf("invalid index name %q: use up to 32 lowercase letters, digits and underscores, starting with a letter", name)
+	}
+	return jsondoc.ParsePath(path)
+}
@@ -0,0 +1,57 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/imsumedhaa/In-memory-database/pkg/jsondoc"
+)
+
+// Errors returned, possibly wrapped, by JSONStore operations.
+var (
+	ErrNotJSON     = jsondoc.ErrNotJSON
+	ErrPatchFailed = jsondoc.ErrPatchFailed
+)
+
+// JSONStore is implemented by backends that can read and change parts of a
+// value holding a JSON document in place, so clients don't fetch, change
+// and write back the whole document. Paths look like $.user.name or
+// $.items[0], $ being the whole document. A missing key or path returns an
+// error wrapping ErrNotFound, a value that is not JSON one wrapping
+// ErrNotJSON.
+type JSONStore interface {
+	// JSONGet returns the JSON at path.
+	JSONGet(key, path string) (string, error)
+	// JSONSet replaces or adds the JSON value at path, whose parent must
+	// exist. The path $ replaces the whole document and creates the key.
+	JSONSet(key, path, value string) error
+	// JSONMerge applies an RFC 7396 merge patch, creating the key, and
+	// returns the new document.
+	JSONMerge(key, patch string) (string, error)
+	// JSONPatch applies an RFC 6902 JSON patch atomically and returns the
+	// new document. A patch that does not apply returns an error wrapping
+	// ErrPatchFailed and leaves the value alone.
+	JSONPatch(key, patch string) (string, error)
+}
+
+// GetPath returns the JSON at path in document, for the backends that
+// implement JSONStore in Go.
+func GetPath(document, path string) (string, error) {
+	value, err := jsondoc.Get(document, path)
+	return value, pathError(err)
+}
+
+// SetPath sets the JSON at path in document, "" when the key is missing,
+// and returns the document to store.
+func SetPath(document, path, value string) (string, error) {
+	document, err := jsondoc.Set(document, path, value)
+	return document, pathError(err)
+}
+
+// pathError reports a missing path as ErrNotFound, like a missing key.
+func pathError(err error) error {
+	if errors.Is(err, jsondoc.ErrPathNotFound) {
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	}
+	return err
+}
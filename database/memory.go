@@ -0,0 +1,91 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrMemoryLimit is returned, possibly wrapped, by the writes a store
+// refuses because it is full and its policy does not evict keys, or has no
+// key left to evict.
+var ErrMemoryLimit = errors.New("memory limit reached")
+
+// EvictionPolicy chooses the keys removed when a store goes over its
+// Limits.
+type EvictionPolicy string
+
+const (
+	// EvictLRU removes the keys read or written least recently.
+	EvictLRU EvictionPolicy = "lru"
+	// EvictLFU removes the keys read or written least often.
+	EvictLFU EvictionPolicy = "lfu"
+	// EvictRandom removes any key.
+	EvictRandom EvictionPolicy = "random"
+	// EvictVolatileTTL removes the keys that expire, see
+	// Namespace.DefaultTTL, closest to their expiry first. Writes are
+	// refused once no such key is left.
+	EvictVolatileTTL EvictionPolicy = "volatile-ttl"
+	// RejectWrites removes nothing and refuses the writes with
+	// ErrMemoryLimit once the store is full. Deletes are still accepted.
+	RejectWrites EvictionPolicy = "reject-writes"
+)
+
+// EvictionPolicies lists the policies by name.
+var EvictionPolicies = []EvictionPolicy{EvictLRU, EvictLFU, EvictRandom, EvictVolatileTTL, RejectWrites}
+
+// Limits bound the number of keys and the approximate memory, in bytes, of
+// a store. Zero means no limit. An empty Policy is RejectWrites.
+type Limits struct {
+	MaxKeys   int            `json:"max_keys,omitempty"`
+	MaxMemory int64          `json:"max_memory,omitempty"`
+	Policy    EvictionPolicy `json:"policy,omitempty"`
+}
+
+// Enabled tells whether any limit is set.
+func (l Limits) Enabled() bool {
+	return l.MaxKeys > 0 || l.MaxMemory > 0
+}
+
+// MemoryStats tells how full a store with Limits is, and how many keys it
+// evicted and writes it refused since it started.
+type MemoryStats struct {
+	Limits
+	Keys      int   `json:"keys"`
+	Bytes     int64 `json:"bytes"`
+	Evictions int64 `json:"evictions"`
+	Rejected  int64 `json:"rejected"`
+}
+
+// MemoryReporter is implemented by backends that account for the memory
+// of their keys, like inmemory.
+type MemoryReporter interface {
+	MemoryStats() MemoryStats
+}
+
+// ParseSize reads a number of bytes with an optional unit, like 96Mi,
+// 100MB or 4096. K, M and G are powers of 1000, Ki, Mi and Gi powers of
+// 1024, and a trailing B is allowed.
+func ParseSize(size string) (int64, error) {
+	units := []struct {
+		suffix string
+		factor int64
+	}{
+		{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30},
+		{"K", 1e3}, {"M", 1e6}, {"G", 1e9},
+	}
+
+	number, factor := strings.TrimSuffix(strings.TrimSpace(size), "B"), int64(1)
+	for _, unit := range units {
+		if strings.HasSuffix(number, unit.suffix) {
+			number, factor = strings.TrimSuffix(number, unit.suffix), unit.factor
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(number), 10, 64)
+	if err != nil || n < 0 || n > (1<<63-1)/factor {
+		return 0, fmt.Errorf("invalid size %q, expected a number of bytes like 4096 or 96Mi", size)
+	}
+	return n * factor, nil
+}
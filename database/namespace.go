@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+)
+
+// ErrNamespaceNotFound is returned, possibly wrapped, when dropping a
+// namespace that was never used.
+var ErrNamespaceNotFound = errors.New("namespace not found")
+
+// Namespace is a separate set of keys in a store, so teams sharing a server
+// do not overwrite each other's keys. DefaultTTL, when positive, is how long
+// the string keys of the namespace live after their last write.
+type Namespace struct {
+	Name       string        `json:"name"`
+	DefaultTTL time.Duration `json:"default_ttl,omitempty"`
+}
+
+// NamespaceStore is implemented by backends that keep namespaces next to
+// their own keys.
+type NamespaceStore interface {
+	// Namespace returns the keys of the namespace name as a database of
+	// their own, with the same optional interfaces as the store except
+	// NamespaceStore. The namespace is created on first use.
+	Namespace(name string) (Database, error)
+	// Namespaces lists the namespaces sorted by name.
+	Namespaces() ([]Namespace, error)
+	// SetNamespaceTTL sets the DefaultTTL of a namespace, creating it. Zero
+	// keeps the keys until they are deleted.
+	SetNamespaceTTL(name string, ttl time.Duration) error
+	// DropNamespace deletes a namespace with all its keys, or returns an
+	// error wrapping ErrNamespaceNotFound.
+	DropNamespace(name string) error
+}
+
+// Expirer is implemented by the databases of namespaces, to delete the
+// string keys last written before the given time. It returns how many were
+// deleted.
+type Expirer interface {
+	Expire(before time.Time) (int, error)
+}
+
+var namespaceName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// CheckNamespace returns an error when name cannot be used as a namespace.
+// Names end up in file and table names, so they are short and lowercase.
+func CheckNamespace(name string) error {
+	if !namespaceName.MatchString(name) {
+		return fmt.Errorf("invalid namespace name %q, expected up to 32 lowercase letters, digits and underscores starting with a letter", name)
+	}
+	return nil
+}
+
+// NamespaceExpirer deletes the keys of every namespace that outlived its
+// DefaultTTL, see ExpireNamespaces.
+type NamespaceExpirer interface {
+	ExpireNamespaces(now time.Time) (int, error)
+}
+
+// ExpireInterval is how often ExpireNamespaces looks for expired keys, so a
+// key can outlive its TTL by up to that much.
+const ExpireInterval = time.Minute
+
+// ExpireNamespaces deletes the expired keys every ExpireInterval until ctx
+// is done. Failures are logged and retried on the next tick.
+func ExpireNamespaces(ctx context.Context, expirer NamespaceExpirer) {
+	ticker := time.NewTicker(ExpireInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, err := expirer.ExpireNamespaces(now)
+			if err != nil {
+				slog.Warn("failed to expire keys", "error", err)
+			} else if expired > 0 {
+				slog.Info("expired keys", "count", expired)
+			}
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// PurgeInterval is how often PurgeTombstones looks for expired tombstones.
+const PurgeInterval = time.Minute
+
+// PurgeTombstones removes the tombstones older than grace every
+// PurgeInterval until ctx is done. Failures are logged and retried on the
+// next tick.
+func PurgeTombstones(ctx context.Context, purger TombstonePurger, grace time.Duration) {
+	ticker := time.NewTicker(PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			purged, err := purger.PurgeTombstones(now.Add(-grace))
+			if err != nil {
+				slog.Warn("failed to purge tombstones", "error", err)
+			} else if purged > 0 {
+				slog.Info("purged tombstones", "count", purged)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package database
+
+import "errors"
+
+// ErrWrongType is returned, possibly wrapped, when a key is used as a type it
+// doesn't hold, like pushing to a key holding a string.
+var ErrWrongType = errors.New("WRONGTYPE operation against a key holding the wrong kind of value")
+
+// Types of the values a key can hold, as returned by Typer.
+const (
+	TypeNone   = "none"
+	TypeString = "string"
+	TypeList   = "list"
+	TypeSet    = "set"
+	TypeHash   = "hash"
+	TypeZSet   = "zset"
+)
+
+// Typer is implemented by backends that hold more than strings.
+type Typer interface {
+	// Type returns the type of the value of key, TypeNone when it is
+	// missing.
+	Type(key string) (string, error)
+}
+
+// ListStore is implemented by backends that hold lists of strings. A list
+// is created by its first push and removed with its last element. Every
+// method returns an error wrapping ErrWrongType when key holds another type.
+type ListStore interface {
+	// LPush and RPush add the values to the head or the tail of the list,
+	// one after the other, and return its new length.
+	LPush(key string, values ...string) (int, error)
+	RPush(key string, values ...string) (int, error)
+	// LPop and RPop remove and return the first or the last element, or an
+	// error wrapping ErrNotFound when the list is missing.
+	LPop(key string) (string, error)
+	RPop(key string) (string, error)
+	// LRange returns the elements from start to stop, both included.
+	// Negative indexes count from the end, -1 being the last element, and
+	// a missing list has no elements.
+	LRange(key string, start, stop int) ([]string, error)
+}
+
+// SetStore is implemented by backends that hold sets of strings. Members are
+// returned sorted and a missing set is empty.
+type SetStore interface {
+	// SAdd and SRem add or remove members and return how many were not
+	// already or were in the set.
+	SAdd(key string, members ...string) (int, error)
+	SRem(key string, members ...string) (int, error)
+	SMembers(key string) ([]string, error)
+	// SInter and SUnion return the members found in all or any of the sets.
+	SInter(keys ...string) ([]string, error)
+	SUnion(keys ...string) ([]string, error)
+}
+
+// HashStore is implemented by backends that hold hashes, maps of fields to
+// values stored under one key.
+type HashStore interface {
+	// HSet sets field to value and reports whether the field is new.
+	HSet(key, field, value string) (bool, error)
+	// HGet returns the value of field, or an error wrapping ErrNotFound when
+	// the hash or the field is missing.
+	HGet(key, field string) (string, error)
+	// HDel removes the fields and returns how many were in the hash.
+	HDel(key string, fields ...string) (int, error)
+	// HGetAll returns every field of the hash, none when it is missing.
+	HGetAll(key string) (map[string]string, error)
+}
+
+// ScoredMember is a member of a sorted set with its score.
+type ScoredMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+// SortedSetStore is implemented by backends that hold sorted sets, sets
+// whose members are ordered by score, then by member for equal scores, like
+// a leaderboard. Ranks start at 0 for the lowest score and a missing sorted
+// set is empty.
+type SortedSetStore interface {
+	// ZAdd sets the score of member and reports whether it is new.
+	ZAdd(key, member string, score float64) (bool, error)
+	// ZIncrBy adds delta to the score of member, 0 when it is missing, and
+	// returns the new score.
+	ZIncrBy(key, member string, delta float64) (float64, error)
+	// ZScore and ZRank return the score and the rank of member, or an error
+	// wrapping ErrNotFound when it is missing.
+	ZScore(key, member string) (float64, error)
+	ZRank(key, member string) (int, error)
+	// ZRem removes members and returns how many were in the sorted set.
+	ZRem(key string, members ...string) (int, error)
+	// ZCard returns the number of members.
+	ZCard(key string) (int, error)
+	// ZRange returns the members ranked from start to stop, both included,
+	// with the indexes of LRange.
+	ZRange(key string, start, stop int) ([]ScoredMember, error)
+	// ZRangeByScore returns the members scored from min to max, both
+	// included.
+	ZRangeByScore(key string, min, max float64) ([]ScoredMember, error)
+}
+
+// ListRange resolves the Redis-style start and stop indexes of LRange and
+// ZRange for a list of the given length into the bounds of a slice, empty
+// when from >= to.
+func ListRange(length, start, stop int) (from, to int) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	start = max(start, 0)
+	stop = min(stop, length-1)
+	if start > stop {
+		return 0, 0
+	}
+	return start, stop + 1
+}
@@ -2,18 +2,39 @@ package filesystem
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/jsondoc"
 	"github.com/spf13/afero"
 )
 
 
 type FileSystem struct { //find out what is necessary for file system
+	// mu serializes the operations of this value on the file, so
+	// read-modify-write operations like IncrBy are atomic within a process
+	mu sync.Mutex
+
 	FileName string
 	store    map[string]string
 	fs       afero.Fs      //afero.Fs is an interface defined by the Afero library  and here f.fs comes
+
+	// Retention bounds the previous values kept in the history file
+	Retention database.Retention
+	// SoftDelete keeps deleted keys in the tombstones file
+	SoftDelete database.SoftDelete
+
+	// namespaces caches the stores of the namespaces opened so far, which
+	// have this store as their parent
+	namespaces map[string]*FileSystem
+	parent     *FileSystem
 }
 
 func NewFileSystemWithFS(name string, fs afero.Fs) (*FileSystem, error){
@@ -25,19 +46,20 @@ func NewFileSystemWithFS(name string, fs afero.Fs) (*FileSystem, error){
 		}		
 	}
 	return &FileSystem{
-		FileName: name,
-		store:    make(map[string]string),
-		fs:       fs,
+		FileName:  name,
+		store:     make(map[string]string),
+		fs:        fs,
+		Retention: database.DefaultRetention,
 	},nil
 }
 
 
-func NewFileSystem(name string) (database.Database, error) { //create some file name database.json only if it is not exist
+func NewFileSystem(name string) (*FileSystem, error) { //create some file name database.json only if it is not exist
 	fs := afero.NewOsFs()
 
 	if _,err := fs.Stat(name); err!= nil{
 		if os.IsNotExist(err){
-			fmt.Println("File not created yet, Creating new one....")
+			fmt.Fprintln(os.Stderr, "File not created yet, Creating new one....")
 			_, err = fs.Create(name)
 				if err != nil{
 					return nil, fmt.Errorf("failed to create file: %w", err)
@@ -46,20 +68,23 @@ func NewFileSystem(name string) (database.Database, error) { //create some file
 			return nil, fmt.Errorf("failed to get the file: %w", err)
 		}
 	}else{
-		fmt.Println("File already exists")
+		fmt.Fprintln(os.Stderr, "File already exists")
 	}
 	return &FileSystem{
 		FileName: name,
 		store: make(map[string]string),
 		fs : fs,
+		Retention: database.DefaultRetention,
 	},nil
 }
 
 func (f *FileSystem) Create(key, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
 	file, err := afero.ReadFile(f.fs , f.FileName)       //f.fs means “use the file system instance (real or virtual) stored in this struct.”
 	if err == nil && len(file) > 0 {
-		json.Unmarshal(file, &f.store)     //
+		decodeStore(file, &f.store)     //
 	}
 
 	if key=="" || value==""{
@@ -72,7 +97,7 @@ func (f *FileSystem) Create(key, value string) error {
 	// Add and save
 	f.store[key] = value
 
-	updateData,err := json.MarshalIndent(f.store,"","  ")
+	updateData,err := encodeStore(f.store)
 	if err != nil{
 		return fmt.Errorf("error while encoding  %w",err)
 	}
@@ -81,16 +106,21 @@ func (f *FileSystem) Create(key, value string) error {
 	if err != nil{
 		return fmt.Errorf("error while writing the file: %w",err)
 	}
+	if err := f.updateMeta(nil, []string{key}, nil); err != nil {
+		return err
+	}
 	fmt.Println("Key value pair successfully created")
 
 	return nil
 }
 
 func (f *FileSystem) Update(key,value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	// Step 1: Load existing data
 	file, err := afero.ReadFile(f.fs, f.FileName)    //f.fs means “use the file system instance (real or virtual) stored in this struct.”
 	if err == nil && len(file) > 0 {
-		json.Unmarshal(file, &f.store)       //json.Unmarshal: Convert JSON ➡️ Go data
+		decodeStore(file, &f.store)       //json.Unmarshal: Convert JSON ➡️ Go data
 	}
 
 	if key == "" {
@@ -108,10 +138,11 @@ func (f *FileSystem) Update(key,value string) error {
 	}
 
 	// Step 5: Update value in store
+	previous := map[string]string{key: f.store[key]}
 	f.store[key] = value
 
 	// Step 6: Write updated data to file
-	updatedData, err := json.MarshalIndent(f.store, "", "  ")       // Convert Go data ➡️  JSON with indent means space
+	updatedData, err := encodeStore(f.store)       // Convert Go data ➡️  JSON with indent means space
 	if err != nil {
 		return fmt.Errorf("error encoding data: %w", err)
 	}
@@ -120,6 +151,9 @@ func (f *FileSystem) Update(key,value string) error {
 	if err != nil {
 		return fmt.Errorf("error writing to file: %w", err)
 	}
+	if err := f.updateMeta(previous, []string{key}, nil); err != nil {
+		return err
+	}
 
 	fmt.Println("Value updated successfully.")
 
@@ -127,10 +161,12 @@ func (f *FileSystem) Update(key,value string) error {
 }
 
 func (f *FileSystem) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
 	file, err := afero.ReadFile(f.fs, f.FileName)
 	if err == nil && len(file) > 0 { //check if the error is nil and the file has some data to decode
-		json.Unmarshal(file, &f.store) //decode the data
+		decodeStore(file, &f.store) //decode the data
 	}
 
 	if key == "" {
@@ -141,9 +177,10 @@ func (f *FileSystem) Delete(key string) error {
 		return fmt.Errorf("key not found")
 	}
 
+	previous := map[string]string{key: f.store[key]}
 	delete(f.store, key)
 
-	updatedData, err := json.MarshalIndent(f.store, "", "  ")
+	updatedData, err := encodeStore(f.store)
 
 	if err != nil {
 		return fmt.Errorf("error encoding data: %w", err)
@@ -153,6 +190,9 @@ func (f *FileSystem) Delete(key string) error {
 	if err != nil {
 		return fmt.Errorf("error writing to file: %w", err)
 	}
+	if err := f.updateMeta(previous, nil, []string{key}); err != nil {
+		return err
+	}
 
 	fmt.Println("Value deleted successfully.")
 
@@ -161,10 +201,12 @@ func (f *FileSystem) Delete(key string) error {
 }
 
 func (f *FileSystem) Get(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
 	file, err := afero.ReadFile(f.fs, f.FileName)
 	if err == nil && len(file) > 0 { //check if the error is nil and the file has some data to decode
-		json.Unmarshal(file, &f.store) //decode the data
+		decodeStore(file, &f.store) //decode the data
 	}
 
 	if key == "" {
@@ -182,13 +224,15 @@ func (f *FileSystem) Get(key string) error {
 }
 
 func (f *FileSystem) Show() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 
 	file, err := afero.ReadFile(f.fs, f.FileName)
 	if err!= nil{
 		return fmt.Errorf("error while reading from the file: %w",err)
 	}
 	if len(file) > 0{
-		err = json.Unmarshal(file, &f.store)     //data will store from json file to map
+		err = decodeStore(file, &f.store)     //data will store from json file to map
 		if err != nil{
 			return fmt.Errorf("failed to decode JSON: %w", err)
 		}
@@ -207,3 +251,996 @@ func (f *FileSystem) Exit() error {
 
 	return nil
 }
+
+// load reads the file into f.store, replacing whatever was cached before.
+func (f *FileSystem) load() error {
+	file, err := afero.ReadFile(f.fs, f.FileName)
+	if err != nil {
+		return fmt.Errorf("error while reading from the file: %w", err)
+	}
+
+	store := make(map[string]string)
+	if len(file) > 0 {
+		if err := decodeStore(file, &store); err != nil {
+			return fmt.Errorf("failed to decode JSON: %w", err)
+		}
+	}
+	f.store = store
+	return nil
+}
+
+// encodeStore encodes store as the JSON map kept in the file. Values that
+// are not valid UTF-8 are written as {"base64": "..."} objects so binary
+// values survive the round trip.
+func encodeStore(store map[string]string) ([]byte, error) {
+	values := make(map[string]database.BinaryString, len(store))
+	for key, value := range store {
+		values[key] = database.BinaryString(value)
+	}
+	return json.MarshalIndent(values, "", "  ")
+}
+
+// decodeStore decodes the JSON map kept in the file into store, like
+// json.Unmarshal would.
+func decodeStore(data []byte, store *map[string]string) error {
+	var values map[string]database.BinaryString
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if *store == nil {
+		*store = make(map[string]string, len(values))
+	}
+	for key, value := range values {
+		(*store)[key] = string(value)
+	}
+	return nil
+}
+
+// save writes f.store back to the file in one go.
+func (f *FileSystem) save() error {
+	updatedData, err := encodeStore(f.store)
+	if err != nil {
+		return fmt.Errorf("error encoding data: %w", err)
+	}
+
+	if err := afero.WriteFile(f.fs, f.FileName, updatedData, 0644); err != nil {
+		return fmt.Errorf("error writing to file: %w", err)
+	}
+	return nil
+}
+
+func (f *FileSystem) MultiGet(keys []string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("key cannot be empty")
+		}
+		if val, ok := f.store[key]; ok {
+			values[key] = val
+		}
+	}
+	return values, nil
+}
+
+func (f *FileSystem) MultiSet(pairs map[string]string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return err
+	}
+
+	for key, value := range pairs {
+		if key == "" || value == "" {
+			return fmt.Errorf("key and value cannot be empty")
+		}
+	}
+
+	previous := make(map[string]string)
+	written := make([]string, 0, len(pairs))
+	for key, value := range pairs {
+		if old, exists := f.store[key]; exists {
+			previous[key] = old
+		}
+		f.store[key] = value
+		written = append(written, key)
+	}
+
+	// The whole batch goes to disk with a single rewrite
+	if err := f.save(); err != nil {
+		return err
+	}
+	return f.updateMeta(previous, written, nil)
+}
+
+func (f *FileSystem) MultiDelete(keys []string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("key cannot be empty")
+		}
+	}
+
+	return f.remove(keys)
+}
+
+// remove deletes the keys of the loaded store that exist and returns them.
+func (f *FileSystem) remove(keys []string) ([]string, error) {
+	previous := make(map[string]string)
+	deleted := []string{}
+	for _, key := range keys {
+		if old, exists := f.store[key]; exists {
+			previous[key] = old
+			delete(f.store, key)
+			deleted = append(deleted, key)
+		}
+	}
+
+	if len(deleted) == 0 {
+		return deleted, nil
+	}
+	if err := f.save(); err != nil {
+		return nil, err
+	}
+	if err := f.updateMeta(previous, nil, deleted); err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+func (f *FileSystem) Keys(prefix string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+
+	keys := []string{}
+	for key := range f.store {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Stat returns the metadata of key, see database.MetadataStore. Keys
+// written before metadata was recorded have zero timestamps.
+func (f *FileSystem) Stat(key string) (database.Metadata, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if key == "" {
+		return database.Metadata{}, fmt.Errorf("key cannot be empty")
+	}
+	if err := f.load(); err != nil {
+		return database.Metadata{}, err
+	}
+	if _, exists := f.store[key]; !exists {
+		return database.Metadata{}, database.ErrNotFound
+	}
+
+	meta, err := f.loadMeta()
+	if err != nil {
+		return database.Metadata{}, err
+	}
+	return meta[key], nil
+}
+
+func (f *FileSystem) SetMetadata(key, contentType string, tags []string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+	if err := f.load(); err != nil {
+		return err
+	}
+	if _, exists := f.store[key]; !exists {
+		return database.ErrNotFound
+	}
+
+	meta, err := f.loadMeta()
+	if err != nil {
+		return err
+	}
+	entry := meta[key]
+	entry.ContentType = contentType
+	entry.Tags = tags
+	entry.UpdatedAt = time.Now().UTC()
+	meta[key] = entry
+	return f.saveMeta(meta)
+}
+
+// metaFile is where the metadata of the keys is kept. It lives next to the
+// data file, which stays a plain JSON object of the values.
+func (f *FileSystem) metaFile() string {
+	return f.FileName + ".meta"
+}
+
+func (f *FileSystem) loadMeta() (map[string]database.Metadata, error) {
+	meta := make(map[string]database.Metadata)
+
+	file, err := afero.ReadFile(f.fs, f.metaFile())
+	if os.IsNotExist(err) {
+		return meta, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while reading the metadata: %w", err)
+	}
+	if len(file) > 0 {
+		if err := json.Unmarshal(file, &meta); err != nil {
+			return nil, fmt.Errorf("failed to decode the metadata: %w", err)
+		}
+	}
+	return meta, nil
+}
+
+func (f *FileSystem) saveMeta(meta map[string]database.Metadata) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding the metadata: %w", err)
+	}
+	if err := afero.WriteFile(f.fs, f.metaFile(), data, 0644); err != nil {
+		return fmt.Errorf("error writing the metadata: %w", err)
+	}
+	return nil
+}
+
+// updateMeta records a write of the keys in written and the deletion of
+// the keys in deleted. The values they had, given in previous, move to the
+// history.
+func (f *FileSystem) updateMeta(previous map[string]string, written, deleted []string) error {
+	meta, err := f.loadMeta()
+	if err != nil {
+		return err
+	}
+	history, err := f.loadHistory()
+	if err != nil {
+		return err
+	}
+	tombstones, err := f.loadTombstones()
+	if err != nil {
+		return err
+	}
+	buried := len(tombstones)
+
+	now := time.Now().UTC()
+	record := func(key string, deleted bool) {
+		old, existed := previous[key]
+		if !existed {
+			return
+		}
+		entry := meta[key]
+		version := database.Version{Version: entry.Version, Value: old, WrittenAt: entry.ValueWrittenAt(), ReplacedAt: now, Deleted: deleted}
+		history[key] = f.Retention.Prune(append(history[key], version), now)
+		if len(history[key]) == 0 {
+			delete(history, key)
+		}
+	}
+
+	for _, key := range written {
+		record(key, false)
+		entry, ok := meta[key]
+		if _, existed := previous[key]; !existed || !ok {
+			// A key created again continues the versions of its history
+			entry = database.Metadata{CreatedAt: now}
+			if past := history[key]; len(past) > 0 {
+				entry.Version = past[len(past)-1].Version
+			}
+		}
+		entry.Version++
+		entry.UpdatedAt = now
+		entry.WrittenAt = now
+		meta[key] = entry
+		// Writing a key again replaces its tombstone
+		delete(tombstones, key)
+	}
+	for _, key := range deleted {
+		record(key, true)
+		if f.SoftDelete.Enabled {
+			tombstones[key] = database.Tombstone{Value: previous[key], Metadata: meta[key], DeletedAt: now}
+		}
+		delete(meta, key)
+	}
+	if f.SoftDelete.Enabled && f.SoftDelete.GracePeriod > 0 {
+		purgeTombstones(tombstones, now.Add(-f.SoftDelete.GracePeriod))
+	}
+
+	if err := f.updateIndexes(previous, written, deleted); err != nil {
+		return err
+	}
+	if err := f.saveHistory(history); err != nil {
+		return err
+	}
+	// The tombstones file is only written once soft delete was used
+	if len(tombstones) > 0 || buried > 0 {
+		if err := f.saveTombstones(tombstones); err != nil {
+			return err
+		}
+	}
+	return f.saveMeta(meta)
+}
+
+// Undelete restores a tombstoned key, see database.Undeleter. It counts
+// as a write of the value, which gets a new version.
+func (f *FileSystem) Undelete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+	if err := f.load(); err != nil {
+		return err
+	}
+	tombstones, err := f.loadTombstones()
+	if err != nil {
+		return err
+	}
+	tombstone, ok := tombstones[key]
+	if !ok {
+		return database.ErrNotFound
+	}
+
+	f.store[key] = tombstone.Value
+	if err := f.save(); err != nil {
+		return err
+	}
+	if err := f.updateMeta(nil, []string{key}, nil); err != nil {
+		return err
+	}
+
+	meta, err := f.loadMeta()
+	if err != nil {
+		return err
+	}
+	entry := meta[key]
+	if !tombstone.Metadata.CreatedAt.IsZero() {
+		entry.CreatedAt = tombstone.Metadata.CreatedAt
+	}
+	entry.ContentType = tombstone.Metadata.ContentType
+	entry.Tags = tombstone.Metadata.Tags
+	meta[key] = entry
+	return f.saveMeta(meta)
+}
+
+// PurgeTombstones removes the keys deleted before the given time for good.
+func (f *FileSystem) PurgeTombstones(before time.Time) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	tombstones, err := f.loadTombstones()
+	if err != nil {
+		return 0, err
+	}
+	purged := purgeTombstones(tombstones, before)
+	if purged == 0 {
+		return 0, nil
+	}
+	return purged, f.saveTombstones(tombstones)
+}
+
+// IncrBy adds delta to the integer value of key, see database.Counter.
+func (f *FileSystem) IncrBy(key string, delta int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result int64
+	err := f.modify(key, func(value string) (string, error) {
+		var err error
+		result, value, err = database.AddInt(value, delta)
+		return value, err
+	})
+	return result, err
+}
+
+// IncrByFloat adds delta to the decimal value of key.
+func (f *FileSystem) IncrByFloat(key string, delta float64) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result float64
+	err := f.modify(key, func(value string) (string, error) {
+		var err error
+		result, value, err = database.AddFloat(value, delta)
+		return value, err
+	})
+	return result, err
+}
+
+// modify replaces the value of key, "" when it is missing, with the one
+// returned by change.
+func (f *FileSystem) modify(key string, change func(value string) (string, error)) error {
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+	if err := f.load(); err != nil {
+		return err
+	}
+
+	old, exists := f.store[key]
+	value, err := change(old)
+	if err != nil {
+		return err
+	}
+
+	previous := map[string]string{}
+	if exists {
+		previous[key] = old
+	}
+	f.store[key] = value
+	if err := f.save(); err != nil {
+		return err
+	}
+	return f.updateMeta(previous, []string{key}, nil)
+}
+
+func purgeTombstones(tombstones map[string]database.Tombstone, before time.Time) int {
+	purged := 0
+	for key, tombstone := range tombstones {
+		if tombstone.DeletedAt.Before(before) {
+			delete(tombstones, key)
+			purged++
+		}
+	}
+	return purged
+}
+
+// tombstonesFile keeps the keys deleted in soft delete mode, next to the
+// data file.
+func (f *FileSystem) tombstonesFile() string {
+	return f.FileName + ".tombstones"
+}
+
+func (f *FileSystem) loadTombstones() (map[string]database.Tombstone, error) {
+	tombstones := make(map[string]database.Tombstone)
+
+	file, err := afero.ReadFile(f.fs, f.tombstonesFile())
+	if os.IsNotExist(err) {
+		return tombstones, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while reading the tombstones: %w", err)
+	}
+	if len(file) > 0 {
+		if err := json.Unmarshal(file, &tombstones); err != nil {
+			return nil, fmt.Errorf("failed to decode the tombstones: %w", err)
+		}
+	}
+	return tombstones, nil
+}
+
+func (f *FileSystem) saveTombstones(tombstones map[string]database.Tombstone) error {
+	data, err := json.MarshalIndent(tombstones, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding the tombstones: %w", err)
+	}
+	if err := afero.WriteFile(f.fs, f.tombstonesFile(), data, 0644); err != nil {
+		return fmt.Errorf("error writing the tombstones: %w", err)
+	}
+	return nil
+}
+
+// History returns the versions of key, see database.HistoryStore.
+func (f *FileSystem) History(key string, limit int) ([]database.Version, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if key == "" {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+
+	current, past, err := f.versions(key)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil && len(past) == 0 {
+		return nil, database.ErrNotFound
+	}
+	return database.BuildHistory(current, past, limit), nil
+}
+
+// GetAt returns the value key had at the given time.
+func (f *FileSystem) GetAt(key string, at time.Time) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if key == "" {
+		return "", fmt.Errorf("key cannot be empty")
+	}
+
+	current, past, err := f.versions(key)
+	if err != nil {
+		return "", err
+	}
+	value, ok := database.ValueAt(current, past, at)
+	if !ok {
+		return "", database.ErrNotFound
+	}
+	return value, nil
+}
+
+// versions returns the current value of key, nil when it is missing, and
+// its retained previous values.
+func (f *FileSystem) versions(key string) (*database.Version, []database.Version, error) {
+	if err := f.load(); err != nil {
+		return nil, nil, err
+	}
+	meta, err := f.loadMeta()
+	if err != nil {
+		return nil, nil, err
+	}
+	history, err := f.loadHistory()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var current *database.Version
+	if value, exists := f.store[key]; exists {
+		current = &database.Version{Version: meta[key].Version, Value: value, WrittenAt: meta[key].ValueWrittenAt()}
+	}
+	return current, f.Retention.Prune(history[key], time.Now()), nil
+}
+
+// historyFile keeps the previous values of the keys, next to the data file.
+func (f *FileSystem) historyFile() string {
+	return f.FileName + ".history"
+}
+
+func (f *FileSystem) loadHistory() (map[string][]database.Version, error) {
+	history := make(map[string][]database.Version)
+
+	file, err := afero.ReadFile(f.fs, f.historyFile())
+	if os.IsNotExist(err) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while reading the history: %w", err)
+	}
+	if len(file) > 0 {
+		if err := json.Unmarshal(file, &history); err != nil {
+			return nil, fmt.Errorf("failed to decode the history: %w", err)
+		}
+	}
+	return history, nil
+}
+
+func (f *FileSystem) saveHistory(history map[string][]database.Version) error {
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding the history: %w", err)
+	}
+	if err := afero.WriteFile(f.fs, f.historyFile(), data, 0644); err != nil {
+		return fmt.Errorf("error writing the history: %w", err)
+	}
+	return nil
+}
+
+// JSONGet returns the JSON at path in the value of key, see
+// database.JSONStore.
+func (f *FileSystem) JSONGet(key, path string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return "", err
+	}
+	document, ok := f.store[key]
+	if !ok {
+		return "", database.ErrNotFound
+	}
+	return database.GetPath(document, path)
+}
+
+// JSONSet replaces or adds the JSON value at path in the value of key.
+func (f *FileSystem) JSONSet(key, path, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.modify(key, func(document string) (string, error) {
+		return database.SetPath(document, path, value)
+	})
+}
+
+// JSONMerge applies a merge patch to the value of key.
+func (f *FileSystem) JSONMerge(key, patch string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result string
+	err := f.modify(key, func(document string) (string, error) {
+		var err error
+		result, err = jsondoc.MergePatch(document, patch)
+		return result, err
+	})
+	return result, err
+}
+
+// JSONPatch applies a JSON patch to the value of key.
+func (f *FileSystem) JSONPatch(key, patch string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result string
+	err := f.modify(key, func(document string) (string, error) {
+		if _, ok := f.store[key]; !ok {
+			return "", database.ErrNotFound
+		}
+		var err error
+		result, err = jsondoc.Patch(document, patch)
+		return result, err
+	})
+	return result, err
+}
+
+// fileIndex is a secondary index as kept in the indexes file: the keys
+// holding every text at Path, sorted.
+type fileIndex struct {
+	Path string              `json:"path"`
+	Keys map[string][]string `json:"keys"`
+}
+
+// indexesFile keeps the secondary indexes, next to the data file.
+func (f *FileSystem) indexesFile() string {
+	return f.FileName + ".indexes"
+}
+
+func (f *FileSystem) loadIndexes() (map[string]fileIndex, error) {
+	indexes := make(map[string]fileIndex)
+
+	file, err := afero.ReadFile(f.fs, f.indexesFile())
+	if os.IsNotExist(err) {
+		return indexes, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while reading the indexes: %w", err)
+	}
+	if len(file) > 0 {
+		if err := json.Unmarshal(file, &indexes); err != nil {
+			return nil, fmt.Errorf("failed to decode the indexes: %w", err)
+		}
+	}
+	return indexes, nil
+}
+
+func (f *FileSystem) saveIndexes(indexes map[string]fileIndex) error {
+	data, err := json.MarshalIndent(indexes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding the indexes: %w", err)
+	}
+	if err := afero.WriteFile(f.fs, f.indexesFile(), data, 0644); err != nil {
+		return fmt.Errorf("error writing the indexes: %w", err)
+	}
+	return nil
+}
+
+// updateIndexes moves the keys in written and deleted from the entries of
+// the values they had, given in previous, to the ones of their values in
+// f.store.
+func (f *FileSystem) updateIndexes(previous map[string]string, written, deleted []string) error {
+	indexes, err := f.loadIndexes()
+	if err != nil || len(indexes) == 0 {
+		return err
+	}
+
+	for name, index := range indexes {
+		steps, err := database.CheckIndex(name, index.Path)
+		if err != nil {
+			return fmt.Errorf("failed to decode the indexes: %w", err)
+		}
+		for _, key := range append(slices.Clone(written), deleted...) {
+			if old, ok := previous[key]; ok {
+				if text, ok := jsondoc.Text(old, steps); ok {
+					index.remove(text, key)
+				}
+			}
+			if value, ok := f.store[key]; ok {
+				if text, ok := jsondoc.Text(value, steps); ok {
+					index.add(text, key)
+				}
+			}
+		}
+	}
+	return f.saveIndexes(indexes)
+}
+
+func (x fileIndex) add(text, key string) {
+	keys := x.Keys[text]
+	if at, found := slices.BinarySearch(keys, key); !found {
+		x.Keys[text] = slices.Insert(keys, at, key)
+	}
+}
+
+func (x fileIndex) remove(text, key string) {
+	keys := x.Keys[text]
+	if at, found := slices.BinarySearch(keys, key); found {
+		keys = slices.Delete(keys, at, at+1)
+		if len(keys) == 0 {
+			delete(x.Keys, text)
+		} else {
+			x.Keys[text] = keys
+		}
+	}
+}
+
+// CreateIndex indexes the values at path, see database.Indexer.
+func (f *FileSystem) CreateIndex(name, path string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	steps, err := database.CheckIndex(name, path)
+	if err != nil {
+		return err
+	}
+	indexes, err := f.loadIndexes()
+	if err != nil {
+		return err
+	}
+	if existing, ok := indexes[name]; ok {
+		if existing.Path != path {
+			return fmt.Errorf("%w: %s is on %s", database.ErrIndexExists, name, existing.Path)
+		}
+		return nil
+	}
+	if err := f.load(); err != nil {
+		return err
+	}
+
+	index := fileIndex{Path: path, Keys: make(map[string][]string)}
+	for key, value := range f.store {
+		if text, ok := jsondoc.Text(value, steps); ok {
+			index.Keys[text] = append(index.Keys[text], key)
+		}
+	}
+	for _, keys := range index.Keys {
+		slices.Sort(keys)
+	}
+	indexes[name] = index
+	return f.saveIndexes(indexes)
+}
+
+func (f *FileSystem) DropIndex(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	indexes, err := f.loadIndexes()
+	if err != nil {
+		return err
+	}
+	if _, ok := indexes[name]; !ok {
+		return fmt.Errorf("%w: %s", database.ErrIndexNotFound, name)
+	}
+	delete(indexes, name)
+	return f.saveIndexes(indexes)
+}
+
+func (f *FileSystem) Indexes() ([]database.Index, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	indexes, err := f.loadIndexes()
+	if err != nil {
+		return nil, err
+	}
+	result := []database.Index{}
+	for name, index := range indexes {
+		result = append(result, database.Index{Name: name, Path: index.Path})
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Name < result[b].Name })
+	return result, nil
+}
+
+// Query reads the keys from the indexes file, not the data file.
+func (f *FileSystem) Query(index, value string) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	indexes, err := f.loadIndexes()
+	if err != nil {
+		return nil, err
+	}
+	x, ok := indexes[index]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", database.ErrIndexNotFound, index)
+	}
+	keys := x.Keys[value]
+	if keys == nil {
+		keys = []string{}
+	}
+	return keys, nil
+}
+
+// namespaceFile is the data file of the namespace name, next to this one.
+// Its metadata, history and indexes files are named after it as usual.
+func (f *FileSystem) namespaceFile(name string) string {
+	return f.FileName + ".ns." + name
+}
+
+// namespacesFile keeps the settings of the namespaces by name.
+func (f *FileSystem) namespacesFile() string {
+	return f.FileName + ".namespaces"
+}
+
+func (f *FileSystem) loadNamespaces() (map[string]database.Namespace, error) {
+	namespaces := make(map[string]database.Namespace)
+
+	file, err := afero.ReadFile(f.fs, f.namespacesFile())
+	if os.IsNotExist(err) {
+		return namespaces, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while reading the namespaces: %w", err)
+	}
+	if len(file) > 0 {
+		if err := json.Unmarshal(file, &namespaces); err != nil {
+			return nil, fmt.Errorf("failed to decode the namespaces: %w", err)
+		}
+	}
+	return namespaces, nil
+}
+
+func (f *FileSystem) saveNamespaces(namespaces map[string]database.Namespace) error {
+	data, err := json.MarshalIndent(namespaces, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding the namespaces: %w", err)
+	}
+	if err := afero.WriteFile(f.fs, f.namespacesFile(), data, 0644); err != nil {
+		return fmt.Errorf("error writing the namespaces: %w", err)
+	}
+	return nil
+}
+
+// Namespace returns the store of the namespace name, see
+// database.NamespaceStore.
+func (f *FileSystem) Namespace(name string) (database.Database, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	namespaces, err := f.loadNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	ns, err := f.namespace(name, namespaces)
+	if err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+// namespace returns the store of the namespace name, registering it in
+// namespaces when it is new.
+func (f *FileSystem) namespace(name string, namespaces map[string]database.Namespace) (*FileSystem, error) {
+	if f.parent != nil {
+		return nil, errors.New("namespaces cannot be nested")
+	}
+	if err := database.CheckNamespace(name); err != nil {
+		return nil, err
+	}
+	if _, ok := namespaces[name]; !ok {
+		namespaces[name] = database.Namespace{Name: name}
+		if err := f.saveNamespaces(namespaces); err != nil {
+			return nil, err
+		}
+	}
+	if ns, ok := f.namespaces[name]; ok {
+		return ns, nil
+	}
+
+	ns, err := NewFileSystemWithFS(f.namespaceFile(name), f.fs)
+	if err != nil {
+		return nil, err
+	}
+	ns.Retention = f.Retention
+	ns.SoftDelete = f.SoftDelete
+	ns.parent = f
+
+	if f.namespaces == nil {
+		f.namespaces = make(map[string]*FileSystem)
+	}
+	f.namespaces[name] = ns
+	return ns, nil
+}
+
+func (f *FileSystem) Namespaces() ([]database.Namespace, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	namespaces, err := f.loadNamespaces()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]database.Namespace, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		result = append(result, namespace)
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Name < result[b].Name })
+	return result, nil
+}
+
+func (f *FileSystem) SetNamespaceTTL(name string, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if ttl < 0 {
+		return fmt.Errorf("invalid TTL %s, expected zero or more", ttl)
+	}
+	namespaces, err := f.loadNamespaces()
+	if err != nil {
+		return err
+	}
+	if _, err := f.namespace(name, namespaces); err != nil {
+		return err
+	}
+	namespaces[name] = database.Namespace{Name: name, DefaultTTL: ttl}
+	return f.saveNamespaces(namespaces)
+}
+
+// DropNamespace removes the files of the namespace.
+func (f *FileSystem) DropNamespace(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	namespaces, err := f.loadNamespaces()
+	if err != nil {
+		return err
+	}
+	if _, ok := namespaces[name]; !ok {
+		return fmt.Errorf("%w: %s", database.ErrNamespaceNotFound, name)
+	}
+
+	ns, ok := f.namespaces[name]
+	if !ok {
+		ns = &FileSystem{FileName: f.namespaceFile(name), fs: f.fs}
+	}
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+	for _, file := range []string{ns.FileName, ns.metaFile(), ns.historyFile(), ns.tombstonesFile(), ns.indexesFile()} {
+		if err := f.fs.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing the namespace: %w", err)
+		}
+	}
+
+	delete(f.namespaces, name)
+	delete(namespaces, name)
+	return f.saveNamespaces(namespaces)
+}
+
+// Expire deletes the keys written before the given time, see
+// database.Expirer. Keys written before metadata was recorded are kept.
+func (f *FileSystem) Expire(before time.Time) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.load(); err != nil {
+		return 0, err
+	}
+	meta, err := f.loadMeta()
+	if err != nil {
+		return 0, err
+	}
+	expired := []string{}
+	for key, entry := range meta {
+		if !entry.UpdatedAt.IsZero() && entry.UpdatedAt.Before(before) {
+			expired = append(expired, key)
+		}
+	}
+	deleted, err := f.remove(expired)
+	return len(deleted), err
+}
@@ -3,7 +3,9 @@ package filesystem
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/imsumedhaa/In-memory-database/database"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 )
@@ -334,3 +336,517 @@ func TestShow(t *testing.T){
 		})
 	}
 }
+
+func TestMultiGet(t *testing.T) {
+	tests := []struct {
+		name           string
+		keys           []string
+		initialStore   map[string]string
+		expectedError  string
+		expectedValues map[string]string
+	}{
+		{
+			name:           "Get existing and missing keys",
+			keys:           []string{"name", "age"},
+			initialStore:   map[string]string{"name": "abc", "city": "xyz"},
+			expectedError:  "",
+			expectedValues: map[string]string{"name": "abc"},
+		},
+		{
+			name:           "Empty key",
+			keys:           []string{""},
+			initialStore:   map[string]string{"name": "abc"},
+			expectedError:  "key cannot be empty",
+			expectedValues: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			filename := "test.json"
+
+			data, _ := json.MarshalIndent(tt.initialStore, "", "  ")
+			_ = afero.WriteFile(fs, filename, data, 0644)
+
+			store, err := NewFileSystemWithFS(filename, fs)
+			assert.NoError(t, err)
+
+			values, err := store.MultiGet(tt.keys)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedValues, values)
+		})
+	}
+}
+
+func TestMultiSet(t *testing.T) {
+	tests := []struct {
+		name          string
+		pairs         map[string]string
+		initialStore  map[string]string
+		expectedError string
+		expectedStore map[string]string
+	}{
+		{
+			name:          "Set new and existing keys",
+			pairs:         map[string]string{"name": "foo", "age": "19"},
+			initialStore:  map[string]string{"name": "abc"},
+			expectedError: "",
+			expectedStore: map[string]string{"name": "foo", "age": "19"},
+		},
+		{
+			name:          "Set into an empty file",
+			pairs:         map[string]string{"name": "foo"},
+			initialStore:  map[string]string{},
+			expectedError: "",
+			expectedStore: map[string]string{"name": "foo"},
+		},
+		{
+			name:          "Empty value leaves the file untouched",
+			pairs:         map[string]string{"name": "foo", "age": ""},
+			initialStore:  map[string]string{"name": "abc"},
+			expectedError: "key and value cannot be empty",
+			expectedStore: map[string]string{"name": "abc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			filename := "test.json"
+
+			if len(tt.initialStore) > 0 {
+				data, _ := json.MarshalIndent(tt.initialStore, "", "  ")
+				_ = afero.WriteFile(fs, filename, data, 0644)
+			}
+
+			store, err := NewFileSystemWithFS(filename, fs)
+			assert.NoError(t, err)
+
+			err = store.MultiSet(tt.pairs)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			data, _ := afero.ReadFile(fs, filename)
+			var actual map[string]string
+			_ = json.Unmarshal(data, &actual)
+
+			assert.Equal(t, normalizeMap(tt.expectedStore), normalizeMap(actual))
+		})
+	}
+}
+
+func TestMultiDelete(t *testing.T) {
+	tests := []struct {
+		name            string
+		keys            []string
+		initialStore    map[string]string
+		expectedError   string
+		expectedDeleted []string
+		expectedStore   map[string]string
+	}{
+		{
+			name:            "Delete existing and missing keys",
+			keys:            []string{"name", "age"},
+			initialStore:    map[string]string{"name": "abc", "city": "xyz"},
+			expectedError:   "",
+			expectedDeleted: []string{"name"},
+			expectedStore:   map[string]string{"city": "xyz"},
+		},
+		{
+			name:            "Empty key",
+			keys:            []string{"name", ""},
+			initialStore:    map[string]string{"name": "abc"},
+			expectedError:   "key cannot be empty",
+			expectedDeleted: nil,
+			expectedStore:   map[string]string{"name": "abc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			filename := "test.json"
+
+			data, _ := json.MarshalIndent(tt.initialStore, "", "  ")
+			_ = afero.WriteFile(fs, filename, data, 0644)
+
+			store, err := NewFileSystemWithFS(filename, fs)
+			assert.NoError(t, err)
+
+			deleted, err := store.MultiDelete(tt.keys)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedDeleted, deleted)
+
+			data, _ = afero.ReadFile(fs, filename)
+			var actual map[string]string
+			_ = json.Unmarshal(data, &actual)
+
+			assert.Equal(t, normalizeMap(tt.expectedStore), normalizeMap(actual))
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		prefix        string
+		initialStore  map[string]string
+		expectedKeys  []string
+		expectedError string
+	}{
+		{
+			name:         "All keys sorted",
+			prefix:       "",
+			initialStore: map[string]string{"user:2": "b", "city": "xyz", "user:1": "a"},
+			expectedKeys: []string{"city", "user:1", "user:2"},
+		},
+		{
+			name:         "Keys with prefix",
+			prefix:       "user:",
+			initialStore: map[string]string{"user:2": "b", "city": "xyz", "user:1": "a"},
+			expectedKeys: []string{"user:1", "user:2"},
+		},
+		{
+			name:         "Empty file",
+			prefix:       "",
+			initialStore: map[string]string{},
+			expectedKeys: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			filename := "test.json"
+
+			if len(tt.initialStore) > 0 {
+				data, _ := json.MarshalIndent(tt.initialStore, "", "  ")
+				_ = afero.WriteFile(fs, filename, data, 0644)
+			}
+
+			store, err := NewFileSystemWithFS(filename, fs)
+			assert.NoError(t, err)
+
+			keys, err := store.Keys(tt.prefix)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedKeys, keys)
+		})
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filename := "test.json"
+
+	store, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+
+	assert.NoError(t, store.Create("name", "abc"))
+	created, err := store.Stat("name")
+	assert.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+	assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+	assert.NoError(t, store.SetMetadata("name", "text/plain", []string{"user"}))
+	tagged, err := store.Stat("name")
+	assert.NoError(t, err)
+	assert.True(t, tagged.UpdatedAt.After(created.UpdatedAt), "changing the metadata refreshes updated_at")
+	assert.Equal(t, created.WrittenAt, tagged.WrittenAt, "changing the metadata keeps the write time of the value")
+
+	assert.NoError(t, store.MultiSet(map[string]string{"name": "foo", "age": "19"}))
+	updated, err := store.Stat("name")
+	assert.NoError(t, err)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+	assert.False(t, updated.UpdatedAt.Before(created.UpdatedAt))
+	assert.Equal(t, "text/plain", updated.ContentType)
+	assert.Equal(t, []string{"user"}, updated.Tags)
+
+	// The data file keeps holding only the values
+	data, _ := afero.ReadFile(fs, filename)
+	var actual map[string]string
+	assert.NoError(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, map[string]string{"name": "foo", "age": "19"}, actual)
+
+	_, err = store.MultiDelete([]string{"name"})
+	assert.NoError(t, err)
+	_, err = store.Stat("name")
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	data, _ = afero.ReadFile(fs, filename+".meta")
+	var meta map[string]database.Metadata
+	assert.NoError(t, json.Unmarshal(data, &meta))
+	assert.NotContains(t, meta, "name")
+	assert.Contains(t, meta, "age")
+}
+
+func TestStat_WithoutMetadata(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filename := "test.json"
+	_ = afero.WriteFile(fs, filename, []byte(`{"name": "abc"}`), 0644)
+
+	store, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+
+	meta, err := store.Stat("name")
+	assert.NoError(t, err)
+	assert.Equal(t, database.Metadata{}, meta)
+}
+
+func TestHistory(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filename := "test.json"
+
+	store, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	store.Retention = database.Retention{MaxVersions: 2}
+
+	assert.NoError(t, store.Create("name", "v1"))
+	assert.NoError(t, store.Update("name", "v2"))
+	assert.NoError(t, store.MultiSet(map[string]string{"name": "v3"}))
+	assert.NoError(t, store.SetMetadata("name", "text/plain", nil))
+	assert.NoError(t, store.Update("name", "v4"))
+	_, err = store.MultiDelete([]string{"name"})
+	assert.NoError(t, err)
+
+	// The history is kept in a file of its own and survives a restart
+	reopened, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+
+	history, err := reopened.History("name", 0)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2, "only the last two previous values are kept")
+	assert.Equal(t, "v4", history[0].Value)
+	assert.Equal(t, int64(4), history[0].Version)
+	assert.True(t, history[0].Deleted)
+	assert.Equal(t, "v3", history[1].Value)
+	assert.False(t, history[1].Deleted)
+
+	value, err := reopened.GetAt("name", history[1].WrittenAt)
+	assert.NoError(t, err)
+	assert.Equal(t, "v3", value)
+	_, err = reopened.GetAt("name", time.Now())
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	assert.NoError(t, reopened.Create("name", "v5"))
+	current, err := reopened.History("name", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []database.Version{{Version: 5, Value: "v5", WrittenAt: current[0].WrittenAt}}, current)
+
+	_, err = reopened.History("age", 0)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestSoftDelete(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filename := "test.json"
+
+	store, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Create("name", "abc"))
+	assert.NoError(t, store.SetMetadata("name", "text/plain", nil))
+	exists, _ := afero.Exists(fs, filename+".tombstones")
+	assert.False(t, exists, "the tombstones file is only written in soft delete mode")
+
+	store.SoftDelete = database.SoftDelete{Enabled: true}
+	assert.NoError(t, store.Delete("name"))
+
+	// The data file only holds the live keys
+	data, _ := afero.ReadFile(fs, filename)
+	var actual map[string]string
+	assert.NoError(t, json.Unmarshal(data, &actual))
+	assert.Empty(t, actual)
+
+	reopened, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	assert.NoError(t, reopened.Undelete("name"))
+	values, _ := reopened.MultiGet([]string{"name"})
+	assert.Equal(t, map[string]string{"name": "abc"}, values)
+	meta, _ := reopened.Stat("name")
+	assert.Equal(t, "text/plain", meta.ContentType)
+	assert.ErrorIs(t, reopened.Undelete("name"), database.ErrNotFound)
+
+	store.SoftDelete = database.SoftDelete{Enabled: true}
+	_, err = store.MultiDelete([]string{"name"})
+	assert.NoError(t, err)
+	purged, err := store.PurgeTombstones(time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, purged)
+	assert.ErrorIs(t, store.Undelete("name"), database.ErrNotFound)
+}
+
+func TestBinaryValues(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filename := "test.json"
+	binary := string([]byte{0x89, 'P', 'N', 'G', 0x00, 0xff})
+
+	store, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Create("logo", binary))
+	assert.NoError(t, store.MultiSet(map[string]string{"name": "abc"}))
+	assert.NoError(t, store.Update("logo", binary+"v2"))
+
+	// Text stays readable, the binary value is written in base64
+	data, _ := afero.ReadFile(fs, filename)
+	var actual map[string]any
+	assert.NoError(t, json.Unmarshal(data, &actual))
+	assert.Equal(t, map[string]any{
+		"logo": map[string]any{"base64": "iVBORwD/djI="},
+		"name": "abc",
+	}, actual)
+
+	reopened, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	values, err := reopened.MultiGet([]string{"logo", "name"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"logo": binary + "v2", "name": "abc"}, values)
+
+	history, err := reopened.History("logo", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, binary, history[1].Value, "the history file keeps binary values too")
+}
+
+func TestIncrBy(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filename := "test.json"
+
+	store, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+
+	result, err := store.IncrBy("hits", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), result)
+	result, err = store.IncrBy("hits", -1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result)
+
+	price, err := store.IncrByFloat("price", 0.25)
+	assert.NoError(t, err)
+	assert.Equal(t, 0.25, price)
+
+	reopened, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	values, _ := reopened.MultiGet([]string{"hits", "price"})
+	assert.Equal(t, map[string]string{"hits": "1", "price": "0.25"}, values)
+	history, _ := reopened.History("hits", 0)
+	assert.Len(t, history, 2, "every increment is a version")
+
+	_, err = reopened.IncrBy("price", 1)
+	assert.ErrorIs(t, err, database.ErrNotNumber)
+}
+
+func TestJSON(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filename := "test.json"
+
+	store, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+
+	_, err = store.JSONPatch("user", `[]`)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	document, err := store.JSONMerge("user", `{"name":"Alice","status":null}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"Alice"}`, document)
+	assert.NoError(t, store.JSONSet("user", "$.status", `"active"`))
+
+	reopened, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	status, err := reopened.JSONGet("user", "$.status")
+	assert.NoError(t, err)
+	assert.Equal(t, `"active"`, status)
+
+	_, err = reopened.JSONPatch("user", `[{"op":"replace","path":"/name","value":"Bob"},{"op":"remove","path":"/email"}]`)
+	assert.ErrorIs(t, err, database.ErrPatchFailed)
+	name, _ := reopened.JSONGet("user", "$.name")
+	assert.Equal(t, `"Alice"`, name, "a failed patch changes nothing")
+
+	history, _ := reopened.History("user", 0)
+	assert.Len(t, history, 2, "every change is a version")
+}
+
+func TestIndexes(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filename := "test.json"
+
+	store, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	assert.NoError(t, store.MultiSet(map[string]string{"user:1": `{"status":"active"}`, "user:2": `{"status":"new"}`}))
+
+	assert.NoError(t, store.CreateIndex("status", "$.status"))
+	assert.ErrorIs(t, store.CreateIndex("status", "$.state"), database.ErrIndexExists)
+
+	_, err = store.JSONMerge("user:2", `{"status":"active"}`)
+	assert.NoError(t, err)
+	assert.NoError(t, store.MultiSet(map[string]string{"user:3": `{"status":"active"}`}))
+	_, err = store.MultiDelete([]string{"user:1"})
+	assert.NoError(t, err)
+
+	reopened, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	keys, err := reopened.Query("status", "active")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:2", "user:3"}, keys)
+	keys, _ = reopened.Query("status", "new")
+	assert.Equal(t, []string{}, keys)
+
+	indexes, _ := reopened.Indexes()
+	assert.Equal(t, []database.Index{{Name: "status", Path: "$.status"}}, indexes)
+	assert.NoError(t, reopened.DropIndex("status"))
+	_, err = reopened.Query("status", "active")
+	assert.ErrorIs(t, err, database.ErrIndexNotFound)
+}
+
+func TestNamespaces(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	filename := "test.json"
+
+	store, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	assert.NoError(t, store.MultiSet(map[string]string{"key": "root"}))
+
+	billing, err := store.Namespace("billing")
+	assert.NoError(t, err)
+	assert.NoError(t, billing.MultiSet(map[string]string{"key": "billing", "old": "x"}))
+	assert.NoError(t, store.SetNamespaceTTL("billing", time.Hour))
+
+	reopened, err := NewFileSystemWithFS(filename, fs)
+	assert.NoError(t, err)
+	values, _ := reopened.MultiGet([]string{"key"})
+	assert.Equal(t, map[string]string{"key": "root"}, values)
+	namespaces, err := reopened.Namespaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []database.Namespace{{Name: "billing", DefaultTTL: time.Hour}}, namespaces)
+
+	billing, _ = reopened.Namespace("billing")
+	values, _ = billing.MultiGet([]string{"key"})
+	assert.Equal(t, map[string]string{"key": "billing"}, values)
+
+	expired, err := billing.(database.Expirer).Expire(time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, expired)
+
+	assert.NoError(t, reopened.DropNamespace("billing"))
+	assert.ErrorIs(t, reopened.DropNamespace("billing"), database.ErrNamespaceNotFound)
+	exists, _ := afero.Exists(fs, filename+".ns.billing")
+	assert.False(t, exists, "the files of the namespace are removed")
+}
@@ -0,0 +1,371 @@
+package inmemory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// The sizes added to the bytes of keys and values for the maps, slices and
+// metadata holding them. They are rough, so the memory counted stays
+// below what the process uses and a limit needs some headroom.
+const (
+	keyOverhead     = 64
+	stringOverhead  = 96
+	versionOverhead = 64
+	elementOverhead = 24
+	memberOverhead  = 72
+)
+
+// evictionSamples is how many keys of every store are compared to choose
+// the key to evict. Like Redis, the policies are approximated by sampling
+// rather than keeping the keys ordered.
+const evictionSamples = 16
+
+// usage is what the eviction policies know about a key.
+type usage struct {
+	size int64
+	// last is the clock of the root when the key was last read or written
+	last uint64
+	hits uint64
+}
+
+// change is a key written by the operation holding the lock, in the store
+// of the root or a namespace.
+type change struct {
+	store *Inmemory
+	key   string
+}
+
+// SetLimits bounds the keys and memory of the store and its namespaces
+// together, see database.Limits. The keys are counted again, and evicted
+// when the store is already over the limits.
+func (i *Inmemory) SetLimits(limits database.Limits) {
+	i.lock()
+	defer i.unlock()
+
+	root := i.root()
+	root.limits = limits
+	root.recount()
+	root.enforce()
+}
+
+// MemoryStats returns the keys and approximate memory of the store and its
+// namespaces, see database.MemoryReporter.
+func (i *Inmemory) MemoryStats() database.MemoryStats {
+	i.lock()
+	defer i.unlock()
+
+	root := i.root()
+	if !root.limits.Enabled() {
+		// Without limits the keys are not accounted for as they change
+		root.recount()
+	}
+	stats := database.MemoryStats{
+		Limits:    root.limits,
+		Keys:      root.keys,
+		Bytes:     root.bytes,
+		Evictions: root.evictions,
+		Rejected:  root.rejected,
+	}
+	if stats.Policy == "" {
+		stats.Policy = database.RejectWrites
+	}
+	return stats
+}
+
+// changed marks key as written, so its size is counted again when the
+// lock is released.
+func (i *Inmemory) changed(key string) {
+	i.dirty = true
+	i.logWrite(key)
+	root := i.root()
+	if !root.limits.Enabled() {
+		return
+	}
+	if root.pending == nil {
+		root.pending = make(map[change]struct{})
+	}
+	root.pending[change{i, key}] = struct{}{}
+}
+
+// accessed marks key as read for the LRU and LFU policies.
+func (i *Inmemory) accessed(key string) {
+	root := i.root()
+	if u, ok := i.usage[key]; ok && root.limits.Enabled() {
+		root.clock++
+		u.last = root.clock
+		u.hits++
+	}
+}
+
+// admit returns an error wrapping database.ErrMemoryLimit when the store
+// is full and writing keys would make it grow, evicting other keys first
+// when the policy allows it.
+func (i *Inmemory) admit(keys ...string) error {
+	root := i.root()
+	if !root.limits.Enabled() {
+		return nil
+	}
+	exclude := make(map[change]struct{}, len(keys))
+	for _, key := range keys {
+		exclude[change{i, key}] = struct{}{}
+	}
+	for root.full(i, keys) {
+		if !root.evicts() || !root.evictOne(exclude) {
+			root.rejected++
+			return fmt.Errorf("%w: %d keys and %d bytes used", database.ErrMemoryLimit, root.keys, root.bytes)
+		}
+	}
+	return nil
+}
+
+// full tells whether the root has no room left for writing keys in store.
+func (i *Inmemory) full(store *Inmemory, keys []string) bool {
+	if i.limits.MaxMemory > 0 && i.bytes >= i.limits.MaxMemory {
+		return true
+	}
+	if i.limits.MaxKeys <= 0 {
+		return false
+	}
+	added := 0
+	for _, key := range keys {
+		if _, ok := store.usage[key]; !ok {
+			added++
+		}
+	}
+	return added > 0 && i.keys+added > i.limits.MaxKeys
+}
+
+// account counts the size of the keys written since the lock was taken,
+// then evicts keys until the root is within its limits again.
+func (i *Inmemory) account() {
+	if !i.limits.Enabled() || len(i.pending) == 0 {
+		return
+	}
+	for c := range i.pending {
+		i.clock++
+		c.store.resize(c.key, i.clock)
+	}
+	i.enforce()
+	i.pending = nil
+}
+
+// resize counts the size of key again after a write at clock.
+func (i *Inmemory) resize(key string, clock uint64) {
+	root := i.root()
+	u, ok := i.usage[key]
+	if !ok {
+		u = &usage{}
+	}
+	size := i.sizeOf(key)
+	if size == 0 {
+		if ok {
+			root.keys--
+			root.bytes -= u.size
+			delete(i.usage, key)
+		}
+		return
+	}
+	if !ok {
+		if i.usage == nil {
+			i.usage = make(map[string]*usage)
+		}
+		i.usage[key] = u
+		root.keys++
+	}
+	root.bytes += size - u.size
+	u.size = size
+	u.last = clock
+	u.hits++
+}
+
+// enforce evicts keys, except the ones written by the current operation,
+// until the root is within its limits or none is left to evict.
+func (i *Inmemory) enforce() {
+	if !i.evicts() {
+		return
+	}
+	for (i.limits.MaxMemory > 0 && i.bytes > i.limits.MaxMemory) || (i.limits.MaxKeys > 0 && i.keys > i.limits.MaxKeys) {
+		if !i.evictOne(i.pending) {
+			return
+		}
+	}
+}
+
+// evicts tells whether the policy of the root makes room by evicting keys.
+func (i *Inmemory) evicts() bool {
+	return i.limits.Policy != "" && i.limits.Policy != database.RejectWrites
+}
+
+// recount accounts for every key of the root and its namespaces from
+// scratch, with no read recorded yet.
+func (i *Inmemory) recount() {
+	i.keys, i.bytes = 0, 0
+	for _, store := range i.stores() {
+		store.usage = make(map[string]*usage)
+		count := func(key string) {
+			size := store.sizeOf(key)
+			store.usage[key] = &usage{size: size}
+			i.keys++
+			i.bytes += size
+		}
+		forEachKey(store.store, count)
+		forEachKey(store.lists, count)
+		forEachKey(store.sets, count)
+		forEachKey(store.hashes, count)
+		forEachKey(store.zsets, count)
+	}
+}
+
+// stores returns the root and its namespaces.
+func (i *Inmemory) stores() []*Inmemory {
+	stores := []*Inmemory{i}
+	for _, name := range sortedKeys(i.namespaces) {
+		stores = append(stores, i.namespaces[name])
+	}
+	return stores
+}
+
+// evictOne evicts the key chosen by the policy among a sample of every
+// store, skipping the keys in exclude. It returns false when there is no
+// key to evict.
+func (i *Inmemory) evictOne(exclude map[change]struct{}) bool {
+	var victim *change
+	var best float64
+	for _, store := range i.stores() {
+		if i.limits.Policy == database.EvictVolatileTTL && store.ttl <= 0 {
+			continue
+		}
+		sampled := 0
+		for key, u := range store.usage {
+			if sampled == evictionSamples {
+				break
+			}
+			if _, ok := exclude[change{store, key}]; ok {
+				continue
+			}
+			score, ok := store.evictionScore(key, u)
+			if !ok {
+				continue
+			}
+			sampled++
+			if victim == nil || score < best {
+				victim, best = &change{store, key}, score
+			}
+		}
+	}
+	if victim == nil {
+		return false
+	}
+	victim.store.evict(victim.key)
+	return true
+}
+
+// evictionScore orders the keys by the policy, the lowest is evicted
+// first. It returns false for the keys the policy never evicts.
+func (i *Inmemory) evictionScore(key string, u *usage) (float64, bool) {
+	switch i.root().limits.Policy {
+	case database.EvictLRU:
+		return float64(u.last), true
+	case database.EvictLFU:
+		// The fewest hits, then the least recent
+		return float64(u.hits)*(1<<32) + float64(u.last), true
+	case database.EvictVolatileTTL:
+		expiresAt, ok := i.expiresAt(key)
+		return float64(expiresAt.UnixNano()), ok
+	}
+	// Random: the iteration order of the usage map is random already
+	return 0, true
+}
+
+// Drop deletes keys for good, without keeping their history or a
+// tombstone, like an eviction. Caches use it to forget values.
+func (i *Inmemory) Drop(keys ...string) {
+	i.lock()
+	defer i.unlock()
+
+	for _, key := range keys {
+		i.drop(key)
+	}
+}
+
+// evict drops key to make room.
+func (i *Inmemory) evict(key string) {
+	i.drop(key)
+	i.root().evictions++
+}
+
+// drop deletes key with its history and without a tombstone, and removes
+// it from the accounting.
+func (i *Inmemory) drop(key string) {
+	root := i.root()
+	if u, ok := i.usage[key]; ok {
+		root.keys--
+		root.bytes -= u.size
+		delete(i.usage, key)
+	}
+	delete(i.store, key)
+	delete(i.meta, key)
+	delete(i.history, key)
+	delete(i.tombstones, key)
+	i.dropComposite(key)
+	i.reindex(key)
+	i.dirty = true
+	i.logWrite(key)
+}
+
+// sizeOf returns the approximate memory used by key with its value,
+// metadata and history, zero when the key is missing.
+func (i *Inmemory) sizeOf(key string) int64 {
+	var size int64
+	if value, ok := i.store[key]; ok {
+		meta := i.meta[key]
+		size += int64(len(value)+len(meta.ContentType)) + stringOverhead
+		for _, tag := range meta.Tags {
+			size += int64(len(tag)) + elementOverhead
+		}
+		for _, version := range i.history[key] {
+			size += int64(len(version.Value)) + versionOverhead
+		}
+	} else if list, ok := i.lists[key]; ok {
+		for _, element := range list {
+			size += int64(len(element)) + elementOverhead
+		}
+	} else if set, ok := i.sets[key]; ok {
+		for member := range set {
+			size += int64(len(member)) + elementOverhead
+		}
+	} else if hash, ok := i.hashes[key]; ok {
+		for field, value := range hash {
+			size += int64(len(field)+len(value)) + 2*elementOverhead
+		}
+	} else if zset, ok := i.zsets[key]; ok {
+		// Every member is in the map of scores and a node of the skiplist
+		for member := range zset.scores {
+			size += 2*int64(len(member)) + memberOverhead
+		}
+	} else {
+		return 0
+	}
+	return size + int64(len(key)) + keyOverhead
+}
+
+// forEachKey calls fn with every key of m, to walk the maps of every type
+// alike.
+func forEachKey[V any](m map[string]V, fn func(key string)) {
+	for key := range m {
+		fn(key)
+	}
+}
+
+// expiresAt returns when key expires. Only the strings of a namespace with
+// a TTL do.
+func (i *Inmemory) expiresAt(key string) (time.Time, bool) {
+	meta, ok := i.meta[key]
+	if !ok || i.ttl <= 0 {
+		return time.Time{}, false
+	}
+	return meta.UpdatedAt.Add(i.ttl), true
+}
@@ -0,0 +1,146 @@
+package inmemory
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits_RejectWrites(t *testing.T) {
+	inmem, _ := NewInmemory()
+	inmem.SetLimits(database.Limits{MaxKeys: 2})
+	_ = inmem.MultiSet(map[string]string{"a": "1", "b": "2"})
+
+	assert.ErrorIs(t, inmem.MultiSet(map[string]string{"c": "3"}), database.ErrMemoryLimit)
+	_, err := inmem.RPush("list", "x")
+	assert.ErrorIs(t, err, database.ErrMemoryLimit)
+	assert.NoError(t, inmem.MultiSet(map[string]string{"a": "updated"}), "existing keys can be written")
+
+	_, _ = inmem.MultiDelete([]string{"b"})
+	assert.NoError(t, inmem.MultiSet(map[string]string{"c": "3"}))
+
+	stats := inmem.MemoryStats()
+	assert.Equal(t, 2, stats.Keys)
+	assert.Equal(t, int64(2), stats.Rejected)
+	assert.Equal(t, int64(0), stats.Evictions)
+	assert.Equal(t, database.RejectWrites, stats.Policy)
+}
+
+func TestLimits_Policies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy database.EvictionPolicy
+		reads  []string
+		want   []string
+	}{
+		{
+			name:   "lru evicts the key read least recently",
+			policy: database.EvictLRU,
+			reads:  []string{"a", "c", "b"},
+			want:   []string{"b", "c", "d"},
+		},
+		{
+			name:   "lfu evicts the key read least often",
+			policy: database.EvictLFU,
+			reads:  []string{"a", "a", "b", "c", "c"},
+			want:   []string{"a", "c", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inmem, _ := NewInmemory()
+			inmem.SetLimits(database.Limits{MaxKeys: 3, Policy: tt.policy})
+			for _, key := range []string{"a", "b", "c"} {
+				_ = inmem.MultiSet(map[string]string{key: "value"})
+			}
+			for _, key := range tt.reads {
+				_, _ = inmem.MultiGet([]string{key})
+			}
+
+			assert.NoError(t, inmem.MultiSet(map[string]string{"d": "value"}))
+			keys, _ := inmem.Keys("")
+			assert.Equal(t, tt.want, keys)
+			assert.Equal(t, int64(1), inmem.MemoryStats().Evictions)
+		})
+	}
+}
+
+func TestLimits_VolatileTTL(t *testing.T) {
+	inmem, _ := NewInmemory()
+	_ = inmem.SetNamespaceTTL("cache", time.Hour)
+	cache, _ := inmem.Namespace("cache")
+	inmem.SetLimits(database.Limits{MaxKeys: 3, Policy: database.EvictVolatileTTL})
+
+	_ = inmem.MultiSet(map[string]string{"kept": "1"})
+	_ = cache.MultiSet(map[string]string{"old": "1"})
+	time.Sleep(time.Millisecond)
+	_ = cache.MultiSet(map[string]string{"new": "1"})
+
+	// The write to the root evicts the key of the namespace expiring first
+	assert.NoError(t, inmem.MultiSet(map[string]string{"other": "1"}))
+	keys, _ := cache.Keys("")
+	assert.Equal(t, []string{"new"}, keys)
+
+	assert.NoError(t, inmem.MultiSet(map[string]string{"more": "1"}))
+	assert.ErrorIs(t, inmem.MultiSet(map[string]string{"last": "1"}), database.ErrMemoryLimit, "keys without a TTL are never evicted")
+	keys, _ = inmem.Keys("")
+	assert.Equal(t, []string{"kept", "more", "other"}, keys)
+}
+
+func TestLimits_MaxMemory(t *testing.T) {
+	inmem, _ := NewInmemory()
+	_ = inmem.MultiSet(map[string]string{"before": strings.Repeat("x", 1000)})
+	inmem.SetLimits(database.Limits{MaxMemory: 4096, Policy: database.EvictRandom})
+	assert.Equal(t, 1, inmem.MemoryStats().Keys, "the keys written before are counted")
+
+	for n := range 20 {
+		assert.NoError(t, inmem.MultiSet(map[string]string{fmt.Sprint("key", n): strings.Repeat("x", 1000)}))
+		assert.LessOrEqual(t, inmem.MemoryStats().Bytes, int64(4096))
+	}
+	_, err := inmem.HSet("hash", "field", strings.Repeat("x", 1000))
+	assert.NoError(t, err)
+
+	stats := inmem.MemoryStats()
+	assert.Greater(t, stats.Evictions, int64(15))
+	assert.Less(t, stats.Keys, 5)
+	assert.Greater(t, stats.Bytes, int64(1000))
+	keys, _ := inmem.Keys("")
+	assert.Contains(t, keys, "hash", "the key written last is never evicted")
+
+	// Deleting frees the memory of the key
+	_, _ = inmem.MultiDelete(keys)
+	assert.Equal(t, database.MemoryStats{Limits: database.Limits{MaxMemory: 4096, Policy: database.EvictRandom}, Evictions: stats.Evictions}, inmem.MemoryStats())
+}
+
+func TestInmemory_Drop(t *testing.T) {
+	inmem, _ := NewInmemory()
+	_ = inmem.MultiSet(map[string]string{"a": "1", "b": "2"})
+	_, _ = inmem.SAdd("set", "x")
+
+	inmem.Drop("a", "set", "missing")
+	keys, _ := inmem.Keys("")
+	assert.Equal(t, []string{"b"}, keys)
+	assert.ErrorIs(t, inmem.Undelete("a"), database.ErrNotFound, "dropped keys leave no history")
+	assert.Equal(t, 1, inmem.MemoryStats().Keys)
+}
+
+func TestLimits_SetLimitsEvicts(t *testing.T) {
+	inmem, _ := NewInmemory()
+	billing, _ := inmem.Namespace("billing")
+	_ = inmem.MultiSet(map[string]string{"a": "1", "b": "2"})
+	_ = billing.MultiSet(map[string]string{"a": "1", "b": "2"})
+	assert.Equal(t, 4, inmem.MemoryStats().Keys, "the namespaces count with their parent")
+
+	inmem.SetLimits(database.Limits{MaxKeys: 1, Policy: database.EvictLRU})
+	stats := billing.(database.MemoryReporter).MemoryStats()
+	assert.Equal(t, 1, stats.Keys)
+	assert.Equal(t, int64(3), stats.Evictions)
+
+	assert.NoError(t, inmem.DropNamespace("billing"))
+	assert.LessOrEqual(t, inmem.MemoryStats().Keys, 1)
+}
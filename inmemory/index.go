@@ -0,0 +1,115 @@
+package inmemory
+
+import (
+	"fmt"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/jsondoc"
+)
+
+// valueIndex maps the text at a JSON path to the keys holding it, and back,
+// so a write can drop the entry of the value it replaces.
+type valueIndex struct {
+	path   string
+	steps  []string
+	keys   map[string]map[string]struct{}
+	values map[string]string
+}
+
+// update moves key to the entry of its new value, if it is indexed.
+func (x *valueIndex) update(key, value string, present bool) {
+	if old, ok := x.values[key]; ok {
+		delete(x.keys[old], key)
+		if len(x.keys[old]) == 0 {
+			delete(x.keys, old)
+		}
+		delete(x.values, key)
+	}
+	if !present {
+		return
+	}
+	text, ok := jsondoc.Text(value, x.steps)
+	if !ok {
+		return
+	}
+	if x.keys[text] == nil {
+		x.keys[text] = make(map[string]struct{})
+	}
+	x.keys[text][key] = struct{}{}
+	x.values[key] = text
+}
+
+// reindex updates every index for a write or a removal of key.
+func (i *Inmemory) reindex(key string) {
+	value, present := i.store[key]
+	for _, index := range i.indexes {
+		index.update(key, value, present)
+	}
+}
+
+// CreateIndex indexes the values at path, see database.Indexer.
+func (i *Inmemory) CreateIndex(name, path string) error {
+	i.lock()
+	defer i.unlock()
+
+	steps, err := database.CheckIndex(name, path)
+	if err != nil {
+		return err
+	}
+	if existing, ok := i.indexes[name]; ok {
+		if existing.path != path {
+			return fmt.Errorf("%w: %s is on %s", database.ErrIndexExists, name, existing.path)
+		}
+		return nil
+	}
+	i.addIndex(name, path, steps)
+	i.reshape()
+	return nil
+}
+
+// addIndex builds an index of the current values.
+func (i *Inmemory) addIndex(name, path string, steps []string) {
+	index := &valueIndex{path: path, steps: steps, keys: make(map[string]map[string]struct{}), values: make(map[string]string)}
+	for key, value := range i.store {
+		index.update(key, value, true)
+	}
+	if i.indexes == nil {
+		i.indexes = make(map[string]*valueIndex)
+	}
+	i.indexes[name] = index
+}
+
+func (i *Inmemory) DropIndex(name string) error {
+	i.lock()
+	defer i.unlock()
+
+	if _, ok := i.indexes[name]; !ok {
+		return fmt.Errorf("%w: %s", database.ErrIndexNotFound, name)
+	}
+	delete(i.indexes, name)
+	i.reshape()
+	return nil
+}
+
+func (i *Inmemory) Indexes() ([]database.Index, error) {
+	i.lock()
+	defer i.unlock()
+
+	indexes := []database.Index{}
+	for _, name := range sortedKeys(i.indexes) {
+		indexes = append(indexes, database.Index{Name: name, Path: i.indexes[name].path})
+	}
+	return indexes, nil
+}
+
+// Query returns the keys whose value at the path of index is value.
+func (i *Inmemory) Query(index, value string) ([]string, error) {
+	i.lock()
+	defer i.unlock()
+
+	x, ok := i.indexes[index]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", database.ErrIndexNotFound, index)
+	}
+	return sortedKeys(x.keys[value]), nil
+}
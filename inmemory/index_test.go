@@ -0,0 +1,69 @@
+package inmemory
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexes(t *testing.T) {
+	inmem, _ := NewInmemory()
+	_ = inmem.MultiSet(map[string]string{
+		"user:1": `{"status":"active","age":30}`,
+		"user:2": `{"status":"inactive","age":25}`,
+		"note":   "not json",
+	})
+
+	assert.NoError(t, inmem.CreateIndex("status", "$.status"))
+	assert.NoError(t, inmem.CreateIndex("status", "$.status"), "creating it again does nothing")
+	assert.ErrorIs(t, inmem.CreateIndex("status", "$.state"), database.ErrIndexExists)
+	assert.Error(t, inmem.CreateIndex("Bad-Name", "$.status"))
+	assert.NoError(t, inmem.CreateIndex("age", "$.age"))
+
+	_ = inmem.MultiSet(map[string]string{"user:3": `{"status":"active","age":25}`})
+	_, _ = inmem.JSONMerge("user:2", `{"status":"active"}`)
+	_, _ = inmem.MultiDelete([]string{"user:1"})
+
+	tests := []struct {
+		name    string
+		index   string
+		value   string
+		want    []string
+		wantErr error
+	}{
+		{name: "string", index: "status", value: "active", want: []string{"user:2", "user:3"}},
+		{name: "number", index: "age", value: "25", want: []string{"user:2", "user:3"}},
+		{name: "no match", index: "status", value: "inactive", want: []string{}},
+		{name: "missing index", index: "email", value: "x", wantErr: database.ErrIndexNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := inmem.Query(tt.index, tt.value)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, keys)
+		})
+	}
+
+	indexes, _ := inmem.Indexes()
+	assert.Equal(t, []database.Index{{Name: "age", Path: "$.age"}, {Name: "status", Path: "$.status"}}, indexes)
+	assert.NoError(t, inmem.DropIndex("age"))
+	assert.ErrorIs(t, inmem.DropIndex("age"), database.ErrIndexNotFound)
+}
+
+func TestIndexes_Snapshot(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+
+	inmem, _ := NewInmemory()
+	inmem.SnapshotFile = file
+	_ = inmem.MultiSet(map[string]string{"user:1": `{"status":"active"}`})
+	assert.NoError(t, inmem.CreateIndex("status", "$.status"))
+
+	loaded, _ := NewInmemory()
+	loaded.SnapshotFile = file
+	assert.NoError(t, loaded.LoadSnapshot())
+	keys, err := loaded.Query("status", "active")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:1"}, keys, "the index is built again")
+}
@@ -0,0 +1,91 @@
+package inmemory
+
+import (
+	"fmt"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/jsondoc"
+)
+
+// JSONGet returns the JSON at path in the value of key, see
+// database.JSONStore.
+func (i *Inmemory) JSONGet(key, path string) (string, error) {
+	i.lock()
+	defer i.unlock()
+
+	document, err := i.document(key)
+	if err != nil {
+		return "", err
+	}
+	i.accessed(key)
+	return database.GetPath(document, path)
+}
+
+// JSONSet replaces or adds the JSON value at path in the value of key.
+func (i *Inmemory) JSONSet(key, path, value string) error {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeString); err != nil {
+		return err
+	}
+	document, err := database.SetPath(i.store[key], path, value)
+	if err != nil {
+		return err
+	}
+	if err := i.admit(key); err != nil {
+		return err
+	}
+	i.write(key, document)
+	return nil
+}
+
+// JSONMerge applies a merge patch to the value of key.
+func (i *Inmemory) JSONMerge(key, patch string) (string, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeString); err != nil {
+		return "", err
+	}
+	document, err := jsondoc.MergePatch(i.store[key], patch)
+	if err != nil {
+		return "", err
+	}
+	if err := i.admit(key); err != nil {
+		return "", err
+	}
+	i.write(key, document)
+	return document, nil
+}
+
+// JSONPatch applies a JSON patch to the value of key.
+func (i *Inmemory) JSONPatch(key, patch string) (string, error) {
+	i.lock()
+	defer i.unlock()
+
+	document, err := i.document(key)
+	if err != nil {
+		return "", err
+	}
+	if document, err = jsondoc.Patch(document, patch); err != nil {
+		return "", err
+	}
+	if err := i.admit(key); err != nil {
+		return "", err
+	}
+	i.write(key, document)
+	return document, nil
+}
+
+// document returns the string value of an existing key.
+func (i *Inmemory) document(key string) (string, error) {
+	if err := i.check(key, database.TypeString); err != nil {
+		return "", err
+	}
+	document, ok := i.store[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", database.ErrNotFound, key)
+	}
+	return document, nil
+}
@@ -0,0 +1,60 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSON(t *testing.T) {
+	inmem, _ := NewInmemory()
+
+	assert.NoError(t, inmem.JSONSet("user", "$", `{"name":"Alice","address":{"city":"Paris"},"tags":["a"]}`))
+
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{name: "member", path: "$.name", want: `"Alice"`},
+		{name: "nested", path: "$.address.city", want: `"Paris"`},
+		{name: "index", path: "$.tags[0]", want: `"a"`},
+		{name: "missing path", path: "$.email", wantErr: database.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inmem.JSONGet("user", tt.path)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	assert.NoError(t, inmem.JSONSet("user", "$.tags[1]", `"b"`))
+	assert.ErrorIs(t, inmem.JSONSet("user", "$.phone.home", `"1"`), database.ErrNotFound, "the parent must exist")
+	assert.ErrorIs(t, inmem.JSONSet("missing", "$.name", `"x"`), database.ErrNotFound)
+
+	document, err := inmem.JSONMerge("user", `{"address":{"city":null,"zip":"75001"},"status":"active"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"address":{"zip":"75001"},"name":"Alice","status":"active","tags":["a","b"]}`, document)
+
+	document, err = inmem.JSONPatch("user", `[{"op":"move","from":"/address/zip","path":"/zip"},{"op":"remove","path":"/address"}]`)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"Alice","status":"active","tags":["a","b"],"zip":"75001"}`, document)
+
+	_, err = inmem.JSONPatch("user", `[{"op":"remove","path":"/name"},{"op":"test","path":"/status","value":"inactive"}]`)
+	assert.ErrorIs(t, err, database.ErrPatchFailed)
+	name, _ := inmem.JSONGet("user", "$.name")
+	assert.Equal(t, `"Alice"`, name, "a failed patch changes nothing")
+
+	_, err = inmem.JSONPatch("missing", `[]`)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	_ = inmem.MultiSet(map[string]string{"plain": "hello"})
+	_, err = inmem.JSONGet("plain", "$")
+	assert.ErrorIs(t, err, database.ErrNotJSON)
+	_, _ = inmem.RPush("queue", "a")
+	_, err = inmem.JSONMerge("queue", `{}`)
+	assert.ErrorIs(t, err, database.ErrWrongType)
+}
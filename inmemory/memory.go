@@ -4,7 +4,12 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
 
 	"github.com/imsumedhaa/In-memory-database/database"
 )
@@ -12,22 +17,102 @@ import (
 //struct name Inmemory
 
 type Inmemory struct {
+	// mu guards the fields below, so the store can be shared between
+	// goroutines and read-modify-write operations like IncrBy are atomic.
+	// Namespaces use the mu of their parent, see lock.
+	mu sync.Mutex
+
 	store  map[string]string
 	reader *bufio.Reader
+
+	// meta holds the timestamps, content type and tags of the keys in store
+	meta map[string]database.Metadata
+	// history holds the previous values of every key, oldest first, bounded
+	// by Retention
+	history   map[string][]database.Version
+	Retention database.Retention
+
+	// tombstones holds the keys deleted while SoftDelete is enabled
+	tombstones map[string]database.Tombstone
+	SoftDelete database.SoftDelete
+
+	// lists, sets, hashes and zsets hold the keys of the other types; a key
+	// is in at most one of them and store
+	lists  map[string][]string
+	sets   map[string]map[string]struct{}
+	hashes map[string]map[string]string
+	zsets  map[string]*sortedSet
+
+	// indexes are the secondary indexes of the JSON values in store, by name
+	indexes map[string]*valueIndex
+
+	// namespaces holds the stores of the namespaces by name. Their parent
+	// is this store, which saves them in its snapshot
+	namespaces map[string]*Inmemory
+	parent     *Inmemory
+	ttl        time.Duration
+
+	// limits bound the keys of this store and its namespaces, see
+	// SetLimits. The fields below account for them in the root, except
+	// usage which every store has for its own keys
+	limits    database.Limits
+	usage     map[string]*usage
+	keys      int
+	bytes     int64
+	clock     uint64
+	evictions int64
+	rejected  int64
+	// pending holds the keys written since the lock was taken
+	pending map[change]struct{}
+
+	// SnapshotFile, when set, is where the store is saved as JSON after
+	// every write, or every SnapshotInterval when it is positive. See
+	// LoadSnapshot.
+	SnapshotFile     string
+	SnapshotInterval time.Duration
+	// dirty is set by the writes not saved to SnapshotFile yet
+	dirty bool
+	// journal holds the keys written since the log was last appended to,
+	// when every write is saved, and reshaped is set by the changes of the
+	// namespaces and indexes, which the log does not hold. See save
+	journal  map[change]struct{}
+	reshaped bool
+	// seq numbers the appends to the log, the snapshot holding the last
+	// one it includes. The sizes of both files tell when to compact the log
+	seq          uint64
+	logSize      int64
+	snapshotSize int64
 }
 
 //Constructor -> A function which returns a pointer to the struct Inmemory
 
-func NewInmemory() (database.Database, error) {
+func NewInmemory() (*Inmemory, error) {
 	return &Inmemory{
 		store:  make(map[string]string),
 		reader: bufio.NewReader(os.Stdin),
+		meta:   make(map[string]database.Metadata),
+
+		history:   make(map[string][]database.Version),
+		Retention: database.DefaultRetention,
+
+		tombstones: make(map[string]database.Tombstone),
+
+		lists:  make(map[string][]string),
+		sets:   make(map[string]map[string]struct{}),
+		hashes: make(map[string]map[string]string),
+		zsets:  make(map[string]*sortedSet),
+
+		indexes: make(map[string]*valueIndex),
+
+		namespaces: make(map[string]*Inmemory),
 	}, nil
 }
 
 //struct Receiver
 
 func (i *Inmemory) Create(key, value string) error {
+	i.lock()
+	defer i.unlock()
 
 	if key == "" || value == "" {
 		return fmt.Errorf("require the key and value")
@@ -36,21 +121,30 @@ func (i *Inmemory) Create(key, value string) error {
 	if existValue, exists := i.store[key]; exists {
 		fmt.Println("Key already exists. Use 'update' to change the value.")
 		fmt.Println(existValue)
+	} else if i.typeOf(key) != database.TypeNone {
+		fmt.Println("Key already exists. Use 'update' to change the value.")
+	} else if err := i.admit(key); err != nil {
+		return err
 	} else {
-		i.store[key] = value
+		i.write(key, value)
 		fmt.Println("Created successfully.")
 	}
 	return nil
 }
 
 func (i *Inmemory) Get(key string) error {
+	i.lock()
+	defer i.unlock()
 
 	if key == "" {
 		return fmt.Errorf("require the key")
 	}
 
 	if val, ok := i.store[key]; ok {
+		i.accessed(key)
 		fmt.Printf("Value: %s\n", val)
+	} else if i.typeOf(key) != database.TypeNone {
+		return database.ErrWrongType
 	} else {
 		return fmt.Errorf("key not found")
 	}
@@ -59,6 +153,8 @@ func (i *Inmemory) Get(key string) error {
 
 
 func (i *Inmemory) Update(key, value string) error {
+	i.lock()
+	defer i.unlock()
 	
 	if key == "" {
 		return fmt.Errorf("require the key")
@@ -68,7 +164,12 @@ func (i *Inmemory) Update(key, value string) error {
 	}
 	
 	if _, ok := i.store[key]; ok {
-		i.store[key] = value
+		if err := i.admit(key); err != nil {
+			return err
+		}
+		i.write(key, value)
+	} else if i.typeOf(key) != database.TypeNone {
+		return database.ErrWrongType
 	} else {
 		return fmt.Errorf("key not found")
 	}
@@ -77,13 +178,15 @@ func (i *Inmemory) Update(key, value string) error {
 
 
 func (i *Inmemory) Delete(key string) error {
+	i.lock()
+	defer i.unlock()
 	
 
 	if key == "" {
 		return fmt.Errorf("require the key")
 	}
-	if _, ok := i.store[key]; ok {
-		delete(i.store, key)
+	if i.typeOf(key) != database.TypeNone {
+		i.remove(key)
 		fmt.Println("Succesfully deleted")
 	} else {
 		return fmt.Errorf("key not found")
@@ -93,14 +196,364 @@ func (i *Inmemory) Delete(key string) error {
 }
 
 func (i *Inmemory) Show() error {
+	i.lock()
+	defer i.unlock()
+
 	fmt.Println("The full map is:")
 	fmt.Println(i.store)
+	if len(i.lists) > 0 {
+		fmt.Println("Lists:")
+		fmt.Println(i.lists)
+	}
+	if len(i.sets) > 0 {
+		fmt.Println("Sets:")
+		for _, key := range sortedKeys(i.sets) {
+			fmt.Printf("%s: %v\n", key, members(i.sets[key]))
+		}
+	}
+	if len(i.hashes) > 0 {
+		fmt.Println("Hashes:")
+		fmt.Println(i.hashes)
+	}
+	if len(i.zsets) > 0 {
+		fmt.Println("Sorted sets:")
+		for _, key := range sortedKeys(i.zsets) {
+			fmt.Printf("%s: %v\n", key, scoredMembers(i.zsets[key].list.byRank(0), all))
+		}
+	}
 
 	return nil
 }
 
 func (i *Inmemory) Exit() error {
+	if err := i.Snapshot(); err != nil {
+		return err
+	}
 	fmt.Println("Exiting program.")
 	os.Exit(0)
 	return nil
 }
+
+func (i *Inmemory) MultiGet(keys []string) (map[string]string, error) {
+	i.lock()
+	defer i.unlock()
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("require the key")
+		}
+		if val, ok := i.store[key]; ok {
+			values[key] = val
+			i.accessed(key)
+		}
+	}
+	return values, nil
+}
+
+func (i *Inmemory) MultiSet(pairs map[string]string) error {
+	i.lock()
+	defer i.unlock()
+
+	// Validate everything first so a bad pair doesn't leave half the batch applied
+	for key, value := range pairs {
+		if key == "" || value == "" {
+			return fmt.Errorf("require the key and value")
+		}
+	}
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	if err := i.admit(keys...); err != nil {
+		return err
+	}
+
+	for key, value := range pairs {
+		i.write(key, value)
+	}
+	return nil
+}
+
+func (i *Inmemory) MultiDelete(keys []string) ([]string, error) {
+	i.lock()
+	defer i.unlock()
+
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("require the key")
+		}
+	}
+
+	deleted := []string{}
+	for _, key := range keys {
+		if i.typeOf(key) != database.TypeNone {
+			i.remove(key)
+			deleted = append(deleted, key)
+		}
+	}
+	return deleted, nil
+}
+
+func (i *Inmemory) Keys(prefix string) ([]string, error) {
+	i.lock()
+	defer i.unlock()
+
+	keys := []string{}
+	for _, names := range [][]string{sortedKeys(i.store), sortedKeys(i.lists), sortedKeys(i.sets), sortedKeys(i.hashes), sortedKeys(i.zsets)} {
+		for _, key := range names {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Stat returns the metadata of key, see database.MetadataStore.
+func (i *Inmemory) Stat(key string) (database.Metadata, error) {
+	i.lock()
+	defer i.unlock()
+
+	if key == "" {
+		return database.Metadata{}, fmt.Errorf("require the key")
+	}
+	if _, ok := i.store[key]; !ok {
+		return database.Metadata{}, database.ErrNotFound
+	}
+	meta := i.meta[key]
+	meta.Tags = slices.Clone(meta.Tags)
+	return meta, nil
+}
+
+func (i *Inmemory) SetMetadata(key, contentType string, tags []string) error {
+	i.lock()
+	defer i.unlock()
+
+	if key == "" {
+		return fmt.Errorf("require the key")
+	}
+	if _, ok := i.store[key]; !ok {
+		return database.ErrNotFound
+	}
+	if i.meta == nil {
+		i.meta = make(map[string]database.Metadata)
+	}
+	meta := i.meta[key]
+	meta.ContentType = contentType
+	meta.Tags = slices.Clone(tags)
+	meta.UpdatedAt = time.Now().UTC()
+	i.meta[key] = meta
+	i.changed(key)
+	return nil
+}
+
+// History returns the versions of key, see database.HistoryStore.
+func (i *Inmemory) History(key string, limit int) ([]database.Version, error) {
+	i.lock()
+	defer i.unlock()
+
+	if key == "" {
+		return nil, fmt.Errorf("require the key")
+	}
+
+	current := i.current(key)
+	past := i.Retention.Prune(slices.Clone(i.history[key]), time.Now())
+	if current == nil && len(past) == 0 {
+		return nil, database.ErrNotFound
+	}
+	return database.BuildHistory(current, past, limit), nil
+}
+
+// GetAt returns the value key had at the given time.
+func (i *Inmemory) GetAt(key string, at time.Time) (string, error) {
+	i.lock()
+	defer i.unlock()
+
+	if key == "" {
+		return "", fmt.Errorf("require the key")
+	}
+
+	past := i.Retention.Prune(slices.Clone(i.history[key]), time.Now())
+	value, ok := database.ValueAt(i.current(key), past, at)
+	if !ok {
+		return "", database.ErrNotFound
+	}
+	return value, nil
+}
+
+// current returns the value of key as a version, nil when it is missing.
+func (i *Inmemory) current(key string) *database.Version {
+	value, ok := i.store[key]
+	if !ok {
+		return nil
+	}
+	meta := i.meta[key]
+	return &database.Version{Version: meta.Version, Value: value, WrittenAt: meta.ValueWrittenAt()}
+}
+
+// write stores value under key and moves the value it replaces to the
+// history.
+func (i *Inmemory) write(key, value string) {
+	if i.meta == nil {
+		i.meta = make(map[string]database.Metadata)
+	}
+	now := time.Now().UTC()
+
+	meta := i.meta[key]
+	if previous := i.current(key); previous != nil {
+		previous.ReplacedAt = now
+		i.record(key, *previous)
+	} else {
+		// A key created again continues the versions of its history
+		meta = database.Metadata{CreatedAt: now}
+		if past := i.history[key]; len(past) > 0 {
+			meta.Version = past[len(past)-1].Version
+		}
+	}
+	meta.Version++
+	meta.UpdatedAt = now
+	meta.WrittenAt = now
+
+	i.store[key] = value
+	i.meta[key] = meta
+	i.reindex(key)
+	// Writing a key again replaces its tombstone, and a string replaces
+	// the value of any other type
+	delete(i.tombstones, key)
+	i.dropComposite(key)
+	i.changed(key)
+}
+
+// remove deletes key and moves its value to the history. In soft delete
+// mode the key is kept as a tombstone, and the tombstones past the grace
+// period are purged.
+func (i *Inmemory) remove(key string) {
+	i.changed(key)
+	if _, ok := i.store[key]; !ok {
+		// Only strings have a history and tombstones
+		i.dropComposite(key)
+		return
+	}
+
+	now := time.Now().UTC()
+	if previous := i.current(key); previous != nil {
+		previous.ReplacedAt = now
+		previous.Deleted = true
+		i.record(key, *previous)
+	}
+
+	if i.SoftDelete.Enabled {
+		if i.tombstones == nil {
+			i.tombstones = make(map[string]database.Tombstone)
+		}
+		i.tombstones[key] = database.Tombstone{Value: i.store[key], Metadata: i.meta[key], DeletedAt: now}
+		if i.SoftDelete.GracePeriod > 0 {
+			i.purgeTombstones(now.Add(-i.SoftDelete.GracePeriod))
+		}
+	}
+	delete(i.store, key)
+	delete(i.meta, key)
+	i.reindex(key)
+}
+
+// Undelete restores a tombstoned key, see database.Undeleter. It counts
+// as a write of the value, which gets a new version.
+func (i *Inmemory) Undelete(key string) error {
+	i.lock()
+	defer i.unlock()
+
+	if key == "" {
+		return fmt.Errorf("require the key")
+	}
+	tombstone, ok := i.tombstones[key]
+	if !ok {
+		return database.ErrNotFound
+	}
+	if err := i.admit(key); err != nil {
+		return err
+	}
+
+	i.write(key, tombstone.Value)
+	meta := i.meta[key]
+	if !tombstone.Metadata.CreatedAt.IsZero() {
+		meta.CreatedAt = tombstone.Metadata.CreatedAt
+	}
+	meta.ContentType = tombstone.Metadata.ContentType
+	meta.Tags = tombstone.Metadata.Tags
+	i.meta[key] = meta
+	return nil
+}
+
+// PurgeTombstones removes the keys deleted before the given time for good.
+func (i *Inmemory) PurgeTombstones(before time.Time) (int, error) {
+	i.lock()
+	defer i.unlock()
+	return i.purgeTombstones(before), nil
+}
+
+func (i *Inmemory) purgeTombstones(before time.Time) int {
+	purged := 0
+	for key, tombstone := range i.tombstones {
+		if tombstone.DeletedAt.Before(before) {
+			delete(i.tombstones, key)
+			purged++
+		}
+	}
+	return purged
+}
+
+// IncrBy adds delta to the integer value of key, see database.Counter.
+func (i *Inmemory) IncrBy(key string, delta int64) (int64, error) {
+	i.lock()
+	defer i.unlock()
+
+	if key == "" {
+		return 0, fmt.Errorf("require the key")
+	}
+	if err := i.check(key, database.TypeString); err != nil {
+		return 0, err
+	}
+	result, value, err := database.AddInt(i.store[key], delta)
+	if err != nil {
+		return 0, err
+	}
+	if err := i.admit(key); err != nil {
+		return 0, err
+	}
+	i.write(key, value)
+	return result, nil
+}
+
+// IncrByFloat adds delta to the decimal value of key.
+func (i *Inmemory) IncrByFloat(key string, delta float64) (float64, error) {
+	i.lock()
+	defer i.unlock()
+
+	if key == "" {
+		return 0, fmt.Errorf("require the key")
+	}
+	if err := i.check(key, database.TypeString); err != nil {
+		return 0, err
+	}
+	result, value, err := database.AddFloat(i.store[key], delta)
+	if err != nil {
+		return 0, err
+	}
+	if err := i.admit(key); err != nil {
+		return 0, err
+	}
+	i.write(key, value)
+	return result, nil
+}
+
+func (i *Inmemory) record(key string, version database.Version) {
+	if i.history == nil {
+		i.history = make(map[string][]database.Version)
+	}
+	i.history[key] = i.Retention.Prune(append(i.history[key], version), version.ReplacedAt)
+	if len(i.history[key]) == 0 {
+		delete(i.history, key)
+	}
+}
@@ -2,8 +2,14 @@ package inmemory
 
 import (
 	"bufio"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestCreate(t *testing.T) {
@@ -308,3 +314,503 @@ func TestShow(t *testing.T) {
 		})
 	}
 }
+
+func TestMultiGet(t *testing.T) {
+	tests := []struct {
+		name           string
+		keys           []string
+		initialStore   map[string]string
+		expectedError  string
+		expectedValues map[string]string
+	}{
+		{
+			name:           "Get existing and missing keys",
+			keys:           []string{"name", "age"},
+			initialStore:   map[string]string{"name": "Alice", "city": "Berlin"},
+			expectedError:  "",
+			expectedValues: map[string]string{"name": "Alice"},
+		},
+		{
+			name:           "Empty key",
+			keys:           []string{"name", ""},
+			initialStore:   map[string]string{"name": "Alice"},
+			expectedError:  "require the key",
+			expectedValues: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inmem := &Inmemory{
+				store: copyMap(tt.initialStore),
+			}
+
+			values, err := inmem.MultiGet(tt.keys)
+
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("expected error '%s', got '%v'", tt.expectedError, err)
+				}
+			}
+
+			if !mapsEqual(values, tt.expectedValues) {
+				t.Errorf("expected values: %v, got: %v", tt.expectedValues, values)
+			}
+		})
+	}
+}
+
+func TestMultiSet(t *testing.T) {
+	tests := []struct {
+		name          string
+		pairs         map[string]string
+		initialStore  map[string]string
+		expectedError string
+		expectedStore map[string]string
+	}{
+		{
+			name:          "Set new and existing keys",
+			pairs:         map[string]string{"name": "Bob", "age": "19"},
+			initialStore:  map[string]string{"name": "Alice"},
+			expectedError: "",
+			expectedStore: map[string]string{"name": "Bob", "age": "19"},
+		},
+		{
+			name:          "Empty value leaves the store untouched",
+			pairs:         map[string]string{"name": "Bob", "age": ""},
+			initialStore:  map[string]string{"name": "Alice"},
+			expectedError: "require the key and value",
+			expectedStore: map[string]string{"name": "Alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inmem := &Inmemory{
+				store: copyMap(tt.initialStore),
+			}
+
+			err := inmem.MultiSet(tt.pairs)
+
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("expected error '%s', got '%v'", tt.expectedError, err)
+				}
+			}
+
+			if !mapsEqual(inmem.store, tt.expectedStore) {
+				t.Errorf("expected store: %v, got: %v", tt.expectedStore, inmem.store)
+			}
+		})
+	}
+}
+
+func TestMultiDelete(t *testing.T) {
+	tests := []struct {
+		name            string
+		keys            []string
+		initialStore    map[string]string
+		expectedError   string
+		expectedDeleted []string
+		expectedStore   map[string]string
+	}{
+		{
+			name:            "Delete existing and missing keys",
+			keys:            []string{"name", "age"},
+			initialStore:    map[string]string{"name": "Alice", "city": "Berlin"},
+			expectedError:   "",
+			expectedDeleted: []string{"name"},
+			expectedStore:   map[string]string{"city": "Berlin"},
+		},
+		{
+			name:            "Empty key",
+			keys:            []string{""},
+			initialStore:    map[string]string{"name": "Alice"},
+			expectedError:   "require the key",
+			expectedDeleted: nil,
+			expectedStore:   map[string]string{"name": "Alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inmem := &Inmemory{
+				store: copyMap(tt.initialStore),
+			}
+
+			deleted, err := inmem.MultiDelete(tt.keys)
+
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			} else {
+				if err == nil || err.Error() != tt.expectedError {
+					t.Errorf("expected error '%s', got '%v'", tt.expectedError, err)
+				}
+			}
+
+			if strings.Join(deleted, ",") != strings.Join(tt.expectedDeleted, ",") {
+				t.Errorf("expected deleted: %v, got: %v", tt.expectedDeleted, deleted)
+			}
+			if !mapsEqual(inmem.store, tt.expectedStore) {
+				t.Errorf("expected store: %v, got: %v", tt.expectedStore, inmem.store)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		name         string
+		prefix       string
+		initialStore map[string]string
+		expectedKeys []string
+	}{
+		{
+			name:         "All keys sorted",
+			prefix:       "",
+			initialStore: map[string]string{"user:2": "b", "city": "Berlin", "user:1": "a"},
+			expectedKeys: []string{"city", "user:1", "user:2"},
+		},
+		{
+			name:         "Keys with prefix",
+			prefix:       "user:",
+			initialStore: map[string]string{"user:2": "b", "city": "Berlin", "user:1": "a"},
+			expectedKeys: []string{"user:1", "user:2"},
+		},
+		{
+			name:         "No match",
+			prefix:       "order:",
+			initialStore: map[string]string{"city": "Berlin"},
+			expectedKeys: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inmem := &Inmemory{
+				store: copyMap(tt.initialStore),
+			}
+
+			keys, err := inmem.Keys(tt.prefix)
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if strings.Join(keys, ",") != strings.Join(tt.expectedKeys, ",") {
+				t.Errorf("expected keys: %v, got: %v", tt.expectedKeys, keys)
+			}
+		})
+	}
+}
+
+func TestStat(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name          string
+		key           string
+		initialStore  map[string]string
+		initialMeta   map[string]database.Metadata
+		expectedError string
+		expectedMeta  database.Metadata
+	}{
+		{
+			name:         "Existing key",
+			key:          "name",
+			initialStore: map[string]string{"name": "Alice"},
+			initialMeta: map[string]database.Metadata{
+				"name": {CreatedAt: created, UpdatedAt: created, ContentType: "text/plain", Tags: []string{"user"}},
+			},
+			expectedMeta: database.Metadata{CreatedAt: created, UpdatedAt: created, ContentType: "text/plain", Tags: []string{"user"}},
+		},
+		{
+			name:          "Key not found",
+			key:           "age",
+			initialStore:  map[string]string{"name": "Alice"},
+			expectedError: "key not found",
+		},
+		{
+			name:          "Empty key",
+			key:           "",
+			expectedError: "require the key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inmem := &Inmemory{
+				store: copyMap(tt.initialStore),
+				meta:  tt.initialMeta,
+			}
+
+			meta, err := inmem.Stat(tt.key)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedMeta, meta)
+			}
+		})
+	}
+}
+
+func TestMetadata_Writes(t *testing.T) {
+	inmem := &Inmemory{store: map[string]string{}}
+
+	assert.NoError(t, inmem.Create("name", "Alice"))
+	created, err := inmem.Stat("name")
+	assert.NoError(t, err)
+	assert.False(t, created.CreatedAt.IsZero())
+	assert.Equal(t, created.CreatedAt, created.UpdatedAt)
+
+	assert.NoError(t, inmem.SetMetadata("name", "text/plain", []string{"user"}))
+	tagged, err := inmem.Stat("name")
+	assert.NoError(t, err)
+	assert.True(t, tagged.UpdatedAt.After(created.UpdatedAt), "changing the metadata refreshes updated_at")
+	assert.Equal(t, created.WrittenAt, tagged.WrittenAt, "changing the metadata keeps the write time of the value")
+	value, err := inmem.GetAt("name", created.WrittenAt)
+	assert.NoError(t, err)
+	assert.Equal(t, "Alice", value)
+
+	assert.NoError(t, inmem.MultiSet(map[string]string{"name": "Bob"}))
+	updated, err := inmem.Stat("name")
+	assert.NoError(t, err)
+	assert.Equal(t, created.CreatedAt, updated.CreatedAt)
+	assert.False(t, updated.UpdatedAt.Before(created.UpdatedAt))
+	assert.Equal(t, "text/plain", updated.ContentType, "writing the value keeps the content type")
+	assert.Equal(t, []string{"user"}, updated.Tags)
+
+	assert.ErrorIs(t, inmem.SetMetadata("age", "", nil), database.ErrNotFound)
+
+	assert.NoError(t, inmem.Delete("name"))
+	assert.NotContains(t, inmem.meta, "name")
+}
+
+func TestHistory(t *testing.T) {
+	inmem := &Inmemory{store: map[string]string{}}
+
+	assert.NoError(t, inmem.Create("name", "Alice"))
+	assert.NoError(t, inmem.Update("name", "Bob"))
+	assert.NoError(t, inmem.SetMetadata("name", "text/plain", nil))
+	assert.NoError(t, inmem.Delete("name"))
+	assert.NoError(t, inmem.MultiSet(map[string]string{"name": "Carol"}))
+
+	history, err := inmem.History("name", 0)
+	assert.NoError(t, err)
+	assert.Len(t, history, 3)
+
+	assert.Equal(t, int64(3), history[0].Version, "a key created again continues its versions")
+	assert.Equal(t, "Carol", history[0].Value)
+	assert.True(t, history[0].ReplacedAt.IsZero())
+
+	assert.Equal(t, int64(2), history[1].Version)
+	assert.Equal(t, "Bob", history[1].Value)
+	assert.True(t, history[1].Deleted)
+
+	assert.Equal(t, int64(1), history[2].Version)
+	assert.Equal(t, "Alice", history[2].Value)
+	assert.False(t, history[2].Deleted)
+	assert.Equal(t, history[1].WrittenAt, history[2].ReplacedAt)
+
+	limited, err := inmem.History("name", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, history[:1], limited)
+
+	_, err = inmem.History("age", 0)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+	_, err = inmem.History("", 0)
+	assert.EqualError(t, err, "require the key")
+}
+
+func TestGetAt(t *testing.T) {
+	written := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	replaced := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	deleted := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	inmem := &Inmemory{
+		store: map[string]string{"name": "Carol"},
+		meta:  map[string]database.Metadata{"name": {CreatedAt: deleted.AddDate(0, 1, 0), UpdatedAt: deleted.AddDate(0, 1, 0), Version: 3}},
+		history: map[string][]database.Version{"name": {
+			{Version: 1, Value: "Alice", WrittenAt: written, ReplacedAt: replaced},
+			{Version: 2, Value: "Bob", WrittenAt: replaced, ReplacedAt: deleted, Deleted: true},
+		}},
+	}
+
+	tests := []struct {
+		name          string
+		at            time.Time
+		expectedValue string
+		expectedError error
+	}{
+		{name: "Before the key existed", at: written.Add(-time.Second), expectedError: database.ErrNotFound},
+		{name: "First version", at: written, expectedValue: "Alice"},
+		{name: "Second version", at: replaced.Add(time.Hour), expectedValue: "Bob"},
+		{name: "While deleted", at: deleted.Add(time.Hour), expectedError: database.ErrNotFound},
+		{name: "Current version", at: time.Now(), expectedValue: "Carol"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := inmem.GetAt("name", tt.at)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestHistory_Retention(t *testing.T) {
+	tests := []struct {
+		name           string
+		retention      database.Retention
+		expectedValues []string
+	}{
+		{name: "Unbounded", expectedValues: []string{"v5", "v4", "v3", "v2", "v1"}},
+		{name: "Max versions", retention: database.Retention{MaxVersions: 2}, expectedValues: []string{"v5", "v4", "v3"}},
+		{name: "Max age", retention: database.Retention{MaxAge: time.Hour}, expectedValues: []string{"v5", "v4", "v3", "v2", "v1"}},
+		{name: "Expired", retention: database.Retention{MaxAge: time.Nanosecond}, expectedValues: []string{"v5"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inmem := &Inmemory{store: map[string]string{}, Retention: tt.retention}
+			for n := 1; n <= 5; n++ {
+				assert.NoError(t, inmem.MultiSet(map[string]string{"name": fmt.Sprintf("v%d", n)}))
+			}
+			time.Sleep(time.Millisecond)
+
+			history, err := inmem.History("name", 0)
+			assert.NoError(t, err)
+			values := []string{}
+			for _, version := range history {
+				values = append(values, version.Value)
+			}
+			assert.Equal(t, tt.expectedValues, values)
+		})
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	inmem := &Inmemory{store: map[string]string{}, SoftDelete: database.SoftDelete{Enabled: true}}
+
+	assert.NoError(t, inmem.Create("name", "Alice"))
+	assert.NoError(t, inmem.SetMetadata("name", "text/plain", []string{"user"}))
+	created, _ := inmem.Stat("name")
+	assert.NoError(t, inmem.Delete("name"))
+
+	values, _ := inmem.MultiGet([]string{"name"})
+	assert.Empty(t, values, "a tombstone is hidden from reads")
+	keys, _ := inmem.Keys("")
+	assert.Empty(t, keys)
+
+	assert.NoError(t, inmem.Undelete("name"))
+	values, _ = inmem.MultiGet([]string{"name"})
+	assert.Equal(t, map[string]string{"name": "Alice"}, values)
+	restored, _ := inmem.Stat("name")
+	assert.Equal(t, created.CreatedAt, restored.CreatedAt)
+	assert.Equal(t, "text/plain", restored.ContentType)
+	assert.Equal(t, []string{"user"}, restored.Tags)
+	assert.Equal(t, int64(2), restored.Version)
+
+	assert.ErrorIs(t, inmem.Undelete("name"), database.ErrNotFound, "the tombstone is gone once restored")
+	assert.ErrorIs(t, inmem.Undelete("age"), database.ErrNotFound)
+
+	// Writing a tombstoned key again replaces the tombstone
+	_, _ = inmem.MultiDelete([]string{"name"})
+	assert.NoError(t, inmem.Create("name", "Bob"))
+	assert.ErrorIs(t, inmem.Undelete("name"), database.ErrNotFound)
+}
+
+func TestPurgeTombstones(t *testing.T) {
+	inmem := &Inmemory{store: map[string]string{}, SoftDelete: database.SoftDelete{Enabled: true, GracePeriod: time.Hour}}
+	_ = inmem.MultiSet(map[string]string{"a": "1", "b": "2"})
+	_, _ = inmem.MultiDelete([]string{"a", "b"})
+
+	purged, err := inmem.PurgeTombstones(time.Now().Add(-time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, purged)
+
+	// Deletes purge the tombstones past the grace period on their own
+	tombstone := inmem.tombstones["a"]
+	tombstone.DeletedAt = time.Now().Add(-2 * time.Hour)
+	inmem.tombstones["a"] = tombstone
+	_ = inmem.MultiSet(map[string]string{"c": "3"})
+	assert.NoError(t, inmem.Delete("c"))
+	assert.ErrorIs(t, inmem.Undelete("a"), database.ErrNotFound)
+
+	purged, err = inmem.PurgeTombstones(time.Now().Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	assert.ErrorIs(t, inmem.Undelete("b"), database.ErrNotFound)
+}
+
+func TestIncrBy(t *testing.T) {
+	tests := []struct {
+		name          string
+		initial       map[string]string
+		key           string
+		delta         int64
+		expected      int64
+		expectedError error
+	}{
+		{name: "Missing key starts at zero", initial: map[string]string{}, key: "hits", delta: 5, expected: 5},
+		{name: "Existing counter", initial: map[string]string{"hits": "41"}, key: "hits", delta: 1, expected: 42},
+		{name: "Decrement below zero", initial: map[string]string{"hits": "0"}, key: "hits", delta: -1, expected: -1},
+		{name: "Not an integer", initial: map[string]string{"hits": "1.5"}, key: "hits", delta: 1, expectedError: database.ErrNotNumber},
+		{name: "Overflow", initial: map[string]string{"hits": "9223372036854775807"}, key: "hits", delta: 1, expectedError: database.ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inmem := &Inmemory{store: tt.initial}
+
+			result, err := inmem.IncrBy(tt.key, tt.delta)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, fmt.Sprint(tt.expected), inmem.store[tt.key])
+		})
+	}
+}
+
+func TestIncrByFloat(t *testing.T) {
+	inmem := &Inmemory{store: map[string]string{"price": "10"}}
+
+	result, err := inmem.IncrByFloat("price", 0.5)
+	assert.NoError(t, err)
+	assert.Equal(t, 10.5, result)
+	assert.Equal(t, "10.5", inmem.store["price"])
+
+	_, err = inmem.IncrBy("price", 1)
+	assert.ErrorIs(t, err, database.ErrNotNumber, "a decimal value is not an integer")
+
+	inmem.store["name"] = "Alice"
+	_, err = inmem.IncrByFloat("name", 1)
+	assert.ErrorIs(t, err, database.ErrNotNumber)
+}
+
+func TestIncrBy_Concurrent(t *testing.T) {
+	inmem, _ := NewInmemory()
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = inmem.IncrBy("hits", 1)
+		}()
+	}
+	wg.Wait()
+
+	values, _ := inmem.MultiGet([]string{"hits"})
+	assert.Equal(t, "50", values["hits"], "no increment is lost")
+}
@@ -0,0 +1,124 @@
+package inmemory
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// Namespace returns the store of the namespace name, see
+// database.NamespaceStore. It is saved with the snapshot of this store.
+func (i *Inmemory) Namespace(name string) (database.Database, error) {
+	i.lock()
+	defer i.unlock()
+
+	ns, err := i.namespace(name)
+	if err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+// namespace returns the store of the namespace name, creating it with the
+// retention and soft delete settings of i.
+func (i *Inmemory) namespace(name string) (*Inmemory, error) {
+	if i.parent != nil {
+		return nil, errors.New("namespaces cannot be nested")
+	}
+	if err := database.CheckNamespace(name); err != nil {
+		return nil, err
+	}
+	if ns, ok := i.namespaces[name]; ok {
+		return ns, nil
+	}
+
+	ns, err := NewInmemory()
+	if err != nil {
+		return nil, err
+	}
+	ns.Retention = i.Retention
+	ns.SoftDelete = i.SoftDelete
+	ns.parent = i
+
+	if i.namespaces == nil {
+		i.namespaces = make(map[string]*Inmemory)
+	}
+	i.namespaces[name] = ns
+	i.reshape()
+	return ns, nil
+}
+
+func (i *Inmemory) Namespaces() ([]database.Namespace, error) {
+	i.lock()
+	defer i.unlock()
+
+	namespaces := make([]database.Namespace, 0, len(i.namespaces))
+	for _, name := range sortedKeys(i.namespaces) {
+		namespaces = append(namespaces, database.Namespace{Name: name, DefaultTTL: i.namespaces[name].ttl})
+	}
+	return namespaces, nil
+}
+
+func (i *Inmemory) SetNamespaceTTL(name string, ttl time.Duration) error {
+	i.lock()
+	defer i.unlock()
+
+	if ttl < 0 {
+		return fmt.Errorf("invalid TTL %s, expected zero or more", ttl)
+	}
+	ns, err := i.namespace(name)
+	if err != nil {
+		return err
+	}
+	ns.ttl = ttl
+	i.reshape()
+	return nil
+}
+
+func (i *Inmemory) DropNamespace(name string) error {
+	i.lock()
+	defer i.unlock()
+
+	if _, ok := i.namespaces[name]; !ok {
+		return fmt.Errorf("%w: %s", database.ErrNamespaceNotFound, name)
+	}
+	delete(i.namespaces, name)
+	if i.limits.Enabled() {
+		i.recount()
+	}
+	i.reshape()
+	return nil
+}
+
+// Expire deletes the strings written before the given time, see
+// database.Expirer. The other types have no write time and are kept.
+func (i *Inmemory) Expire(before time.Time) (int, error) {
+	i.lock()
+	defer i.unlock()
+
+	expired := []string{}
+	for key, meta := range i.meta {
+		if meta.UpdatedAt.Before(before) {
+			expired = append(expired, key)
+		}
+	}
+	for _, key := range expired {
+		i.remove(key)
+	}
+	return len(expired), nil
+}
+
+// root is the store whose mu guards i: i itself, or the parent of a
+// namespace, so a write to any namespace can evict the keys of another.
+func (i *Inmemory) root() *Inmemory {
+	if i.parent != nil {
+		return i.parent
+	}
+	return i
+}
+
+func (i *Inmemory) lock() {
+	i.root().mu.Lock()
+}
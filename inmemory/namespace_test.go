@@ -0,0 +1,78 @@
+package inmemory
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaces(t *testing.T) {
+	inmem, _ := NewInmemory()
+	_ = inmem.MultiSet(map[string]string{"key": "root"})
+
+	billing, err := inmem.Namespace("billing")
+	assert.NoError(t, err)
+	search, _ := inmem.Namespace("search")
+	_ = billing.MultiSet(map[string]string{"key": "billing"})
+
+	values, _ := search.MultiGet([]string{"key"})
+	assert.Empty(t, values, "namespaces do not share keys")
+	values, _ = inmem.MultiGet([]string{"key"})
+	assert.Equal(t, map[string]string{"key": "root"}, values)
+	again, _ := inmem.Namespace("billing")
+	values, _ = again.MultiGet([]string{"key"})
+	assert.Equal(t, map[string]string{"key": "billing"}, values)
+
+	_, err = inmem.Namespace("Bad-Name")
+	assert.Error(t, err)
+	_, err = billing.(database.NamespaceStore).Namespace("nested")
+	assert.Error(t, err)
+
+	assert.NoError(t, inmem.SetNamespaceTTL("search", time.Hour))
+	namespaces, _ := inmem.Namespaces()
+	assert.Equal(t, []database.Namespace{{Name: "billing"}, {Name: "search", DefaultTTL: time.Hour}}, namespaces)
+
+	assert.NoError(t, inmem.DropNamespace("billing"))
+	assert.ErrorIs(t, inmem.DropNamespace("billing"), database.ErrNamespaceNotFound)
+	billing, _ = inmem.Namespace("billing")
+	keys, _ := billing.Keys("")
+	assert.Empty(t, keys, "a dropped namespace starts empty")
+}
+
+func TestNamespaces_Snapshot(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+
+	inmem, _ := NewInmemory()
+	inmem.SnapshotFile = file
+	assert.NoError(t, inmem.SetNamespaceTTL("billing", time.Hour))
+	billing, _ := inmem.Namespace("billing")
+	// Writes to the namespace save the snapshot of its parent
+	_ = billing.MultiSet(map[string]string{"invoice:1": "paid"})
+
+	loaded, _ := NewInmemory()
+	loaded.SnapshotFile = file
+	assert.NoError(t, loaded.LoadSnapshot())
+	namespaces, _ := loaded.Namespaces()
+	assert.Equal(t, []database.Namespace{{Name: "billing", DefaultTTL: time.Hour}}, namespaces)
+	billing, _ = loaded.Namespace("billing")
+	values, _ := billing.MultiGet([]string{"invoice:1"})
+	assert.Equal(t, map[string]string{"invoice:1": "paid"}, values)
+}
+
+func TestExpire(t *testing.T) {
+	inmem, _ := NewInmemory()
+	_ = inmem.MultiSet(map[string]string{"old": "1"})
+	_, _ = inmem.RPush("list", "a")
+	cutoff := time.Now().UTC().Add(time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+	_ = inmem.MultiSet(map[string]string{"new": "2"})
+
+	expired, err := inmem.Expire(cutoff)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, expired)
+	keys, _ := inmem.Keys("")
+	assert.Equal(t, []string{"list", "new"}, keys, "only strings expire")
+}
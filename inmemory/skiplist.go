@@ -0,0 +1,234 @@
+package inmemory
+
+import (
+	"math/rand/v2"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+const (
+	// maxLevel bounds the levels of the skiplist, enough for 4^32 members
+	maxLevel = 32
+	// levelUp is the chance of a node to reach the next level
+	levelUp = 0.25
+)
+
+// skiplist keeps the members of a sorted set ordered by score, then member,
+// so finding a member, its rank or a range takes O(log n) on average. Every
+// link records its span, the number of nodes it skips, to compute ranks on
+// the way down like Redis does.
+type skiplist struct {
+	head   *skipNode
+	level  int
+	length int
+}
+
+type skipNode struct {
+	member string
+	score  float64
+	next   []skipLink
+}
+
+type skipLink struct {
+	forward *skipNode
+	span    int
+}
+
+func newSkiplist() *skiplist {
+	return &skiplist{head: &skipNode{next: make([]skipLink, maxLevel)}, level: 1}
+}
+
+// before reports whether node sorts before the given score and member.
+func (n *skipNode) before(score float64, member string) bool {
+	return n.score < score || (n.score == score && n.member < member)
+}
+
+// after reports whether node sorts after the given score and member.
+func (n *skipNode) after(score float64, member string) bool {
+	return n.score > score || (n.score == score && n.member > member)
+}
+
+func randomLevel() int {
+	level := 1
+	for level < maxLevel && rand.Float64() < levelUp {
+		level++
+	}
+	return level
+}
+
+// insert adds a member that is not in the list yet.
+func (s *skiplist) insert(member string, score float64) {
+	var update [maxLevel]*skipNode
+	var rank [maxLevel]int
+
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		if i < s.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.next[i].forward != nil && x.next[i].forward.before(score, member) {
+			rank[i] += x.next[i].span
+			x = x.next[i].forward
+		}
+		update[i] = x
+	}
+
+	level := randomLevel()
+	if level > s.level {
+		for i := s.level; i < level; i++ {
+			update[i] = s.head
+			update[i].next[i].span = s.length
+		}
+		s.level = level
+	}
+
+	node := &skipNode{member: member, score: score, next: make([]skipLink, level)}
+	for i := 0; i < level; i++ {
+		node.next[i].forward = update[i].next[i].forward
+		update[i].next[i].forward = node
+		// rank[0] - rank[i] nodes lie between update[i] and the new node
+		node.next[i].span = update[i].next[i].span - (rank[0] - rank[i])
+		update[i].next[i].span = rank[0] - rank[i] + 1
+	}
+	for i := level; i < s.level; i++ {
+		update[i].next[i].span++
+	}
+	s.length++
+}
+
+// remove deletes a member with its current score, and reports whether it
+// was found.
+func (s *skiplist) remove(member string, score float64) bool {
+	var update [maxLevel]*skipNode
+
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i].forward != nil && x.next[i].forward.before(score, member) {
+			x = x.next[i].forward
+		}
+		update[i] = x
+	}
+
+	node := x.next[0].forward
+	if node == nil || node.score != score || node.member != member {
+		return false
+	}
+	for i := 0; i < s.level; i++ {
+		if update[i].next[i].forward == node {
+			update[i].next[i].span += node.next[i].span - 1
+			update[i].next[i].forward = node.next[i].forward
+		} else {
+			update[i].next[i].span--
+		}
+	}
+	for s.level > 1 && s.head.next[s.level-1].forward == nil {
+		s.level--
+	}
+	s.length--
+	return true
+}
+
+// rank returns the 0-based rank of a member with its current score, or -1
+// when it is missing.
+func (s *skiplist) rank(member string, score float64) int {
+	rank := 0
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i].forward != nil && !x.next[i].forward.after(score, member) {
+			rank += x.next[i].span
+			x = x.next[i].forward
+		}
+		if x != s.head && x.member == member {
+			return rank - 1
+		}
+	}
+	return -1
+}
+
+// byRank returns the node at the 0-based rank, nil when it is out of range.
+func (s *skiplist) byRank(rank int) *skipNode {
+	if rank < 0 || rank >= s.length {
+		return nil
+	}
+	traversed := 0
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i].forward != nil && traversed+x.next[i].span <= rank+1 {
+			traversed += x.next[i].span
+			x = x.next[i].forward
+		}
+		if traversed == rank+1 {
+			return x
+		}
+	}
+	return nil
+}
+
+// firstFrom returns the first node scored min or more, nil when there is
+// none.
+func (s *skiplist) firstFrom(min float64) *skipNode {
+	x := s.head
+	for i := s.level - 1; i >= 0; i-- {
+		for x.next[i].forward != nil && x.next[i].forward.score < min {
+			x = x.next[i].forward
+		}
+	}
+	return x.next[0].forward
+}
+
+// sortedSet is a skiplist for the ordered queries plus a map for the score
+// of a member.
+type sortedSet struct {
+	scores map[string]float64
+	list   *skiplist
+}
+
+func newSortedSet() *sortedSet {
+	return &sortedSet{scores: make(map[string]float64), list: newSkiplist()}
+}
+
+// set gives member a score and reports whether it is new.
+func (z *sortedSet) set(member string, score float64) bool {
+	current, exists := z.scores[member]
+	if exists {
+		if current == score {
+			return false
+		}
+		z.list.remove(member, current)
+	}
+	z.list.insert(member, score)
+	z.scores[member] = score
+	return !exists
+}
+
+// lookup returns the score of member, on a nil sorted set too.
+func (z *sortedSet) lookup(member string) (float64, bool) {
+	if z == nil {
+		return 0, false
+	}
+	score, ok := z.scores[member]
+	return score, ok
+}
+
+func (z *sortedSet) remove(member string) bool {
+	score, ok := z.scores[member]
+	if !ok {
+		return false
+	}
+	z.list.remove(member, score)
+	delete(z.scores, member)
+	return true
+}
+
+// scoredMembers returns the members from the node on, while keep accepts
+// them.
+func scoredMembers(from *skipNode, keep func(*skipNode) bool) []database.ScoredMember {
+	result := []database.ScoredMember{}
+	for x := from; x != nil && keep(x); x = x.next[0].forward {
+		result = append(result, database.ScoredMember{Member: x.member, Score: x.score})
+	}
+	return result
+}
+
+// all keeps every member, for scoredMembers.
+func all(*skipNode) bool { return true }
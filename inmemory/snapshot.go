@@ -0,0 +1,510 @@
+package inmemory
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// compactAfter is the size past which the log is compacted into a new
+// snapshot, once it is also bigger than the snapshot.
+var compactAfter int64 = 1 << 20
+
+// snapshot is the JSON document SnapshotFile holds. The history and the
+// tombstones are not saved. Values and elements may hold any bytes, keys,
+// hash fields and names are saved as text.
+type snapshot struct {
+	Strings map[string]database.BinaryString            `json:"strings"`
+	Meta    map[string]database.Metadata                `json:"meta,omitempty"`
+	Lists   map[string][]database.BinaryString          `json:"lists,omitempty"`
+	Sets    map[string][]database.BinaryString          `json:"sets,omitempty"`
+	Hashes  map[string]map[string]database.BinaryString `json:"hashes,omitempty"`
+	ZSets   map[string][]savedMember                    `json:"zsets,omitempty"`
+	// Indexes maps the name of every index to its path, the entries are
+	// built again by LoadSnapshot
+	Indexes map[string]string `json:"indexes,omitempty"`
+	// Namespaces holds the stores of the namespaces by name
+	Namespaces map[string]namespaceSnapshot `json:"namespaces,omitempty"`
+	// Seq is the last append to the log the snapshot includes
+	Seq uint64 `json:"seq,omitempty"`
+}
+
+// savedMember is a database.ScoredMember as saved.
+type savedMember struct {
+	Member database.BinaryString `json:"member"`
+	Score  float64               `json:"score"`
+}
+
+// namespaceSnapshot is a namespace as saved in the snapshot of its parent.
+type namespaceSnapshot struct {
+	DefaultTTL time.Duration `json:"default_ttl,omitempty"`
+	snapshot
+}
+
+// logRecord is a line of the log kept next to SnapshotFile: the keys of a
+// store that a write changed, as they are after it, and the ones it
+// deleted. Its Seq is the one of the append.
+type logRecord struct {
+	Namespace string   `json:"namespace,omitempty"`
+	Deleted   []string `json:"deleted,omitempty"`
+	snapshot
+}
+
+func newSnapshot() snapshot {
+	return snapshot{
+		Strings: make(map[string]database.BinaryString),
+		Meta:    make(map[string]database.Metadata),
+		Lists:   make(map[string][]database.BinaryString),
+		Sets:    make(map[string][]database.BinaryString),
+		Hashes:  make(map[string]map[string]database.BinaryString),
+		ZSets:   make(map[string][]savedMember),
+	}
+}
+
+// unlock releases mu, saving the store first when a write changed it and
+// SnapshotFile is saved after every write. A failed save is logged, the
+// write stays in memory and is saved again with the next one. The writes to
+// a namespace are saved with its parent. With limits, the keys written are
+// accounted for and other keys evicted first, see SetLimits.
+func (i *Inmemory) unlock() {
+	root := i.root()
+	defer root.mu.Unlock()
+	root.account()
+	if i != root && i.dirty {
+		root.dirty = true
+		i.dirty = false
+	}
+	if root.dirty && root.SnapshotFile != "" && root.SnapshotInterval <= 0 {
+		if err := root.save(); err != nil {
+			slog.Warn("failed to save the snapshot", "file", root.SnapshotFile, "error", err)
+		}
+	}
+}
+
+// save appends the keys written to the log instead of writing the whole
+// store. The log is compacted into a new snapshot once it outgrows it, and
+// the snapshot written again when the namespaces or indexes changed.
+func (i *Inmemory) save() error {
+	if i.reshaped || i.snapshotSize == 0 || i.logSize > max(compactAfter, i.snapshotSize) {
+		return i.saveSnapshot()
+	}
+	return i.appendLog()
+}
+
+// logWrite records key as written, for the log appended to after every
+// write.
+func (i *Inmemory) logWrite(key string) {
+	root := i.root()
+	if root.SnapshotFile == "" || root.SnapshotInterval > 0 {
+		return
+	}
+	if root.journal == nil {
+		root.journal = make(map[change]struct{})
+	}
+	root.journal[change{i, key}] = struct{}{}
+}
+
+// reshape marks a change of the namespaces or indexes.
+func (i *Inmemory) reshape() {
+	i.dirty = true
+	i.root().reshaped = true
+}
+
+// Snapshot saves the store to SnapshotFile when it changed since the last
+// save. It does nothing without a SnapshotFile. A namespace saves the
+// snapshot of its parent.
+func (i *Inmemory) Snapshot() error {
+	if i.parent != nil {
+		return i.parent.Snapshot()
+	}
+
+	i.lock()
+	defer i.mu.Unlock()
+
+	if !i.dirty || i.SnapshotFile == "" {
+		return nil
+	}
+	return i.saveSnapshot()
+}
+
+// SaveSnapshots calls Snapshot every SnapshotInterval until ctx is done.
+func (i *Inmemory) SaveSnapshots(ctx context.Context) {
+	ticker := time.NewTicker(i.SnapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := i.Snapshot(); err != nil {
+				slog.Warn("failed to save the snapshot", "file", i.SnapshotFile, "error", err)
+			}
+		}
+	}
+}
+
+// logFile is where the writes made since the snapshot are appended.
+func (i *Inmemory) logFile() string {
+	return i.SnapshotFile + ".log"
+}
+
+// appendLog writes a line per store with the keys written since the last
+// append, all numbered with the same Seq.
+func (i *Inmemory) appendLog() error {
+	names := map[*Inmemory]string{i: ""}
+	for name, ns := range i.namespaces {
+		names[ns] = name
+	}
+	records := make(map[*Inmemory]*logRecord)
+	for c := range i.journal {
+		name, ok := names[c.store]
+		if !ok {
+			// The namespace was dropped since
+			continue
+		}
+		record, ok := records[c.store]
+		if !ok {
+			record = &logRecord{Namespace: name, snapshot: newSnapshot()}
+			records[c.store] = record
+		}
+		if !c.store.saveKey(&record.snapshot, c.key) {
+			record.Deleted = append(record.Deleted, c.key)
+		}
+	}
+
+	i.seq++
+	var lines bytes.Buffer
+	encoder := json.NewEncoder(&lines)
+	for _, record := range records {
+		record.Seq = i.seq
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode the log: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(i.logFile(), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open the log: %w", err)
+	}
+	n, err := file.Write(lines.Bytes())
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	i.logSize += int64(n)
+	if err != nil {
+		// A line cut short would be read together with the next one, so
+		// the next save starts over from a snapshot
+		i.reshaped = true
+		return fmt.Errorf("failed to write the log: %w", err)
+	}
+	i.journal = nil
+	i.dirty = false
+	return nil
+}
+
+// saveSnapshot writes the store to a temporary file renamed over
+// SnapshotFile, so a crash in the middle leaves the previous snapshot, and
+// then removes the log it includes.
+func (i *Inmemory) saveSnapshot() error {
+	snap := i.snapshot()
+	if len(i.namespaces) > 0 {
+		snap.Namespaces = make(map[string]namespaceSnapshot, len(i.namespaces))
+	}
+	for name, ns := range i.namespaces {
+		snap.Namespaces[name] = namespaceSnapshot{DefaultTTL: ns.ttl, snapshot: ns.snapshot()}
+	}
+	snap.Seq = i.seq
+
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode the snapshot: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(i.SnapshotFile), filepath.Base(i.SnapshotFile)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to create the snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write the snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write the snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), i.SnapshotFile); err != nil {
+		return fmt.Errorf("failed to write the snapshot: %w", err)
+	}
+	// A log left behind only holds lines older than the snapshot, which
+	// LoadSnapshot skips
+	_ = os.Remove(i.logFile())
+
+	i.dirty = false
+	i.reshaped = false
+	i.journal = nil
+	i.logSize = 0
+	i.snapshotSize = int64(len(data))
+	return nil
+}
+
+// snapshot returns the keys of the store, without its namespaces, as they
+// are saved.
+func (i *Inmemory) snapshot() snapshot {
+	snap := newSnapshot()
+	save := func(key string) { i.saveKey(&snap, key) }
+	forEachKey(i.store, save)
+	forEachKey(i.lists, save)
+	forEachKey(i.sets, save)
+	forEachKey(i.hashes, save)
+	forEachKey(i.zsets, save)
+	snap.Indexes = make(map[string]string, len(i.indexes))
+	for name, index := range i.indexes {
+		snap.Indexes[name] = index.path
+	}
+	return snap
+}
+
+// saveKey adds key as it is now to snap and reports whether it exists.
+func (i *Inmemory) saveKey(snap *snapshot, key string) bool {
+	if value, ok := i.store[key]; ok {
+		snap.Strings[key] = database.BinaryString(value)
+		if meta, ok := i.meta[key]; ok {
+			snap.Meta[key] = meta
+		}
+		return true
+	}
+	if list, ok := i.lists[key]; ok {
+		snap.Lists[key] = binaryStrings(list)
+		return true
+	}
+	if set, ok := i.sets[key]; ok {
+		snap.Sets[key] = binaryStrings(members(set))
+		return true
+	}
+	if hash, ok := i.hashes[key]; ok {
+		saved := make(map[string]database.BinaryString, len(hash))
+		for field, value := range hash {
+			saved[field] = database.BinaryString(value)
+		}
+		snap.Hashes[key] = saved
+		return true
+	}
+	if zset, ok := i.zsets[key]; ok {
+		scored := scoredMembers(zset.list.byRank(0), all)
+		saved := make([]savedMember, len(scored))
+		for n, member := range scored {
+			saved[n] = savedMember{Member: database.BinaryString(member.Member), Score: member.Score}
+		}
+		snap.ZSets[key] = saved
+		return true
+	}
+	return false
+}
+
+func binaryStrings(values []string) []database.BinaryString {
+	converted := make([]database.BinaryString, len(values))
+	for n, value := range values {
+		converted[n] = database.BinaryString(value)
+	}
+	return converted
+}
+
+func plainStrings(values []database.BinaryString) []string {
+	converted := make([]string, len(values))
+	for n, value := range values {
+		converted[n] = string(value)
+	}
+	return converted
+}
+
+// LoadSnapshot replaces the store with the one saved in SnapshotFile and
+// applies the writes appended to its log since. A missing file leaves the
+// store empty, as it is on the first start.
+func (i *Inmemory) LoadSnapshot() error {
+	i.lock()
+	defer i.mu.Unlock()
+
+	data, err := os.ReadFile(i.SnapshotFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode the snapshot %s: %w", i.SnapshotFile, err)
+	}
+	if err := i.restore(snap); err != nil {
+		return fmt.Errorf("failed to decode the snapshot %s: %w", i.SnapshotFile, err)
+	}
+
+	i.namespaces = make(map[string]*Inmemory, len(snap.Namespaces))
+	for name, saved := range snap.Namespaces {
+		ns, err := i.namespace(name)
+		if err == nil {
+			err = ns.restore(saved.snapshot)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to decode the snapshot %s: %w", i.SnapshotFile, err)
+		}
+		ns.ttl = saved.DefaultTTL
+	}
+	if err := i.replay(snap.Seq); err != nil {
+		return err
+	}
+	if i.limits.Enabled() {
+		i.recount()
+		i.enforce()
+	}
+	i.snapshotSize = int64(len(data))
+	i.dirty = false
+	i.reshaped = false
+	i.journal = nil
+	return nil
+}
+
+// replay applies the lines of the log appended after the snapshot, whose
+// last append is seq. A last line cut short by a crash is removed from the
+// file.
+func (i *Inmemory) replay(seq uint64) error {
+	i.seq = seq
+	i.logSize = 0
+
+	file, err := os.Open(i.logFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read the log: %w", err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(data) > 0 {
+				slog.Warn("dropping the last line of the log, which was not written in full", "file", i.logFile())
+				if err := os.Truncate(i.logFile(), i.logSize); err != nil {
+					return fmt.Errorf("failed to repair the log: %w", err)
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read the log: %w", err)
+		}
+		i.logSize += int64(len(data))
+
+		var record logRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("failed to decode line %d of the log %s: %w", line, i.logFile(), err)
+		}
+		if record.Seq <= seq {
+			continue
+		}
+		store := i
+		if record.Namespace != "" {
+			if store, err = i.namespace(record.Namespace); err != nil {
+				return fmt.Errorf("failed to decode line %d of the log %s: %w", line, i.logFile(), err)
+			}
+		}
+		store.apply(record.snapshot, record.Deleted)
+		i.seq = record.Seq
+	}
+}
+
+// restore replaces the keys of the store with the ones of snap.
+func (i *Inmemory) restore(snap snapshot) error {
+	i.store = make(map[string]string, len(snap.Strings))
+	i.meta = make(map[string]database.Metadata, len(snap.Meta))
+	i.lists = make(map[string][]string, len(snap.Lists))
+	i.sets = make(map[string]map[string]struct{}, len(snap.Sets))
+	i.hashes = make(map[string]map[string]string, len(snap.Hashes))
+	i.zsets = make(map[string]*sortedSet, len(snap.ZSets))
+	i.indexes = make(map[string]*valueIndex, len(snap.Indexes))
+	for name, path := range snap.Indexes {
+		steps, err := database.CheckIndex(name, path)
+		if err != nil {
+			return err
+		}
+		i.addIndex(name, path, steps)
+	}
+	i.apply(snap, nil)
+	return nil
+}
+
+// apply deletes the keys in deleted and writes the ones of snap over the
+// keys of the store.
+func (i *Inmemory) apply(snap snapshot, deleted []string) {
+	for _, key := range deleted {
+		i.forget(key)
+	}
+	for key, value := range snap.Strings {
+		i.forget(key)
+		i.store[key] = string(value)
+		if meta, ok := snap.Meta[key]; ok {
+			i.meta[key] = meta
+		}
+		i.reindex(key)
+	}
+	for key, list := range snap.Lists {
+		i.forget(key)
+		if len(list) > 0 {
+			i.lists[key] = plainStrings(list)
+		}
+	}
+	for key, members := range snap.Sets {
+		i.forget(key)
+		if len(members) == 0 {
+			continue
+		}
+		set := make(map[string]struct{}, len(members))
+		for _, member := range members {
+			set[string(member)] = struct{}{}
+		}
+		i.sets[key] = set
+	}
+	for key, saved := range snap.Hashes {
+		i.forget(key)
+		if len(saved) == 0 {
+			continue
+		}
+		hash := make(map[string]string, len(saved))
+		for field, value := range saved {
+			hash[field] = string(value)
+		}
+		i.hashes[key] = hash
+	}
+	for key, members := range snap.ZSets {
+		i.forget(key)
+		if len(members) == 0 {
+			continue
+		}
+		zset := newSortedSet()
+		for _, member := range members {
+			zset.set(string(member.Member), member.Score)
+		}
+		i.zsets[key] = zset
+	}
+}
+
+// forget removes key whatever its type, without keeping its history.
+func (i *Inmemory) forget(key string) {
+	delete(i.store, key)
+	delete(i.meta, key)
+	i.dropComposite(key)
+	i.reindex(key)
+}
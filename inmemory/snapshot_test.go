@@ -0,0 +1,158 @@
+package inmemory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+
+	inmem, _ := NewInmemory()
+	inmem.SnapshotFile = file
+	assert.NoError(t, inmem.LoadSnapshot(), "a missing snapshot is an empty store")
+
+	_ = inmem.MultiSet(map[string]string{"name": "Alice", "blob": "\xff\x00"})
+	_ = inmem.SetMetadata("name", "text/plain", []string{"people"})
+	_, _ = inmem.RPush("queue", "a", "b")
+	_, _ = inmem.SAdd("tags", "go", "db")
+	_, _ = inmem.HSet("user", "name", "Bob")
+	_, _ = inmem.ZAdd("board", "alice", 12.5)
+	assert.FileExists(t, file, "every write is saved")
+
+	loaded, _ := NewInmemory()
+	loaded.SnapshotFile = file
+	assert.NoError(t, loaded.LoadSnapshot())
+
+	values, _ := loaded.MultiGet([]string{"name", "blob"})
+	assert.Equal(t, map[string]string{"name": "Alice", "blob": "\xff\x00"}, values)
+	meta, err := loaded.Stat("name")
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain", meta.ContentType)
+	assert.Equal(t, []string{"people"}, meta.Tags)
+	list, _ := loaded.LRange("queue", 0, -1)
+	assert.Equal(t, []string{"a", "b"}, list)
+	members, _ := loaded.SMembers("tags")
+	assert.Equal(t, []string{"db", "go"}, members)
+	name, _ := loaded.HGet("user", "name")
+	assert.Equal(t, "Bob", name)
+	rank, _ := loaded.ZRange("board", 0, -1)
+	assert.Equal(t, []database.ScoredMember{{Member: "alice", Score: 12.5}}, rank)
+}
+
+func TestSnapshot_Interval(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+
+	inmem, _ := NewInmemory()
+	inmem.SnapshotFile = file
+	inmem.SnapshotInterval = time.Hour
+
+	_, _ = inmem.SAdd("tags", "go")
+	assert.NoFileExists(t, file, "writes wait for the interval")
+
+	assert.NoError(t, inmem.Snapshot())
+	assert.FileExists(t, file)
+}
+
+func TestLoadSnapshot_Invalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+	assert.NoError(t, os.WriteFile(file, []byte("not json"), 0o644))
+
+	inmem, _ := NewInmemory()
+	inmem.SnapshotFile = file
+	assert.ErrorContains(t, inmem.LoadSnapshot(), "failed to decode the snapshot")
+}
+
+// load opens the store saved in file.
+func load(t *testing.T, file string) *Inmemory {
+	t.Helper()
+	inmem, _ := NewInmemory()
+	inmem.SnapshotFile = file
+	assert.NoError(t, inmem.LoadSnapshot())
+	return inmem
+}
+
+func TestSnapshot_Log(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+
+	inmem, _ := NewInmemory()
+	inmem.SnapshotFile = file
+	billing, _ := inmem.Namespace("billing")
+	saved, _ := os.ReadFile(file)
+
+	_ = inmem.MultiSet(map[string]string{"name": "Alice", "gone": "1"})
+	_ = inmem.MultiSet(map[string]string{"name": "Bob"})
+	_, _ = inmem.MultiDelete([]string{"gone"})
+	_, _ = inmem.RPush("queue", "a", "\xff")
+	_, _ = inmem.HSet("user", "photo", "\x00\xfe")
+	_, _ = inmem.ZAdd("board", "\xfe", 1)
+	_ = billing.MultiSet(map[string]string{"invoice": "paid"})
+	current, _ := os.ReadFile(file)
+	assert.Equal(t, saved, current, "the writes are appended to the log")
+	assert.FileExists(t, file+".log")
+
+	loaded := load(t, file)
+	values, _ := loaded.MultiGet([]string{"name", "gone"})
+	assert.Equal(t, map[string]string{"name": "Bob"}, values)
+	list, _ := loaded.LRange("queue", 0, -1)
+	assert.Equal(t, []string{"a", "\xff"}, list)
+	photo, _ := loaded.HGet("user", "photo")
+	assert.Equal(t, "\x00\xfe", photo)
+	rank, _ := loaded.ZRange("board", 0, -1)
+	assert.Equal(t, []database.ScoredMember{{Member: "\xfe", Score: 1}}, rank)
+	ns, _ := loaded.Namespace("billing")
+	invoices, _ := ns.MultiGet([]string{"invoice"})
+	assert.Equal(t, map[string]string{"invoice": "paid"}, invoices)
+}
+
+func TestSnapshot_Compaction(t *testing.T) {
+	defer func(size int64) { compactAfter = size }(compactAfter)
+	compactAfter = 0
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+
+	inmem, _ := NewInmemory()
+	inmem.SnapshotFile = file
+	compacted := false
+	for n := range 10 {
+		_ = inmem.MultiSet(map[string]string{"count": fmt.Sprint(n)})
+		if _, err := os.Stat(file + ".log"); n > 0 && errors.Is(err, os.ErrNotExist) {
+			compacted = true
+		}
+	}
+	assert.True(t, compacted, "the log is compacted once it outgrows the snapshot")
+
+	values, _ := load(t, file).MultiGet([]string{"count"})
+	assert.Equal(t, map[string]string{"count": "9"}, values)
+}
+
+func TestLoadSnapshot_CutLog(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "snapshot.json")
+
+	inmem, _ := NewInmemory()
+	inmem.SnapshotFile = file
+	_ = inmem.MultiSet(map[string]string{"a": "1"})
+	_ = inmem.MultiSet(map[string]string{"b": "2"})
+	written, _ := os.ReadFile(file + ".log")
+
+	// A crash in the middle of an append
+	log, _ := os.OpenFile(file+".log", os.O_WRONLY|os.O_APPEND, 0o644)
+	_, _ = log.WriteString(`{"strings":{"c"`)
+	_ = log.Close()
+
+	loaded := load(t, file)
+	values, _ := loaded.MultiGet([]string{"a", "b", "c"})
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, values)
+	repaired, _ := os.ReadFile(file + ".log")
+	assert.Equal(t, written, repaired, "the cut line is removed")
+
+	_ = loaded.MultiSet(map[string]string{"c": "3"})
+	values, _ = load(t, file).MultiGet([]string{"a", "b", "c"})
+	assert.Equal(t, map[string]string{"a": "1", "b": "2", "c": "3"}, values)
+}
@@ -0,0 +1,176 @@
+package inmemory
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// ZAdd sets the score of member, see database.SortedSetStore.
+func (i *Inmemory) ZAdd(key, member string, score float64) (bool, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeZSet); err != nil {
+		return false, err
+	}
+	if math.IsNaN(score) {
+		return false, fmt.Errorf("%w: the score cannot be NaN", database.ErrNotNumber)
+	}
+	if err := i.admit(key); err != nil {
+		return false, err
+	}
+	added := i.sortedSet(key).set(member, score)
+	i.changed(key)
+	return added, nil
+}
+
+// ZIncrBy adds delta to the score of member.
+func (i *Inmemory) ZIncrBy(key, member string, delta float64) (float64, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeZSet); err != nil {
+		return 0, err
+	}
+	zset := i.sortedSet(key)
+	score := zset.scores[member] + delta
+	if math.IsNaN(score) {
+		return 0, fmt.Errorf("%w: the score would be NaN", database.ErrNotNumber)
+	}
+	if err := i.admit(key); err != nil {
+		return 0, err
+	}
+	zset.set(member, score)
+	i.changed(key)
+	return score, nil
+}
+
+// sortedSet returns the sorted set of key, creating it.
+func (i *Inmemory) sortedSet(key string) *sortedSet {
+	if i.zsets == nil {
+		i.zsets = make(map[string]*sortedSet)
+	}
+	zset, ok := i.zsets[key]
+	if !ok {
+		zset = newSortedSet()
+		i.zsets[key] = zset
+	}
+	return zset
+}
+
+// ZScore returns the score of member.
+func (i *Inmemory) ZScore(key, member string) (float64, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeZSet); err != nil {
+		return 0, err
+	}
+	i.accessed(key)
+	score, ok := i.zsets[key].lookup(member)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s %s", database.ErrNotFound, key, member)
+	}
+	return score, nil
+}
+
+// ZRank returns the rank of member, the lowest score being 0.
+func (i *Inmemory) ZRank(key, member string) (int, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeZSet); err != nil {
+		return 0, err
+	}
+	zset := i.zsets[key]
+	score, ok := zset.lookup(member)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s %s", database.ErrNotFound, key, member)
+	}
+	return zset.list.rank(member, score), nil
+}
+
+// ZRem removes members from the sorted set.
+func (i *Inmemory) ZRem(key string, members ...string) (int, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeZSet); err != nil {
+		return 0, err
+	}
+	zset, ok := i.zsets[key]
+	if !ok {
+		return 0, nil
+	}
+	removed := 0
+	for _, member := range members {
+		if zset.remove(member) {
+			removed++
+		}
+	}
+	if removed > 0 {
+		if zset.list.length == 0 {
+			delete(i.zsets, key)
+		}
+		i.changed(key)
+	}
+	return removed, nil
+}
+
+// ZCard returns the number of members of the sorted set.
+func (i *Inmemory) ZCard(key string) (int, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeZSet); err != nil {
+		return 0, err
+	}
+	if zset, ok := i.zsets[key]; ok {
+		return zset.list.length, nil
+	}
+	return 0, nil
+}
+
+// ZRange returns the members ranked from start to stop, both included.
+func (i *Inmemory) ZRange(key string, start, stop int) ([]database.ScoredMember, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeZSet); err != nil {
+		return nil, err
+	}
+	i.accessed(key)
+	zset, ok := i.zsets[key]
+	if !ok {
+		return []database.ScoredMember{}, nil
+	}
+	from, to := database.ListRange(zset.list.length, start, stop)
+	if from >= to {
+		return []database.ScoredMember{}, nil
+	}
+	left := to - from
+	return scoredMembers(zset.list.byRank(from), func(*skipNode) bool {
+		left--
+		return left >= 0
+	}), nil
+}
+
+// ZRangeByScore returns the members scored from min to max, both included.
+func (i *Inmemory) ZRangeByScore(key string, min, max float64) ([]database.ScoredMember, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeZSet); err != nil {
+		return nil, err
+	}
+	i.accessed(key)
+	zset, ok := i.zsets[key]
+	if !ok {
+		return []database.ScoredMember{}, nil
+	}
+	return scoredMembers(zset.list.firstFrom(min), func(node *skipNode) bool {
+		return node.score <= max
+	}), nil
+}
@@ -0,0 +1,121 @@
+package inmemory
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedSets(t *testing.T) {
+	inmem, _ := NewInmemory()
+
+	added, err := inmem.ZAdd("board", "alice", 30)
+	assert.NoError(t, err)
+	assert.True(t, added)
+	_, _ = inmem.ZAdd("board", "bob", 10)
+	_, _ = inmem.ZAdd("board", "carol", 20)
+	_, _ = inmem.ZAdd("board", "dave", 20)
+	added, err = inmem.ZAdd("board", "alice", 5)
+	assert.NoError(t, err)
+	assert.False(t, added, "a new score moves the member")
+
+	score, err := inmem.ZIncrBy("board", "bob", 25)
+	assert.NoError(t, err)
+	assert.Equal(t, 35.0, score)
+
+	all, err := inmem.ZRange("board", 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []database.ScoredMember{{Member: "alice", Score: 5}, {Member: "carol", Score: 20}, {Member: "dave", Score: 20}, {Member: "bob", Score: 35}}, all)
+
+	tests := []struct {
+		name     string
+		member   string
+		rank     int
+		score    float64
+		notFound bool
+	}{
+		{name: "Lowest score", member: "alice", rank: 0, score: 5},
+		{name: "Equal scores by member", member: "dave", rank: 2, score: 20},
+		{name: "Highest score", member: "bob", rank: 3, score: 35},
+		{name: "Missing member", member: "erin", notFound: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rank, err := inmem.ZRank("board", tt.member)
+			if tt.notFound {
+				assert.ErrorIs(t, err, database.ErrNotFound)
+				_, err = inmem.ZScore("board", tt.member)
+				assert.ErrorIs(t, err, database.ErrNotFound)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.rank, rank)
+			score, err := inmem.ZScore("board", tt.member)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.score, score)
+		})
+	}
+
+	top, _ := inmem.ZRange("board", -2, -1)
+	assert.Equal(t, []database.ScoredMember{{Member: "dave", Score: 20}, {Member: "bob", Score: 35}}, top)
+	middle, _ := inmem.ZRangeByScore("board", 10, 30)
+	assert.Equal(t, []database.ScoredMember{{Member: "carol", Score: 20}, {Member: "dave", Score: 20}}, middle)
+	below, _ := inmem.ZRangeByScore("board", math.Inf(-1), 5)
+	assert.Equal(t, []database.ScoredMember{{Member: "alice", Score: 5}}, below)
+
+	removed, err := inmem.ZRem("board", "carol", "erin")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	count, _ := inmem.ZCard("board")
+	assert.Equal(t, 3, count)
+
+	_, err = inmem.ZAdd("board", "x", math.NaN())
+	assert.ErrorIs(t, err, database.ErrNotNumber)
+	_, _ = inmem.RPush("queue", "a")
+	_, err = inmem.ZAdd("queue", "x", 1)
+	assert.ErrorIs(t, err, database.ErrWrongType)
+
+	_, _ = inmem.ZRem("board", "alice", "bob", "dave")
+	kind, _ := inmem.Type("board")
+	assert.Equal(t, database.TypeNone, kind, "the last member removes the sorted set")
+}
+
+func TestSkiplist(t *testing.T) {
+	zset := newSortedSet()
+	expected := map[string]float64{}
+	for n := range 2000 {
+		member := fmt.Sprintf("m%d", rand.IntN(500))
+		if n%3 == 0 {
+			zset.remove(member)
+			delete(expected, member)
+			continue
+		}
+		score := float64(rand.IntN(100))
+		zset.set(member, score)
+		expected[member] = score
+	}
+
+	sorted := []database.ScoredMember{}
+	for member, score := range expected {
+		sorted = append(sorted, database.ScoredMember{Member: member, Score: score})
+	}
+	slices.SortFunc(sorted, func(a, b database.ScoredMember) int {
+		if a.Score != b.Score {
+			return int(a.Score - b.Score)
+		}
+		return strings.Compare(a.Member, b.Member)
+	})
+
+	assert.Equal(t, len(sorted), zset.list.length)
+	assert.Equal(t, sorted, scoredMembers(zset.list.byRank(0), all))
+	for rank, member := range sorted {
+		assert.Equal(t, rank, zset.list.rank(member.Member, member.Score), member.Member)
+		assert.Equal(t, member.Member, zset.list.byRank(rank).member)
+	}
+}
@@ -0,0 +1,370 @@
+package inmemory
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+// Type returns the type of the value of key, see database.Typer.
+func (i *Inmemory) Type(key string) (string, error) {
+	i.lock()
+	defer i.unlock()
+
+	if key == "" {
+		return "", fmt.Errorf("require the key")
+	}
+	return i.typeOf(key), nil
+}
+
+func (i *Inmemory) typeOf(key string) string {
+	if _, ok := i.store[key]; ok {
+		return database.TypeString
+	}
+	if _, ok := i.lists[key]; ok {
+		return database.TypeList
+	}
+	if _, ok := i.sets[key]; ok {
+		return database.TypeSet
+	}
+	if _, ok := i.hashes[key]; ok {
+		return database.TypeHash
+	}
+	if _, ok := i.zsets[key]; ok {
+		return database.TypeZSet
+	}
+	return database.TypeNone
+}
+
+// check returns ErrWrongType when key holds a value of another type than
+// want. A missing key can become any type.
+func (i *Inmemory) check(key, want string) error {
+	if key == "" {
+		return fmt.Errorf("require the key")
+	}
+	if got := i.typeOf(key); got != want && got != database.TypeNone {
+		return fmt.Errorf("%w: %s holds a %s", database.ErrWrongType, key, got)
+	}
+	return nil
+}
+
+// dropComposite removes key from the lists, sets, hashes and sorted sets.
+func (i *Inmemory) dropComposite(key string) {
+	delete(i.lists, key)
+	delete(i.sets, key)
+	delete(i.hashes, key)
+	delete(i.zsets, key)
+}
+
+// LPush adds values to the head of the list, see database.ListStore.
+func (i *Inmemory) LPush(key string, values ...string) (int, error) {
+	return i.push(key, values, true)
+}
+
+// RPush adds values to the tail of the list.
+func (i *Inmemory) RPush(key string, values ...string) (int, error) {
+	return i.push(key, values, false)
+}
+
+func (i *Inmemory) push(key string, values []string, head bool) (int, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeList); err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, fmt.Errorf("require the values")
+	}
+	if err := i.admit(key); err != nil {
+		return 0, err
+	}
+	if i.lists == nil {
+		i.lists = make(map[string][]string)
+	}
+
+	list := i.lists[key]
+	if head {
+		// Like pushing them one by one, the last value ends up first
+		reversed := slices.Clone(values)
+		slices.Reverse(reversed)
+		list = append(reversed, list...)
+	} else {
+		list = append(list, values...)
+	}
+	i.lists[key] = list
+	i.changed(key)
+	return len(list), nil
+}
+
+// LPop removes and returns the first element of the list.
+func (i *Inmemory) LPop(key string) (string, error) {
+	return i.pop(key, true)
+}
+
+// RPop removes and returns the last element of the list.
+func (i *Inmemory) RPop(key string) (string, error) {
+	return i.pop(key, false)
+}
+
+func (i *Inmemory) pop(key string, head bool) (string, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeList); err != nil {
+		return "", err
+	}
+	list, ok := i.lists[key]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", database.ErrNotFound, key)
+	}
+
+	var value string
+	if head {
+		value, list = list[0], list[1:]
+	} else {
+		value, list = list[len(list)-1], list[:len(list)-1]
+	}
+	if len(list) == 0 {
+		delete(i.lists, key)
+	} else {
+		i.lists[key] = list
+	}
+	i.changed(key)
+	return value, nil
+}
+
+// LRange returns the elements from start to stop, both included.
+func (i *Inmemory) LRange(key string, start, stop int) ([]string, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeList); err != nil {
+		return nil, err
+	}
+	i.accessed(key)
+	list := i.lists[key]
+	from, to := database.ListRange(len(list), start, stop)
+	return slices.Clone(list[from:to]), nil
+}
+
+// SAdd adds members to the set, see database.SetStore.
+func (i *Inmemory) SAdd(key string, members ...string) (int, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeSet); err != nil {
+		return 0, err
+	}
+	if len(members) == 0 {
+		return 0, fmt.Errorf("require the members")
+	}
+	if err := i.admit(key); err != nil {
+		return 0, err
+	}
+	if i.sets == nil {
+		i.sets = make(map[string]map[string]struct{})
+	}
+	set, ok := i.sets[key]
+	if !ok {
+		set = make(map[string]struct{})
+		i.sets[key] = set
+	}
+
+	added := 0
+	for _, member := range members {
+		if _, ok := set[member]; !ok {
+			set[member] = struct{}{}
+			added++
+		}
+	}
+	i.changed(key)
+	return added, nil
+}
+
+// SRem removes members from the set.
+func (i *Inmemory) SRem(key string, members ...string) (int, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeSet); err != nil {
+		return 0, err
+	}
+	set := i.sets[key]
+	removed := 0
+	for _, member := range members {
+		if _, ok := set[member]; ok {
+			delete(set, member)
+			removed++
+		}
+	}
+	if removed > 0 {
+		if len(set) == 0 {
+			delete(i.sets, key)
+		}
+		i.changed(key)
+	}
+	return removed, nil
+}
+
+// SMembers returns the members of the set.
+func (i *Inmemory) SMembers(key string) ([]string, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeSet); err != nil {
+		return nil, err
+	}
+	i.accessed(key)
+	return members(i.sets[key]), nil
+}
+
+// SInter returns the members found in all the sets.
+func (i *Inmemory) SInter(keys ...string) ([]string, error) {
+	i.lock()
+	defer i.unlock()
+
+	sets, err := i.lookupSets(keys)
+	if err != nil {
+		return nil, err
+	}
+	result := maps.Clone(sets[0])
+	for _, set := range sets[1:] {
+		for member := range result {
+			if _, ok := set[member]; !ok {
+				delete(result, member)
+			}
+		}
+	}
+	return members(result), nil
+}
+
+// SUnion returns the members found in any of the sets.
+func (i *Inmemory) SUnion(keys ...string) ([]string, error) {
+	i.lock()
+	defer i.unlock()
+
+	sets, err := i.lookupSets(keys)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]struct{})
+	for _, set := range sets {
+		maps.Copy(result, set)
+	}
+	return members(result), nil
+}
+
+// lookupSets returns the sets of keys, nil for the missing ones.
+func (i *Inmemory) lookupSets(keys []string) ([]map[string]struct{}, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("require the key")
+	}
+	sets := make([]map[string]struct{}, len(keys))
+	for n, key := range keys {
+		if err := i.check(key, database.TypeSet); err != nil {
+			return nil, err
+		}
+		sets[n] = i.sets[key]
+	}
+	return sets, nil
+}
+
+// HSet sets a field of the hash, see database.HashStore.
+func (i *Inmemory) HSet(key, field, value string) (bool, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeHash); err != nil {
+		return false, err
+	}
+	if field == "" {
+		return false, fmt.Errorf("require the field")
+	}
+	if err := i.admit(key); err != nil {
+		return false, err
+	}
+	if i.hashes == nil {
+		i.hashes = make(map[string]map[string]string)
+	}
+	hash, ok := i.hashes[key]
+	if !ok {
+		hash = make(map[string]string)
+		i.hashes[key] = hash
+	}
+
+	_, exists := hash[field]
+	hash[field] = value
+	i.changed(key)
+	return !exists, nil
+}
+
+// HGet returns a field of the hash.
+func (i *Inmemory) HGet(key, field string) (string, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeHash); err != nil {
+		return "", err
+	}
+	i.accessed(key)
+	value, ok := i.hashes[key][field]
+	if !ok {
+		return "", fmt.Errorf("%w: %s %s", database.ErrNotFound, key, field)
+	}
+	return value, nil
+}
+
+// HDel removes fields from the hash.
+func (i *Inmemory) HDel(key string, fields ...string) (int, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeHash); err != nil {
+		return 0, err
+	}
+	hash := i.hashes[key]
+	removed := 0
+	for _, field := range fields {
+		if _, ok := hash[field]; ok {
+			delete(hash, field)
+			removed++
+		}
+	}
+	if removed > 0 {
+		if len(hash) == 0 {
+			delete(i.hashes, key)
+		}
+		i.changed(key)
+	}
+	return removed, nil
+}
+
+// HGetAll returns every field of the hash.
+func (i *Inmemory) HGetAll(key string) (map[string]string, error) {
+	i.lock()
+	defer i.unlock()
+
+	if err := i.check(key, database.TypeHash); err != nil {
+		return nil, err
+	}
+	i.accessed(key)
+	hash := maps.Clone(i.hashes[key])
+	if hash == nil {
+		hash = map[string]string{}
+	}
+	return hash, nil
+}
+
+// members returns the members of set, sorted.
+func members(set map[string]struct{}) []string {
+	return sortedKeys(set)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := slices.AppendSeq(make([]string, 0, len(m)), maps.Keys(m))
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,160 @@
+package inmemory
+
+import (
+	"testing"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLists(t *testing.T) {
+	inmem, _ := NewInmemory()
+
+	length, err := inmem.RPush("queue", "b", "c")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, length)
+	length, err = inmem.LPush("queue", "a", "z")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, length)
+
+	values, err := inmem.LRange("queue", 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"z", "a", "b", "c"}, values)
+
+	tests := []struct {
+		name        string
+		start, stop int
+		expected    []string
+	}{
+		{name: "First two", start: 0, stop: 1, expected: []string{"z", "a"}},
+		{name: "Last two", start: -2, stop: -1, expected: []string{"b", "c"}},
+		{name: "Stop past the end", start: 2, stop: 10, expected: []string{"b", "c"}},
+		{name: "Start after stop", start: 3, stop: 1, expected: []string{}},
+		{name: "Start past the end", start: 5, stop: 6, expected: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values, err := inmem.LRange("queue", tt.start, tt.stop)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, values)
+		})
+	}
+
+	value, err := inmem.LPop("queue")
+	assert.NoError(t, err)
+	assert.Equal(t, "z", value)
+	value, err = inmem.RPop("queue")
+	assert.NoError(t, err)
+	assert.Equal(t, "c", value)
+
+	_, _ = inmem.LPop("queue")
+	_, _ = inmem.LPop("queue")
+	_, err = inmem.LPop("queue")
+	assert.ErrorIs(t, err, database.ErrNotFound, "the last pop removes the list")
+	kind, _ := inmem.Type("queue")
+	assert.Equal(t, database.TypeNone, kind)
+}
+
+func TestSets(t *testing.T) {
+	inmem, _ := NewInmemory()
+
+	added, err := inmem.SAdd("a", "x", "y", "z", "x")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, added)
+	_, _ = inmem.SAdd("b", "y", "z", "w")
+
+	members, err := inmem.SMembers("a")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x", "y", "z"}, members)
+
+	inter, err := inmem.SInter("a", "b")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"y", "z"}, inter)
+	inter, err = inmem.SInter("a", "missing")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, inter)
+
+	union, err := inmem.SUnion("a", "b", "missing")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"w", "x", "y", "z"}, union)
+
+	removed, err := inmem.SRem("a", "x", "nope")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	_, _ = inmem.SRem("a", "y", "z")
+	kind, _ := inmem.Type("a")
+	assert.Equal(t, database.TypeNone, kind, "the last member removes the set")
+}
+
+func TestHashes(t *testing.T) {
+	inmem, _ := NewInmemory()
+
+	created, err := inmem.HSet("user", "name", "Alice")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	created, err = inmem.HSet("user", "name", "Bob")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	_, _ = inmem.HSet("user", "age", "30")
+
+	value, err := inmem.HGet("user", "name")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bob", value)
+	_, err = inmem.HGet("user", "email")
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	all, err := inmem.HGetAll("user")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "Bob", "age": "30"}, all)
+
+	removed, err := inmem.HDel("user", "name", "email")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	all, err = inmem.HGetAll("missing")
+	assert.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func TestWrongType(t *testing.T) {
+	inmem, _ := NewInmemory()
+	_ = inmem.MultiSet(map[string]string{"name": "Alice"})
+	_, _ = inmem.RPush("list", "a")
+	_, _ = inmem.SAdd("set", "a")
+	_, _ = inmem.HSet("hash", "f", "v")
+
+	for key, expected := range map[string]string{"name": database.TypeString, "list": database.TypeList, "set": database.TypeSet, "hash": database.TypeHash, "missing": database.TypeNone} {
+		kind, err := inmem.Type(key)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, kind, key)
+	}
+
+	_, err := inmem.LPush("name", "a")
+	assert.ErrorIs(t, err, database.ErrWrongType)
+	_, err = inmem.SMembers("list")
+	assert.ErrorIs(t, err, database.ErrWrongType)
+	_, err = inmem.SUnion("set", "hash")
+	assert.ErrorIs(t, err, database.ErrWrongType)
+	_, err = inmem.HGet("set", "a")
+	assert.ErrorIs(t, err, database.ErrWrongType)
+	_, err = inmem.IncrBy("hash", 1)
+	assert.ErrorIs(t, err, database.ErrWrongType)
+	assert.ErrorIs(t, inmem.Update("list", "b"), database.ErrWrongType)
+
+	values, err := inmem.MultiGet([]string{"name", "list"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"name": "Alice"}, values, "only strings are returned")
+
+	keys, _ := inmem.Keys("")
+	assert.Equal(t, []string{"hash", "list", "name", "set"}, keys)
+
+	// A string write replaces any type, like a delete does
+	assert.NoError(t, inmem.MultiSet(map[string]string{"list": "now a string"}))
+	kind, _ := inmem.Type("list")
+	assert.Equal(t, database.TypeString, kind)
+	deleted, err := inmem.MultiDelete([]string{"set", "hash"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"set", "hash"}, deleted)
+	keys, _ = inmem.Keys("")
+	assert.Equal(t, []string{"list", "name"}, keys)
+}
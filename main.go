@@ -1,18 +1,32 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"log/slog"
+	"maps"
 	"os"
+	"os/signal"
+	"slices"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/imsumedhaa/In-memory-database/api"
+	"github.com/imsumedhaa/In-memory-database/cli"
+	"github.com/imsumedhaa/In-memory-database/config"
 	"github.com/imsumedhaa/In-memory-database/database"
 	"github.com/imsumedhaa/In-memory-database/filesystem"
 	"github.com/imsumedhaa/In-memory-database/inmemory"
+	pgclient "github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+	remoteclient "github.com/imsumedhaa/In-memory-database/pkg/client/remote"
 	"github.com/imsumedhaa/In-memory-database/postgres"
+	"github.com/imsumedhaa/In-memory-database/remote"
+	"github.com/imsumedhaa/In-memory-database/transfer"
 	"github.com/joho/godotenv"
 )
 
@@ -24,153 +38,655 @@ func init() {
 }
 
 var (
-	name string
+	configFile      string
+	name            string
+	output          string
+	history         string
+	script          string
+	continueOnError bool
+	namespace       string
 )
 
 func main() {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	username := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
-
-	if host == "" || port == "" || username == "" || password == "" || dbname == "" {
-		log.Fatal("Missing one or more required environment variables")
-	}
-
 	if len(os.Args) < 2 {
-		fmt.Println("Expected subcommand 'filesystem' or 'inmemory' or 'postgres'")
+		fmt.Println("Expected subcommand 'filesystem' or 'inmemory' or 'postgres' or 'remote'")
 		os.Exit(1)
 	}
 
 	cmd := os.Args[1]
 
 	flags := flag.NewFlagSet(cmd, flag.ExitOnError)
+	configFlag(flags)
 	flags.StringVar(&name, "name", "database.json", "this is the name")
-	flags.Parse(os.Args[2:]) // Parse args after the subcommand
+	flags.StringVar(&output, "output", cli.OutputText, "output of one-shot commands: text or json")
+	flags.StringVar(&history, "history", cli.DefaultHistoryFile(), "file the prompt history is kept in, empty to disable")
+	flags.StringVar(&script, "script", "", "run the commands in this file, - for stdin, instead of the prompt")
+	flags.BoolVar(&continueOnError, "continue-on-error", false, "keep running a script after a command fails")
+	flags.StringVar(&namespace, "namespace", "", "run the commands on the keys of this namespace")
+
+	switch cmd {
+	case "remote":
+		remoteFlags(flags)
+	case "server":
+		flags.String("addr", ":8080", "address the server listens on")
+		flags.String("backend", "postgres", "backend the server stores the keys in")
+	}
+
+	if cmd != "import" && cmd != "export" && cmd != "migrate" { // those parse their own flags
+		flags.Parse(os.Args[2:]) // Parse args after the subcommand
+	}
 
 	var operation database.Database = nil
 	var err error
 
 	switch cmd {
-	case "filesystem":
-		operation, err = filesystem.NewFileSystem(name)
+	case "filesystem", "inmemory", "postgres", "remote":
+		cfg := mustLoadConfig(flags, cmd)
+		operation, err = openDatabase(cmd, cfg)
+		if err == nil && namespace != "" {
+			operation, err = openNamespace(operation, namespace)
+		}
 		if err != nil {
-			fmt.Printf("Error creating filesystem: %v\n", err)
+			fmt.Printf("Error opening %s: %v\n", cmd, err)
 			os.Exit(1)
 		}
 
-	case "inmemory":
-		operation, err = inmemory.NewInmemory()
-		if err != nil {
-			fmt.Printf("Error creating the map: %v", err)
+	case "import":
+		if err := runImport(os.Args[2:]); err != nil {
+			fmt.Printf("Error importing: %v\n", err)
 			os.Exit(1)
 		}
+		os.Exit(0)
 
-	case "postgres":
-
-		operation, err = postgres.NewPostgres(host,port, username, password, dbname)
-		if err != nil {
-			fmt.Printf("Error creating the connection: %v\n", err)
+	case "export":
+		if err := runExport(os.Args[2:]); err != nil {
+			fmt.Printf("Error exporting: %v\n", err)
 			os.Exit(1)
 		}
+		os.Exit(0)
 
-	case "server":
-
-		httpConfig, err := api.NewHttp(host,port, username, password, dbname)
-
-		if err != nil {
-			fmt.Printf("Error creating the http connection: %v\n", err)
+	case "migrate":
+		if err := runMigrate(os.Args[2:]); err != nil {
+			fmt.Printf("Error migrating: %v\n", err)
 			os.Exit(1)
 		}
+		os.Exit(0)
 
-		if err := httpConfig.Run(); err != nil {
+	case "server":
+		cfg := mustLoadConfig(flags, "server")
+
+		if err := runServer(cfg); err != nil {
 			fmt.Printf("Error run http server: %v\n", err)
 			os.Exit(1)
 		}
 
 	default:
-		fmt.Println("Wrong Command. Should be either 'filesystem' or 'inmemory' or 'postgres'")
+		fmt.Println("Wrong Command. Should be either 'filesystem' or 'inmemory' or 'postgres' or 'remote'")
 		os.Exit(1)
 	}
 
-	reader := bufio.NewReader(os.Stdin)
+	if script != "" {
+		code := runScript(operation)
+		saveSnapshot(operation)
+		os.Exit(code)
+	}
+
+	// Anything after the flags is a one-shot command like "get mykey"
+	if flags.NArg() > 0 {
+		code := cli.Run(operation, flags.Args(), output, os.Stdout, os.Stderr)
+		saveSnapshot(operation)
+		os.Exit(code)
+	}
 
-	for {
-		fmt.Println("Enter subcommand: create,update,get,delete,show & exit to quit the program")
-		input, _ := reader.ReadString('\n') //to read the input from user and store into input var
-		command := strings.TrimSpace(input) //delete the "\n" from input var and store it in command var
+	if err := cli.RunREPL(operation, cli.REPLConfig{HistoryFile: history}); err != nil {
+		fmt.Printf("Error running the prompt: %v\n", err)
+		os.Exit(1)
+	}
 
-		switch command {
-		case "create":
-			fmt.Println("Enter the key:")
-			key, _ := reader.ReadString('\n')
-			key = strings.TrimSpace(key)
+	if err := operation.Exit(); err != nil {
+		fmt.Printf("Error while exiting the program: %v\n", err)
+		os.Exit(1)
+	}
+}
 
-			fmt.Println("Enter the value:")
-			value, _ := reader.ReadString('\n')
-			value = strings.TrimSpace(value)
+func configFlag(flags *flag.FlagSet) {
+	flags.StringVar(&configFile, "config", os.Getenv("KVSTORE_CONFIG"), "YAML or TOML config file")
+}
 
-			err := operation.Create(key, value)
-			if err != nil {
-				fmt.Printf("Error while creating the map: %v\n", err)
-				fmt.Println("try using another key...")
-			}
+// remoteFlags registers the flags of the remote client. Their defaults are
+// only shown in the help, the values come from the config unless a flag is
+// given.
+func remoteFlags(flags *flag.FlagSet) {
+	flags.String("url", "http://localhost:8080", "address of the api server")
+	flags.Var(headerFlag{}, "header", `header sent with every request, "Name: value" (repeatable)`)
+	flags.String("token", "", "bearer token sent in the Authorization header")
+	flags.Duration("timeout", 10*time.Second, "timeout of a single request")
+	flags.Int("retries", 2, "how many times a failed request is retried")
+}
 
-		case "get":
-			fmt.Println("Enter the key:")
-			key, _ := reader.ReadString('\n')
-			key = strings.TrimSpace(key)
+// loadConfig layers the config file, the environment and the flags given on
+// the command line, and validates the sections the command uses.
+func loadConfig(flags *flag.FlagSet, sections ...string) (*config.Config, error) {
+	cfg, err := config.Load(configFile, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
 
-			err := operation.Get(key)
-			if err != nil {
-				fmt.Printf("Error while getting the value: %v\n", err)
-				os.Exit(1)
+	flags.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "name":
+			// import and export take the server url in --name for remote
+			if backend := flags.Lookup("backend"); backend != nil && flags.Name() != "server" && backend.Value.String() == "remote" {
+				cfg.Remote.URL = name
+			} else {
+				cfg.Filesystem.Name = name
+			}
+		case "url":
+			cfg.Remote.URL = f.Value.String()
+		case "token":
+			cfg.Remote.Token = f.Value.String()
+		case "header":
+			maps.Copy(cfg.Remote.Headers, f.Value.(headerFlag))
+		case "timeout":
+			cfg.Remote.Timeout = f.Value.(flag.Getter).Get().(time.Duration)
+		case "retries":
+			cfg.Remote.Retries = f.Value.(flag.Getter).Get().(int)
+		case "addr":
+			cfg.Server.Addr = f.Value.String()
+		case "backend":
+			// import and export have a --backend flag of their own
+			if flags.Name() == "server" {
+				cfg.Server.Backend = f.Value.String()
 			}
+		}
+	})
 
-		case "update":
-			fmt.Println("Enter the key:")
-			key, _ := reader.ReadString('\n')
-			key = strings.TrimSpace(key)
+	if err := cfg.Validate(sections...); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
 
-			fmt.Println("Enter the value:")
-			value, _ := reader.ReadString('\n')
-			value = strings.TrimSpace(value)
+	slog.SetDefault(cfg.Logging.NewLogger(os.Stderr))
+	return cfg, nil
+}
 
-			err := operation.Update(key, value)
-			if err != nil {
-				fmt.Printf("Error while updating the value: %v\n", err)
-				os.Exit(1)
-			}
+func mustLoadConfig(flags *flag.FlagSet, sections ...string) *config.Config {
+	cfg, err := loadConfig(flags, sections...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading the configuration: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
 
-		case "delete":
-			fmt.Println("Enter the key you want to delete:")
-			key, _ := reader.ReadString('\n')
-			key = strings.TrimSpace(key)
+// remoteConfig builds the client settings, sending the token of the auth
+// section when the remote one is empty.
+func remoteConfig(cfg *config.Config) remoteclient.Config {
+	headers := maps.Clone(cfg.Remote.Headers)
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	token := cfg.Remote.Token
+	if token == "" {
+		token = cfg.Auth.Token
+	}
+	if token != "" {
+		headers["Authorization"] = "Bearer " + token
+	}
+	return remoteclient.Config{
+		URL:     cfg.Remote.URL,
+		Headers: headers,
+		Timeout: cfg.Remote.Timeout,
+		Retries: cfg.Remote.Retries,
+	}
+}
 
-			err := operation.Delete(key)
-			if err != nil {
-				fmt.Printf("Error while Deleteing the pair: %v\n", err)
-				os.Exit(1)
+// openDatabase creates the backend selected by name.
+func openDatabase(backend string, cfg *config.Config) (database.Database, error) {
+	switch backend {
+	case "filesystem":
+		db, err := filesystem.NewFileSystem(cfg.Filesystem.Name)
+		if err != nil {
+			return nil, err
+		}
+		db.Retention = cfg.History.Retention()
+		db.SoftDelete = cfg.SoftDelete.Settings()
+		return db, nil
+	case "inmemory":
+		db, err := inmemory.NewInmemory()
+		if err != nil {
+			return nil, err
+		}
+		db.Retention = cfg.History.Retention()
+		db.SoftDelete = cfg.SoftDelete.Settings()
+		db.SetLimits(cfg.Inmemory.Limits())
+		if cfg.Inmemory.Snapshot != "" {
+			db.SnapshotFile = cfg.Inmemory.Snapshot
+			db.SnapshotInterval = cfg.Inmemory.SnapshotInterval
+			if err := db.LoadSnapshot(); err != nil {
+				return nil, err
 			}
-
-		case "show":
-			err := operation.Show()
-			if err != nil {
-				fmt.Printf("Error while showing the map: %v\n", err)
-				os.Exit(1)
+			if db.SnapshotInterval > 0 {
+				go db.SaveSnapshots(context.Background())
 			}
+		}
+		return db, nil
+	case "postgres":
+		db, err := postgres.NewPostgres(cfg.PostgresConfig())
+		if err != nil {
+			return nil, err
+		}
+		go cfg.WatchSecrets(context.Background(), db.Reconnect)
+		purgeTombstones(cfg, db)
+		return db, nil
+	case "remote":
+		db, err := remote.NewRemote(remoteConfig(cfg))
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+	return nil, fmt.Errorf("unknown backend %q, should be either 'filesystem' or 'inmemory' or 'postgres' or 'remote'", backend)
+}
+
+// openNamespace returns the keys of the namespace name in db.
+func openNamespace(db database.Database, name string) (database.Database, error) {
+	store, ok := db.(database.NamespaceStore)
+	if !ok {
+		return nil, fmt.Errorf("the backend does not support namespaces")
+	}
+	return store.Namespace(name)
+}
+
+// saveSnapshot saves the writes of a one-shot command or a script that are
+// still waiting for the next inmemory snapshot, like Exit does for the
+// prompt.
+func saveSnapshot(db database.Database) {
+	if db, ok := db.(*inmemory.Inmemory); ok {
+		if err := db.Snapshot(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error saving the snapshot: %v\n", err)
+		}
+	}
+}
+
+// purgeTombstones starts purging the expired tombstones in the background
+// when soft delete is enabled. The filesystem and inmemory backends purge on
+// their own writes instead, as they are not safe for concurrent use.
+func purgeTombstones(cfg *config.Config, purger database.TombstonePurger) {
+	if cfg.SoftDelete.Enabled && cfg.SoftDelete.GracePeriod > 0 {
+		go database.PurgeTombstones(context.Background(), purger, cfg.SoftDelete.GracePeriod)
+	}
+}
+
+func runServer(cfg *config.Config) error {
+	var server *api.Http
+	if cfg.Server.Backend == "postgres" {
+		httpConfig, err := api.NewHttp(cfg.PostgresConfig())
+		if err != nil {
+			return fmt.Errorf("failed to create the http connection: %w", err)
+		}
+		server = httpConfig
+		go cfg.WatchSecrets(context.Background(), server.Reconnect)
+		purgeTombstones(cfg, server)
+		go database.ExpireNamespaces(context.Background(), server)
+	} else {
+		db, err := openDatabase(cfg.Server.Backend, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", cfg.Server.Backend, err)
+		}
+		server = api.NewDatabaseHttp(db)
+		// The server serializes the requests, so the purge can run next to
+		// them on backends that are not safe for concurrent use
+		if _, ok := db.(database.TombstonePurger); ok {
+			purgeTombstones(cfg, server)
+		}
+		// A remote server expires the keys of its namespaces itself
+		if _, ok := db.(database.NamespaceStore); ok && cfg.Server.Backend != "remote" {
+			go database.ExpireNamespaces(context.Background(), server)
+		}
+	}
+
+	server.Token = cfg.Auth.Token
+	if cfg.Cache.Mode == "" {
+		return server.Run(cfg.Server.Addr)
+	}
+
+	cached, err := server.Cache(cfg.Cache.Settings())
+	if err != nil {
+		return err
+	}
+	// The writes of a write-behind cache are flushed before stopping
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopped := make(chan error, 1)
+	go func() { stopped <- cached.Run(ctx) }()
+
+	errs := make(chan error, 1)
+	go func() { errs <- server.Run(cfg.Server.Addr) }()
+	select {
+	case err := <-errs:
+		stop()
+		return errors.Join(err, <-stopped)
+	case err := <-stopped:
+		return err
+	}
+}
+
+// openFile returns stdin/stdout for "-" and the named file otherwise.
+func openFile(path string, write bool) (*os.File, error) {
+	if path == "-" {
+		if write {
+			return os.Stdout, nil
+		}
+		return os.Stdin, nil
+	}
+	if write {
+		return os.Create(path)
+	}
+	return os.Open(path)
+}
+
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	configFlag(flags)
+	backend := flags.String("backend", "filesystem", "backend to import into: filesystem, inmemory, postgres or remote")
+	flags.StringVar(&name, "name", "database.json", "file used by the filesystem backend, server url for remote")
+	file := flags.String("file", "-", "file to read from, - for stdin")
+	format := flags.String("format", "", "jsonl, csv or json (detected from --file when empty)")
+	batchSize := flags.Int("batch-size", transfer.DefaultBatchSize, "number of records written per batch")
+	dryRun := flags.Bool("dry-run", false, "validate the input without writing anything")
+	flags.Parse(args)
+
+	if *format == "" {
+		detected, err := transfer.DetectFormat(*file)
+		if err != nil {
+			return err
+		}
+		*format = detected
+	}
+
+	in, err := openFile(*file, false)
+	if err != nil {
+		return fmt.Errorf("failed to open input: %w", err)
+	}
+	defer in.Close()
 
-		case "exit":
-			err := operation.Exit()
+	reader, err := transfer.NewReader(*format, in)
+	if err != nil {
+		return err
+	}
 
+	cfg, err := loadConfig(flags, *backend)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(*backend, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", *backend, err)
+	}
+
+	count, err := transfer.Import(db, reader, transfer.ImportOptions{
+		BatchSize: *batchSize,
+		DryRun:    *dryRun,
+		Progress:  os.Stderr,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Fprintf(os.Stderr, "Dry run: %d records are valid, nothing was written.\n", count)
+	} else {
+		fmt.Fprintf(os.Stderr, "Imported %d records into %s.\n", count, *backend)
+	}
+	return nil
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	configFlag(flags)
+	backend := flags.String("backend", "filesystem", "backend to export from: filesystem, inmemory, postgres or remote")
+	flags.StringVar(&name, "name", "database.json", "file used by the filesystem backend, server url for remote")
+	file := flags.String("file", "-", "file to write to, - for stdout")
+	format := flags.String("format", "", "jsonl, csv or json (detected from --file when empty)")
+	prefix := flags.String("prefix", "", "only export keys starting with this prefix")
+	batchSize := flags.Int("batch-size", transfer.DefaultBatchSize, "number of keys read per batch")
+	dryRun := flags.Bool("dry-run", false, "count the records without writing the output file")
+	flags.Parse(args)
+
+	if *format == "" {
+		if *file == "-" {
+			*format = transfer.FormatJSONL
+		} else {
+			detected, err := transfer.DetectFormat(*file)
 			if err != nil {
-				fmt.Printf("Error while exiting the program: %v\n", err)
-				os.Exit(1)
+				return err
 			}
+			*format = detected
+		}
+	}
+
+	cfg, err := loadConfig(flags, *backend)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase(*backend, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", *backend, err)
+	}
+
+	var out io.Writer = io.Discard
+	if !*dryRun {
+		f, err := openFile(*file, true)
+		if err != nil {
+			return fmt.Errorf("failed to open output: %w", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	writer, err := transfer.NewWriter(*format, out)
+	if err != nil {
+		return err
+	}
 
+	count, err := transfer.Export(db, writer, transfer.ExportOptions{
+		BatchSize: *batchSize,
+		Prefix:    *prefix,
+		Progress:  os.Stderr,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		fmt.Fprintf(os.Stderr, "Dry run: %d records would be exported.\n", count)
+	} else {
+		fmt.Fprintf(os.Stderr, "Exported %d records from %s.\n", count, *backend)
+	}
+	return nil
+}
+
+// openSpec opens a backend written as "kind" or "kind:name", for example
+// "filesystem:db.json", "remote:http://localhost:8080" or "postgres". The
+// name overrides the file or server url of the config.
+func openSpec(spec string, cfg *config.Config) (database.Database, error) {
+	backend, arg, found := strings.Cut(spec, ":")
+	if found && arg == "" {
+		return nil, fmt.Errorf("missing %s argument in %q", backend, spec)
+	}
+
+	specCfg := *cfg
+	if found {
+		switch backend {
+		case "filesystem":
+			specCfg.Filesystem.Name = arg
+		case "remote":
+			specCfg.Remote.URL = arg
 		default:
-			fmt.Println("Wrong Command.")
+			return nil, fmt.Errorf("%s takes no argument in %q", backend, spec)
+		}
+	}
+	return openDatabase(backend, &specCfg)
+}
+
+func runMigrate(args []string) error {
+	if len(args) > 0 && slices.Contains([]string{"up", "down", "status"}, args[0]) {
+		return runSchemaMigrate(args[0], args[1:])
+	}
+
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	configFlag(flags)
+	from := flags.String("from", "", "source backend, e.g. filesystem:db.json")
+	to := flags.String("to", "", "destination backend, e.g. postgres")
+	batchSize := flags.Int("batch-size", transfer.DefaultBatchSize, "number of keys copied per batch")
+	follow := flags.Bool("follow", false, "keep copying changes until interrupted (Ctrl+C) for cutover")
+	interval := flags.Duration("interval", 5*time.Second, "how often changes are copied with --follow")
+	flags.Parse(args)
+
+	if *from == "" || *to == "" {
+		return fmt.Errorf("both --from and --to are required")
+	}
+	if *from == *to {
+		return fmt.Errorf("source and destination are the same")
+	}
+
+	fromBackend, _, _ := strings.Cut(*from, ":")
+	toBackend, _, _ := strings.Cut(*to, ":")
+	cfg, err := loadConfig(flags, fromBackend, toBackend)
+	if err != nil {
+		return err
+	}
+
+	src, err := openSpec(*from, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open source %s: %w", *from, err)
+	}
+	dst, err := openSpec(*to, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to open destination %s: %w", *to, err)
+	}
+
+	opts := transfer.MigrateOptions{BatchSize: *batchSize, Progress: os.Stderr}
+
+	count, err := transfer.Copy(src, dst, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Copied %d keys from %s to %s.\n", count, *from, *to)
+
+	if *follow {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		fmt.Fprintf(os.Stderr, "Following changes every %s, press Ctrl+C to cut over.\n", *interval)
+		if err := transfer.Follow(ctx, src, dst, *interval, opts); err != nil {
+			return err
+		}
+	}
+
+	summary, err := transfer.Verify(src, dst, *batchSize)
+	if err != nil {
+		return fmt.Errorf("verification failed: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Verified %d keys, checksum %s.\n", summary.Count, summary.Checksum)
+	return nil
+}
+
+// runSchemaMigrate manages the schema of the Postgres table with
+// "migrate up", "migrate down" and "migrate status".
+func runSchemaMigrate(command string, args []string) error {
+	flags := flag.NewFlagSet("migrate "+command, flag.ExitOnError)
+	configFlag(flags)
+	to := flags.Int("to", 0, "version to migrate up to, 0 for the latest (up)")
+	steps := flags.Int("steps", 1, "number of migrations to revert (down)")
+	flags.Parse(args)
+
+	cfg, err := loadConfig(flags, "postgres")
+	if err != nil {
+		return err
+	}
+
+	migrator, err := pgclient.NewMigrator(cfg.PostgresConfig())
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx, *to)
+		for _, migration := range applied {
+			fmt.Fprintf(os.Stderr, "Applied %04d_%s.\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(os.Stderr, "The schema is up to date.")
+		}
+
+	case "down":
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, migration := range reverted {
+			fmt.Fprintf(os.Stderr, "Reverted %04d_%s.\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(reverted) == 0 {
+			fmt.Fprintln(os.Stderr, "No migration to revert.")
+		}
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.Applied() {
+				applied = "applied " + status.AppliedAt.Local().Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-30s %s\n", status.Version, status.Name, applied)
 		}
 	}
+	return nil
+}
+
+func runScript(operation database.Database) int {
+	in, err := openFile(script, false)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening the script: %v\n", err)
+		return cli.ExitError
+	}
+	defer in.Close()
+
+	report, err := cli.RunScript(operation, in, cli.ScriptOptions{ContinueOnError: continueOnError})
+	cli.PrintReport(report, output, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error running the script: %v\n", err)
+		return cli.ExitError
+	}
+	return report.ExitCode()
+}
+
+// headerFlag collects repeated --header "Name: value" flags.
+type headerFlag map[string]string
+
+func (h headerFlag) String() string {
+	return fmt.Sprint(map[string]string(h))
+}
+
+func (h headerFlag) Set(value string) error {
+	name, val, found := strings.Cut(value, ":")
+	if !found || strings.TrimSpace(name) == "" {
+		return fmt.Errorf(`header must look like "Name: value"`)
+	}
+	h[strings.TrimSpace(name)] = strings.TrimSpace(val)
+	return nil
 }
@@ -0,0 +1,896 @@
+// Package cache puts the inmemory store in front of a slower backend,
+// typically Postgres, as an LRU cache of its string values and their
+// metadata.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/inmemory"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+)
+
+// Mode is how the writes reach the backend and the cache.
+type Mode string
+
+const (
+	// ReadThrough caches the values read. Writes go to the backend and
+	// drop the cached value.
+	ReadThrough Mode = "read-through"
+	// WriteThrough also caches the values written, once the backend has
+	// them.
+	WriteThrough Mode = "write-through"
+	// WriteBehind caches the values written and sends them to the backend
+	// in batches every FlushInterval. The writes not flushed yet are lost
+	// when the server crashes.
+	WriteBehind Mode = "write-behind"
+)
+
+// Modes lists the modes by name.
+var Modes = []Mode{ReadThrough, WriteThrough, WriteBehind}
+
+// DefaultFlushInterval is how often the writes are flushed in WriteBehind
+// mode when Settings.FlushInterval is zero.
+const DefaultFlushInterval = time.Second
+
+// FlushBatch is the most writes a flush sends to the backend in one call.
+const FlushBatch = 500
+
+// flushFailures is how many failed calls to the backend a flush makes
+// while it looks for the writes the backend refuses, before it leaves the
+// rest for the next flush.
+const flushFailures = 16
+
+// drainTimeout is how long Run keeps trying to flush the pending writes
+// once it is stopped.
+var drainTimeout = 30 * time.Second
+
+// Settings of the cache. MaxKeys and MaxMemory bound it, the values used
+// least recently are evicted first. Zero means no limit.
+type Settings struct {
+	Mode          Mode
+	MaxKeys       int
+	MaxMemory     int64
+	FlushInterval time.Duration
+}
+
+// Client caches the string values of the backend it wraps, and their
+// metadata, which a get reads with the value. The operations it does not
+// serve from the cache, like the history or the other types, go to the
+// backend, after the pending writes of their keys in WriteBehind mode. The
+// namespaces are not cached.
+//
+// With several servers in front of one backend, Run drops the values the
+// other servers write, from the change notifications of the backend.
+type Client struct {
+	postgres.Client
+
+	settings Settings
+	values   *inmemory.Inmemory
+	// metadata holds the metadata as JSON, in a namespace of values so
+	// both share the limits
+	metadata *inmemory.Inmemory
+
+	// mu guards the cache and the fields below. It is never held while
+	// the backend is called, so a slow backend only slows the misses
+	mu sync.Mutex
+	// generation counts the writes and the invalidations, so a read that
+	// raced with one does not cache the value it read before
+	generation uint64
+	// writing holds the keys written to the backend right now
+	writing map[string]*inflight
+	// pending holds the writes not flushed yet in WriteBehind mode
+	pending map[string]*pendingWrite
+	// own counts the change notifications still to come for the values
+	// this client cached as it wrote them, which must not drop them. They
+	// are only counted while the backend sends notifications
+	own       map[string]int
+	listening bool
+
+	hits, misses int64
+
+	// flushing lets one flush run at a time
+	flushing sync.Mutex
+}
+
+// pendingWrite is a write waiting for the next flush.
+type pendingWrite struct {
+	// value is nil for a delete
+	value *string
+	at    time.Time
+}
+
+// inflight is a key being written to the backend.
+type inflight struct {
+	writers int
+	// raced is set when another write or a change of the key by another
+	// client came during the write, so its value is not cached
+	raced bool
+}
+
+// NewClient wraps backend with a cache.
+func NewClient(backend postgres.Client, settings Settings) (*Client, error) {
+	if !slices.Contains(Modes, settings.Mode) {
+		return nil, fmt.Errorf("invalid cache mode %q, expected read-through, write-through or write-behind", settings.Mode)
+	}
+	if settings.FlushInterval <= 0 {
+		settings.FlushInterval = DefaultFlushInterval
+	}
+
+	values, err := inmemory.NewInmemory()
+	if err != nil {
+		return nil, err
+	}
+	values.SetLimits(database.Limits{MaxKeys: settings.MaxKeys, MaxMemory: settings.MaxMemory, Policy: database.EvictLRU})
+	metadata, err := values.Namespace("metadata")
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		Client:   backend,
+		settings: settings,
+		values:   values,
+		metadata: metadata.(*inmemory.Inmemory),
+		writing:  make(map[string]*inflight),
+		pending:  make(map[string]*pendingWrite),
+		own:      make(map[string]int),
+	}, nil
+}
+
+// Stats tells how full the cache is and how many keys were read from it,
+// the hits, or from the backend, the misses.
+type Stats struct {
+	database.MemoryStats
+	Hits   int64
+	Misses int64
+}
+
+func (c *Client) Stats() Stats {
+	c.mu.Lock()
+	hits, misses := c.hits, c.misses
+	c.mu.Unlock()
+	return Stats{MemoryStats: c.values.MemoryStats(), Hits: hits, Misses: misses}
+}
+
+// Run drops the values written by the other clients of the backend, as it
+// announces them, and flushes the writes every FlushInterval in
+// WriteBehind mode, until ctx is done. Without change notifications from
+// the backend, the cache only sees the writes made through it.
+//
+// Once ctx is done, Run keeps trying to flush the last writes for a while,
+// and returns an error if some could not be.
+func (c *Client) Run(ctx context.Context) error {
+	var wg sync.WaitGroup
+	if c.settings.Mode == WriteBehind {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.flushEvery(ctx)
+		}()
+	}
+
+	err := c.Client.ListenPostgresChanges(ctx, c.invalidate)
+	c.stopListening()
+	if err != nil {
+		slog.Warn("the cache is not invalidated by the writes of other servers", "error", err)
+		<-ctx.Done()
+	}
+	wg.Wait()
+	return c.drain()
+}
+
+func (c *Client) flushEvery(ctx context.Context) {
+	ticker := time.NewTicker(c.settings.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Flush(); err != nil {
+				slog.Warn("failed to flush the cache", "error", err)
+			}
+		}
+	}
+}
+
+// drain flushes the pending writes, trying again every FlushInterval for
+// up to drainTimeout. The keys of the writes it could not flush are
+// logged, so they can be checked once the backend is back.
+func (c *Client) drain() error {
+	deadline := time.Now().Add(drainTimeout)
+	for {
+		err := c.Flush()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(c.settings.FlushInterval).After(deadline) {
+			c.mu.Lock()
+			keys := slices.Sorted(maps.Keys(c.pending))
+			c.mu.Unlock()
+			for _, key := range keys {
+				slog.Error("write not flushed to the backend", "key", key)
+			}
+			return fmt.Errorf("%d writes were not flushed: %w", len(keys), err)
+		}
+		slog.Warn("failed to flush the cache, trying again", "error", err)
+		time.Sleep(c.settings.FlushInterval)
+	}
+}
+
+func (c *Client) stopListening() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listening = false
+	clear(c.own)
+}
+
+// invalidate drops the cached value and metadata of a key written by any
+// client of the backend, or of every key for the empty key, which the
+// backend also sends once it listens.
+func (c *Client) invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if key == "" {
+		c.listening = true
+		clear(c.own)
+		for _, write := range c.writing {
+			write.raced = true
+		}
+		keys, _ := c.values.Keys("")
+		c.forget(keys...)
+		keys, _ = c.metadata.Keys("")
+		c.metadata.Drop(keys...)
+		return
+	}
+	if c.own[key] > 0 {
+		// The notification of a value this client cached as it wrote it
+		c.unexpectOwn(key)
+		return
+	}
+	if write, ok := c.writing[key]; ok {
+		write.raced = true
+	}
+	c.forget(key)
+}
+
+// forget drops the cached values and metadata of keys.
+func (c *Client) forget(keys ...string) {
+	c.values.Drop(keys...)
+	c.metadata.Drop(keys...)
+}
+
+// expectOwn records that the backend will announce the write of a value
+// this client caches.
+func (c *Client) expectOwn(key string) {
+	if c.listening {
+		c.own[key]++
+	}
+}
+
+// unexpectOwn takes back expectOwn, for a write that failed or whose
+// notification came.
+func (c *Client) unexpectOwn(key string) {
+	if c.own[key] > 1 {
+		c.own[key]--
+	} else {
+		delete(c.own, key)
+	}
+}
+
+// Flush sends the pending writes to the backend in WriteBehind mode, in
+// batches of FlushBatch. The writes the backend refuses are kept and sent
+// again by the next flush, without holding back the others.
+func (c *Client) Flush() error {
+	return c.flush(nil)
+}
+
+// flushState is what a flush found so far.
+type flushState struct {
+	failures int
+	failed   int
+	err      error
+}
+
+// flush sends the pending writes of keys, or all of them for nil keys.
+func (c *Client) flush(keys []string) error {
+	c.mu.Lock()
+	if keys != nil && !slices.ContainsFunc(keys, func(key string) bool { return c.pending[key] != nil }) {
+		c.mu.Unlock()
+		return nil
+	}
+	c.mu.Unlock()
+
+	c.flushing.Lock()
+	defer c.flushing.Unlock()
+
+	c.mu.Lock()
+	batch := make(map[string]*pendingWrite)
+	if keys == nil {
+		maps.Copy(batch, c.pending)
+	}
+	for _, key := range keys {
+		if write, ok := c.pending[key]; ok {
+			batch[key] = write
+		}
+	}
+	c.mu.Unlock()
+
+	var sets, deletes []string
+	for _, key := range slices.Sorted(maps.Keys(batch)) {
+		if batch[key].value == nil {
+			deletes = append(deletes, key)
+		} else {
+			sets = append(sets, key)
+		}
+	}
+
+	state := &flushState{}
+	for chunk := range slices.Chunk(sets, FlushBatch) {
+		c.send(chunk, batch, state, c.flushSets)
+	}
+	for chunk := range slices.Chunk(deletes, FlushBatch) {
+		c.send(chunk, batch, state, c.flushDeletes)
+	}
+	if state.failed > 0 {
+		return fmt.Errorf("failed to flush %d writes: %w", state.failed, state.err)
+	}
+	return nil
+}
+
+// send applies the writes of keys to the backend. A batch that fails is
+// halved until the writes the backend refuses are found, which stay
+// pending, unless the flush failed too often already.
+func (c *Client) send(keys []string, batch map[string]*pendingWrite, state *flushState, apply func([]string, map[string]*pendingWrite) error) {
+	if state.failures >= flushFailures {
+		state.failed += len(keys)
+		return
+	}
+	err := apply(keys, batch)
+	if err == nil {
+		c.flushed(keys, batch)
+		return
+	}
+
+	state.failures++
+	if state.err == nil {
+		state.err = err
+	}
+	if len(keys) == 1 {
+		state.failed++
+		slog.Warn("failed to flush a write", "key", keys[0], "error", err)
+		return
+	}
+	half := len(keys) / 2
+	c.send(keys[:half], batch, state, apply)
+	c.send(keys[half:], batch, state, apply)
+}
+
+func (c *Client) flushSets(keys []string, batch map[string]*pendingWrite) error {
+	pairs := make(map[string]string, len(keys))
+	for _, key := range keys {
+		pairs[key] = *batch[key].value
+	}
+
+	// The notifications can come before MultiSetPostgresRows returns
+	c.mu.Lock()
+	for _, key := range keys {
+		c.expectOwn(key)
+	}
+	c.mu.Unlock()
+	err := c.Client.MultiSetPostgresRows(pairs)
+	if err != nil {
+		c.mu.Lock()
+		for _, key := range keys {
+			c.unexpectOwn(key)
+		}
+		c.mu.Unlock()
+	}
+	return err
+}
+
+func (c *Client) flushDeletes(keys []string, _ map[string]*pendingWrite) error {
+	_, err := c.Client.MultiDeletePostgresRows(keys)
+	return err
+}
+
+// flushed removes the writes the backend has from the pending ones, unless
+// they were written again since.
+func (c *Client) flushed(keys []string, batch map[string]*pendingWrite) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		if c.pending[key] == batch[key] {
+			delete(c.pending, key)
+		}
+	}
+}
+
+// cache stores values, replacing the cached ones without keeping them in
+// the history. A full cache only means the values are read again.
+func (c *Client) cache(values map[string]string) {
+	if len(values) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	c.values.Drop(keys...)
+	_ = c.values.MultiSet(values)
+}
+
+// lookup returns the values of keys known to the client: pending or
+// cached. It also returns the keys to read from the backend, and the
+// generation to cache them at.
+func (c *Client) lookup(keys []string) (map[string]string, []string, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	found := make(map[string]string, len(keys))
+	rest := []string{}
+	for _, key := range keys {
+		if write, ok := c.pending[key]; ok {
+			if write.value != nil {
+				found[key] = *write.value
+			}
+			continue
+		}
+		rest = append(rest, key)
+	}
+	cached, err := c.values.MultiGet(rest)
+	if err != nil {
+		// Like an empty key, which the backend reports
+		return found, rest, c.generation
+	}
+	missing := []string{}
+	for _, key := range rest {
+		if value, ok := cached[key]; ok {
+			found[key] = value
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	c.hits += int64(len(keys) - len(missing))
+	c.misses += int64(len(missing))
+	return found, missing, c.generation
+}
+
+// fill caches the values read from the backend, unless a write or an
+// invalidation happened since generation.
+func (c *Client) fill(values map[string]string, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.cache(values)
+	}
+}
+
+func (c *Client) GetPostgresRow(key string) (string, error) {
+	found, missing, generation := c.lookup([]string{key})
+	if len(missing) == 0 {
+		value, ok := found[key]
+		if !ok {
+			return "", postgres.ErrNotFound
+		}
+		return value, nil
+	}
+
+	value, err := c.Client.GetPostgresRow(key)
+	if err != nil {
+		return "", err
+	}
+	c.fill(map[string]string{key: value}, generation)
+	return value, nil
+}
+
+func (c *Client) MultiGetPostgresRows(keys []string) (map[string]string, error) {
+	found, missing, generation := c.lookup(keys)
+	if len(missing) == 0 {
+		return found, nil
+	}
+
+	values, err := c.Client.MultiGetPostgresRows(missing)
+	if err != nil {
+		return nil, err
+	}
+	c.fill(values, generation)
+	for key, value := range values {
+		found[key] = value
+	}
+	return found, nil
+}
+
+// write runs a write of values, or deletes for nil values, on the backend
+// and updates the cache: WriteThrough caches the values, the other modes
+// drop them. In WriteBehind mode the write is staged instead, see stage.
+func (c *Client) write(values map[string]*string, backend func() error, check func() error) error {
+	if c.settings.Mode == WriteBehind {
+		return c.stage(values, check)
+	}
+
+	keys := slices.Sorted(maps.Keys(values))
+	alone := c.begin(keys, values)
+	err := backend()
+	c.end(keys, alone, values, err)
+	return err
+}
+
+// begin records the writes of keys about to be sent to the backend and
+// drops their cached values. It returns the keys no other write was in
+// progress for. The values are those that end caches.
+func (c *Client) begin(keys []string, values map[string]*string) map[string]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	alone := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		write, ok := c.writing[key]
+		if ok {
+			write.raced = true
+		} else {
+			write = &inflight{}
+			c.writing[key] = write
+		}
+		write.writers++
+		alone[key] = !ok
+		c.forget(key)
+		if c.caches(values[key]) {
+			// The notification can come before the backend returns
+			c.expectOwn(key)
+		}
+	}
+	return alone
+}
+
+// end records the writes begin started as done, and caches the values of
+// the ones that succeeded when no other write of their key raced with
+// them.
+func (c *Client) end(keys []string, alone map[string]bool, values map[string]*string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	cached := make(map[string]string)
+	for _, key := range keys {
+		write := c.writing[key]
+		value := values[key]
+		switch {
+		case !c.caches(value):
+		case err != nil:
+			c.unexpectOwn(key)
+		case alone[key] && !write.raced:
+			cached[key] = *value
+		}
+		c.forget(key)
+
+		write.writers--
+		if write.writers == 0 {
+			delete(c.writing, key)
+		}
+	}
+	c.cache(cached)
+}
+
+// caches tells whether a value written goes to the cache once the backend
+// has it.
+func (c *Client) caches(value *string) bool {
+	return value != nil && c.settings.Mode == WriteThrough
+}
+
+// stage keeps a write in the pending writes and the cache for the next
+// flush in WriteBehind mode, if check allows it.
+func (c *Client) stage(values map[string]*string, check func() error) error {
+	for key, value := range values {
+		if key == "" || (value != nil && *value == "") {
+			return fmt.Errorf("require the key and value")
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := check(); err != nil {
+		return err
+	}
+
+	c.generation++
+	now := time.Now()
+	cached := make(map[string]string)
+	for key, value := range values {
+		c.pending[key] = &pendingWrite{value: value, at: now}
+		c.forget(key)
+		if value != nil {
+			cached[key] = *value
+		}
+	}
+	c.cache(cached)
+	return nil
+}
+
+// exists tells whether key has a value, pending, cached or in the
+// backend. It is called with mu held, which it releases while it asks the
+// backend.
+func (c *Client) exists(key string) (bool, error) {
+	if write, ok := c.pending[key]; ok {
+		return write.value != nil, nil
+	}
+	if cached, _ := c.values.MultiGet([]string{key}); len(cached) > 0 {
+		return true, nil
+	}
+
+	c.mu.Unlock()
+	values, err := c.Client.MultiGetPostgresRows([]string{key})
+	c.mu.Lock()
+	if err != nil {
+		return false, err
+	}
+	// Written meanwhile
+	if write, ok := c.pending[key]; ok {
+		return write.value != nil, nil
+	}
+	_, ok := values[key]
+	return ok, nil
+}
+
+func (c *Client) CreatePostgresRow(key, val string) error {
+	return c.write(map[string]*string{key: &val}, func() error {
+		return c.Client.CreatePostgresRow(key, val)
+	}, func() error {
+		exists, err := c.exists(key)
+		if err == nil && exists {
+			err = fmt.Errorf("%w. Use 'update' to change the value", postgres.ErrConflict)
+		}
+		return err
+	})
+}
+
+func (c *Client) UpdatePostgresRow(key, value string) error {
+	return c.write(map[string]*string{key: &value}, func() error {
+		return c.Client.UpdatePostgresRow(key, value)
+	}, func() error {
+		exists, err := c.exists(key)
+		if err == nil && !exists {
+			err = postgres.ErrNotFound
+		}
+		return err
+	})
+}
+
+func (c *Client) DeletePostgresRow(key string) error {
+	return c.write(map[string]*string{key: nil}, func() error {
+		return c.Client.DeletePostgresRow(key)
+	}, func() error {
+		exists, err := c.exists(key)
+		if err == nil && !exists {
+			err = postgres.ErrNotFound
+		}
+		return err
+	})
+}
+
+func (c *Client) MultiSetPostgresRows(pairs map[string]string) error {
+	values := make(map[string]*string, len(pairs))
+	for key, value := range pairs {
+		values[key] = &value
+	}
+	return c.write(values, func() error {
+		return c.Client.MultiSetPostgresRows(pairs)
+	}, func() error { return nil })
+}
+
+func (c *Client) MultiDeletePostgresRows(keys []string) ([]string, error) {
+	var deleted []string
+	values := make(map[string]*string, len(keys))
+	for _, key := range keys {
+		values[key] = nil
+	}
+	err := c.write(values, func() error {
+		var err error
+		deleted, err = c.Client.MultiDeletePostgresRows(keys)
+		return err
+	}, func() error {
+		deleted = []string{}
+		for _, key := range keys {
+			exists, err := c.exists(key)
+			if err != nil {
+				return err
+			}
+			if exists {
+				deleted = append(deleted, key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// change runs an operation of the backend that writes keys, after their
+// pending writes, and drops their cached values.
+func (c *Client) change(keys []string, backend func() error) error {
+	if err := c.settle(keys...); err != nil {
+		return err
+	}
+	alone := c.begin(keys, nil)
+	err := backend()
+	c.end(keys, alone, nil, err)
+	return err
+}
+
+// settle flushes the pending writes of keys, or all of them without keys,
+// before an operation of the backend that reads them.
+func (c *Client) settle(keys ...string) error {
+	if c.settings.Mode != WriteBehind {
+		return nil
+	}
+	return c.flush(keys)
+}
+
+func (c *Client) CopyPostgresRows(pairs map[string]string) error {
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	return c.change(keys, func() error { return c.Client.CopyPostgresRows(pairs) })
+}
+
+func (c *Client) UndeletePostgresRow(key string) error {
+	return c.change([]string{key}, func() error { return c.Client.UndeletePostgresRow(key) })
+}
+
+func (c *Client) WriteStreamPostgresRow(key string, r io.Reader) error {
+	return c.change([]string{key}, func() error { return c.Client.WriteStreamPostgresRow(key, r) })
+}
+
+func (c *Client) IncrByPostgresRow(key string, delta int64) (result int64, err error) {
+	err = c.change([]string{key}, func() error {
+		result, err = c.Client.IncrByPostgresRow(key, delta)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) IncrByFloatPostgresRow(key string, delta float64) (result float64, err error) {
+	err = c.change([]string{key}, func() error {
+		result, err = c.Client.IncrByFloatPostgresRow(key, delta)
+		return err
+	})
+	return result, err
+}
+
+func (c *Client) JSONSetPostgresRow(key, path, value string) error {
+	return c.change([]string{key}, func() error { return c.Client.JSONSetPostgresRow(key, path, value) })
+}
+
+func (c *Client) JSONMergePostgresRow(key, patch string) (document string, err error) {
+	err = c.change([]string{key}, func() error {
+		document, err = c.Client.JSONMergePostgresRow(key, patch)
+		return err
+	})
+	return document, err
+}
+
+func (c *Client) JSONPatchPostgresRow(key, patch string) (document string, err error) {
+	err = c.change([]string{key}, func() error {
+		document, err = c.Client.JSONPatchPostgresRow(key, patch)
+		return err
+	})
+	return document, err
+}
+
+func (c *Client) ShowPostgresRow() (map[string]string, error) {
+	if err := c.settle(); err != nil {
+		return nil, err
+	}
+	return c.Client.ShowPostgresRow()
+}
+
+func (c *Client) KeysPostgresRows(prefix string) ([]string, error) {
+	if err := c.settle(); err != nil {
+		return nil, err
+	}
+	return c.Client.KeysPostgresRows(prefix)
+}
+
+// StatPostgresRow serves the metadata from the cache. The metadata of a
+// pending write is the one it will have once flushed, as far as it is
+// known: the backend sets the time of the update when the flush reaches
+// it.
+func (c *Client) StatPostgresRow(key string) (postgres.Metadata, error) {
+	c.mu.Lock()
+	generation := c.generation
+	write, pending := c.pending[key]
+	cached, err := c.metadata.MultiGet([]string{key})
+	c.mu.Unlock()
+
+	var meta postgres.Metadata
+	if pending {
+		if write.value == nil {
+			return postgres.Metadata{}, postgres.ErrNotFound
+		}
+		meta, err = c.Client.StatPostgresRow(key)
+		if errors.Is(err, postgres.ErrNotFound) {
+			return postgres.Metadata{CreatedAt: write.at, UpdatedAt: write.at, WrittenAt: write.at, Version: 1}, nil
+		}
+		if err != nil {
+			return postgres.Metadata{}, err
+		}
+		meta.UpdatedAt, meta.WrittenAt = write.at, write.at
+		meta.Version++
+		return meta, nil
+	}
+	if err == nil && len(cached) > 0 && json.Unmarshal([]byte(cached[key]), &meta) == nil {
+		return meta, nil
+	}
+
+	meta, err = c.Client.StatPostgresRow(key)
+	if err != nil {
+		return postgres.Metadata{}, err
+	}
+	encoded, err := json.Marshal(meta)
+	if err != nil {
+		return meta, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.metadata.Drop(key)
+		_ = c.metadata.MultiSet(map[string]string{key: string(encoded)})
+	}
+	return meta, nil
+}
+
+func (c *Client) SetMetadataPostgresRow(key, contentType string, tags []string) error {
+	return c.change([]string{key}, func() error { return c.Client.SetMetadataPostgresRow(key, contentType, tags) })
+}
+
+func (c *Client) HistoryPostgresRows(key string, limit int) ([]postgres.Version, error) {
+	if err := c.settle(key); err != nil {
+		return nil, err
+	}
+	return c.Client.HistoryPostgresRows(key, limit)
+}
+
+func (c *Client) GetAtPostgresRow(key string, at time.Time) (string, error) {
+	if err := c.settle(key); err != nil {
+		return "", err
+	}
+	return c.Client.GetAtPostgresRow(key, at)
+}
+
+func (c *Client) ReadStreamPostgresRow(key string, w io.Writer) error {
+	if err := c.settle(key); err != nil {
+		return err
+	}
+	return c.Client.ReadStreamPostgresRow(key, w)
+}
+
+func (c *Client) JSONGetPostgresRow(key, path string) (string, error) {
+	if err := c.settle(key); err != nil {
+		return "", err
+	}
+	return c.Client.JSONGetPostgresRow(key, path)
+}
+
+func (c *Client) QueryPostgresRows(index, value string) ([]string, error) {
+	if err := c.settle(); err != nil {
+		return nil, err
+	}
+	return c.Client.QueryPostgresRows(index, value)
+}
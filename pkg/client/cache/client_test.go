@@ -0,0 +1,331 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewClient(t *testing.T) {
+	_, err := NewClient(mocks.NewClient(t), Settings{Mode: "write-around"})
+	assert.EqualError(t, err, `invalid cache mode "write-around", expected read-through, write-through or write-behind`)
+}
+
+func TestClient_Modes(t *testing.T) {
+	tests := []struct {
+		name     string
+		mode     Mode
+		mockFunc func(m *mocks.Client)
+		// hits and misses of the reads after the update
+		expectedHits, expectedMisses int64
+	}{
+		{
+			name: "Read-through reads the value written again",
+			mode: ReadThrough,
+			mockFunc: func(m *mocks.Client) {
+				m.On("GetPostgresRow", "user:1").Return("alice", nil).Times(1)
+				m.On("UpdatePostgresRow", "user:1", "bob").Return(nil).Times(1)
+				m.On("GetPostgresRow", "user:1").Return("bob", nil).Times(1)
+			},
+			expectedHits:   2,
+			expectedMisses: 2,
+		},
+		{
+			name: "Write-through caches the value written",
+			mode: WriteThrough,
+			mockFunc: func(m *mocks.Client) {
+				m.On("GetPostgresRow", "user:1").Return("alice", nil).Times(1)
+				m.On("UpdatePostgresRow", "user:1", "bob").Return(nil).Times(1)
+			},
+			expectedHits:   3,
+			expectedMisses: 1,
+		},
+		{
+			name: "Write-behind writes on flush",
+			mode: WriteBehind,
+			mockFunc: func(m *mocks.Client) {
+				m.On("GetPostgresRow", "user:1").Return("alice", nil).Times(1)
+				m.On("MultiSetPostgresRows", map[string]string{"user:1": "bob"}).Return(nil).Times(1)
+			},
+			expectedHits:   3,
+			expectedMisses: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			client, err := NewClient(mockClient, Settings{Mode: tt.mode})
+			assert.NoError(t, err)
+
+			for range 2 {
+				value, err := client.GetPostgresRow("user:1")
+				assert.NoError(t, err)
+				assert.Equal(t, "alice", value)
+			}
+			assert.NoError(t, client.UpdatePostgresRow("user:1", "bob"))
+			for range 2 {
+				value, err := client.GetPostgresRow("user:1")
+				assert.NoError(t, err)
+				assert.Equal(t, "bob", value)
+			}
+			assert.NoError(t, client.Flush())
+
+			stats := client.Stats()
+			assert.Equal(t, tt.expectedHits, stats.Hits)
+			assert.Equal(t, tt.expectedMisses, stats.Misses)
+			assert.Equal(t, 1, stats.Keys)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestClient_WriteBehind(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	client, err := NewClient(mockClient, Settings{Mode: WriteBehind})
+	assert.NoError(t, err)
+
+	mockClient.On("MultiGetPostgresRows", []string{"user:1"}).Return(map[string]string{}, nil).Times(1)
+	assert.NoError(t, client.CreatePostgresRow("user:1", "alice"))
+	assert.ErrorIs(t, client.CreatePostgresRow("user:1", "bob"), postgres.ErrConflict, "the pending write exists")
+	assert.EqualError(t, client.MultiSetPostgresRows(map[string]string{"": "empty"}), "require the key and value")
+
+	deleted, err := client.MultiDeletePostgresRows([]string{"user:1"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:1"}, deleted)
+	_, err = client.GetPostgresRow("user:1")
+	assert.ErrorIs(t, err, postgres.ErrNotFound, "the pending delete hides the key")
+	values, err := client.MultiGetPostgresRows([]string{"user:1"})
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+
+	// A failed flush is tried again
+	mockClient.On("MultiDeletePostgresRows", []string{"user:1"}).Return(nil, errors.New("connection refused")).Times(1)
+	assert.EqualError(t, client.Flush(), "failed to flush 1 writes: connection refused")
+	mockClient.On("MultiDeletePostgresRows", []string{"user:1"}).Return([]string{}, nil).Times(1)
+	assert.NoError(t, client.Flush())
+
+	// The operations the cache does not serve see the pending writes
+	assert.NoError(t, client.MultiSetPostgresRows(map[string]string{"user:2": "carol"}))
+	mockClient.On("MultiSetPostgresRows", map[string]string{"user:2": "carol"}).Return(nil).Times(1)
+	mockClient.On("KeysPostgresRows", "user:").Return([]string{"user:2"}, nil).Times(1)
+	keys, err := client.KeysPostgresRows("user:")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:2"}, keys)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestClient_Invalidate(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	client, err := NewClient(mockClient, Settings{Mode: WriteThrough})
+	assert.NoError(t, err)
+
+	listening := make(chan func(string))
+	mockClient.On("ListenPostgresChanges", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		changed := args.Get(1).(func(string))
+		changed("")
+		listening <- changed
+		<-args.Get(0).(context.Context).Done()
+	}).Return(nil).Times(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		client.Run(ctx)
+		close(stopped)
+	}()
+	changed := <-listening
+
+	mockClient.On("UpdatePostgresRow", "user:1", "alice").Return(nil).Times(1)
+	assert.NoError(t, client.UpdatePostgresRow("user:1", "alice"))
+	// The notification of its own write keeps the value cached
+	changed("user:1")
+	value, _ := client.GetPostgresRow("user:1")
+	assert.Equal(t, "alice", value)
+
+	// The write of another server drops it
+	changed("user:1")
+	mockClient.On("GetPostgresRow", "user:1").Return("bob", nil).Times(1)
+	value, _ = client.GetPostgresRow("user:1")
+	assert.Equal(t, "bob", value)
+
+	// Missed notifications drop every value
+	changed("")
+	mockClient.On("GetPostgresRow", "user:1").Return("carol", nil).Times(1)
+	value, _ = client.GetPostgresRow("user:1")
+	assert.Equal(t, "carol", value)
+
+	cancel()
+	<-stopped
+	mockClient.AssertExpectations(t)
+}
+
+func TestClient_Limits(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	client, err := NewClient(mockClient, Settings{Mode: WriteThrough, MaxKeys: 2})
+	assert.NoError(t, err)
+
+	mockClient.On("MultiSetPostgresRows", mock.Anything).Return(nil).Times(3)
+	for _, key := range []string{"a", "b", "c"} {
+		assert.NoError(t, client.MultiSetPostgresRows(map[string]string{key: "value"}))
+	}
+
+	stats := client.Stats()
+	assert.Equal(t, 2, stats.Keys)
+	assert.Equal(t, int64(1), stats.Evictions)
+
+	// The evicted value is read from the backend
+	mockClient.On("MultiGetPostgresRows", []string{"a"}).Return(map[string]string{"a": "value"}, nil).Times(1)
+	values, err := client.MultiGetPostgresRows([]string{"a", "b", "c"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "value", "b": "value", "c": "value"}, values)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestClient_Metadata(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	client, err := NewClient(mockClient, Settings{Mode: WriteThrough})
+	assert.NoError(t, err)
+
+	first := postgres.Metadata{Version: 1, Tags: []string{"a"}}
+	mockClient.On("StatPostgresRow", "user:1").Return(first, nil).Times(1)
+	for range 2 {
+		meta, err := client.StatPostgresRow("user:1")
+		assert.NoError(t, err)
+		assert.Equal(t, first, meta)
+	}
+
+	// Writes change the metadata
+	second := postgres.Metadata{Version: 2, Tags: []string{"a"}}
+	mockClient.On("UpdatePostgresRow", "user:1", "bob").Return(nil).Times(1)
+	mockClient.On("StatPostgresRow", "user:1").Return(second, nil).Times(1)
+	assert.NoError(t, client.UpdatePostgresRow("user:1", "bob"))
+	meta, _ := client.StatPostgresRow("user:1")
+	assert.Equal(t, second, meta)
+
+	third := postgres.Metadata{Version: 2, Tags: []string{"b"}}
+	mockClient.On("SetMetadataPostgresRow", "user:1", "", []string{"b"}).Return(nil).Times(1)
+	mockClient.On("StatPostgresRow", "user:1").Return(third, nil).Times(1)
+	assert.NoError(t, client.SetMetadataPostgresRow("user:1", "", []string{"b"}))
+	meta, _ = client.StatPostgresRow("user:1")
+	assert.Equal(t, third, meta)
+
+	mockClient.On("StatPostgresRow", "user:2").Return(postgres.Metadata{}, postgres.ErrNotFound)
+	_, err = client.StatPostgresRow("user:2")
+	assert.ErrorIs(t, err, postgres.ErrNotFound)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestClient_Flush(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	client, err := NewClient(mockClient, Settings{Mode: WriteBehind})
+	assert.NoError(t, err)
+
+	pairs := make(map[string]string)
+	for n := range 2*FlushBatch + 1 {
+		pairs[fmt.Sprint("key", n)] = "value"
+	}
+	pairs["bad"] = "refused"
+	assert.NoError(t, client.MultiSetPostgresRows(pairs))
+
+	// The batches with the refused write are halved until it is found
+	var written []string
+	refused := func(pairs map[string]string) bool { _, ok := pairs["bad"]; return ok }
+	mockClient.On("MultiSetPostgresRows", mock.MatchedBy(refused)).Return(errors.New("value too long"))
+	mockClient.On("MultiSetPostgresRows", mock.MatchedBy(func(pairs map[string]string) bool { return !refused(pairs) })).Run(func(args mock.Arguments) {
+		pairs := args.Get(0).(map[string]string)
+		assert.LessOrEqual(t, len(pairs), FlushBatch)
+		for key := range pairs {
+			written = append(written, key)
+		}
+	}).Return(nil)
+
+	assert.EqualError(t, client.Flush(), "failed to flush 1 writes: value too long")
+	assert.Len(t, written, 2*FlushBatch+1)
+	assert.Len(t, client.pending, 1, "the refused write stays pending")
+	value, err := client.GetPostgresRow("bad")
+	assert.NoError(t, err)
+	assert.Equal(t, "refused", value)
+}
+
+func TestClient_WriteBehindStat(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	client, err := NewClient(mockClient, Settings{Mode: WriteBehind})
+	assert.NoError(t, err)
+
+	// The metadata of a pending write is known without flushing it
+	assert.NoError(t, client.MultiSetPostgresRows(map[string]string{"new": "1", "old": "2"}))
+	mockClient.On("StatPostgresRow", "new").Return(postgres.Metadata{}, postgres.ErrNotFound).Times(1)
+	mockClient.On("StatPostgresRow", "old").Return(postgres.Metadata{Version: 3, ContentType: "text/plain"}, nil).Times(1)
+
+	meta, err := client.StatPostgresRow("new")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), meta.Version)
+	assert.False(t, meta.UpdatedAt.IsZero())
+	meta, err = client.StatPostgresRow("old")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), meta.Version)
+	assert.Equal(t, "text/plain", meta.ContentType)
+
+	_, err = client.MultiDeletePostgresRows([]string{"new"})
+	assert.NoError(t, err)
+	_, err = client.StatPostgresRow("new")
+	assert.ErrorIs(t, err, postgres.ErrNotFound)
+
+	mockClient.AssertExpectations(t)
+}
+
+func TestClient_Drain(t *testing.T) {
+	defer func(timeout time.Duration) { drainTimeout = timeout }(drainTimeout)
+	drainTimeout = 50 * time.Millisecond
+
+	mockClient := mocks.NewClient(t)
+	client, err := NewClient(mockClient, Settings{Mode: WriteBehind, FlushInterval: 10 * time.Millisecond})
+	assert.NoError(t, err)
+	assert.NoError(t, client.MultiSetPostgresRows(map[string]string{"user:1": "alice"}))
+
+	mockClient.On("ListenPostgresChanges", mock.Anything, mock.Anything).Return(errors.New("not supported by this backend")).Times(1)
+	mockClient.On("MultiSetPostgresRows", map[string]string{"user:1": "alice"}).Return(errors.New("connection refused"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.EqualError(t, client.Run(ctx), "1 writes were not flushed: failed to flush 1 writes: connection refused")
+	assert.Greater(t, len(mockClient.Calls), 2, "the flush is tried again")
+}
+
+func TestClient_SlowBackend(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	client, err := NewClient(mockClient, Settings{Mode: WriteThrough})
+	assert.NoError(t, err)
+	mockClient.On("MultiSetPostgresRows", map[string]string{"user:1": "alice"}).Return(nil).Times(1)
+	assert.NoError(t, client.MultiSetPostgresRows(map[string]string{"user:1": "alice"}))
+
+	// A write waiting for the backend does not hold up the hits
+	started, release := make(chan struct{}), make(chan struct{})
+	mockClient.On("UpdatePostgresRow", "user:2", "bob").Run(func(mock.Arguments) {
+		close(started)
+		<-release
+	}).Return(nil).Times(1)
+	done := make(chan error)
+	go func() { done <- client.UpdatePostgresRow("user:2", "bob") }()
+	<-started
+
+	value, err := client.GetPostgresRow("user:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "alice", value)
+	close(release)
+	assert.NoError(t, <-done)
+
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ListenPostgresChanges calls changed with the key of every row written to
+// the table, by this client or any other, until ctx is done. The keys are
+// announced by the trigger of the 0010_change_notifications migration once
+// the write commits. The empty key means notifications may have been
+// missed, so any key may have changed: it is sent once listening starts
+// and after the connection was lost. The listener connects again after
+// Reconnect, as its own reconnects would keep the old credentials.
+func (r *realClient) ListenPostgresChanges(ctx context.Context, changed func(key string)) error {
+	for {
+		// Taken before the config, so a Reconnect in between is not missed
+		rotated := r.rotated()
+		r.mu.RLock()
+		cfg := r.config
+		r.mu.RUnlock()
+
+		again, err := listenChanges(ctx, cfg, rotated, changed)
+		if err != nil || !again {
+			return err
+		}
+	}
+}
+
+// listenChanges listens with the credentials of cfg until ctx is done, or
+// until rotated is closed, when it returns true.
+func listenChanges(ctx context.Context, cfg Config, rotated <-chan struct{}, changed func(key string)) (bool, error) {
+	connStr, err := listenConnString(cfg)
+	if err != nil {
+		return false, err
+	}
+	listener := pq.NewListener(connStr, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			slog.Warn("change notifications interrupted", "table", cfg.tableName(), "error", err)
+		}
+	})
+	defer listener.Close()
+	// Listen waits for the connection, which ctx can give up on
+	stop := context.AfterFunc(ctx, func() { listener.Close() })
+	defer stop()
+	if err := listener.Listen(cfg.changesChannel()); err != nil {
+		if ctx.Err() != nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to listen for changes: %w", err)
+	}
+	changed("")
+
+	// The driver only notices a dead connection when it is used
+	ping := time.NewTicker(90 * time.Second)
+	defer ping.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false, nil
+		case <-rotated:
+			return true, nil
+		case notification, ok := <-listener.Notify:
+			if !ok {
+				return false, nil
+			}
+			if notification == nil {
+				// Sent after the listener reconnected
+				changed("")
+				continue
+			}
+			changed(notification.Extra)
+		case <-ping.C:
+			go listener.Ping()
+		}
+	}
+}
+
+// rotated returns a channel that Reconnect closes once the credentials
+// changed. The namespaces share the one of their root.
+func (r *realClient) rotated() <-chan struct{} {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	if root.credentials == nil {
+		root.credentials = make(chan struct{})
+	}
+	return root.credentials
+}
+
+// listenConnString returns the connection string of the first sslmode that
+// connects, like open, since the listener keeps its own connection.
+func listenConnString(cfg Config) (string, error) {
+	modes := cfg.sslModes()
+	var firstErr error
+	for _, mode := range modes {
+		connStr, err := cfg.connString(mode)
+		if err != nil {
+			return "", err
+		}
+		if len(modes) == 1 {
+			return connStr, nil
+		}
+
+		database, err := sql.Open("postgres", connStr)
+		if err != nil {
+			return "", fmt.Errorf("failed to connect to postgres: %w", err)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.connectTimeout())
+		err = database.PingContext(ctx)
+		cancel()
+		database.Close()
+		if err == nil {
+			return connStr, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return "", fmt.Errorf("failed to reach postgres: %w", firstErr)
+}
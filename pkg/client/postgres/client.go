@@ -1,8 +1,22 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
 )
 
 type Client interface {
@@ -11,49 +25,197 @@ type Client interface {
 	UpdatePostgresRow(key, value string) error
 	GetPostgresRow(key string) (string, error)
 	ShowPostgresRow() (map[string]string,error)
+	MultiGetPostgresRows(keys []string) (map[string]string, error)
+	MultiSetPostgresRows(pairs map[string]string) error
+	MultiDeletePostgresRows(keys []string) ([]string, error)
+	KeysPostgresRows(prefix string) ([]string, error)
+	CopyPostgresRows(pairs map[string]string) error
+	StatPostgresRow(key string) (Metadata, error)
+	SetMetadataPostgresRow(key, contentType string, tags []string) error
+	UndeletePostgresRow(key string) error
+	PurgeTombstonesPostgresRows(before time.Time) (int, error)
+	HistoryPostgresRows(key string, limit int) ([]Version, error)
+	GetAtPostgresRow(key string, at time.Time) (string, error)
+	ReadStreamPostgresRow(key string, w io.Writer) error
+	WriteStreamPostgresRow(key string, r io.Reader) error
+	IncrByPostgresRow(key string, delta int64) (int64, error)
+	IncrByFloatPostgresRow(key string, delta float64) (float64, error)
+	ZAddPostgresRow(key, member string, score float64) (bool, error)
+	ZIncrByPostgresRow(key, member string, delta float64) (float64, error)
+	ZScorePostgresRow(key, member string) (float64, error)
+	ZRankPostgresRow(key, member string) (int, error)
+	ZRemPostgresRows(key string, members []string) (int, error)
+	ZCardPostgresRows(key string) (int, error)
+	ZRangePostgresRows(key string, start, stop int) ([]ScoredMember, error)
+	ZRangeByScorePostgresRows(key string, min, max float64) ([]ScoredMember, error)
+	JSONGetPostgresRow(key, path string) (string, error)
+	JSONSetPostgresRow(key, path, value string) error
+	JSONMergePostgresRow(key, patch string) (string, error)
+	JSONPatchPostgresRow(key, patch string) (string, error)
+	CreateIndexPostgresRow(name, path string) error
+	DropIndexPostgresRow(name string) error
+	IndexesPostgresRows() ([]Index, error)
+	QueryPostgresRows(index, value string) ([]string, error)
+	Namespace(name string) (Client, error)
+	NamespacesPostgresRows() ([]Namespace, error)
+	SetNamespaceTTLPostgresRow(name string, ttl time.Duration) error
+	DropNamespacePostgresRow(name string) error
+	ExpirePostgresRows(before time.Time) (int, error)
+	ListenPostgresChanges(ctx context.Context, changed func(key string)) error
+	Reconnect(username, password string) error
 }
 
-// NewClient creates new HCloud clients.
-func NewClient(host,port, username, password, dbname string) (Client, error) {
-	// Build connection string
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host,port, username, password, dbname)
+// Metadata is kept for every row next to its value.
+type Metadata struct {
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	WrittenAt   time.Time
+	ContentType string
+	Tags        []string
+	Version     int64
+}
+
+// Version is one value a row had, see HistoryPostgresRows.
+type Version struct {
+	Version    int64
+	Value      string
+	WrittenAt  time.Time
+	ReplacedAt time.Time
+	Deleted    bool
+}
+
+// ScoredMember is a member of a sorted set with its score.
+type ScoredMember struct {
+	Member string
+	Score  float64
+}
+
+// Errors returned by the client when a key is missing or already taken, or
+// a counter cannot be incremented, so callers can tell them apart from
+// database failures with errors.Is.
+var (
+	ErrNotFound  = errors.New("key not found")
+	ErrConflict  = errors.New("key already exists")
+	ErrNotNumber = errors.New("value is not a number")
+	ErrOverflow  = errors.New("increment would overflow")
+	// ErrWrongType is returned when a key holding a sorted set is written
+	// as a string, or the other way around.
+	ErrWrongType = errors.New("WRONGTYPE operation against a key holding the wrong kind of value")
+)
 
-	database, err := sql.Open("postgres", connStr)
+// NewClient connects to Postgres and brings the schema of the table up to
+// date.
+func NewClient(cfg Config) (Client, error) {
+	database, err := open(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+		return nil, err
 	}
 
-	// Create a simple table if not exists
-	_, err = database.Exec(`CREATE TABLE IF NOT EXISTS kvstore (
-		key TEXT PRIMARY KEY,
-		value TEXT
-	)`)
+	migrator, err := newMigrator(database, cfg)
+	if err == nil {
+		_, err = migrator.Up(context.Background(), 0)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create key value: %w", err)
+		database.Close()
+		return nil, fmt.Errorf("failed to migrate the schema: %w", err)
 	}
 
-	fmt.Println("Connected to Postgres successfully.")
-	return &realClient{db: database}, nil
+	fmt.Fprintln(os.Stderr, "Connected to Postgres successfully.")
+	return &realClient{db: database, config: cfg, table: cfg.table(), history: cfg.historyTable(), zset: cfg.zsetTable()}, nil
 }
 
 type realClient struct {
+	mu sync.RWMutex
 	db *sql.DB
+
+	// Kept to open a new pool when the credentials change
+	config Config
+	// table is the quoted, schema qualified name of the table
+	table string
+	// history is the quoted name of the table of previous values, filled
+	// by a trigger on table
+	history string
+	// zset is the quoted name of the table of the sorted set members
+	zset string
+	// credentials is closed by Reconnect, so the listeners of changes
+	// connect again with the new credentials, see rotated
+	credentials chan struct{}
+
+	// namespaces caches the clients of the namespaces opened so far, which
+	// share the pool and have this client as their parent
+	namespaces map[string]*realClient
+	parent     *realClient
+	// name is the name of the namespace of a client with a parent
+	name string
+}
+
+// pool returns the current connection pool.
+func (r *realClient) pool() *sql.DB {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.db
+}
+
+// retireGrace is how long a replaced pool stays open, so callers that took
+// it from pool() just before the swap can still start their queries.
+var retireGrace = time.Minute
+
+// Reconnect opens a pool with new credentials and swaps it in once it
+// answers, so a rotated password takes effect without a restart. The old
+// pool is closed after retireGrace, and queries still running on it then
+// finish before it goes away. The listeners of changes connect again.
+func (r *realClient) Reconnect(username, password string) error {
+	r.mu.RLock()
+	cfg := r.config
+	r.mu.RUnlock()
+	cfg.User, cfg.Password = username, password
+
+	database, err := open(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect with the new credentials: %w", err)
+	}
+
+	r.mu.Lock()
+	old := r.db
+	r.db, r.config = database, cfg
+	for _, ns := range r.namespaces {
+		ns.mu.Lock()
+		ns.db = database
+		ns.config.User, ns.config.Password = username, password
+		ns.mu.Unlock()
+	}
+	if r.credentials != nil {
+		close(r.credentials)
+		r.credentials = nil
+	}
+	r.mu.Unlock()
+
+	time.AfterFunc(retireGrace, func() {
+		if err := old.Close(); err != nil {
+			slog.Warn("failed to close the replaced pool", "error", err)
+		}
+	})
+	return nil
 }
 
 func (r *realClient) CreatePostgresRow(key, val string) error {
 
 	// Check if key already exists
 	var existing string
-	err := r.db.QueryRow("SELECT key FROM kvstore WHERE key = $1", key).Scan(&existing)
+	err := r.pool().QueryRow("SELECT key FROM "+r.table+" WHERE key = $1 AND deleted_at IS NULL", key).Scan(&existing)
 	if err == nil {
-		return fmt.Errorf("key already exists. Use 'update' to change the value")
+		return fmt.Errorf("%w. Use 'update' to change the value", ErrConflict)
 	}
 
-	// Insert new key-value pair
-	_, err = r.db.Exec("INSERT INTO kvstore (key, value) VALUES ($1, $2)", key, val)
+	// Insert new key-value pair, over its tombstone if it has one
+	result, err := r.pool().Exec("INSERT INTO "+r.table+" AS kv (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET "+
+		revive+" WHERE kv.deleted_at IS NOT NULL", key, []byte(val))
 	if err != nil {
-		return fmt.Errorf("error inserting data: %w", err)
+		return typeError("error inserting data", err)
+	}
+	// Nothing inserted when another client created the key since the check
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return fmt.Errorf("%w. Use 'update' to change the value", ErrConflict)
 	}
 
 	return nil
@@ -61,23 +223,14 @@ func (r *realClient) CreatePostgresRow(key, val string) error {
 
 func (r *realClient) DeletePostgresRow(key string) error {
 
-	var existing string
-
-	err := r.db.QueryRow("SELECT key FROM kvstore WHERE key = $1", key).Scan(&existing)
-
-	if err == sql.ErrNoRows {
-		return fmt.Errorf("key does not exist")
-
-	} else if err != nil {
-		fmt.Println("Error is ", err)
-	} else {
-		// Delete new key-value pair
-		_, err = r.db.Exec("DELETE FROM kvstore WHERE key = $1", key)
-		if err != nil {
-			return fmt.Errorf("error deleting data: %w", err)
-		}
-		fmt.Println("Key deleted successfully.")
+	deleted, err := r.deleteRows("key = $1", key)
+	if err != nil {
+		return err
+	}
+	if len(deleted) == 0 {
+		return ErrNotFound
 	}
+	fmt.Println("Key deleted successfully.")
 	return nil
 }
 
@@ -85,11 +238,11 @@ func (r *realClient) UpdatePostgresRow(key, value string) error {
 
 	var existing string
 
-	err := r.db.QueryRow("SELECT key FROM kvstore WHERE key = $1", key).Scan(&existing)
+	err := r.pool().QueryRow("SELECT key FROM "+r.table+" WHERE key = $1 AND deleted_at IS NULL", key).Scan(&existing)
 
 	if err == sql.ErrNoRows {
 
-		return fmt.Errorf("key does not found")
+		return ErrNotFound
 	} else if err != nil {
 		fmt.Println("Error while checking: ", err)
 	} else {
@@ -99,10 +252,11 @@ func (r *realClient) UpdatePostgresRow(key, value string) error {
 		if value == "" {
 			return fmt.Errorf("value can not be empty")
 		} else {
-			_, err = r.db.Exec("UPDATE kvstore SET value = $1 WHERE key = $2", value, key)
+			_, err = r.pool().Exec("UPDATE "+r.table+" SET value = $1, updated_at = now() WHERE key = $2 AND deleted_at IS NULL", []byte(value), key)
 			if err != nil {
 				return fmt.Errorf("error updating data: %w", err)
 			}
+			r.pruneHistory([]string{key})
 			fmt.Println("Key value updated successfully.")
 
 		}
@@ -114,10 +268,10 @@ func (r *realClient) UpdatePostgresRow(key, value string) error {
 func (r *realClient) GetPostgresRow(key string) (string, error) {
 
 	var value string
-	err := r.db.QueryRow("SELECT value FROM kvstore WHERE key = $1", key).Scan(&value)
+	err := r.pool().QueryRow("SELECT value FROM "+r.table+" WHERE key = $1 AND deleted_at IS NULL", key).Scan(&value)
 
 	if err == sql.ErrNoRows {
-		return "", fmt.Errorf("key not found")
+		return "", ErrNotFound
 	} else if err != nil {
 		return "", fmt.Errorf("error while checking the key: %w", err)
 	}
@@ -130,7 +284,7 @@ func (r *realClient) ShowPostgresRow() (map[string]string, error) {
 
 	store := make(map[string]string)
 
-	rows, err := r.db.Query("SELECT key, value from kvstore")
+	rows, err := r.pool().Query("SELECT key, value from " + r.table + " WHERE deleted_at IS NULL")
 	if err != nil {
 		return nil, fmt.Errorf("error retrieving data %w", err)
 	}
@@ -157,6 +311,489 @@ func (r *realClient) ShowPostgresRow() (map[string]string, error) {
 	return store,nil
 }
 
+func (r *realClient) MultiGetPostgresRows(keys []string) (map[string]string, error) {
+
+	store := make(map[string]string, len(keys))
+
+	rows, err := r.pool().Query("SELECT key, value FROM "+r.table+" WHERE key = ANY($1) AND deleted_at IS NULL", pq.Array(keys))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving data: %w", err)
+	}
+	defer rows.Close()
+
+	var key, value string
+	for rows.Next() {
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("error while scanning the data: %w", err)
+		}
+		store[key] = value
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return store, nil
+}
+
+func (r *realClient) MultiSetPostgresRows(pairs map[string]string) error {
+
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	// One upsert of two arrays instead of a round trip per key, so the
+	// number of parameters does not grow with the batch
+	keys := make([]string, 0, len(pairs))
+	values := make([][]byte, 0, len(pairs))
+	for key, value := range pairs {
+		keys = append(keys, key)
+		// Values are BYTEA, which the driver only writes as is from []byte
+		values = append(values, []byte(value))
+	}
+
+	query := "INSERT INTO "+r.table+" AS kv (key, value) SELECT * FROM unnest($1::text[], $2::bytea[])" +
+		" ON CONFLICT (key) DO UPDATE SET " + revive
+
+	if _, err := r.pool().Exec(query, pq.Array(keys), pq.Array(values)); err != nil {
+		return typeError("error inserting data", err)
+	}
+	r.pruneHistory(slices.Collect(maps.Keys(pairs)))
+	return nil
+}
+
+func (r *realClient) MultiDeletePostgresRows(keys []string) ([]string, error) {
+	return r.deleteRows("key = ANY($1)", pq.Array(keys))
+}
+
+// deleteRows deletes the live rows matching condition, or tombstones them
+// in soft delete mode, and returns their keys. The sorted sets matching it,
+// whose updated_at is the one of their last written member, are deleted
+// with them. They have no tombstones, so they are gone in soft delete mode
+// too.
+func (r *realClient) deleteRows(condition string, args ...any) ([]string, error) {
+
+	rowsQuery := "DELETE FROM " + r.table + " WHERE " + condition + " AND deleted_at IS NULL RETURNING key"
+	if r.softDelete() {
+		rowsQuery = "UPDATE " + r.table + " SET deleted_at = now() WHERE " + condition + " AND deleted_at IS NULL RETURNING key"
+	}
+	query := `WITH sets AS (
+			SELECT key FROM (SELECT key, max(updated_at) AS updated_at FROM ` + r.zset + ` GROUP BY key) z WHERE ` + condition + `
+		), members AS (
+			DELETE FROM ` + r.zset + ` WHERE key IN (SELECT key FROM sets) RETURNING key
+		), deleted AS (` + rowsQuery + `)
+		SELECT key FROM deleted UNION SELECT key FROM members`
+	rows, err := r.pool().Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error deleting data: %w", err)
+	}
+	defer rows.Close()
+
+	deleted := []string{}
+	var key string
+	for rows.Next() {
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("error while scanning the data: %w", err)
+		}
+		deleted = append(deleted, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	r.pruneHistory(deleted)
+	return deleted, nil
+}
+
+func (r *realClient) KeysPostgresRows(prefix string) ([]string, error) {
+
+	// Escape LIKE wildcards so the prefix is matched literally
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+
+	rows, err := r.pool().Query(`SELECT key FROM `+r.table+` WHERE key LIKE $1 ESCAPE '\' AND deleted_at IS NULL
+		UNION SELECT key FROM `+r.zset+` WHERE key LIKE $1 ESCAPE '\' ORDER BY key`, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving keys: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	var key string
+	for rows.Next() {
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("error while scanning the data: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *realClient) CopyPostgresRows(pairs map[string]string) error {
+
+	tx, err := r.pool().Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// COPY cannot resolve conflicts, so load into a temporary table first and
+	// upsert from there in a single statement.
+	_, err = tx.Exec(`CREATE TEMP TABLE kvstore_import (key TEXT, value BYTEA) ON COMMIT DROP`)
+	if err != nil {
+		return fmt.Errorf("error creating import table: %w", err)
+	}
+
+	stmt, err := tx.Prepare(pq.CopyIn("kvstore_import", "key", "value"))
+	if err != nil {
+		return fmt.Errorf("error preparing copy: %w", err)
+	}
+
+	for key, value := range pairs {
+		if _, err := stmt.Exec(key, []byte(value)); err != nil {
+			stmt.Close()
+			return fmt.Errorf("error copying data: %w", err)
+		}
+	}
+
+	// An Exec without arguments flushes the buffered rows
+	if _, err := stmt.Exec(); err != nil {
+		stmt.Close()
+		return fmt.Errorf("error copying data: %w", err)
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("error finishing copy: %w", err)
+	}
+
+	_, err = tx.Exec(`INSERT INTO `+r.table+` AS kv (key, value) SELECT key, value FROM kvstore_import
+		ON CONFLICT (key) DO UPDATE SET `+revive)
+	if err != nil {
+		return typeError("error inserting data", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing import: %w", err)
+	}
+	r.pruneHistory(slices.Collect(maps.Keys(pairs)))
+	return nil
+}
+
+func (r *realClient) StatPostgresRow(key string) (Metadata, error) {
+
+	var meta Metadata
+	err := r.pool().QueryRow("SELECT created_at, updated_at, written_at, content_type, tags, version FROM "+r.table+" WHERE key = $1 AND deleted_at IS NULL", key).
+		Scan(&meta.CreatedAt, &meta.UpdatedAt, &meta.WrittenAt, &meta.ContentType, pq.Array(&meta.Tags), &meta.Version)
+	if err == sql.ErrNoRows {
+		return Metadata{}, ErrNotFound
+	} else if err != nil {
+		return Metadata{}, fmt.Errorf("error retrieving metadata: %w", err)
+	}
+
+	if len(meta.Tags) == 0 {
+		meta.Tags = nil
+	}
+	return meta, nil
+}
+
+func (r *realClient) SetMetadataPostgresRow(key, contentType string, tags []string) error {
+
+	if tags == nil {
+		tags = []string{}
+	}
+	result, err := r.pool().Exec("UPDATE "+r.table+" SET content_type = $1, tags = $2, updated_at = now() WHERE key = $3 AND deleted_at IS NULL",
+		contentType, pq.Array(tags), key)
+	if err != nil {
+		return fmt.Errorf("error updating metadata: %w", err)
+	}
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// UndeletePostgresRow restores a tombstoned row with the value, content
+// type and tags it had.
+func (r *realClient) UndeletePostgresRow(key string) error {
+
+	result, err := r.pool().Exec("UPDATE "+r.table+" SET deleted_at = NULL WHERE key = $1 AND deleted_at IS NOT NULL", key)
+	if err != nil {
+		return typeError("error restoring data", err)
+	}
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PurgeTombstonesPostgresRows deletes for good the rows tombstoned before
+// the given time.
+func (r *realClient) PurgeTombstonesPostgresRows(before time.Time) (int, error) {
+
+	result, err := r.pool().Exec("DELETE FROM "+r.table+" WHERE deleted_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("error purging tombstones: %w", err)
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error purging tombstones: %w", err)
+	}
+	return int(count), nil
+}
+
+// HistoryPostgresRows returns up to limit versions of key, newest first,
+// starting with the current row. A limit of 0 returns them all.
+func (r *realClient) HistoryPostgresRows(key string, limit int) ([]Version, error) {
+
+	rows, err := r.pool().Query(`SELECT version, COALESCE(value, ''), written_at, replaced_at, deleted FROM (
+		SELECT version, value, written_at, NULL::timestamptz AS replaced_at, false AS deleted FROM `+r.table+` WHERE key = $1 AND deleted_at IS NULL
+		UNION ALL
+		SELECT version, value, written_at, replaced_at, deleted FROM `+r.history+` WHERE key = $1 AND replaced_at >= $2
+	) versions ORDER BY version DESC LIMIT $3`, key, r.historyCutoff(), sql.NullInt64{Int64: int64(limit), Valid: limit > 0})
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving history: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []Version{}
+	for rows.Next() {
+		var version Version
+		var replacedAt sql.NullTime
+		if err := rows.Scan(&version.Version, &version.Value, &version.WrittenAt, &replacedAt, &version.Deleted); err != nil {
+			return nil, fmt.Errorf("error while scanning the data: %w", err)
+		}
+		version.ReplacedAt = replacedAt.Time
+		versions = append(versions, version)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	if len(versions) == 0 {
+		return nil, ErrNotFound
+	}
+	return versions, nil
+}
+
+// GetAtPostgresRow returns the value key had at the given time.
+func (r *realClient) GetAtPostgresRow(key string, at time.Time) (string, error) {
+
+	var value string
+	err := r.pool().QueryRow(`SELECT COALESCE(value, '') FROM (
+		SELECT value, version FROM `+r.table+` WHERE key = $1 AND written_at <= $2 AND deleted_at IS NULL
+		UNION ALL
+		SELECT value, version FROM `+r.history+` WHERE key = $1 AND written_at <= $2 AND replaced_at > $2 AND replaced_at >= $3
+	) versions ORDER BY version DESC LIMIT 1`, key, at, r.historyCutoff()).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("error retrieving data: %w", err)
+	}
+	return value, nil
+}
+
+// ReadStreamPostgresRow writes the value of key to w. A value larger than
+// the stream threshold is read in chunks of that size from one snapshot,
+// so it is never held in memory whole.
+func (r *realClient) ReadStreamPostgresRow(key string, w io.Writer) error {
+
+	tx, err := r.pool().BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var size int64
+	err = tx.QueryRow("SELECT COALESCE(octet_length(value), 0) FROM "+r.table+" WHERE key = $1 AND deleted_at IS NULL", key).Scan(&size)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("error retrieving data: %w", err)
+	}
+
+	chunk := int64(r.streamThreshold())
+	for offset := int64(0); offset < size; offset += chunk {
+		var data []byte
+		// substring counts from 1
+		err := tx.QueryRow("SELECT substring(value FROM $2 FOR $3) FROM "+r.table+" WHERE key = $1", key, offset+1, chunk).Scan(&data)
+		if err != nil {
+			return fmt.Errorf("error retrieving data: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("error writing value: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// WriteStreamPostgresRow stores everything read from reader as the value of
+// key. A value up to the stream threshold is a plain upsert. A larger one
+// is sent in chunks of that size to a temporary table and assembled there,
+// so the value is written once and has a single version in the history.
+func (r *realClient) WriteStreamPostgresRow(key string, reader io.Reader) error {
+
+	chunk := make([]byte, r.streamThreshold())
+	n, err := io.ReadFull(reader, chunk)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return r.MultiSetPostgresRows(map[string]string{key: string(chunk[:n])})
+	} else if err != nil {
+		return fmt.Errorf("error reading value: %w", err)
+	}
+
+	tx, err := r.pool().Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`CREATE TEMP TABLE kvstore_upload (seq INT, data BYTEA) ON COMMIT DROP`)
+	if err != nil {
+		return fmt.Errorf("error creating upload table: %w", err)
+	}
+
+	for seq := 0; n > 0; seq++ {
+		if _, err := tx.Exec(`INSERT INTO kvstore_upload (seq, data) VALUES ($1, $2)`, seq, chunk[:n]); err != nil {
+			return fmt.Errorf("error uploading data: %w", err)
+		}
+
+		n, err = io.ReadFull(reader, chunk)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return fmt.Errorf("error reading value: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`INSERT INTO `+r.table+` AS kv (key, value)
+		SELECT $1, string_agg(data, ''::bytea ORDER BY seq) FROM kvstore_upload
+		ON CONFLICT (key) DO UPDATE SET `+revive, key)
+	if err != nil {
+		return typeError("error inserting data", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing upload: %w", err)
+	}
+	r.pruneHistory([]string{key})
+	return nil
+}
+
+// IncrByPostgresRow adds delta to the integer value of key in a single
+// statement, so concurrent increments are serialized by the row lock. A
+// missing key, or a tombstone, starts at 0.
+func (r *realClient) IncrByPostgresRow(key string, delta int64) (int64, error) {
+
+	var result int64
+	err := r.increment(key, "bigint", strconv.FormatInt(delta, 10), &result)
+	return result, err
+}
+
+// IncrByFloatPostgresRow is IncrByPostgresRow for decimal values.
+func (r *realClient) IncrByFloatPostgresRow(key string, delta float64) (float64, error) {
+
+	if math.IsNaN(delta) || math.IsInf(delta, 0) {
+		return 0, fmt.Errorf("%w: the increment must be finite", ErrNotNumber)
+	}
+	var result float64
+	err := r.increment(key, "double precision", strconv.FormatFloat(delta, 'f', -1, 64), &result)
+	return result, err
+}
+
+// increment adds delta to the value of key read as numeric type and scans
+// the new value into result. The value is cast in SQL, so a value that is
+// not a number or a result out of range fail the statement.
+func (r *realClient) increment(key, numeric, delta string, result any) error {
+
+	err := r.pool().QueryRow(`INSERT INTO `+r.table+` AS kv (key, value) VALUES ($1, convert_to($2, 'UTF8'))
+		ON CONFLICT (key) DO UPDATE SET value = CASE WHEN kv.deleted_at IS NULL
+			THEN convert_to((convert_from(kv.value, 'UTF8')::`+numeric+` + $2::`+numeric+`)::text, 'UTF8')
+			ELSE EXCLUDED.value END, `+reviveMetadata+`
+		RETURNING convert_from(value, 'UTF8')::`+numeric, key, delta).Scan(result)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "22P02", "22021":
+			// invalid_text_representation, character_not_in_repertoire
+			return fmt.Errorf("%w: %s", ErrNotNumber, pqErr.Message)
+		case "22003":
+			// numeric_value_out_of_range
+			return ErrOverflow
+		}
+	}
+	if err != nil {
+		return typeError("error incrementing data", err)
+	}
+	r.pruneHistory([]string{key})
+	return nil
+}
+
+// revive is the update of an upsert. A tombstone written again starts over
+// as a new key, so it loses its creation time, content type and tags.
+const revive = `value = EXCLUDED.value, ` + reviveMetadata
+
+// reviveMetadata is revive without the value.
+const reviveMetadata = `updated_at = now(), deleted_at = NULL,
+	created_at = CASE WHEN kv.deleted_at IS NULL THEN kv.created_at ELSE now() END,
+	content_type = CASE WHEN kv.deleted_at IS NULL THEN kv.content_type ELSE '' END,
+	tags = CASE WHEN kv.deleted_at IS NULL THEN kv.tags ELSE '{}' END`
+
+// softDelete tells whether deletes keep the rows as tombstones.
+func (r *realClient) softDelete() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config.SoftDelete
+}
+
+// streamThreshold is the size above which values are streamed in chunks.
+func (r *realClient) streamThreshold() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.config.StreamThreshold > 0 {
+		return r.config.StreamThreshold
+	}
+	return DefaultStreamThreshold
+}
+
+// historyCutoff is the oldest replaced_at still retained. Reads filter on
+// it, as versions only get pruned on writes.
+func (r *realClient) historyCutoff() time.Time {
+	r.mu.RLock()
+	maxAge := r.config.HistoryMaxAge
+	r.mu.RUnlock()
+
+	if maxAge <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(-maxAge)
+}
+
+// pruneHistory drops the versions of keys beyond the retention, and every
+// version that is too old. The write already succeeded, so a failure is
+// only logged and retried on the next write.
+func (r *realClient) pruneHistory(keys []string) {
+	r.mu.RLock()
+	maxVersions := r.config.HistoryMaxVersions
+	r.mu.RUnlock()
+
+	if maxVersions > 0 && len(keys) > 0 {
+		_, err := r.pool().Exec(`DELETE FROM `+r.history+` WHERE id IN (
+			SELECT id FROM (
+				SELECT id, row_number() OVER (PARTITION BY key ORDER BY version DESC) AS n FROM `+r.history+` WHERE key = ANY($1)
+			) ranked WHERE n > $2)`, pq.Array(keys), maxVersions)
+		if err != nil {
+			slog.Warn("failed to prune the history", "error", err)
+		}
+	}
+	if cutoff := r.historyCutoff(); !cutoff.IsZero() {
+		if _, err := r.pool().Exec("DELETE FROM "+r.history+" WHERE replaced_at < $1", cutoff); err != nil {
+			slog.Warn("failed to prune the history", "error", err)
+		}
+	}
+}
+
 func (r *realClient) ExitPostgressRow() error {
 
 	return nil
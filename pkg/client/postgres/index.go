@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/imsumedhaa/In-memory-database/pkg/jsondoc"
+	"github.com/lib/pq"
+)
+
+// Index is a secondary index of the JSON values by the text at Path.
+type Index struct {
+	Name string
+	Path string
+}
+
+// Errors returned by the index methods for an index that is missing, or
+// taken with another path.
+var (
+	ErrIndexNotFound = errors.New("index not found")
+	ErrIndexExists   = errors.New("index already exists")
+)
+
+// CreateIndexPostgresRow records the index and builds it as an expression
+// index on the text at path, the one QueryPostgresRows filters on. Only the
+// live rows are indexed.
+func (r *realClient) CreateIndexPostgresRow(name, path string) error {
+
+	expression, err := r.indexExpression(path)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.pool().Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing string
+	err = tx.QueryRow(`INSERT INTO `+r.root().object("indexes")+` AS i (namespace, name, path) VALUES ($1, $2, $3)
+		ON CONFLICT (namespace, name) DO UPDATE SET path = i.path
+		RETURNING path`, r.name, name, path).Scan(&existing)
+	if err != nil {
+		return fmt.Errorf("error recording the index: %w", err)
+	}
+	if existing != path {
+		return fmt.Errorf("%w: %s is on %s", ErrIndexExists, name, existing)
+	}
+
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS ` + r.indexName(name, false) + ` ON ` + r.table + ` (` + expression + `)
+		WHERE deleted_at IS NULL`)
+	if err != nil {
+		return fmt.Errorf("error creating the index: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *realClient) DropIndexPostgresRow(name string) error {
+
+	tx, err := r.pool().Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM `+r.root().object("indexes")+` WHERE namespace = $1 AND name = $2`, r.name, name)
+	if err != nil {
+		return fmt.Errorf("error dropping the index: %w", err)
+	}
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return fmt.Errorf("%w: %s", ErrIndexNotFound, name)
+	}
+	if _, err := tx.Exec(`DROP INDEX IF EXISTS ` + r.indexName(name, true)); err != nil {
+		return fmt.Errorf("error dropping the index: %w", err)
+	}
+	return tx.Commit()
+}
+
+func (r *realClient) IndexesPostgresRows() ([]Index, error) {
+
+	rows, err := r.pool().Query(`SELECT name, path FROM `+r.root().object("indexes")+` WHERE namespace = $1 ORDER BY name`, r.name)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the indexes: %w", err)
+	}
+	defer rows.Close()
+
+	indexes := []Index{}
+	for rows.Next() {
+		var index Index
+		if err := rows.Scan(&index.Name, &index.Path); err != nil {
+			return nil, fmt.Errorf("error while scanning the data: %w", err)
+		}
+		indexes = append(indexes, index)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return indexes, nil
+}
+
+// QueryPostgresRows returns the keys whose text at the path of index is
+// value. The path is written into the query as a constant, so the filter
+// is the expression of the index and the planner can use it.
+func (r *realClient) QueryPostgresRows(index, value string) ([]string, error) {
+
+	var path string
+	err := r.pool().QueryRow(`SELECT path FROM `+r.root().object("indexes")+` WHERE namespace = $1 AND name = $2`, r.name, index).Scan(&path)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrIndexNotFound, index)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the index: %w", err)
+	}
+	expression, err := r.indexExpression(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool().Query(`SELECT key FROM `+r.table+` WHERE `+expression+` = $1 AND deleted_at IS NULL
+		ORDER BY key`, value)
+	if err != nil {
+		return nil, fmt.Errorf("error querying the index: %w", err)
+	}
+	defer rows.Close()
+
+	keys := []string{}
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, fmt.Errorf("error while scanning the data: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return keys, nil
+}
+
+// indexName is the quoted name of the index called name, with the schema
+// when qualified, see Config.indexName.
+func (r *realClient) indexName(name string, qualified bool) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	index := pq.QuoteIdentifier(r.config.indexName(name))
+	if qualified && r.config.Schema != "" {
+		index = pq.QuoteIdentifier(r.config.Schema) + "." + index
+	}
+	return index
+}
+
+// indexName is the bare name of the index called name, <table>_idx_<name>
+// unless it has to be hashed, see ident.
+func (c Config) indexName(name string) string {
+	return c.ident("idx_" + name)
+}
+
+// indexExpression is the indexed expression of a path: the json_text of the
+// 0008_json_indexes migration with the steps of the path as a constant.
+func (r *realClient) indexExpression(path string) (string, error) {
+	steps, err := jsondoc.ParsePath(path)
+	if err != nil {
+		return "", err
+	}
+	literal, err := pq.StringArray(steps).Value()
+	if err != nil {
+		return "", err
+	}
+	return r.root().object("json_text") + `(value, ` + pq.QuoteLiteral(literal.(string)) + `::text[])`, nil
+}
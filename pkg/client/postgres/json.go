@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/imsumedhaa/In-memory-database/pkg/jsondoc"
+	"github.com/lib/pq"
+)
+
+// Errors returned by the JSON methods when a value or a patch is not JSON,
+// or a JSON patch does not apply. They are the ones of jsondoc, so the
+// backends patching in Go return the same.
+var (
+	ErrNotJSON     = jsondoc.ErrNotJSON
+	ErrPatchFailed = jsondoc.ErrPatchFailed
+)
+
+// JSONGetPostgresRow returns the JSON at path in the value of key.
+func (r *realClient) JSONGetPostgresRow(key, path string) (string, error) {
+
+	steps, err := jsondoc.ParsePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	var value sql.NullString
+	err = r.pool().QueryRow(`SELECT (convert_from(value, 'UTF8')::jsonb #> $2)::text FROM `+r.table+`
+		WHERE key = $1 AND deleted_at IS NULL`, key, pq.Array(steps)).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", jsonError("error retrieving data", err)
+	}
+	if !value.Valid {
+		return "", fmt.Errorf("%w: JSON path not found: %s", ErrNotFound, path)
+	}
+	return value.String, nil
+}
+
+// JSONSetPostgresRow replaces or adds the JSON at path in the value of key.
+// The path $ stores value as the whole document, creating the key.
+func (r *realClient) JSONSetPostgresRow(key, path, value string) error {
+
+	steps, err := jsondoc.ParsePath(path)
+	if err != nil {
+		return err
+	}
+	if err := jsondoc.Valid(value); err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return r.MultiSetPostgresRows(map[string]string{key: value})
+	}
+
+	result, err := r.pool().Exec(`UPDATE `+r.table+`
+		SET value = convert_to(`+r.root().object("json_set")+`(convert_from(value, 'UTF8')::jsonb, $2, $3::jsonb)::text, 'UTF8')
+		WHERE key = $1 AND deleted_at IS NULL`, key, pq.Array(steps), value)
+	if err != nil {
+		return jsonError("error updating data", err)
+	}
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return ErrNotFound
+	}
+	r.pruneHistory([]string{key})
+	return nil
+}
+
+// JSONMergePostgresRow applies an RFC 7396 merge patch to the value of key,
+// a missing key being merged into nothing, and returns the new document.
+func (r *realClient) JSONMergePostgresRow(key, patch string) (string, error) {
+
+	if err := jsondoc.Valid(patch); err != nil {
+		return "", err
+	}
+
+	var document string
+	err := r.pool().QueryRow(`INSERT INTO `+r.table+` AS kv (key, value) VALUES ($1, convert_to(`+r.root().object("merge_patch")+`(NULL, $2::jsonb)::text, 'UTF8'))
+		ON CONFLICT (key) DO UPDATE SET value = convert_to(`+r.root().object("merge_patch")+`(
+			CASE WHEN kv.deleted_at IS NULL THEN convert_from(kv.value, 'UTF8')::jsonb END, $2::jsonb)::text, 'UTF8'),
+			`+reviveMetadata+`
+		RETURNING convert_from(value, 'UTF8')`, key, patch).Scan(&document)
+	if err != nil {
+		return "", jsonError("error merging data", err)
+	}
+	r.pruneHistory([]string{key})
+	return document, nil
+}
+
+// JSONPatchPostgresRow applies an RFC 6902 JSON patch to the value of key
+// and returns the new document.
+func (r *realClient) JSONPatchPostgresRow(key, patch string) (string, error) {
+
+	if err := jsondoc.Valid(patch); err != nil {
+		return "", err
+	}
+
+	var document string
+	err := r.pool().QueryRow(`UPDATE `+r.table+`
+		SET value = convert_to(`+r.root().object("json_patch")+`(convert_from(value, 'UTF8')::jsonb, $2::jsonb)::text, 'UTF8')
+		WHERE key = $1 AND deleted_at IS NULL
+		RETURNING convert_from(value, 'UTF8')`, key, patch).Scan(&document)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", jsonError("error patching data", err)
+	}
+	r.pruneHistory([]string{key})
+	return document, nil
+}
+
+// object returns the qualified name of a table or function created by the
+// migrations for the table, like {{.Name}} does. The namespaces share the
+// functions and the records of their root, see root.
+func (r *realClient) object(suffix string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return templateData{config: r.config}.Name(suffix)
+}
+
+// root returns the client of the main table, or r for the main table.
+func (r *realClient) root() *realClient {
+	if r.parent != nil {
+		return r.parent
+	}
+	return r
+}
+
+// jsonError converts the errors raised by the casts to JSONB and the
+// functions of the 0007_json_documents migration.
+func jsonError(action string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "22P02", "22021", "22032":
+			// invalid_text_representation, character_not_in_repertoire,
+			// invalid_json_text
+			return fmt.Errorf("%w: %s", ErrNotJSON, pqErr.Message)
+		case "22000":
+			// data_exception, raised by json_patch
+			return fmt.Errorf("%w: %s", ErrPatchFailed, pqErr.Message)
+		case "P0002":
+			// no_data_found, raised by json_set
+			return fmt.Errorf("%w: %s", ErrNotFound, pqErr.Message)
+		}
+	}
+	return typeError(action, err)
+}
@@ -0,0 +1,386 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is one step of the schema, read from a pair of
+// NNNN_name.up.sql and NNNN_name.down.sql files. The SQL is a template
+// where {{.Table}} is the quoted, schema qualified name of the table, so
+// the same migrations work for any configured table.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus tells whether a migration was applied, and when.
+// AppliedAt is zero for a pending migration.
+type MigrationStatus struct {
+	Migration
+	AppliedAt time.Time
+}
+
+func (s MigrationStatus) Applied() bool {
+	return !s.AppliedAt.IsZero()
+}
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// loadMigrations reads the migrations in dir, ordered by version. Every
+// version needs both an up and a down file.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q, expected NNNN_name.up.sql or NNNN_name.down.sql", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		if version == 0 {
+			return nil, fmt.Errorf("invalid migration %q, versions start at 1", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %q and %q", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+	return migrations, nil
+}
+
+// Migrator applies the embedded migrations to the configured table and
+// records them in a schema_migrations table next to it. It holds an
+// advisory lock while it works, so replicas starting at the same time
+// migrate one after the other.
+type Migrator struct {
+	db         *sql.DB
+	config     Config
+	migrations []Migration
+}
+
+// NewMigrator connects to Postgres to manage the schema of the table.
+func NewMigrator(cfg Config) (*Migrator, error) {
+	database, err := open(cfg)
+	if err != nil {
+		return nil, err
+	}
+	migrator, err := newMigrator(database, cfg)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+	return migrator, nil
+}
+
+func newMigrator(database *sql.DB, cfg Config) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: database, config: cfg, migrations: migrations}, nil
+}
+
+func (m *Migrator) Close() error {
+	return m.db.Close()
+}
+
+// Up applies the pending migrations up to version, or all of them when
+// version is 0, and returns the ones it applied.
+func (m *Migrator) Up(ctx context.Context, version int) ([]Migration, error) {
+	if version < 0 || version > m.latest() {
+		return nil, fmt.Errorf("unknown migration version %d, the latest is %d", version, m.latest())
+	}
+	if version == 0 {
+		version = m.latest()
+	}
+
+	applied := []Migration{}
+	err := m.locked(ctx, func(conn *sql.Conn, done map[int]time.Time) error {
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			err := m.apply(ctx, conn, migration, migration.Up,
+				"INSERT INTO "+m.config.migrationsTable()+" (table_name, version, name) VALUES ($1, $2, $3)",
+				m.config.tableName(), migration.Version, migration.Name)
+			if err != nil {
+				return err
+			}
+			slog.Info("applied migration", "version", migration.Version, "name", migration.Name)
+			applied = append(applied, migration)
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations and returns the ones it
+// reverted, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1, got %d", steps)
+	}
+
+	reverted := []Migration{}
+	err := m.locked(ctx, func(conn *sql.Conn, done map[int]time.Time) error {
+		versions := slices.Sorted(maps.Keys(done))
+		slices.Reverse(versions)
+
+		for _, version := range versions[:min(steps, len(versions))] {
+			index := slices.IndexFunc(m.migrations, func(migration Migration) bool { return migration.Version == version })
+			if index < 0 {
+				return fmt.Errorf("migration %d was applied by a newer release and cannot be reverted by this one", version)
+			}
+			migration := m.migrations[index]
+			err := m.apply(ctx, conn, migration, migration.Down,
+				"DELETE FROM "+m.config.migrationsTable()+" WHERE table_name = $1 AND version = $2",
+				m.config.tableName(), migration.Version)
+			if err != nil {
+				return err
+			}
+			slog.Info("reverted migration", "version", migration.Version, "name", migration.Name)
+			reverted = append(reverted, migration)
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status lists every known migration with the time it was applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	err := m.locked(ctx, func(conn *sql.Conn, done map[int]time.Time) error {
+		for _, migration := range m.migrations {
+			statuses = append(statuses, MigrationStatus{Migration: migration, AppliedAt: done[migration.Version]})
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+func (m *Migrator) latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// locked runs fn on a single connection holding the advisory lock of the
+// table, with the versions applied so far. Session locks belong to a
+// connection, so everything runs on that one.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, done map[int]time.Time) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get a connection: %w", err)
+	}
+	defer conn.Close()
+
+	lock := m.config.lockID()
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lock); err != nil {
+		return fmt.Errorf("failed to take the migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lock)
+
+	if m.config.Schema != "" {
+		if _, err := conn.ExecContext(ctx, "CREATE SCHEMA IF NOT EXISTS "+pq.QuoteIdentifier(m.config.Schema)); err != nil {
+			return fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+	_, err = conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+m.config.migrationsTable()+` (
+		table_name TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+		PRIMARY KEY (table_name, version)
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create the schema_migrations table: %w", err)
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM "+m.config.migrationsTable()+" WHERE table_name = $1", m.config.tableName())
+	if err != nil {
+		return fmt.Errorf("failed to read the applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	done := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return fmt.Errorf("error while scanning the migrations: %w", err)
+		}
+		done[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating over migrations: %w", err)
+	}
+	rows.Close()
+
+	return fn(conn, done)
+}
+
+// apply runs the SQL of a migration and records it in one transaction, so
+// a failed migration leaves nothing behind.
+func (m *Migrator) apply(ctx context.Context, conn *sql.Conn, migration Migration, script, record string, args ...any) error {
+	query, err := m.config.render(script)
+	if err != nil {
+		return fmt.Errorf("invalid migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start a transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return fmt.Errorf("failed to record migration %d_%s: %w", migration.Version, migration.Name, err)
+	}
+	return tx.Commit()
+}
+
+// render fills in the table name of a migration.
+func (c Config) render(script string) (string, error) {
+	tmpl, err := template.New("migration").Option("missingkey=error").Parse(script)
+	if err != nil {
+		return "", err
+	}
+	var query strings.Builder
+	if err := tmpl.Execute(&query, templateData{Table: c.table(), config: c}); err != nil {
+		return "", err
+	}
+	return query.String(), nil
+}
+
+// templateData is what the migrations see. Objects that belong to the table
+// are named after it, so two stores in one schema don't share them:
+// {{.Name "history"}} is the qualified name of kvstore_history, and
+// {{.Ident "history_idx"}} the bare name, for indexes and triggers.
+type templateData struct {
+	Table  string
+	config Config
+}
+
+func (d templateData) Name(suffix string) string {
+	if d.config.Schema == "" {
+		return d.Ident(suffix)
+	}
+	return pq.QuoteIdentifier(d.config.Schema) + "." + d.Ident(suffix)
+}
+
+func (d templateData) Ident(suffix string) string {
+	return pq.QuoteIdentifier(d.config.ident(suffix))
+}
+
+// Regclass is the OID of an object of the table, as a regclass literal for
+// the catalog queries.
+func (d templateData) Regclass(suffix string) string {
+	return pq.QuoteLiteral(d.Name(suffix)) + "::regclass"
+}
+
+// Channel is the quoted channel of the change notifications of the table,
+// see Config.changesChannel.
+func (d templateData) Channel() string {
+	return pq.QuoteLiteral(d.config.changesChannel())
+}
+
+// migrationsTable is the quoted name of the schema_migrations table, which
+// lives in the schema of the table.
+func (c Config) migrationsTable() string {
+	if c.Schema == "" {
+		return "schema_migrations"
+	}
+	return pq.QuoteIdentifier(c.Schema) + ".schema_migrations"
+}
+
+// changesChannel is the channel the writes of the table are announced on:
+// <schema>.<table>_changes, or a hash of it when that is longer than the
+// 63 bytes Postgres allows.
+func (c Config) changesChannel() string {
+	channel := c.tableName() + "_changes"
+	if c.Schema != "" {
+		channel = c.Schema + "." + channel
+	}
+	if len(channel) > 63 {
+		hash := fnv.New64a()
+		hash.Write([]byte(channel))
+		channel = fmt.Sprintf("kvstore_changes_%016x", hash.Sum64())
+	}
+	return channel
+}
+
+// ident is the bare name of an object of the table: <table>_<suffix>, or a
+// hash of it when that is longer than the 63 bytes Postgres allows, as
+// Postgres would cut it short and let two objects share the name.
+func (c Config) ident(suffix string) string {
+	name := c.tableName() + "_" + suffix
+	if len(name) > 63 {
+		hash := fnv.New64a()
+		hash.Write([]byte(name))
+		name = fmt.Sprintf("kvstore_%016x", hash.Sum64())
+	}
+	return name
+}
+
+// lockID is the advisory lock key of the table, so stores in different
+// tables don't wait for each other.
+func (c Config) lockID() int64 {
+	hash := fnv.New64a()
+	hash.Write([]byte("kvstore migrations " + c.table()))
+	return int64(hash.Sum64())
+}
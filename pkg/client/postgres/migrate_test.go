@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         fstest.MapFS
+		expected      []Migration
+		expectedError string
+	}{
+		{
+			name: "Ordered by version",
+			files: fstest.MapFS{
+				"m/0010_add_index.up.sql":    {Data: []byte("CREATE INDEX")},
+				"m/0010_add_index.down.sql":  {Data: []byte("DROP INDEX")},
+				"m/0002_add_column.up.sql":   {Data: []byte("ALTER TABLE ADD")},
+				"m/0002_add_column.down.sql": {Data: []byte("ALTER TABLE DROP")},
+			},
+			expected: []Migration{
+				{Version: 2, Name: "add_column", Up: "ALTER TABLE ADD", Down: "ALTER TABLE DROP"},
+				{Version: 10, Name: "add_index", Up: "CREATE INDEX", Down: "DROP INDEX"},
+			},
+		},
+		{
+			name: "Missing down",
+			files: fstest.MapFS{
+				"m/0001_create.up.sql": {Data: []byte("CREATE TABLE")},
+			},
+			expectedError: "migration 1_create needs both an up and a down file",
+		},
+		{
+			name: "Different names",
+			files: fstest.MapFS{
+				"m/0001_create.up.sql": {Data: []byte("CREATE TABLE")},
+				"m/0001_make.down.sql": {Data: []byte("DROP TABLE")},
+			},
+			expectedError: `migration 1 is named both "create" and "make"`,
+		},
+		{
+			name: "Invalid file name",
+			files: fstest.MapFS{
+				"m/create.sql": {Data: []byte("CREATE TABLE")},
+			},
+			expectedError: `invalid migration file name "create.sql"`,
+		},
+		{
+			name: "Version zero",
+			files: fstest.MapFS{
+				"m/0000_create.up.sql": {Data: []byte("CREATE TABLE")},
+			},
+			expectedError: "versions start at 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.files, "m")
+
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, migrations)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+
+	assert.NoError(t, err)
+	for i, migration := range migrations {
+		assert.Equal(t, i+1, migration.Version, "versions have no gaps")
+
+		for _, script := range []string{migration.Up, migration.Down} {
+			_, err := Config{}.render(script)
+			assert.NoError(t, err, "%d_%s", migration.Version, migration.Name)
+
+			// The objects of a table with the longest name still fit
+			query, err := Config{Table: strings.Repeat("t", 63)}.render(script)
+			assert.NoError(t, err)
+			for _, ident := range quotedIdent.FindAllStringSubmatch(query, -1) {
+				assert.LessOrEqual(t, len(ident[1]), 63, "%d_%s: %s", migration.Version, migration.Name, ident[1])
+			}
+		}
+	}
+}
+
+var quotedIdent = regexp.MustCompile(`"([^"]+)"`)
+
+func TestConfig_Render(t *testing.T) {
+	query, err := Config{Schema: "billing", Table: "sessions"}.render("DROP TABLE {{.Table}}")
+	assert.NoError(t, err)
+	assert.Equal(t, `DROP TABLE "billing"."sessions"`, query)
+
+	_, err = Config{}.render("DROP TABLE {{.Name}}")
+	assert.Error(t, err)
+
+	query, err = Config{Schema: "billing", Table: "sessions"}.render(`CREATE INDEX {{.Ident "history_idx"}} ON {{.Name "history"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `CREATE INDEX "sessions_history_idx" ON "billing"."sessions_history"`, query)
+
+	long := Config{Table: strings.Repeat("t", 50)}
+	query, err = long.render(`CREATE INDEX {{.Ident "history_replaced_at_idx"}} ON {{.Name "history"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `CREATE INDEX "`+long.ident("history_replaced_at_idx")+`" ON "`+strings.Repeat("t", 50)+`_history"`, query)
+	assert.Regexp(t, `^kvstore_[0-9a-f]{16}$`, long.ident("history_replaced_at_idx"), "names longer than 63 bytes are hashed")
+	assert.NotEqual(t, long.ident("history_replaced_at_idx"), long.ident("history_replaced_at_idy"))
+
+	query, err = Config{}.render(`DROP TABLE {{.Name "history"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `DROP TABLE "kvstore_history"`, query)
+
+	query, err = Config{Schema: "billing"}.render(`SELECT {{.Regclass "indexes"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `SELECT '"billing"."kvstore_indexes"'::regclass`, query)
+}
+
+func TestConfig_RenderNamespace(t *testing.T) {
+	cfg := Config{Schema: "billing", Table: "kvstore_ns_invoices"}
+	for _, script := range []string{createNamespaceScript, dropNamespaceScript} {
+		query, err := cfg.render(script)
+		assert.NoError(t, err)
+		assert.Contains(t, query, `"billing"."kvstore_ns_invoices_history"`)
+		assert.Contains(t, query, `"billing"."kvstore_ns_invoices_zset"`)
+	}
+
+	assert.Equal(t, cfg, Config{Schema: "billing"}.namespace("invoices"))
+
+	// Namespaces of a long table sharing a long prefix keep tables of their own
+	long := Config{Table: strings.Repeat("t", 40)}
+	first, second := long.namespace(strings.Repeat("n", 31)+"a"), long.namespace(strings.Repeat("n", 31)+"b")
+	assert.NotEqual(t, first.Table, second.Table)
+	assert.NotEqual(t, first.ident("history"), second.ident("history"))
+	for _, ns := range []Config{first, second} {
+		query, err := ns.render(createNamespaceScript)
+		assert.NoError(t, err)
+		for _, ident := range quotedIdent.FindAllStringSubmatch(query, -1) {
+			assert.LessOrEqual(t, len(ident[1]), 63, ident[1])
+		}
+	}
+}
+
+func TestConfig_Migrations(t *testing.T) {
+	assert.Equal(t, "schema_migrations", Config{}.migrationsTable())
+	assert.Equal(t, `"billing".schema_migrations`, Config{Schema: "billing"}.migrationsTable())
+
+	assert.Equal(t, "kvstore_changes", Config{}.changesChannel())
+	assert.Equal(t, "billing.sessions_changes", Config{Schema: "billing", Table: "sessions"}.changesChannel())
+	assert.Len(t, Config{Schema: strings.Repeat("s", 40), Table: strings.Repeat("t", 40)}.changesChannel(), 32, "channels are at most 63 bytes")
+
+	assert.Equal(t, Config{}.lockID(), Config{Table: "kvstore"}.lockID())
+	assert.NotEqual(t, Config{}.lockID(), Config{Table: "sessions"}.lockID())
+	assert.NotEqual(t, Config{}.lockID(), Config{Schema: "billing"}.lockID())
+}
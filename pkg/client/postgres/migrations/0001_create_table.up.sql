@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+	key TEXT PRIMARY KEY,
+	value TEXT
+);
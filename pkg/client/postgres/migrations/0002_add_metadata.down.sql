@@ -0,0 +1,5 @@
+ALTER TABLE {{.Table}}
+	DROP COLUMN IF EXISTS created_at,
+	DROP COLUMN IF EXISTS updated_at,
+	DROP COLUMN IF EXISTS content_type,
+	DROP COLUMN IF EXISTS tags;
@@ -0,0 +1,5 @@
+ALTER TABLE {{.Table}}
+	ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	ADD COLUMN IF NOT EXISTS content_type TEXT NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
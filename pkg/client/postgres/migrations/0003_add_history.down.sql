@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS {{.Ident "history"}} ON {{.Table}};
+DROP FUNCTION IF EXISTS {{.Name "record_history"}}();
+DROP TABLE IF EXISTS {{.Name "history"}};
+ALTER TABLE {{.Table}}
+	DROP COLUMN IF EXISTS version;
@@ -0,0 +1,39 @@
+ALTER TABLE {{.Table}}
+	ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS {{.Name "history"}} (
+	id BIGSERIAL PRIMARY KEY,
+	key TEXT NOT NULL,
+	version BIGINT NOT NULL,
+	value TEXT,
+	written_at TIMESTAMPTZ NOT NULL,
+	replaced_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	deleted BOOLEAN NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS {{.Ident "history_key_idx"}} ON {{.Name "history"}} (key, version DESC);
+CREATE INDEX IF NOT EXISTS {{.Ident "history_replaced_at_idx"}} ON {{.Name "history"}} (replaced_at);
+
+-- Every write of a value keeps the one it replaces, so no write path of the
+-- client can forget to. A key created again continues its version numbers.
+CREATE OR REPLACE FUNCTION {{.Name "record_history"}}() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		NEW.version := COALESCE((SELECT max(version) FROM {{.Name "history"}} WHERE key = NEW.key), 0) + 1;
+		RETURN NEW;
+	END IF;
+
+	INSERT INTO {{.Name "history"}} (key, version, value, written_at, deleted)
+		VALUES (OLD.key, OLD.version, OLD.value, OLD.updated_at, TG_OP = 'DELETE');
+	IF TG_OP = 'DELETE' THEN
+		RETURN OLD;
+	END IF;
+
+	NEW.version := OLD.version + 1;
+	NEW.updated_at := now();
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER {{.Ident "history"}}
+	BEFORE INSERT OR UPDATE OF value OR DELETE ON {{.Table}}
+	FOR EACH ROW EXECUTE FUNCTION {{.Name "record_history"}}();
@@ -0,0 +1,30 @@
+-- Tombstones cannot be represented any more, so they are purged
+DELETE FROM {{.Table}} WHERE deleted_at IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION {{.Name "record_history"}}() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		NEW.version := COALESCE((SELECT max(version) FROM {{.Name "history"}} WHERE key = NEW.key), 0) + 1;
+		RETURN NEW;
+	END IF;
+
+	INSERT INTO {{.Name "history"}} (key, version, value, written_at, deleted)
+		VALUES (OLD.key, OLD.version, OLD.value, OLD.updated_at, TG_OP = 'DELETE');
+	IF TG_OP = 'DELETE' THEN
+		RETURN OLD;
+	END IF;
+
+	NEW.version := OLD.version + 1;
+	NEW.updated_at := now();
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS {{.Ident "history"}} ON {{.Table}};
+CREATE TRIGGER {{.Ident "history"}}
+	BEFORE INSERT OR UPDATE OF value OR DELETE ON {{.Table}}
+	FOR EACH ROW EXECUTE FUNCTION {{.Name "record_history"}}();
+
+DROP INDEX IF EXISTS {{.Name "deleted_at_idx"}};
+ALTER TABLE {{.Table}}
+	DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,40 @@
+ALTER TABLE {{.Table}}
+	ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS {{.Ident "deleted_at_idx"}} ON {{.Table}} (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- A soft delete sets deleted_at and counts as the delete in the history.
+-- Purging the tombstone later records nothing more, and an undelete is a
+-- new version of the value it had.
+CREATE OR REPLACE FUNCTION {{.Name "record_history"}}() RETURNS trigger AS $$
+DECLARE
+	deleted BOOLEAN := TG_OP = 'DELETE';
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		NEW.version := COALESCE((SELECT max(version) FROM {{.Name "history"}} WHERE key = NEW.key), 0) + 1;
+		RETURN NEW;
+	END IF;
+
+	IF NOT deleted THEN
+		deleted := NEW.deleted_at IS NOT NULL;
+	END IF;
+	IF OLD.deleted_at IS NULL THEN
+		INSERT INTO {{.Name "history"}} (key, version, value, written_at, deleted)
+			VALUES (OLD.key, OLD.version, OLD.value, OLD.updated_at, deleted);
+	END IF;
+	IF TG_OP = 'DELETE' THEN
+		RETURN OLD;
+	END IF;
+
+	IF NEW.deleted_at IS NULL THEN
+		NEW.version := OLD.version + 1;
+		NEW.updated_at := now();
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS {{.Ident "history"}} ON {{.Table}};
+CREATE TRIGGER {{.Ident "history"}}
+	BEFORE INSERT OR UPDATE OF value, deleted_at OR DELETE ON {{.Table}}
+	FOR EACH ROW EXECUTE FUNCTION {{.Name "record_history"}}();
@@ -0,0 +1,8 @@
+ALTER TABLE {{.Table}}
+	ALTER COLUMN value SET STORAGE EXTENDED;
+
+-- Fails on values that are not valid UTF-8, which TEXT cannot hold
+ALTER TABLE {{.Name "history"}}
+	ALTER COLUMN value TYPE TEXT USING convert_from(value, 'UTF8');
+ALTER TABLE {{.Table}}
+	ALTER COLUMN value TYPE TEXT USING convert_from(value, 'UTF8');
@@ -0,0 +1,11 @@
+-- Values are stored as raw bytes so binary values are kept as they are.
+-- Existing text is converted to its UTF-8 bytes.
+ALTER TABLE {{.Table}}
+	ALTER COLUMN value TYPE BYTEA USING convert_to(value, 'UTF8');
+ALTER TABLE {{.Name "history"}}
+	ALTER COLUMN value TYPE BYTEA USING convert_to(value, 'UTF8');
+
+-- Large values are read in chunks with substring, which only fetches the
+-- chunk when the value is stored uncompressed
+ALTER TABLE {{.Table}}
+	ALTER COLUMN value SET STORAGE EXTERNAL;
@@ -0,0 +1,9 @@
+-- Sorted sets live in their own table, one row per member. The index
+-- serves the score lookups and ranges of a key in score, then member order.
+CREATE TABLE IF NOT EXISTS {{.Name "zset"}} (
+	key TEXT NOT NULL,
+	member TEXT NOT NULL,
+	score DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (key, member)
+);
+CREATE INDEX IF NOT EXISTS {{.Ident "zset_score_idx"}} ON {{.Name "zset"}} (key, score, member);
@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS {{.Name "json_patch"}}(jsonb, jsonb);
+DROP FUNCTION IF EXISTS {{.Name "json_add"}}(jsonb, text[], jsonb);
+DROP FUNCTION IF EXISTS {{.Name "json_pointer"}}(text);
+DROP FUNCTION IF EXISTS {{.Name "merge_patch"}}(jsonb, jsonb);
+DROP FUNCTION IF EXISTS {{.Name "json_set"}}(jsonb, text[], jsonb);
@@ -0,0 +1,147 @@
+-- Values are changed in place as JSONB documents by these functions, so a
+-- path update or a patch is a single UPDATE that holds the row lock. They
+-- raise no_data_found for a missing key or path and data_exception for a
+-- JSON patch that does not apply.
+
+-- json_set is jsonb_set that fails rather than ignore a missing parent, and
+-- only appends to an array at the index of its length.
+CREATE OR REPLACE FUNCTION {{.Name "json_set"}}(doc jsonb, path text[], value jsonb) RETURNS jsonb AS $$
+DECLARE
+	parent jsonb := doc #> path[1:cardinality(path) - 1];
+	last text := path[cardinality(path)];
+BEGIN
+	IF cardinality(path) = 0 THEN
+		RETURN value;
+	END IF;
+	IF jsonb_typeof(parent) = 'object' OR (jsonb_typeof(parent) = 'array'
+		AND last ~ '^(0|[1-9][0-9]{0,8})$' AND last::int <= jsonb_array_length(parent)) THEN
+		RETURN jsonb_set(doc, path, value, true);
+	END IF;
+	RAISE EXCEPTION 'JSON path not found: %', array_to_string(path, '.') USING ERRCODE = 'no_data_found';
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- merge_patch applies an RFC 7396 merge patch.
+CREATE OR REPLACE FUNCTION {{.Name "merge_patch"}}(target jsonb, patch jsonb) RETURNS jsonb AS $$
+BEGIN
+	IF jsonb_typeof(patch) <> 'object' THEN
+		RETURN patch;
+	END IF;
+	IF target IS NULL OR jsonb_typeof(target) <> 'object' THEN
+		target := '{}';
+	END IF;
+	RETURN COALESCE((SELECT jsonb_object_agg(merged.key, merged.value) FROM (
+		SELECT t.key, t.value FROM jsonb_each(target) t WHERE NOT patch ? t.key
+		UNION ALL
+		SELECT p.key, {{.Name "merge_patch"}}(target -> p.key, p.value) FROM jsonb_each(patch) p
+			WHERE jsonb_typeof(p.value) <> 'null'
+	) merged), '{}');
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- json_pointer splits an RFC 6901 JSON pointer into the path of #>.
+CREATE OR REPLACE FUNCTION {{.Name "json_pointer"}}(pointer text) RETURNS text[] AS $$
+BEGIN
+	IF pointer IS NULL OR (pointer <> '' AND left(pointer, 1) <> '/') THEN
+		RAISE EXCEPTION 'invalid JSON pointer %', pointer USING ERRCODE = 'data_exception';
+	END IF;
+	IF pointer = '' THEN
+		RETURN '{}';
+	END IF;
+	RETURN ARRAY(SELECT replace(replace(step, '~1', '/'), '~0', '~')
+		FROM unnest(string_to_array(substr(pointer, 2), '/', NULL)) WITH ORDINALITY s(step, n) ORDER BY n);
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- json_add is the add operation of RFC 6902: a member is added or replaced,
+-- an array element inserted before the index, - appending it.
+CREATE OR REPLACE FUNCTION {{.Name "json_add"}}(doc jsonb, path text[], value jsonb) RETURNS jsonb AS $$
+DECLARE
+	up text[] := path[1:cardinality(path) - 1];
+	parent jsonb := doc #> up;
+	last text := path[cardinality(path)];
+	i int;
+BEGIN
+	IF cardinality(path) = 0 THEN
+		RETURN value;
+	END IF;
+	IF jsonb_typeof(parent) = 'object' THEN
+		RETURN jsonb_set(doc, path, value, true);
+	END IF;
+	IF jsonb_typeof(parent) = 'array' AND (last = '-' OR (last ~ '^(0|[1-9][0-9]{0,8})$'
+		AND last::int <= jsonb_array_length(parent))) THEN
+		i := CASE WHEN last = '-' THEN jsonb_array_length(parent) ELSE last::int END;
+		parent := COALESCE((SELECT jsonb_agg(e ORDER BY n) FROM jsonb_array_elements(parent) WITH ORDINALITY a(e, n) WHERE n <= i), '[]')
+			|| jsonb_build_array(value)
+			|| COALESCE((SELECT jsonb_agg(e ORDER BY n) FROM jsonb_array_elements(parent) WITH ORDINALITY a(e, n) WHERE n > i), '[]');
+		IF cardinality(up) = 0 THEN
+			RETURN parent;
+		END IF;
+		RETURN jsonb_set(doc, up, parent);
+	END IF;
+	RAISE EXCEPTION '% not found', array_to_string(path, '/') USING ERRCODE = 'data_exception';
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- json_patch applies an RFC 6902 JSON patch. An exception aborts the
+-- statement, so either every operation applies or none does.
+CREATE OR REPLACE FUNCTION {{.Name "json_patch"}}(doc jsonb, patch jsonb) RETURNS jsonb AS $$
+DECLARE
+	op jsonb;
+	n int := 0;
+	path text[];
+	source text[];
+	value jsonb;
+BEGIN
+	IF jsonb_typeof(patch) <> 'array' THEN
+		RAISE EXCEPTION 'a JSON patch is an array of operations' USING ERRCODE = 'invalid_text_representation';
+	END IF;
+
+	FOR op IN SELECT e FROM jsonb_array_elements(patch) WITH ORDINALITY a(e, i) ORDER BY i LOOP
+		path := {{.Name "json_pointer"}}(op ->> 'path');
+		IF op ->> 'op' IN ('move', 'copy') THEN
+			source := {{.Name "json_pointer"}}(op ->> 'from');
+			value := doc #> source;
+		ELSIF op ->> 'op' IN ('remove', 'replace', 'test') THEN
+			value := doc #> path;
+		END IF;
+		IF op ->> 'op' <> 'add' AND value IS NULL THEN
+			RAISE EXCEPTION 'operation %: % not found', n, COALESCE(op ->> 'from', op ->> 'path') USING ERRCODE = 'data_exception';
+		END IF;
+		IF op ->> 'op' IN ('add', 'replace', 'test') AND NOT op ? 'value' THEN
+			RAISE EXCEPTION 'operation %: missing value', n USING ERRCODE = 'data_exception';
+		END IF;
+
+		CASE op ->> 'op'
+		WHEN 'add' THEN
+			doc := {{.Name "json_add"}}(doc, path, op -> 'value');
+		WHEN 'remove' THEN
+			IF cardinality(path) = 0 THEN
+				RAISE EXCEPTION 'operation %: cannot remove the whole document', n USING ERRCODE = 'data_exception';
+			END IF;
+			doc := doc #- path;
+		WHEN 'replace' THEN
+			doc := CASE WHEN cardinality(path) = 0 THEN op -> 'value' ELSE jsonb_set(doc, path, op -> 'value') END;
+		WHEN 'move' THEN
+			IF path[1:cardinality(source)] = source AND cardinality(path) > cardinality(source) THEN
+				RAISE EXCEPTION 'operation %: cannot move a value into itself', n USING ERRCODE = 'data_exception';
+			END IF;
+			IF cardinality(source) = 0 THEN
+				doc := value;
+			ELSE
+				doc := {{.Name "json_add"}}(doc #- source, path, value);
+			END IF;
+		WHEN 'copy' THEN
+			doc := {{.Name "json_add"}}(doc, path, value);
+		WHEN 'test' THEN
+			IF value <> op -> 'value' THEN
+				RAISE EXCEPTION 'operation %: test of % failed', n, op ->> 'path' USING ERRCODE = 'data_exception';
+			END IF;
+		ELSE
+			RAISE EXCEPTION 'operation %: unknown op %', n, op ->> 'op' USING ERRCODE = 'data_exception';
+		END CASE;
+		n := n + 1;
+	END LOOP;
+	RETURN doc;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
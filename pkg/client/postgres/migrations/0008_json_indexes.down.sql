@@ -0,0 +1,3 @@
+-- CASCADE drops the expression indexes built on json_text
+DROP FUNCTION IF EXISTS {{.Name "json_text"}}(bytea, text[]) CASCADE;
+DROP TABLE IF EXISTS {{.Name "indexes"}};
@@ -0,0 +1,17 @@
+-- The secondary indexes declared on JSON paths. Each one is an expression
+-- index on json_text of the table, named <table>_idx_<name>.
+CREATE TABLE IF NOT EXISTS {{.Name "indexes"}} (
+	name TEXT PRIMARY KEY,
+	path TEXT NOT NULL,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- json_text is the text at path in a value, like #>>, or NULL when the value
+-- is not JSON, so the expression indexes never fail a write.
+CREATE OR REPLACE FUNCTION {{.Name "json_text"}}(value bytea, path text[]) RETURNS text AS $$
+BEGIN
+	RETURN convert_from(value, 'UTF8')::jsonb #>> path;
+EXCEPTION WHEN data_exception THEN
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
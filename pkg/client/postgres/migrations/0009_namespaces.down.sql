@@ -0,0 +1,2 @@
+-- The tables of the namespaces are left behind, drop the namespaces first
+DROP TABLE IF EXISTS {{.Name "namespaces"}};
@@ -0,0 +1,7 @@
+-- The namespaces of the table. Each one keeps its keys in a table of its
+-- own, <table>_ns_<name>, migrated like this one.
+CREATE TABLE IF NOT EXISTS {{.Name "namespaces"}} (
+	name TEXT PRIMARY KEY,
+	default_ttl INTERVAL NOT NULL DEFAULT '0',
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS {{.Ident "notify_change"}} ON {{.Table}};
+DROP FUNCTION IF EXISTS {{.Name "notify_change"}}();
@@ -0,0 +1,19 @@
+-- Every write of a row announces its key on a channel named after the
+-- table, so caches in front of any client can drop their copy, see
+-- ListenPostgresChanges. Keys too long for a notification are announced
+-- as the empty key, which drops every copy.
+CREATE OR REPLACE FUNCTION {{.Name "notify_change"}}() RETURNS trigger AS $$
+DECLARE
+	changed TEXT := CASE WHEN TG_OP = 'DELETE' THEN OLD.key ELSE NEW.key END;
+BEGIN
+	IF octet_length(changed) >= 8000 THEN
+		changed := '';
+	END IF;
+	PERFORM pg_notify({{.Channel}}, changed);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER {{.Ident "notify_change"}}
+	AFTER INSERT OR UPDATE OR DELETE ON {{.Table}}
+	FOR EACH ROW EXECUTE FUNCTION {{.Name "notify_change"}}();
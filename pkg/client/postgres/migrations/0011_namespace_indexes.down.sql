@@ -0,0 +1,13 @@
+-- The records of the indexes of the namespaces are lost, their expression
+-- indexes are left behind
+DELETE FROM {{.Name "indexes"}} WHERE namespace <> '';
+
+DO $$
+BEGIN
+	EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', {{.Regclass "indexes"}},
+		(SELECT conname FROM pg_constraint WHERE conrelid = {{.Regclass "indexes"}} AND contype = 'p'));
+END;
+$$;
+ALTER TABLE {{.Name "indexes"}}
+	DROP COLUMN namespace,
+	ADD PRIMARY KEY (name);
@@ -0,0 +1,14 @@
+-- The indexes of the namespaces are recorded with the ones of the table,
+-- by the name of their namespace, empty for the table itself.
+ALTER TABLE {{.Name "indexes"}}
+	ADD COLUMN IF NOT EXISTS namespace TEXT NOT NULL DEFAULT '';
+
+-- The primary key has the name Postgres chose for it
+DO $$
+BEGIN
+	EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', {{.Regclass "indexes"}},
+		(SELECT conname FROM pg_constraint WHERE conrelid = {{.Regclass "indexes"}} AND contype = 'p'));
+END;
+$$;
+ALTER TABLE {{.Name "indexes"}}
+	ADD PRIMARY KEY (namespace, name);
@@ -0,0 +1,9 @@
+DROP TRIGGER IF EXISTS {{.Ident "zset_check_type"}} ON {{.Name "zset"}};
+DROP FUNCTION IF EXISTS {{.Name "zset_check_type"}}();
+DROP TRIGGER IF EXISTS {{.Ident "check_type"}} ON {{.Table}};
+DROP FUNCTION IF EXISTS {{.Name "check_type"}}();
+
+ALTER TABLE {{.Name "zset"}}
+	DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE {{.Name "zset"}}
+	ALTER COLUMN member TYPE TEXT COLLATE "default";
@@ -0,0 +1,36 @@
+-- Members of equal score are ordered by their bytes, like in the other
+-- stores, rather than by the collation of the database.
+ALTER TABLE {{.Name "zset"}}
+	ALTER COLUMN member TYPE TEXT COLLATE "C";
+-- When a member was last written, so the sorted sets of a namespace expire
+-- like its values.
+ALTER TABLE {{.Name "zset"}}
+	ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- A key holds either a value of the table or a sorted set. Writing it as
+-- the other type raises wrong_object_type.
+CREATE OR REPLACE FUNCTION {{.Name "check_type"}}() RETURNS trigger AS $$
+BEGIN
+	IF NEW.deleted_at IS NULL AND EXISTS (SELECT 1 FROM {{.Name "zset"}} WHERE key = NEW.key) THEN
+		RAISE EXCEPTION '% holds a sorted set', NEW.key USING ERRCODE = 'wrong_object_type';
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER {{.Ident "check_type"}}
+	BEFORE INSERT OR UPDATE OF deleted_at ON {{.Table}}
+	FOR EACH ROW EXECUTE FUNCTION {{.Name "check_type"}}();
+
+CREATE OR REPLACE FUNCTION {{.Name "zset_check_type"}}() RETURNS trigger AS $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM {{.Table}} WHERE key = NEW.key AND deleted_at IS NULL) THEN
+		RAISE EXCEPTION '% holds a string', NEW.key USING ERRCODE = 'wrong_object_type';
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER {{.Ident "zset_check_type"}}
+	BEFORE INSERT ON {{.Name "zset"}}
+	FOR EACH ROW EXECUTE FUNCTION {{.Name "zset_check_type"}}();
@@ -0,0 +1,31 @@
+-- See 0004_add_tombstones
+CREATE OR REPLACE FUNCTION {{.Name "record_history"}}() RETURNS trigger AS $$
+DECLARE
+	deleted BOOLEAN := TG_OP = 'DELETE';
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		NEW.version := COALESCE((SELECT max(version) FROM {{.Name "history"}} WHERE key = NEW.key), 0) + 1;
+		RETURN NEW;
+	END IF;
+
+	IF NOT deleted THEN
+		deleted := NEW.deleted_at IS NOT NULL;
+	END IF;
+	IF OLD.deleted_at IS NULL THEN
+		INSERT INTO {{.Name "history"}} (key, version, value, written_at, deleted)
+			VALUES (OLD.key, OLD.version, OLD.value, OLD.updated_at, deleted);
+	END IF;
+	IF TG_OP = 'DELETE' THEN
+		RETURN OLD;
+	END IF;
+
+	IF NEW.deleted_at IS NULL THEN
+		NEW.version := OLD.version + 1;
+		NEW.updated_at := now();
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE {{.Table}}
+	DROP COLUMN IF EXISTS written_at;
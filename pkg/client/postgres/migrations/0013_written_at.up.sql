@@ -0,0 +1,38 @@
+-- updated_at moves with the content type and tags too, so the history
+-- keeps the time the value itself was written in a column of its own.
+-- Existing rows get the time of their last write.
+ALTER TABLE {{.Table}}
+	ADD COLUMN IF NOT EXISTS written_at TIMESTAMPTZ;
+UPDATE {{.Table}} SET written_at = updated_at WHERE written_at IS NULL;
+ALTER TABLE {{.Table}}
+	ALTER COLUMN written_at SET DEFAULT now(),
+	ALTER COLUMN written_at SET NOT NULL;
+
+CREATE OR REPLACE FUNCTION {{.Name "record_history"}}() RETURNS trigger AS $$
+DECLARE
+	deleted BOOLEAN := TG_OP = 'DELETE';
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		NEW.version := COALESCE((SELECT max(version) FROM {{.Name "history"}} WHERE key = NEW.key), 0) + 1;
+		RETURN NEW;
+	END IF;
+
+	IF NOT deleted THEN
+		deleted := NEW.deleted_at IS NOT NULL;
+	END IF;
+	IF OLD.deleted_at IS NULL THEN
+		INSERT INTO {{.Name "history"}} (key, version, value, written_at, deleted)
+			VALUES (OLD.key, OLD.version, OLD.value, OLD.written_at, deleted);
+	END IF;
+	IF TG_OP = 'DELETE' THEN
+		RETURN OLD;
+	END IF;
+
+	IF NEW.deleted_at IS NULL THEN
+		NEW.version := OLD.version + 1;
+		NEW.updated_at := now();
+		NEW.written_at := now();
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
@@ -2,13 +2,58 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	context "context"
+
+	postgres "github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+	mock "github.com/stretchr/testify/mock"
+
+	io "io"
+
+	time "time"
+)
 
 // Client is an autogenerated mock type for the Client type
 type Client struct {
 	mock.Mock
 }
 
+// CopyPostgresRows provides a mock function with given fields: pairs
+func (_m *Client) CopyPostgresRows(pairs map[string]string) error {
+	ret := _m.Called(pairs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CopyPostgresRows")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(map[string]string) error); ok {
+		r0 = rf(pairs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateIndexPostgresRow provides a mock function with given fields: name, path
+func (_m *Client) CreateIndexPostgresRow(name string, path string) error {
+	ret := _m.Called(name, path)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateIndexPostgresRow")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(name, path)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // CreatePostgresRow provides a mock function with given fields: key, val
 func (_m *Client) CreatePostgresRow(key string, val string) error {
 	ret := _m.Called(key, val)
@@ -45,6 +90,98 @@ func (_m *Client) DeletePostgresRow(key string) error {
 	return r0
 }
 
+// DropIndexPostgresRow provides a mock function with given fields: name
+func (_m *Client) DropIndexPostgresRow(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DropIndexPostgresRow")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DropNamespacePostgresRow provides a mock function with given fields: name
+func (_m *Client) DropNamespacePostgresRow(name string) error {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DropNamespacePostgresRow")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExpirePostgresRows provides a mock function with given fields: before
+func (_m *Client) ExpirePostgresRows(before time.Time) (int, error) {
+	ret := _m.Called(before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ExpirePostgresRows")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int, error)); ok {
+		return rf(before)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int); ok {
+		r0 = rf(before)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAtPostgresRow provides a mock function with given fields: key, at
+func (_m *Client) GetAtPostgresRow(key string, at time.Time) (string, error) {
+	ret := _m.Called(key, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAtPostgresRow")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) (string, error)); ok {
+		return rf(key, at)
+	}
+	if rf, ok := ret.Get(0).(func(string, time.Time) string); ok {
+		r0 = rf(key, at)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, time.Time) error); ok {
+		r1 = rf(key, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetPostgresRow provides a mock function with given fields: key
 func (_m *Client) GetPostgresRow(key string) (string, error) {
 	ret := _m.Called(key)
@@ -73,24 +210,110 @@ func (_m *Client) GetPostgresRow(key string) (string, error) {
 	return r0, r1
 }
 
-// ShowPostgresRow provides a mock function with no fields
-func (_m *Client) ShowPostgresRow() (map[string]string, error) {
+// HistoryPostgresRows provides a mock function with given fields: key, limit
+func (_m *Client) HistoryPostgresRows(key string, limit int) ([]postgres.Version, error) {
+	ret := _m.Called(key, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HistoryPostgresRows")
+	}
+
+	var r0 []postgres.Version
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]postgres.Version, error)); ok {
+		return rf(key, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []postgres.Version); ok {
+		r0 = rf(key, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]postgres.Version)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(key, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrByFloatPostgresRow provides a mock function with given fields: key, delta
+func (_m *Client) IncrByFloatPostgresRow(key string, delta float64) (float64, error) {
+	ret := _m.Called(key, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrByFloatPostgresRow")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, float64) (float64, error)); ok {
+		return rf(key, delta)
+	}
+	if rf, ok := ret.Get(0).(func(string, float64) float64); ok {
+		r0 = rf(key, delta)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, float64) error); ok {
+		r1 = rf(key, delta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IncrByPostgresRow provides a mock function with given fields: key, delta
+func (_m *Client) IncrByPostgresRow(key string, delta int64) (int64, error) {
+	ret := _m.Called(key, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IncrByPostgresRow")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64) (int64, error)); ok {
+		return rf(key, delta)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64) int64); ok {
+		r0 = rf(key, delta)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64) error); ok {
+		r1 = rf(key, delta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IndexesPostgresRows provides a mock function with no fields
+func (_m *Client) IndexesPostgresRows() ([]postgres.Index, error) {
 	ret := _m.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for ShowPostgresRow")
+		panic("no return value specified for IndexesPostgresRows")
 	}
 
-	var r0 map[string]string
+	var r0 []postgres.Index
 	var r1 error
-	if rf, ok := ret.Get(0).(func() (map[string]string, error)); ok {
+	if rf, ok := ret.Get(0).(func() ([]postgres.Index, error)); ok {
 		return rf()
 	}
-	if rf, ok := ret.Get(0).(func() map[string]string); ok {
+	if rf, ok := ret.Get(0).(func() []postgres.Index); ok {
 		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(map[string]string)
+			r0 = ret.Get(0).([]postgres.Index)
 		}
 	}
 
@@ -103,17 +326,149 @@ func (_m *Client) ShowPostgresRow() (map[string]string, error) {
 	return r0, r1
 }
 
-// UpdatePostgresRow provides a mock function with given fields: key, value
-func (_m *Client) UpdatePostgresRow(key string, value string) error {
-	ret := _m.Called(key, value)
+// JSONGetPostgresRow provides a mock function with given fields: key, path
+func (_m *Client) JSONGetPostgresRow(key string, path string) (string, error) {
+	ret := _m.Called(key, path)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdatePostgresRow")
+		panic("no return value specified for JSONGetPostgresRow")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return rf(key, path)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(key, path)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(key, path)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// JSONMergePostgresRow provides a mock function with given fields: key, patch
+func (_m *Client) JSONMergePostgresRow(key string, patch string) (string, error) {
+	ret := _m.Called(key, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for JSONMergePostgresRow")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return rf(key, patch)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(key, patch)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(key, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// JSONPatchPostgresRow provides a mock function with given fields: key, patch
+func (_m *Client) JSONPatchPostgresRow(key string, patch string) (string, error) {
+	ret := _m.Called(key, patch)
+
+	if len(ret) == 0 {
+		panic("no return value specified for JSONPatchPostgresRow")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return rf(key, patch)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(key, patch)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(key, patch)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// JSONSetPostgresRow provides a mock function with given fields: key, path, value
+func (_m *Client) JSONSetPostgresRow(key string, path string, value string) error {
+	ret := _m.Called(key, path, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for JSONSetPostgresRow")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(key, value)
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(key, path, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// KeysPostgresRows provides a mock function with given fields: prefix
+func (_m *Client) KeysPostgresRows(prefix string) ([]string, error) {
+	ret := _m.Called(prefix)
+
+	if len(ret) == 0 {
+		panic("no return value specified for KeysPostgresRows")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]string, error)); ok {
+		return rf(prefix)
+	}
+	if rf, ok := ret.Get(0).(func(string) []string); ok {
+		r0 = rf(prefix)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(prefix)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListenPostgresChanges provides a mock function with given fields: ctx, changed
+func (_m *Client) ListenPostgresChanges(ctx context.Context, changed func(string)) error {
+	ret := _m.Called(ctx, changed)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListenPostgresChanges")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(string)) error); ok {
+		r0 = rf(ctx, changed)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -121,6 +476,614 @@ func (_m *Client) UpdatePostgresRow(key string, value string) error {
 	return r0
 }
 
+// MultiDeletePostgresRows provides a mock function with given fields: keys
+func (_m *Client) MultiDeletePostgresRows(keys []string) ([]string, error) {
+	ret := _m.Called(keys)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MultiDeletePostgresRows")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) ([]string, error)); ok {
+		return rf(keys)
+	}
+	if rf, ok := ret.Get(0).(func([]string) []string); ok {
+		r0 = rf(keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(keys)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MultiGetPostgresRows provides a mock function with given fields: keys
+func (_m *Client) MultiGetPostgresRows(keys []string) (map[string]string, error) {
+	ret := _m.Called(keys)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MultiGetPostgresRows")
+	}
+
+	var r0 map[string]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) (map[string]string, error)); ok {
+		return rf(keys)
+	}
+	if rf, ok := ret.Get(0).(func([]string) map[string]string); ok {
+		r0 = rf(keys)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(keys)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MultiSetPostgresRows provides a mock function with given fields: pairs
+func (_m *Client) MultiSetPostgresRows(pairs map[string]string) error {
+	ret := _m.Called(pairs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MultiSetPostgresRows")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(map[string]string) error); ok {
+		r0 = rf(pairs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Namespace provides a mock function with given fields: name
+func (_m *Client) Namespace(name string) (postgres.Client, error) {
+	ret := _m.Called(name)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Namespace")
+	}
+
+	var r0 postgres.Client
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (postgres.Client, error)); ok {
+		return rf(name)
+	}
+	if rf, ok := ret.Get(0).(func(string) postgres.Client); ok {
+		r0 = rf(name)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(postgres.Client)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(name)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NamespacesPostgresRows provides a mock function with no fields
+func (_m *Client) NamespacesPostgresRows() ([]postgres.Namespace, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for NamespacesPostgresRows")
+	}
+
+	var r0 []postgres.Namespace
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]postgres.Namespace, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []postgres.Namespace); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]postgres.Namespace)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeTombstonesPostgresRows provides a mock function with given fields: before
+func (_m *Client) PurgeTombstonesPostgresRows(before time.Time) (int, error) {
+	ret := _m.Called(before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeTombstonesPostgresRows")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int, error)); ok {
+		return rf(before)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int); ok {
+		r0 = rf(before)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// QueryPostgresRows provides a mock function with given fields: index, value
+func (_m *Client) QueryPostgresRows(index string, value string) ([]string, error) {
+	ret := _m.Called(index, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryPostgresRows")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) ([]string, error)); ok {
+		return rf(index, value)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) []string); ok {
+		r0 = rf(index, value)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(index, value)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReadStreamPostgresRow provides a mock function with given fields: key, w
+func (_m *Client) ReadStreamPostgresRow(key string, w io.Writer) error {
+	ret := _m.Called(key, w)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReadStreamPostgresRow")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, io.Writer) error); ok {
+		r0 = rf(key, w)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reconnect provides a mock function with given fields: username, password
+func (_m *Client) Reconnect(username string, password string) error {
+	ret := _m.Called(username, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reconnect")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(username, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetMetadataPostgresRow provides a mock function with given fields: key, contentType, tags
+func (_m *Client) SetMetadataPostgresRow(key string, contentType string, tags []string) error {
+	ret := _m.Called(key, contentType, tags)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMetadataPostgresRow")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, []string) error); ok {
+		r0 = rf(key, contentType, tags)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetNamespaceTTLPostgresRow provides a mock function with given fields: name, ttl
+func (_m *Client) SetNamespaceTTLPostgresRow(name string, ttl time.Duration) error {
+	ret := _m.Called(name, ttl)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetNamespaceTTLPostgresRow")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Duration) error); ok {
+		r0 = rf(name, ttl)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ShowPostgresRow provides a mock function with no fields
+func (_m *Client) ShowPostgresRow() (map[string]string, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ShowPostgresRow")
+	}
+
+	var r0 map[string]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (map[string]string, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() map[string]string); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// StatPostgresRow provides a mock function with given fields: key
+func (_m *Client) StatPostgresRow(key string) (postgres.Metadata, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StatPostgresRow")
+	}
+
+	var r0 postgres.Metadata
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (postgres.Metadata, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) postgres.Metadata); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(postgres.Metadata)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UndeletePostgresRow provides a mock function with given fields: key
+func (_m *Client) UndeletePostgresRow(key string) error {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UndeletePostgresRow")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePostgresRow provides a mock function with given fields: key, value
+func (_m *Client) UpdatePostgresRow(key string, value string) error {
+	ret := _m.Called(key, value)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePostgresRow")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(key, value)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WriteStreamPostgresRow provides a mock function with given fields: key, r
+func (_m *Client) WriteStreamPostgresRow(key string, r io.Reader) error {
+	ret := _m.Called(key, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for WriteStreamPostgresRow")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, io.Reader) error); ok {
+		r0 = rf(key, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ZAddPostgresRow provides a mock function with given fields: key, member, score
+func (_m *Client) ZAddPostgresRow(key string, member string, score float64) (bool, error) {
+	ret := _m.Called(key, member, score)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZAddPostgresRow")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, float64) (bool, error)); ok {
+		return rf(key, member, score)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, float64) bool); ok {
+		r0 = rf(key, member, score)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, float64) error); ok {
+		r1 = rf(key, member, score)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ZCardPostgresRows provides a mock function with given fields: key
+func (_m *Client) ZCardPostgresRows(key string) (int, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZCardPostgresRows")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (int, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) int); ok {
+		r0 = rf(key)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ZIncrByPostgresRow provides a mock function with given fields: key, member, delta
+func (_m *Client) ZIncrByPostgresRow(key string, member string, delta float64) (float64, error) {
+	ret := _m.Called(key, member, delta)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZIncrByPostgresRow")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, float64) (float64, error)); ok {
+		return rf(key, member, delta)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, float64) float64); ok {
+		r0 = rf(key, member, delta)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, float64) error); ok {
+		r1 = rf(key, member, delta)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ZRangeByScorePostgresRows provides a mock function with given fields: key, min, max
+func (_m *Client) ZRangeByScorePostgresRows(key string, min float64, max float64) ([]postgres.ScoredMember, error) {
+	ret := _m.Called(key, min, max)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZRangeByScorePostgresRows")
+	}
+
+	var r0 []postgres.ScoredMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, float64, float64) ([]postgres.ScoredMember, error)); ok {
+		return rf(key, min, max)
+	}
+	if rf, ok := ret.Get(0).(func(string, float64, float64) []postgres.ScoredMember); ok {
+		r0 = rf(key, min, max)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]postgres.ScoredMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, float64, float64) error); ok {
+		r1 = rf(key, min, max)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ZRangePostgresRows provides a mock function with given fields: key, start, stop
+func (_m *Client) ZRangePostgresRows(key string, start int, stop int) ([]postgres.ScoredMember, error) {
+	ret := _m.Called(key, start, stop)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZRangePostgresRows")
+	}
+
+	var r0 []postgres.ScoredMember
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int, int) ([]postgres.ScoredMember, error)); ok {
+		return rf(key, start, stop)
+	}
+	if rf, ok := ret.Get(0).(func(string, int, int) []postgres.ScoredMember); ok {
+		r0 = rf(key, start, stop)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]postgres.ScoredMember)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int, int) error); ok {
+		r1 = rf(key, start, stop)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ZRankPostgresRow provides a mock function with given fields: key, member
+func (_m *Client) ZRankPostgresRow(key string, member string) (int, error) {
+	ret := _m.Called(key, member)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZRankPostgresRow")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (int, error)); ok {
+		return rf(key, member)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) int); ok {
+		r0 = rf(key, member)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(key, member)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ZRemPostgresRows provides a mock function with given fields: key, members
+func (_m *Client) ZRemPostgresRows(key string, members []string) (int, error) {
+	ret := _m.Called(key, members)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZRemPostgresRows")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string) (int, error)); ok {
+		return rf(key, members)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string) int); ok {
+		r0 = rf(key, members)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(key, members)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ZScorePostgresRow provides a mock function with given fields: key, member
+func (_m *Client) ZScorePostgresRow(key string, member string) (float64, error) {
+	ret := _m.Called(key, member)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ZScorePostgresRow")
+	}
+
+	var r0 float64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (float64, error)); ok {
+		return rf(key, member)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) float64); ok {
+		r0 = rf(key, member)
+	} else {
+		r0 = ret.Get(0).(float64)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(key, member)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewClient creates a new instance of Client. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewClient(t interface {
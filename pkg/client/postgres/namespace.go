@@ -0,0 +1,226 @@
+package postgres
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// The tables of a namespace are created and dropped by these templates,
+// rendered like the migrations, rather than by the migrations of the main
+// table: the namespaces have none of its other tables and share its
+// functions.
+var (
+	//go:embed namespace/create.sql
+	createNamespaceScript string
+	//go:embed namespace/drop.sql
+	dropNamespaceScript string
+)
+
+// Namespace is a separate set of keys, kept in a table of its own. The
+// keys of a namespace with a DefaultTTL expire that long after their last
+// write, see ExpirePostgresRows.
+type Namespace struct {
+	Name       string
+	DefaultTTL time.Duration
+}
+
+// ErrNamespaceNotFound is returned when dropping a namespace that was never
+// used.
+var ErrNamespaceNotFound = errors.New("namespace not found")
+
+var namespaceName = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+// Namespace returns a client of the namespace name, whose keys are in the
+// table <table>_ns_<name>, or a hash of it, next to this one. The table is created on first
+// use, and the client shares the pool of this one.
+func (r *realClient) Namespace(name string) (Client, error) {
+	ns, err := r.namespace(name)
+	if err != nil {
+		return nil, err
+	}
+	return ns, nil
+}
+
+func (r *realClient) namespace(name string) (*realClient, error) {
+	if r.parent != nil {
+		return nil, errors.New("namespaces cannot be nested")
+	}
+	if !namespaceName.MatchString(name) {
+		return nil, fmt.Errorf("invalid namespace name %q, expected up to 32 lowercase letters, digits and underscores starting with a letter", name)
+	}
+
+	r.mu.RLock()
+	ns, ok := r.namespaces[name]
+	r.mu.RUnlock()
+	if ok {
+		return ns, nil
+	}
+
+	cfg := r.namespaceConfig(name)
+	if err := r.createNamespace(name, cfg); err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if ns, ok := r.namespaces[name]; ok {
+		return ns, nil
+	}
+	// The pool and the credentials are the current ones, in case they
+	// were rotated while the table was migrated
+	cfg.User, cfg.Password = r.config.User, r.config.Password
+	ns = &realClient{db: r.db, config: cfg, table: cfg.table(), history: cfg.historyTable(), zset: cfg.zsetTable(), parent: r, name: name}
+	if r.namespaces == nil {
+		r.namespaces = make(map[string]*realClient)
+	}
+	r.namespaces[name] = ns
+	return ns, nil
+}
+
+// createNamespace creates the tables of the namespace name and records it,
+// in one transaction. The advisory lock of the migrations of its table
+// keeps replicas from creating them at the same time.
+func (r *realClient) createNamespace(name string, cfg Config) error {
+	script, err := cfg.render(createNamespaceScript)
+	if err != nil {
+		return fmt.Errorf("invalid namespace script: %w", err)
+	}
+
+	tx, err := r.pool().Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, cfg.lockID()); err != nil {
+		return fmt.Errorf("failed to lock the namespace %s: %w", name, err)
+	}
+	if _, err := tx.Exec(script); err != nil {
+		return fmt.Errorf("failed to create the namespace %s: %w", name, err)
+	}
+	_, err = tx.Exec(`INSERT INTO `+r.object("namespaces")+` (name) VALUES ($1) ON CONFLICT (name) DO NOTHING`, name)
+	if err != nil {
+		return fmt.Errorf("error recording the namespace: %w", err)
+	}
+	return tx.Commit()
+}
+
+// namespaceConfig is the configuration of this client for the table of the
+// namespace name.
+func (r *realClient) namespaceConfig(name string) Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.config.namespace(name)
+}
+
+// namespace is the configuration of the table of the namespace name,
+// <table>_ns_<name>, which is hashed like the other objects of the table
+// when that is too long, see ident.
+func (c Config) namespace(name string) Config {
+	c.Table = c.ident("ns_" + name)
+	return c
+}
+
+// NamespacesPostgresRows lists the namespaces sorted by name.
+func (r *realClient) NamespacesPostgresRows() ([]Namespace, error) {
+
+	rows, err := r.pool().Query(`SELECT name, (EXTRACT(EPOCH FROM default_ttl) * 1000000)::bigint FROM ` + r.object("namespaces") + ` ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving namespaces: %w", err)
+	}
+	defer rows.Close()
+
+	namespaces := []Namespace{}
+	for rows.Next() {
+		var namespace Namespace
+		var ttl int64
+		if err := rows.Scan(&namespace.Name, &ttl); err != nil {
+			return nil, fmt.Errorf("error while scanning the data: %w", err)
+		}
+		namespace.DefaultTTL = time.Duration(ttl) * time.Microsecond
+		namespaces = append(namespaces, namespace)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return namespaces, nil
+}
+
+// SetNamespaceTTLPostgresRow sets the DefaultTTL of a namespace, creating
+// it. Zero keeps the keys until they are deleted.
+func (r *realClient) SetNamespaceTTLPostgresRow(name string, ttl time.Duration) error {
+
+	if ttl < 0 {
+		return fmt.Errorf("invalid TTL %s, expected zero or more", ttl)
+	}
+	if _, err := r.namespace(name); err != nil {
+		return err
+	}
+	_, err := r.pool().Exec(`UPDATE `+r.object("namespaces")+` SET default_ttl = $2 * interval '1 microsecond' WHERE name = $1`,
+		name, ttl.Microseconds())
+	if err != nil {
+		return fmt.Errorf("error updating the namespace: %w", err)
+	}
+	return nil
+}
+
+// DropNamespacePostgresRow drops the tables of the namespace with its
+// history and sorted sets, and its records, in one transaction.
+func (r *realClient) DropNamespacePostgresRow(name string) error {
+
+	cfg := r.namespaceConfig(name)
+	script, err := cfg.render(dropNamespaceScript)
+	if err != nil {
+		return fmt.Errorf("invalid namespace script: %w", err)
+	}
+
+	tx, err := r.pool().Begin()
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, cfg.lockID()); err != nil {
+		return fmt.Errorf("failed to lock the namespace %s: %w", name, err)
+	}
+	result, err := tx.Exec(`DELETE FROM `+r.object("namespaces")+` WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("error deleting the namespace: %w", err)
+	}
+	if count, err := result.RowsAffected(); err == nil && count == 0 {
+		return fmt.Errorf("%w: %s", ErrNamespaceNotFound, name)
+	}
+	if _, err := tx.Exec(script); err != nil {
+		return fmt.Errorf("failed to drop the namespace %s: %w", name, err)
+	}
+	if _, err := tx.Exec(`DELETE FROM `+r.object("indexes")+` WHERE namespace = $1`, name); err != nil {
+		return fmt.Errorf("error deleting the indexes of the namespace: %w", err)
+	}
+	// Left by the namespaces migrated like the main table
+	if _, err := tx.Exec(`DELETE FROM `+cfg.migrationsTable()+` WHERE table_name = $1`, cfg.tableName()); err != nil {
+		return fmt.Errorf("error deleting the migrations of the namespace: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to drop the namespace %s: %w", name, err)
+	}
+
+	r.mu.Lock()
+	delete(r.namespaces, name)
+	r.mu.Unlock()
+	return nil
+}
+
+// ExpirePostgresRows deletes the rows last written before the given time,
+// or tombstones them in soft delete mode, and returns how many there were.
+func (r *realClient) ExpirePostgresRows(before time.Time) (int, error) {
+
+	expired, err := r.deleteRows("updated_at < $1", before)
+	if err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
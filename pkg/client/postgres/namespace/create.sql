@@ -0,0 +1,102 @@
+-- The table of a namespace, with its history and sorted sets, as the
+-- migrations leave those of the main table. The JSON functions and the
+-- index records are the ones of the main table.
+CREATE TABLE IF NOT EXISTS {{.Table}} (
+	key TEXT PRIMARY KEY,
+	value BYTEA,
+	created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	written_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	content_type TEXT NOT NULL DEFAULT '',
+	tags TEXT[] NOT NULL DEFAULT '{}',
+	version BIGINT NOT NULL DEFAULT 1,
+	deleted_at TIMESTAMPTZ
+);
+ALTER TABLE {{.Table}}
+	ALTER COLUMN value SET STORAGE EXTERNAL;
+CREATE INDEX IF NOT EXISTS {{.Ident "deleted_at_idx"}} ON {{.Table}} (deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS {{.Name "history"}} (
+	id BIGSERIAL PRIMARY KEY,
+	key TEXT NOT NULL,
+	version BIGINT NOT NULL,
+	value BYTEA,
+	written_at TIMESTAMPTZ NOT NULL,
+	replaced_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	deleted BOOLEAN NOT NULL DEFAULT false
+);
+CREATE INDEX IF NOT EXISTS {{.Ident "history_key_idx"}} ON {{.Name "history"}} (key, version DESC);
+CREATE INDEX IF NOT EXISTS {{.Ident "history_replaced_at_idx"}} ON {{.Name "history"}} (replaced_at);
+
+-- See 0013_written_at
+CREATE OR REPLACE FUNCTION {{.Name "record_history"}}() RETURNS trigger AS $$
+DECLARE
+	deleted BOOLEAN := TG_OP = 'DELETE';
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		NEW.version := COALESCE((SELECT max(version) FROM {{.Name "history"}} WHERE key = NEW.key), 0) + 1;
+		RETURN NEW;
+	END IF;
+
+	IF NOT deleted THEN
+		deleted := NEW.deleted_at IS NOT NULL;
+	END IF;
+	IF OLD.deleted_at IS NULL THEN
+		INSERT INTO {{.Name "history"}} (key, version, value, written_at, deleted)
+			VALUES (OLD.key, OLD.version, OLD.value, OLD.written_at, deleted);
+	END IF;
+	IF TG_OP = 'DELETE' THEN
+		RETURN OLD;
+	END IF;
+
+	IF NEW.deleted_at IS NULL THEN
+		NEW.version := OLD.version + 1;
+		NEW.updated_at := now();
+		NEW.written_at := now();
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS {{.Ident "history"}} ON {{.Table}};
+CREATE TRIGGER {{.Ident "history"}}
+	BEFORE INSERT OR UPDATE OF value, deleted_at OR DELETE ON {{.Table}}
+	FOR EACH ROW EXECUTE FUNCTION {{.Name "record_history"}}();
+
+CREATE TABLE IF NOT EXISTS {{.Name "zset"}} (
+	key TEXT NOT NULL,
+	member TEXT COLLATE "C" NOT NULL,
+	score DOUBLE PRECISION NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (key, member)
+);
+CREATE INDEX IF NOT EXISTS {{.Ident "zset_score_idx"}} ON {{.Name "zset"}} (key, score, member);
+
+-- See 0012_sorted_set_types
+CREATE OR REPLACE FUNCTION {{.Name "check_type"}}() RETURNS trigger AS $$
+BEGIN
+	IF NEW.deleted_at IS NULL AND EXISTS (SELECT 1 FROM {{.Name "zset"}} WHERE key = NEW.key) THEN
+		RAISE EXCEPTION '% holds a sorted set', NEW.key USING ERRCODE = 'wrong_object_type';
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS {{.Ident "check_type"}} ON {{.Table}};
+CREATE TRIGGER {{.Ident "check_type"}}
+	BEFORE INSERT OR UPDATE OF deleted_at ON {{.Table}}
+	FOR EACH ROW EXECUTE FUNCTION {{.Name "check_type"}}();
+
+CREATE OR REPLACE FUNCTION {{.Name "zset_check_type"}}() RETURNS trigger AS $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM {{.Table}} WHERE key = NEW.key AND deleted_at IS NULL) THEN
+		RAISE EXCEPTION '% holds a string', NEW.key USING ERRCODE = 'wrong_object_type';
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS {{.Ident "zset_check_type"}} ON {{.Name "zset"}};
+CREATE TRIGGER {{.Ident "zset_check_type"}}
+	BEFORE INSERT ON {{.Name "zset"}}
+	FOR EACH ROW EXECUTE FUNCTION {{.Name "zset_check_type"}}();
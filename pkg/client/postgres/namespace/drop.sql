@@ -0,0 +1,8 @@
+-- Drops what create.sql made, and what the migrations made for the
+-- namespaces created before it, whatever their state.
+DROP TABLE IF EXISTS {{.Table}}, {{.Name "history"}}, {{.Name "zset"}}, {{.Name "indexes"}}, {{.Name "namespaces"}} CASCADE;
+DROP FUNCTION IF EXISTS {{.Name "record_history"}}(), {{.Name "notify_change"}}(),
+	{{.Name "check_type"}}(), {{.Name "zset_check_type"}}() CASCADE;
+DROP FUNCTION IF EXISTS {{.Name "json_set"}}(jsonb, text[], jsonb), {{.Name "merge_patch"}}(jsonb, jsonb),
+	{{.Name "json_pointer"}}(text), {{.Name "json_add"}}(jsonb, text[], jsonb), {{.Name "json_patch"}}(jsonb, jsonb),
+	{{.Name "json_text"}}(bytea, text[]) CASCADE;
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/lib/pq"
+)
+
+// ZAddPostgresRow sets the score of member in the sorted set of key and
+// reports whether the member is new.
+func (r *realClient) ZAddPostgresRow(key, member string, score float64) (bool, error) {
+
+	if math.IsNaN(score) {
+		return false, fmt.Errorf("%w: the score cannot be NaN", ErrNotNumber)
+	}
+	// xmax is only set on the rows the upsert updated
+	var added bool
+	err := r.pool().QueryRow(`INSERT INTO `+r.zset+` (key, member, score) VALUES ($1, $2, $3)
+		ON CONFLICT (key, member) DO UPDATE SET score = EXCLUDED.score, updated_at = now()
+		RETURNING xmax = 0`, key, member, score).Scan(&added)
+	if err != nil {
+		return false, typeError("error adding the member", err)
+	}
+	return added, nil
+}
+
+// ZIncrByPostgresRow adds delta to the score of member, 0 when it is
+// missing, in a single upsert.
+func (r *realClient) ZIncrByPostgresRow(key, member string, delta float64) (float64, error) {
+
+	if math.IsNaN(delta) {
+		return 0, fmt.Errorf("%w: the increment cannot be NaN", ErrNotNumber)
+	}
+	// Adding infinities of both signs gives NaN, which leaves the row alone
+	// and returns nothing
+	var score float64
+	err := r.pool().QueryRow(`INSERT INTO `+r.zset+` AS z (key, member, score) VALUES ($1, $2, $3)
+		ON CONFLICT (key, member) DO UPDATE SET score = z.score + EXCLUDED.score, updated_at = now()
+		WHERE z.score + EXCLUDED.score <> 'NaN'::float8
+		RETURNING score`, key, member, delta).Scan(&score)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: the score would be NaN", ErrNotNumber)
+	}
+	if err != nil {
+		return 0, typeError("error incrementing the score", err)
+	}
+	return score, nil
+}
+
+func (r *realClient) ZScorePostgresRow(key, member string) (float64, error) {
+
+	var score float64
+	err := r.pool().QueryRow(`SELECT score FROM `+r.zset+` WHERE key = $1 AND member = $2`, key, member).Scan(&score)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving the score: %w", err)
+	}
+	return score, nil
+}
+
+// ZRankPostgresRow counts the members ordered before member. The count is
+// an index only scan of the (key, score, member) index, so it grows with the
+// rank rather than with the size of the table.
+func (r *realClient) ZRankPostgresRow(key, member string) (int, error) {
+
+	var rank int
+	err := r.pool().QueryRow(`SELECT (SELECT count(*) FROM `+r.zset+` o
+			WHERE o.key = z.key AND (o.score, o.member) < (z.score, z.member))
+		FROM `+r.zset+` z WHERE z.key = $1 AND z.member = $2`, key, member).Scan(&rank)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrNotFound
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error retrieving the rank: %w", err)
+	}
+	return rank, nil
+}
+
+func (r *realClient) ZRemPostgresRows(key string, members []string) (int, error) {
+
+	result, err := r.pool().Exec(`DELETE FROM `+r.zset+` WHERE key = $1 AND member = ANY($2)`, key, pq.Array(members))
+	if err != nil {
+		return 0, fmt.Errorf("error removing the members: %w", err)
+	}
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error removing the members: %w", err)
+	}
+	return int(removed), nil
+}
+
+func (r *realClient) ZCardPostgresRows(key string) (int, error) {
+
+	var count int
+	if err := r.pool().QueryRow(`SELECT count(*) FROM `+r.zset+` WHERE key = $1`, key).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting the members: %w", err)
+	}
+	return count, nil
+}
+
+// ZRangePostgresRows returns the members ranked from start to stop, both
+// included, negative indexes counting from the end. Those need the number
+// of members, which is read in the same snapshot as the range.
+func (r *realClient) ZRangePostgresRows(key string, start, stop int) ([]ScoredMember, error) {
+
+	tx, err := r.pool().BeginTx(context.Background(), &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("error starting the transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if start < 0 || stop < 0 {
+		var count int
+		if err := tx.QueryRow(`SELECT count(*) FROM `+r.zset+` WHERE key = $1`, key).Scan(&count); err != nil {
+			return nil, fmt.Errorf("error counting the members: %w", err)
+		}
+		if start < 0 {
+			start += count
+		}
+		if stop < 0 {
+			stop += count
+		}
+	}
+	start = max(start, 0)
+	if start > stop {
+		return []ScoredMember{}, nil
+	}
+
+	rows, err := tx.Query(`SELECT member, score FROM `+r.zset+` WHERE key = $1
+		ORDER BY score, member OFFSET $2 LIMIT $3`, key, start, stop-start+1)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the members: %w", err)
+	}
+	return scanMembers(rows)
+}
+
+func (r *realClient) ZRangeByScorePostgresRows(key string, min, max float64) ([]ScoredMember, error) {
+
+	rows, err := r.pool().Query(`SELECT member, score FROM `+r.zset+` WHERE key = $1 AND score BETWEEN $2 AND $3
+		ORDER BY score, member`, key, min, max)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving the members: %w", err)
+	}
+	return scanMembers(rows)
+}
+
+// typeError converts the error raised by the triggers of the
+// 0012_sorted_set_types migration when a key is written as another type
+// than the one it holds.
+func typeError(action string, err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "42809" {
+		// wrong_object_type
+		return fmt.Errorf("%w: %s", ErrWrongType, pqErr.Message)
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+func scanMembers(rows *sql.Rows) ([]ScoredMember, error) {
+	defer rows.Close()
+
+	members := []ScoredMember{}
+	for rows.Next() {
+		var member ScoredMember
+		if err := rows.Scan(&member.Member, &member.Score); err != nil {
+			return nil, fmt.Errorf("error while scanning the data: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over rows: %w", err)
+	}
+	return members, nil
+}
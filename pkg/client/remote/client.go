@@ -0,0 +1,696 @@
+// Package remote is the Go client of the api server. It mirrors the
+// endpoints of the server with typed methods:
+//
+//	client, err := remote.NewClient(remote.Config{URL: "http://localhost:8080"})
+//	if err != nil {
+//		return err
+//	}
+//	value, err := client.Get(ctx, "user:1")
+//	if errors.Is(err, remote.ErrNotFound) {
+//		...
+//	}
+//
+// A client is safe for concurrent use and keeps its connections to the
+// server open between requests, so create one and share it.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors matched by the *Error the client returns, for use with errors.Is.
+// ErrNotNumber is returned when a counter holds something else than a
+// number or its increment would overflow.
+var (
+	ErrNotFound  = errors.New("key not found")
+	ErrConflict  = errors.New("key already exists")
+	ErrNotNumber = errors.New("value is not a number")
+)
+
+// Error is returned when the server answers with a status other than 200.
+// Message is the error text sent by the server.
+type Error struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s %s returned %d: %s", e.Method, e.Path, e.StatusCode, e.Message)
+}
+
+// Is makes errors.Is(err, ErrNotFound) and errors.Is(err, ErrConflict) work
+// on the status code of the response.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrNotNumber:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	}
+	return false
+}
+
+type Client interface {
+	Create(ctx context.Context, key, value string) error
+	Update(ctx context.Context, key, value string) error
+	Delete(ctx context.Context, key string) error
+	Get(ctx context.Context, key string) (string, error)
+	Show(ctx context.Context) (map[string]string, error)
+	Keys(ctx context.Context, prefix string) ([]string, error)
+	Batch(ctx context.Context, ops []BatchOperation) ([]BatchResult, error)
+	Stat(ctx context.Context, key string) (Metadata, error)
+	SetMetadata(ctx context.Context, key, contentType string, tags []string) error
+	History(ctx context.Context, key string, limit int) ([]Version, error)
+	GetAt(ctx context.Context, key string, at time.Time) (string, error)
+	Undelete(ctx context.Context, key string) error
+	Download(ctx context.Context, key string, w io.Writer) error
+	Upload(ctx context.Context, key string, body io.Reader) error
+	IncrBy(ctx context.Context, key string, delta int64) (int64, error)
+	IncrByFloat(ctx context.Context, key string, delta float64) (float64, error)
+	JSONGet(ctx context.Context, key, path string) (string, error)
+	JSONSet(ctx context.Context, key, path, value string) error
+	JSONMerge(ctx context.Context, key, patch string) (string, error)
+	JSONPatch(ctx context.Context, key, patch string) (string, error)
+	CreateIndex(ctx context.Context, name, path string) error
+	DropIndex(ctx context.Context, name string) error
+	Indexes(ctx context.Context) ([]Index, error)
+	Query(ctx context.Context, index, value string) ([]string, error)
+	Namespace(name string) (Client, error)
+	Namespaces(ctx context.Context) ([]Namespace, error)
+	NamespaceStats(ctx context.Context, name string) (Namespace, error)
+	SetNamespaceTTL(ctx context.Context, name string, ttl time.Duration) error
+	DropNamespace(ctx context.Context, name string) error
+}
+
+// Metadata mirrors the /stat response of the server.
+type Metadata struct {
+	CreatedAt   time.Time `json:"CreatedAt"`
+	UpdatedAt   time.Time `json:"UpdatedAt"`
+	WrittenAt   time.Time `json:"WrittenAt,omitzero"`
+	ContentType string    `json:"ContentType,omitempty"`
+	Tags        []string  `json:"Tags,omitempty"`
+	Version     int64     `json:"Version,omitempty"`
+}
+
+// Version mirrors an entry of the /history response of the server.
+type Version struct {
+	Version    int64     `json:"Version"`
+	Value      string    `json:"Value"`
+	WrittenAt  time.Time `json:"WrittenAt"`
+	ReplacedAt time.Time `json:"ReplacedAt,omitzero"`
+	Deleted    bool      `json:"Deleted,omitempty"`
+}
+
+// Index mirrors an entry of the /indexes response of the server: a
+// secondary index of the JSON values by the value at Path.
+type Index struct {
+	Name string `json:"Name"`
+	Path string `json:"Path"`
+}
+
+// Namespace mirrors an entry of the /ns response of the server. DefaultTTL
+// is zero when its keys do not expire, and Keys is only filled in by
+// NamespaceStats.
+type Namespace struct {
+	Name       string
+	DefaultTTL time.Duration
+	Keys       int
+}
+
+type namespaceResponse struct {
+	Name       string `json:"Name"`
+	DefaultTTL string `json:"DefaultTTL,omitempty"`
+	Keys       int    `json:"Keys,omitempty"`
+}
+
+func (n namespaceResponse) namespace() (Namespace, error) {
+	namespace := Namespace{Name: n.Name, Keys: n.Keys}
+	if n.DefaultTTL != "" {
+		ttl, err := time.ParseDuration(n.DefaultTTL)
+		if err != nil {
+			return Namespace{}, fmt.Errorf("server returned an invalid TTL for %s: %w", n.Name, err)
+		}
+		namespace.DefaultTTL = ttl
+	}
+	return namespace, nil
+}
+
+// BatchOperation and BatchResult mirror the /batch payloads of the server.
+type BatchOperation struct {
+	Op    string `json:"Op"`
+	Key   string `json:"Key"`
+	Value string `json:"Value,omitempty"`
+}
+
+type BatchResult struct {
+	Op     string `json:"Op"`
+	Key    string `json:"Key"`
+	Value  string `json:"Value,omitempty"`
+	Status string `json:"Status"`
+	Error  string `json:"Error,omitempty"`
+}
+
+type Config struct {
+	// URL of the api server, e.g. http://localhost:8080
+	URL string
+	// Headers are sent with every request, typically Authorization.
+	Headers map[string]string
+	// Timeout bounds every single attempt. Zero means 10 seconds.
+	Timeout time.Duration
+	// Retries is how many times a failed request is tried again.
+	Retries int
+	// RetryWait is the pause before the first retry, doubled for every
+	// following one. Zero means 200ms.
+	RetryWait time.Duration
+	// MaxRetryWait caps the pause between attempts, Retry-After included.
+	// Zero means 5 seconds.
+	MaxRetryWait time.Duration
+	// HTTPClient replaces the default client, which keeps idle connections
+	// to the server open. Timeout is ignored when it is set.
+	HTTPClient *http.Client
+}
+
+// NewClient creates a client for a running api server.
+func NewClient(cfg Config) (Client, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("server url cannot be empty")
+	}
+	if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+		return nil, fmt.Errorf("server url must start with http:// or https://")
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.RetryWait == 0 {
+		cfg.RetryWait = 200 * time.Millisecond
+	}
+	if cfg.MaxRetryWait == 0 {
+		cfg.MaxRetryWait = 5 * time.Second
+	}
+
+	client := cfg.HTTPClient
+	if client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		// All requests go to the same host, keep enough of them around
+		transport.MaxIdleConnsPerHost = 16
+		client = &http.Client{Transport: transport, Timeout: cfg.Timeout}
+	}
+
+	return &realClient{
+		baseURL: strings.TrimSuffix(cfg.URL, "/"),
+		config:  cfg,
+		http:    client,
+	}, nil
+}
+
+type realClient struct {
+	baseURL string
+	config  Config
+	http    *http.Client
+	// prefix is /ns/{namespace} for the client of a namespace, whose routes
+	// are the ones of the store under it
+	prefix string
+}
+
+type request struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
+type incrRequest struct {
+	By      *int64   `json:"By,omitempty"`
+	ByFloat *float64 `json:"ByFloat,omitempty"`
+}
+
+type incrResponse struct {
+	Value json.Number `json:"Value"`
+}
+
+func (r *realClient) Create(ctx context.Context, key, value string) error {
+	return r.do(ctx, http.MethodPost, "/create", request{Key: key, Value: value}, nil)
+}
+
+func (r *realClient) Update(ctx context.Context, key, value string) error {
+	return r.do(ctx, http.MethodPut, "/update", request{Key: key, Value: value}, nil)
+}
+
+func (r *realClient) Delete(ctx context.Context, key string) error {
+	return r.do(ctx, http.MethodDelete, "/delete", request{Key: key}, nil)
+}
+
+func (r *realClient) Get(ctx context.Context, key string) (string, error) {
+	var response struct {
+		Value string `json:"Value"`
+	}
+	if err := r.do(ctx, http.MethodGet, "/get", request{Key: key}, &response); err != nil {
+		return "", err
+	}
+	return response.Value, nil
+}
+
+func (r *realClient) Show(ctx context.Context) (map[string]string, error) {
+	store := make(map[string]string)
+	if err := r.do(ctx, http.MethodGet, "/show", nil, &store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Keys lists the keys starting with prefix, sorted, or every key for the
+// empty prefix, without their values.
+func (r *realClient) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var response struct {
+		Keys []string `json:"Keys"`
+	}
+	if err := r.do(ctx, http.MethodGet, "/keys?prefix="+url.QueryEscape(prefix), nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Keys, nil
+}
+
+func (r *realClient) Batch(ctx context.Context, ops []BatchOperation) ([]BatchResult, error) {
+	var response struct {
+		Results []BatchResult `json:"Results"`
+	}
+	body := struct {
+		Operations []BatchOperation `json:"Operations"`
+	}{Operations: ops}
+
+	if err := r.do(ctx, http.MethodPost, "/batch", body, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Results) != len(ops) {
+		return nil, fmt.Errorf("server returned %d results for %d operations", len(response.Results), len(ops))
+	}
+	return response.Results, nil
+}
+
+func (r *realClient) Stat(ctx context.Context, key string) (Metadata, error) {
+	var meta Metadata
+	if err := r.do(ctx, http.MethodGet, "/stat", request{Key: key}, &meta); err != nil {
+		return Metadata{}, err
+	}
+	return meta, nil
+}
+
+func (r *realClient) SetMetadata(ctx context.Context, key, contentType string, tags []string) error {
+	body := struct {
+		Key         string   `json:"Key"`
+		ContentType string   `json:"ContentType"`
+		Tags        []string `json:"Tags"`
+	}{Key: key, ContentType: contentType, Tags: tags}
+	return r.do(ctx, http.MethodPut, "/metadata", body, nil)
+}
+
+func (r *realClient) History(ctx context.Context, key string, limit int) ([]Version, error) {
+	var response struct {
+		Versions []Version `json:"Versions"`
+	}
+	body := struct {
+		Key   string `json:"Key"`
+		Limit int    `json:"Limit,omitempty"`
+	}{Key: key, Limit: limit}
+
+	if err := r.do(ctx, http.MethodGet, "/history", body, &response); err != nil {
+		return nil, err
+	}
+	return response.Versions, nil
+}
+
+func (r *realClient) GetAt(ctx context.Context, key string, at time.Time) (string, error) {
+	var response struct {
+		Value string `json:"Value"`
+	}
+	body := struct {
+		Key string    `json:"Key"`
+		At  time.Time `json:"At"`
+	}{Key: key, At: at}
+
+	if err := r.do(ctx, http.MethodGet, "/get", body, &response); err != nil {
+		return "", err
+	}
+	return response.Value, nil
+}
+
+func (r *realClient) Undelete(ctx context.Context, key string) error {
+	return r.do(ctx, http.MethodPost, "/keys/"+url.PathEscape(key)+"/restore", nil, nil)
+}
+
+// IncrBy atomically adds delta to the integer value of key, creating it
+// when it is missing, and returns the result. Use 1 and -1 to increment
+// and decrement.
+func (r *realClient) IncrBy(ctx context.Context, key string, delta int64) (int64, error) {
+	var response incrResponse
+	if err := r.do(ctx, http.MethodPost, "/keys/"+url.PathEscape(key)+"/incr", incrRequest{By: &delta}, &response); err != nil {
+		return 0, err
+	}
+	return response.Value.Int64()
+}
+
+// IncrByFloat is IncrBy for decimal values.
+func (r *realClient) IncrByFloat(ctx context.Context, key string, delta float64) (float64, error) {
+	var response incrResponse
+	if err := r.do(ctx, http.MethodPost, "/keys/"+url.PathEscape(key)+"/incr", incrRequest{ByFloat: &delta}, &response); err != nil {
+		return 0, err
+	}
+	return response.Value.Float64()
+}
+
+// JSONGet returns the JSON at path, like $.user.name, in the value of key.
+// The path $ returns the whole document.
+func (r *realClient) JSONGet(ctx context.Context, key, path string) (string, error) {
+	var document json.RawMessage
+	if err := r.do(ctx, http.MethodGet, documentPath(key, path), nil, &document); err != nil {
+		return "", err
+	}
+	return string(document), nil
+}
+
+// JSONSet sets the JSON at path, whose parent must exist, to value, which
+// must be JSON itself. The path $ sets the whole value, creating the key.
+func (r *realClient) JSONSet(ctx context.Context, key, path, value string) error {
+	return r.do(ctx, http.MethodPut, documentPath(key, path), json.RawMessage(value), nil)
+}
+
+// JSONMerge applies an RFC 7396 merge patch to the value of key, creating
+// it, and returns the new document.
+func (r *realClient) JSONMerge(ctx context.Context, key, patch string) (string, error) {
+	return r.patchDocument(ctx, key, "application/merge-patch+json", patch)
+}
+
+// JSONPatch applies an RFC 6902 JSON patch, every operation or none, to the
+// value of key and returns the new document. A patch that does not apply is
+// reported as ErrConflict. Unlike JSONMerge it is never retried after a
+// server error, as operations like adding to an array apply twice.
+func (r *realClient) JSONPatch(ctx context.Context, key, patch string) (string, error) {
+	return r.patchDocument(ctx, key, "application/json-patch+json", patch)
+}
+
+func (r *realClient) patchDocument(ctx context.Context, key, contentType, patch string) (string, error) {
+	var document json.RawMessage
+	if err := r.doAs(ctx, http.MethodPatch, documentPath(key, "$"), contentType, json.RawMessage(patch), &document); err != nil {
+		return "", err
+	}
+	return string(document), nil
+}
+
+// documentPath is the /keys/{key}/json route for the JSON at path.
+func documentPath(key, path string) string {
+	return "/keys/" + url.PathEscape(key) + "/json?path=" + url.QueryEscape(path)
+}
+
+// CreateIndex indexes the JSON values by the value at path, like $.status.
+// Creating it again with the same path does nothing, with another path it
+// is reported as ErrConflict.
+func (r *realClient) CreateIndex(ctx context.Context, name, path string) error {
+	body := struct {
+		Path string `json:"Path"`
+	}{Path: path}
+	return r.do(ctx, http.MethodPut, "/indexes/"+url.PathEscape(name), body, nil)
+}
+
+// DropIndex removes the index name, reporting ErrNotFound when it is missing.
+func (r *realClient) DropIndex(ctx context.Context, name string) error {
+	return r.do(ctx, http.MethodDelete, "/indexes/"+url.PathEscape(name), nil, nil)
+}
+
+func (r *realClient) Indexes(ctx context.Context) ([]Index, error) {
+	var response struct {
+		Indexes []Index `json:"Indexes"`
+	}
+	if err := r.do(ctx, http.MethodGet, "/indexes", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Indexes, nil
+}
+
+// Query returns the keys, sorted, whose JSON value holds value at the path
+// of index: a string without its quotes and anything else as JSON.
+func (r *realClient) Query(ctx context.Context, index, value string) ([]string, error) {
+	var response struct {
+		Keys []string `json:"Keys"`
+	}
+	query := url.Values{"index": {index}, "value": {value}}
+	if err := r.do(ctx, http.MethodGet, "/query?"+query.Encode(), nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Keys, nil
+}
+
+// Namespace returns a client of the keys of the namespace name, which
+// shares the connections of this one. The namespace is created by its
+// first write; reading from one that was never written to reports
+// ErrNotFound.
+func (r *realClient) Namespace(name string) (Client, error) {
+	if r.prefix != "" {
+		return nil, errors.New("namespaces cannot be nested")
+	}
+	if name == "" {
+		return nil, errors.New("namespace name cannot be empty")
+	}
+	ns := *r
+	ns.prefix = "/ns/" + url.PathEscape(name)
+	return &ns, nil
+}
+
+// Namespaces lists the namespaces sorted by name.
+func (r *realClient) Namespaces(ctx context.Context) ([]Namespace, error) {
+	var response struct {
+		Namespaces []namespaceResponse `json:"Namespaces"`
+	}
+	if err := r.root().do(ctx, http.MethodGet, "/ns", nil, &response); err != nil {
+		return nil, err
+	}
+	namespaces := make([]Namespace, 0, len(response.Namespaces))
+	for _, n := range response.Namespaces {
+		namespace, err := n.namespace()
+		if err != nil {
+			return nil, err
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces, nil
+}
+
+// NamespaceStats returns the namespace name with the number of its keys.
+func (r *realClient) NamespaceStats(ctx context.Context, name string) (Namespace, error) {
+	var response namespaceResponse
+	if err := r.root().do(ctx, http.MethodGet, "/ns/"+url.PathEscape(name)+"/stats", nil, &response); err != nil {
+		return Namespace{}, err
+	}
+	return response.namespace()
+}
+
+// SetNamespaceTTL creates the namespace name, or changes the TTL given to
+// the keys written to it. Zero keeps the keys until they are deleted.
+func (r *realClient) SetNamespaceTTL(ctx context.Context, name string, ttl time.Duration) error {
+	body := struct {
+		DefaultTTL string `json:"DefaultTTL"`
+	}{}
+	if ttl > 0 {
+		body.DefaultTTL = ttl.String()
+	}
+	return r.root().do(ctx, http.MethodPut, "/ns/"+url.PathEscape(name), body, nil)
+}
+
+// DropNamespace removes the namespace name with all its keys.
+func (r *realClient) DropNamespace(ctx context.Context, name string) error {
+	return r.root().do(ctx, http.MethodDelete, "/ns/"+url.PathEscape(name), nil, nil)
+}
+
+// root is the client of the store for a client of a namespace, as the
+// namespaces themselves are managed outside of /ns/{namespace}.
+func (r *realClient) root() *realClient {
+	if r.prefix == "" {
+		return r
+	}
+	root := *r
+	root.prefix = ""
+	return &root
+}
+
+// Download writes the raw value of key to w as it arrives from GET
+// /keys/{key}. Unlike Get it keeps binary values intact. It is not retried,
+// as part of the value may have been written to w already, and only ctx
+// bounds it since large values can take longer than Timeout.
+func (r *realClient) Download(ctx context.Context, key string, w io.Writer) error {
+	resp, err := r.stream(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("error downloading %s: %w", key, err)
+	}
+	return nil
+}
+
+// Upload stores everything read from body as the raw value of key with PUT
+// /keys/{key}, creating or overwriting it. It is not retried, as body cannot
+// be read twice, and only ctx bounds it like Download.
+func (r *realClient) Upload(ctx context.Context, key string, body io.Reader) error {
+	resp, err := r.stream(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
+
+// stream makes a single request to /keys/{key} with a raw body and returns
+// the response once the server answered 200.
+func (r *realClient) stream(ctx context.Context, method, key string, body io.Reader) (*http.Response, error) {
+	path := r.prefix + "/keys/" + url.PathEscape(key)
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	for name, value := range r.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	client := *r.http
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, responseError(method, path, resp)
+	}
+	return resp, nil
+}
+
+// do sends one request and decodes the JSON response into out, retrying
+// with exponential backoff on network errors, 5xx and 429. A POST, like a
+// create, an increment or a batch, or a JSON patch may have been applied
+// when the server failed, so it is only retried on 429, which the server
+// sends before doing anything.
+func (r *realClient) do(ctx context.Context, method, path string, body any, out any) error {
+	return r.doAs(ctx, method, path, "application/json", body, out)
+}
+
+// doAs is do with another Content-Type for the JSON body.
+func (r *realClient) doAs(ctx context.Context, method, path, contentType string, body any, out any) error {
+	path = r.prefix + path
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error encoding request: %w", err)
+		}
+	}
+
+	wait := r.config.RetryWait
+	for attempt := 0; ; attempt++ {
+		retryAfter, err := r.send(ctx, method, path, contentType, payload, out)
+		if err == nil {
+			return nil
+		}
+		if attempt == r.config.Retries || !r.retryable(method, contentType, err) {
+			return err
+		}
+
+		pause := max(wait/2+rand.N(wait/2+1), retryAfter)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(min(pause, r.config.MaxRetryWait)):
+		}
+		wait = min(wait*2, r.config.MaxRetryWait)
+	}
+}
+
+func (r *realClient) retryable(method, contentType string, err error) bool {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		// Network error, unless the caller gave up
+		return idempotent(method, contentType) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	if apiErr.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return idempotent(method, contentType) && apiErr.StatusCode >= 500 && apiErr.StatusCode != http.StatusNotImplemented
+}
+
+// idempotent tells whether sending a request with method twice has the same
+// effect as sending it once. The reads, puts, deletes and merge patches of
+// the server are; its POSTs create, increment, restore or run a batch of any
+// of them, and a JSON patch may add to an array.
+func idempotent(method, contentType string) bool {
+	return method != http.MethodPost && contentType != "application/json-patch+json"
+}
+
+// send makes a single attempt. On failure it also returns how long the
+// server asked to wait through Retry-After, if it did.
+func (r *realClient) send(ctx context.Context, method, path, contentType string, payload []byte, out any) (time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, method, r.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range r.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error calling %s %s: %w", method, path, err)
+	}
+	defer func() {
+		// Reading the body to the end lets the connection be reused
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		var retryAfter time.Duration
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			retryAfter = time.Duration(seconds) * time.Second
+		}
+		return retryAfter, responseError(method, path, resp)
+	}
+
+	if out == nil {
+		return 0, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return 0, fmt.Errorf("error decoding response of %s %s: %w", method, path, err)
+	}
+	return 0, nil
+}
+
+// responseError reads the error text the server sent with a failed
+// response.
+func responseError(method, path string, resp *http.Response) *Error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return &Error{
+		Method:     method,
+		Path:       path,
+		StatusCode: resp.StatusCode,
+		Message:    strings.TrimSpace(string(message)),
+	}
+}
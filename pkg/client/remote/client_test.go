@@ -0,0 +1,507 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClient(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		expectedError string
+	}{
+		{name: "Empty url", url: "", expectedError: "server url cannot be empty"},
+		{name: "Missing scheme", url: "localhost:8080", expectedError: "server url must start with http:// or https://"},
+		{name: "Valid url", url: "http://localhost:8080/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient(Config{URL: tt.url})
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_Requests(t *testing.T) {
+	tests := []struct {
+		name           string
+		call           func(c Client) (any, error)
+		handler        http.HandlerFunc
+		expectedMethod string
+		expectedPath   string
+		expectedQuery  string
+		expectedType   string
+		expectedBody   string
+		expectedResult any
+		expectedError  string
+	}{
+		{
+			name: "Create",
+			call: func(c Client) (any, error) { return nil, c.Create(context.Background(), "Hello", "World") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"message":"Row created succesfully"}`))
+			},
+			expectedMethod: http.MethodPost,
+			expectedPath:   "/create",
+			expectedBody:   `{"Key":"Hello","Value":"World"}`,
+		},
+		{
+			name: "Update",
+			call: func(c Client) (any, error) { return nil, c.Update(context.Background(), "Hello", "World") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"message":"Row updated succesfully"}`))
+			},
+			expectedMethod: http.MethodPut,
+			expectedPath:   "/update",
+			expectedBody:   `{"Key":"Hello","Value":"World"}`,
+		},
+		{
+			name: "Delete",
+			call: func(c Client) (any, error) { return nil, c.Delete(context.Background(), "Hello") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"message":"Row deleted succesfully"}`))
+			},
+			expectedMethod: http.MethodDelete,
+			expectedPath:   "/delete",
+			expectedBody:   `{"Key":"Hello","Value":""}`,
+		},
+		{
+			name: "Get",
+			call: func(c Client) (any, error) { return c.Get(context.Background(), "Hello") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Key":"Hello","Value":"World"}`))
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/get",
+			expectedBody:   `{"Key":"Hello","Value":""}`,
+			expectedResult: "World",
+		},
+		{
+			name: "Show",
+			call: func(c Client) (any, error) { return c.Show(context.Background()) },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Hello":"World"}`))
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/show",
+			expectedBody:   ``,
+			expectedResult: map[string]string{"Hello": "World"},
+		},
+		{
+			name: "Batch",
+			call: func(c Client) (any, error) {
+				return c.Batch(context.Background(), []BatchOperation{{Op: "get", Key: "Hello"}})
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Results":[{"Op":"get","Key":"Hello","Value":"World","Status":"ok"}]}`))
+			},
+			expectedMethod: http.MethodPost,
+			expectedPath:   "/batch",
+			expectedBody:   `{"Operations":[{"Op":"get","Key":"Hello"}]}`,
+			expectedResult: []BatchResult{{Op: "get", Key: "Hello", Value: "World", Status: "ok"}},
+		},
+		{
+			name: "JSONGet",
+			call: func(c Client) (any, error) { return c.JSONGet(context.Background(), "user/1", "$.name") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`"Alice"`))
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/keys/user/1/json",
+			expectedQuery:  "path=%24.name",
+			expectedResult: `"Alice"`,
+		},
+		{
+			name: "JSONSet",
+			call: func(c Client) (any, error) {
+				return nil, c.JSONSet(context.Background(), "user", "$.address", `{"city": "Paris"}`)
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"message":"JSON saved succesfully"}`))
+			},
+			expectedMethod: http.MethodPut,
+			expectedPath:   "/keys/user/json",
+			expectedQuery:  "path=%24.address",
+			expectedType:   "application/json",
+			expectedBody:   `{"city":"Paris"}`,
+		},
+		{
+			name: "JSONMerge",
+			call: func(c Client) (any, error) {
+				return c.JSONMerge(context.Background(), "user", `{"status": null}`)
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"name":"Alice"}`))
+			},
+			expectedMethod: http.MethodPatch,
+			expectedPath:   "/keys/user/json",
+			expectedQuery:  "path=%24",
+			expectedType:   "application/merge-patch+json",
+			expectedBody:   `{"status":null}`,
+			expectedResult: `{"name":"Alice"}`,
+		},
+		{
+			name: "JSONPatch",
+			call: func(c Client) (any, error) {
+				return c.JSONPatch(context.Background(), "user", `[{"op": "remove", "path": "/status"}]`)
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"name":"Alice"}`))
+			},
+			expectedMethod: http.MethodPatch,
+			expectedPath:   "/keys/user/json",
+			expectedQuery:  "path=%24",
+			expectedType:   "application/json-patch+json",
+			expectedBody:   `[{"op":"remove","path":"/status"}]`,
+			expectedResult: `{"name":"Alice"}`,
+		},
+		{
+			name: "CreateIndex",
+			call: func(c Client) (any, error) { return nil, c.CreateIndex(context.Background(), "status", "$.status") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"message":"Index created succesfully"}`))
+			},
+			expectedMethod: http.MethodPut,
+			expectedPath:   "/indexes/status",
+			expectedBody:   `{"Path":"$.status"}`,
+		},
+		{
+			name: "DropIndex",
+			call: func(c Client) (any, error) { return nil, c.DropIndex(context.Background(), "status") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"message":"Index dropped succesfully"}`))
+			},
+			expectedMethod: http.MethodDelete,
+			expectedPath:   "/indexes/status",
+		},
+		{
+			name: "Indexes",
+			call: func(c Client) (any, error) { return c.Indexes(context.Background()) },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Indexes":[{"Name":"status","Path":"$.status"}]}`))
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/indexes",
+			expectedResult: []Index{{Name: "status", Path: "$.status"}},
+		},
+		{
+			name: "Query",
+			call: func(c Client) (any, error) { return c.Query(context.Background(), "status", "on hold") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Index":"status","Value":"on hold","Keys":["order/1"]}`))
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/query",
+			expectedQuery:  "index=status&value=on+hold",
+			expectedResult: []string{"order/1"},
+		},
+		{
+			name: "Keys",
+			call: func(c Client) (any, error) { return c.Keys(context.Background(), "user:") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Keys":["user:1"]}`))
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/keys",
+			expectedQuery:  "prefix=user%3A",
+			expectedResult: []string{"user:1"},
+		},
+		{
+			name: "Get in a namespace",
+			call: func(c Client) (any, error) {
+				ns, _ := c.Namespace("billing")
+				return ns.Get(context.Background(), "Hello")
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Key":"Hello","Value":"World"}`))
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/ns/billing/get",
+			expectedBody:   `{"Key":"Hello","Value":""}`,
+			expectedResult: "World",
+		},
+		{
+			name: "Namespaces",
+			call: func(c Client) (any, error) { return c.Namespaces(context.Background()) },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Namespaces":[{"Name":"billing"},{"Name":"cache","DefaultTTL":"1h30m0s"}]}`))
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/ns",
+			expectedResult: []Namespace{{Name: "billing"}, {Name: "cache", DefaultTTL: 90 * time.Minute}},
+		},
+		{
+			name: "NamespaceStats from a namespace",
+			call: func(c Client) (any, error) {
+				ns, _ := c.Namespace("billing")
+				return ns.NamespaceStats(context.Background(), "cache")
+			},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"Name":"cache","DefaultTTL":"24h0m0s","Keys":3}`))
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/ns/cache/stats",
+			expectedResult: Namespace{Name: "cache", DefaultTTL: 24 * time.Hour, Keys: 3},
+		},
+		{
+			name: "SetNamespaceTTL",
+			call: func(c Client) (any, error) { return nil, c.SetNamespaceTTL(context.Background(), "cache", time.Hour) },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"message":"Namespace saved succesfully"}`))
+			},
+			expectedMethod: http.MethodPut,
+			expectedPath:   "/ns/cache",
+			expectedBody:   `{"DefaultTTL":"1h0m0s"}`,
+		},
+		{
+			name: "DropNamespace",
+			call: func(c Client) (any, error) { return nil, c.DropNamespace(context.Background(), "cache") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"message":"Namespace dropped succesfully"}`))
+			},
+			expectedMethod: http.MethodDelete,
+			expectedPath:   "/ns/cache",
+		},
+		{
+			name: "Server error",
+			call: func(c Client) (any, error) { return c.Get(context.Background(), "Hello") },
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "Failed to get the row: key not found", http.StatusInternalServerError)
+			},
+			expectedMethod: http.MethodGet,
+			expectedPath:   "/get",
+			expectedBody:   `{"Key":"Hello","Value":""}`,
+			expectedResult: "",
+			expectedError:  "GET /get returned 500: Failed to get the row: key not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var method, path, query, contentType, body, auth string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				method, path, query, body, auth = r.Method, r.URL.Path, r.URL.RawQuery, string(data), r.Header.Get("Authorization")
+				contentType = r.Header.Get("Content-Type")
+				tt.handler(w, r)
+			}))
+			defer server.Close()
+
+			client, err := NewClient(Config{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer secret"}})
+			assert.NoError(t, err)
+
+			result, err := tt.call(client)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			if tt.expectedResult != nil {
+				assert.Equal(t, tt.expectedResult, result)
+			}
+
+			assert.Equal(t, tt.expectedMethod, method)
+			assert.Equal(t, tt.expectedPath, path)
+			assert.Equal(t, tt.expectedQuery, query)
+			if tt.expectedType != "" {
+				assert.Equal(t, tt.expectedType, contentType)
+			}
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, body)
+			}
+			assert.Equal(t, "Bearer secret", auth)
+		})
+	}
+}
+
+func TestClient_Retries(t *testing.T) {
+	tests := []struct {
+		name             string
+		call             func(c Client) error
+		failures         int32
+		status           int
+		expectedAttempts int32
+		expectedError    bool
+	}{
+		{
+			name:             "Unavailable server is retried",
+			call:             func(c Client) error { _, err := c.Get(context.Background(), "Hello"); return err },
+			failures:         2,
+			status:           http.StatusServiceUnavailable,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "Retries run out",
+			call:             func(c Client) error { _, err := c.Get(context.Background(), "Hello"); return err },
+			failures:         5,
+			status:           http.StatusServiceUnavailable,
+			expectedAttempts: 3,
+			expectedError:    true,
+		},
+		{
+			name:             "Internal error is retried",
+			call:             func(c Client) error { _, err := c.Get(context.Background(), "Hello"); return err },
+			failures:         1,
+			status:           http.StatusInternalServerError,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "Too many requests is retried",
+			call:             func(c Client) error { _, err := c.Get(context.Background(), "Hello"); return err },
+			failures:         2,
+			status:           http.StatusTooManyRequests,
+			expectedAttempts: 3,
+		},
+		{
+			name:             "Bad request is not retried",
+			call:             func(c Client) error { _, err := c.Get(context.Background(), "Hello"); return err },
+			failures:         1,
+			status:           http.StatusBadRequest,
+			expectedAttempts: 1,
+			expectedError:    true,
+		},
+		{
+			name:             "Create is not retried on server errors",
+			call:             func(c Client) error { return c.Create(context.Background(), "Hello", "World") },
+			failures:         1,
+			status:           http.StatusServiceUnavailable,
+			expectedAttempts: 1,
+			expectedError:    true,
+		},
+		{
+			name:             "Create is retried on too many requests",
+			call:             func(c Client) error { return c.Create(context.Background(), "Hello", "World") },
+			failures:         1,
+			status:           http.StatusTooManyRequests,
+			expectedAttempts: 2,
+		},
+		{
+			name:             "Incr is not retried on server errors",
+			call:             func(c Client) error { _, err := c.IncrBy(context.Background(), "Hello", 1); return err },
+			failures:         1,
+			status:           http.StatusInternalServerError,
+			expectedAttempts: 1,
+			expectedError:    true,
+		},
+		{
+			name: "Batch is not retried on server errors",
+			call: func(c Client) error {
+				_, err := c.Batch(context.Background(), []BatchOperation{{Op: "create", Key: "Hello", Value: "World"}})
+				return err
+			},
+			failures:         1,
+			status:           http.StatusBadGateway,
+			expectedAttempts: 1,
+			expectedError:    true,
+		},
+		{
+			name: "JSON patch is not retried on server errors",
+			call: func(c Client) error {
+				_, err := c.JSONPatch(context.Background(), "Hello", `[{"op": "add", "path": "/-", "value": 1}]`)
+				return err
+			},
+			failures:         1,
+			status:           http.StatusServiceUnavailable,
+			expectedAttempts: 1,
+			expectedError:    true,
+		},
+		{
+			name:             "Update is retried on server errors",
+			call:             func(c Client) error { return c.Update(context.Background(), "Hello", "World") },
+			failures:         1,
+			status:           http.StatusServiceUnavailable,
+			expectedAttempts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) <= tt.failures {
+					http.Error(w, "try again", tt.status)
+					return
+				}
+				json.NewEncoder(w).Encode(map[string]string{"Key": "Hello", "Value": "World"})
+			}))
+			defer server.Close()
+
+			client, _ := NewClient(Config{URL: server.URL, Retries: 2, RetryWait: time.Millisecond})
+
+			err := tt.call(client)
+
+			assert.Equal(t, tt.expectedError, err != nil)
+			assert.Equal(t, tt.expectedAttempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(Config{URL: server.URL, Timeout: 10 * time.Millisecond})
+
+	_, err := client.Show(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Client.Timeout exceeded")
+}
+
+func TestClient_ContextCanceled(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(Config{URL: server.URL, Retries: 5, RetryWait: time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Show(ctx)
+
+	// Retry-After holds the second attempt back until the context is done
+	assert.EqualError(t, err, "GET /show returned 429: slow down")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestError_Is(t *testing.T) {
+	tests := []struct {
+		name             string
+		statusCode       int
+		expectedNotFound bool
+		expectedConflict bool
+	}{
+		{name: "Not found", statusCode: http.StatusNotFound, expectedNotFound: true},
+		{name: "Conflict", statusCode: http.StatusConflict, expectedConflict: true},
+		{name: "Internal error", statusCode: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("wrapped: %w", &Error{Method: http.MethodGet, Path: "/get", StatusCode: tt.statusCode})
+
+			assert.Equal(t, tt.expectedNotFound, errors.Is(err, ErrNotFound))
+			assert.Equal(t, tt.expectedConflict, errors.Is(err, ErrConflict))
+		})
+	}
+}
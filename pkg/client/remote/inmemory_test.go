@@ -0,0 +1,309 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/api"
+	"github.com/imsumedhaa/In-memory-database/inmemory"
+	"github.com/stretchr/testify/assert"
+)
+
+// newInmemoryServer serves a fresh inmemory store through the real handlers.
+func newInmemoryServer(t *testing.T) Client {
+	db, err := inmemory.NewInmemory()
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(api.NewDatabaseHttp(db).Handler())
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(Config{URL: server.URL})
+	assert.NoError(t, err)
+	return client
+}
+
+func TestClient_Inmemory(t *testing.T) {
+	ctx := context.Background()
+	client := newInmemoryServer(t)
+
+	assert.NoError(t, client.Create(ctx, "Hello", "World"))
+
+	err := client.Create(ctx, "Hello", "Again")
+	assert.True(t, errors.Is(err, ErrConflict), "expected ErrConflict, got %v", err)
+
+	value, err := client.Get(ctx, "Hello")
+	assert.NoError(t, err)
+	assert.Equal(t, "World", value)
+
+	assert.NoError(t, client.Update(ctx, "Hello", "Gophers"))
+	value, _ = client.Get(ctx, "Hello")
+	assert.Equal(t, "Gophers", value)
+
+	err = client.Update(ctx, "Missing", "Value")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+
+	results, err := client.Batch(ctx, []BatchOperation{
+		{Op: "set", Key: "a", Value: "1"},
+		{Op: "get", Key: "a"},
+		{Op: "delete", Key: "b"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []BatchResult{
+		{Op: "set", Key: "a", Status: "ok"},
+		{Op: "get", Key: "a", Value: "1", Status: "ok"},
+		{Op: "delete", Key: "b", Status: "not found"},
+	}, results)
+
+	store, err := client.Show(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"Hello": "Gophers", "a": "1"}, store)
+
+	assert.NoError(t, client.Delete(ctx, "Hello"))
+
+	_, err = client.Get(ctx, "Hello")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+
+	err = client.Delete(ctx, "Hello")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+}
+
+func TestClient_InmemoryConcurrentCreate(t *testing.T) {
+	ctx := context.Background()
+	client := newInmemoryServer(t)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	created, conflicts := 0, 0
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := client.Create(ctx, "counter", "1")
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				created++
+			case errors.Is(err, ErrConflict):
+				conflicts++
+			default:
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, created)
+	assert.Equal(t, 19, conflicts)
+}
+
+func TestClient_InmemoryMetadata(t *testing.T) {
+	ctx := context.Background()
+	client := newInmemoryServer(t)
+
+	assert.NoError(t, client.Create(ctx, "Hello", "World"))
+	assert.NoError(t, client.SetMetadata(ctx, "Hello", "text/plain", []string{"greeting"}))
+
+	meta, err := client.Stat(ctx, "Hello")
+	assert.NoError(t, err)
+	assert.False(t, meta.CreatedAt.IsZero())
+	assert.False(t, meta.UpdatedAt.Before(meta.CreatedAt))
+	assert.Equal(t, "text/plain", meta.ContentType)
+	assert.Equal(t, []string{"greeting"}, meta.Tags)
+
+	_, err = client.Stat(ctx, "Missing")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+	err = client.SetMetadata(ctx, "Missing", "", nil)
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+}
+
+func TestClient_InmemoryHistory(t *testing.T) {
+	ctx := context.Background()
+	client := newInmemoryServer(t)
+
+	assert.NoError(t, client.Create(ctx, "Hello", "World"))
+	assert.NoError(t, client.Update(ctx, "Hello", "Earth"))
+
+	versions, err := client.History(ctx, "Hello", 0)
+	assert.NoError(t, err)
+	assert.Len(t, versions, 2)
+	assert.Equal(t, "Earth", versions[0].Value)
+	assert.Equal(t, int64(2), versions[0].Version)
+	assert.Equal(t, "World", versions[1].Value)
+	assert.False(t, versions[1].ReplacedAt.IsZero())
+
+	value, err := client.GetAt(ctx, "Hello", versions[1].WrittenAt)
+	assert.NoError(t, err)
+	assert.Equal(t, "World", value)
+
+	_, err = client.History(ctx, "Missing", 0)
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+	_, err = client.GetAt(ctx, "Hello", versions[1].WrittenAt.Add(-time.Hour))
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+}
+
+func TestClient_InmemoryUndelete(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+	db.SoftDelete.Enabled = true
+	server := httptest.NewServer(api.NewDatabaseHttp(db).Handler())
+	t.Cleanup(server.Close)
+	client, err := NewClient(Config{URL: server.URL})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NoError(t, client.Create(ctx, "team/a", "World"))
+	assert.NoError(t, client.Delete(ctx, "team/a"))
+	_, err = client.Get(ctx, "team/a")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+
+	assert.NoError(t, client.Undelete(ctx, "team/a"))
+	value, err := client.Get(ctx, "team/a")
+	assert.NoError(t, err)
+	assert.Equal(t, "World", value)
+
+	err = client.Undelete(ctx, "team/a")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+}
+
+func TestClient_InmemoryUploadDownload(t *testing.T) {
+	client := newInmemoryServer(t)
+	ctx := context.Background()
+
+	binary := bytes.Repeat([]byte{0x89, 'P', 'N', 'G', 0x00, 0xff}, 1000)
+	assert.NoError(t, client.Upload(ctx, "images/logo", bytes.NewReader(binary)))
+
+	var buf bytes.Buffer
+	assert.NoError(t, client.Download(ctx, "images/logo", &buf))
+	assert.Equal(t, binary, buf.Bytes())
+
+	err := client.Download(ctx, "missing", &buf)
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+}
+
+func TestClient_InmemoryIncr(t *testing.T) {
+	client := newInmemoryServer(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.IncrBy(ctx, "team/hits", 1)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	result, err := client.IncrBy(ctx, "team/hits", -5)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(15), result)
+
+	price, err := client.IncrByFloat(ctx, "price", 1.25)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.25, price)
+
+	_, err = client.IncrBy(ctx, "price", 1)
+	assert.True(t, errors.Is(err, ErrNotNumber), "expected ErrNotNumber, got %v", err)
+}
+
+func TestClient_InmemoryJSON(t *testing.T) {
+	client := newInmemoryServer(t)
+	ctx := context.Background()
+
+	assert.NoError(t, client.JSONSet(ctx, "user/1", "$", `{"name": "Alice", "tags": []}`))
+	assert.NoError(t, client.JSONSet(ctx, "user/1", "$.address", `{"city": "Paris"}`))
+
+	city, err := client.JSONGet(ctx, "user/1", "$.address.city")
+	assert.NoError(t, err)
+	assert.Equal(t, `"Paris"`, city)
+
+	document, err := client.JSONMerge(ctx, "user/1", `{"address": null, "status": "active"}`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name": "Alice", "tags": [], "status": "active"}`, document)
+
+	document, err = client.JSONPatch(ctx, "user/1", `[{"op": "add", "path": "/tags/-", "value": "vip"}]`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"name": "Alice", "tags": ["vip"], "status": "active"}`, document)
+
+	_, err = client.JSONPatch(ctx, "user/1", `[{"op": "test", "path": "/status", "value": "new"}]`)
+	assert.True(t, errors.Is(err, ErrConflict), "expected ErrConflict, got %v", err)
+	_, err = client.JSONGet(ctx, "missing", "$")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+}
+
+func TestClient_InmemoryIndexes(t *testing.T) {
+	client := newInmemoryServer(t)
+	ctx := context.Background()
+
+	assert.NoError(t, client.Create(ctx, "order/1", `{"status": "open"}`))
+	assert.NoError(t, client.Create(ctx, "order/2", `{"status": "closed"}`))
+	assert.NoError(t, client.CreateIndex(ctx, "status", "$.status"))
+	assert.NoError(t, client.CreateIndex(ctx, "status", "$.status"))
+	err := client.CreateIndex(ctx, "status", "$.state")
+	assert.True(t, errors.Is(err, ErrConflict), "expected ErrConflict, got %v", err)
+
+	indexes, err := client.Indexes(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Index{{Name: "status", Path: "$.status"}}, indexes)
+
+	keys, err := client.Query(ctx, "status", "open")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"order/1"}, keys)
+
+	assert.NoError(t, client.DropIndex(ctx, "status"))
+	_, err = client.Query(ctx, "status", "open")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+	err = client.DropIndex(ctx, "status")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+}
+
+func TestClient_InmemoryNamespaces(t *testing.T) {
+	client := newInmemoryServer(t)
+	ctx := context.Background()
+
+	assert.NoError(t, client.Create(ctx, "user:1", "root"))
+	billing, err := client.Namespace("billing")
+	assert.NoError(t, err)
+	_, err = billing.Get(ctx, "user:1")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+
+	assert.NoError(t, billing.Create(ctx, "user:1", "billed"))
+	assert.NoError(t, billing.Create(ctx, "invoice:1", "due"))
+	value, err := billing.Get(ctx, "user:1")
+	assert.NoError(t, err)
+	assert.Equal(t, "billed", value)
+	value, _ = client.Get(ctx, "user:1")
+	assert.Equal(t, "root", value, "the namespace keeps its keys apart")
+
+	keys, err := billing.Keys(ctx, "user:")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:1"}, keys)
+	keys, err = client.Keys(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"user:1"}, keys)
+
+	assert.NoError(t, client.SetNamespaceTTL(ctx, "cache", time.Hour))
+	namespaces, err := billing.Namespaces(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []Namespace{{Name: "billing"}, {Name: "cache", DefaultTTL: time.Hour}}, namespaces)
+
+	stats, err := client.NamespaceStats(ctx, "billing")
+	assert.NoError(t, err)
+	assert.Equal(t, Namespace{Name: "billing", Keys: 2}, stats)
+
+	_, err = billing.Namespace("nested")
+	assert.EqualError(t, err, "namespaces cannot be nested")
+
+	assert.NoError(t, client.DropNamespace(ctx, "billing"))
+	_, err = client.NamespaceStats(ctx, "billing")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+	err = client.DropNamespace(ctx, "billing")
+	assert.True(t, errors.Is(err, ErrNotFound), "expected ErrNotFound, got %v", err)
+}
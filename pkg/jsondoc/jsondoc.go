@@ -0,0 +1,466 @@
+// Package jsondoc reads and changes parts of JSON documents held as text:
+// values at a path like $.user.name, RFC 7396 merge patches and RFC 6902
+// JSON patches. The backends that don't have JSON support of their own use
+// it, and Postgres uses its paths.
+package jsondoc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Errors returned, possibly wrapped, when a document or a path does not
+// allow the operation.
+var (
+	ErrNotJSON      = errors.New("value is not a JSON document")
+	ErrPathNotFound = errors.New("JSON path not found")
+	ErrPatchFailed  = errors.New("JSON patch cannot be applied")
+)
+
+// ParsePath splits a path like $.user.name, $.items[0] or $["a key"] into
+// its steps, none for the whole document $. An index is a step like any
+// other and only means an index when the value it applies to is an array.
+func ParsePath(path string) ([]string, error) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, fmt.Errorf("invalid JSON path %q: it must start with $", path)
+	}
+
+	steps := []string{}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("invalid JSON path %q: empty member name", path)
+			}
+			steps = append(steps, rest[1:1+end])
+			rest = rest[1+end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if strings.HasPrefix(rest, `["`) {
+				// A quoted name may hold ], so decode the string first
+				decoder := json.NewDecoder(strings.NewReader(rest[1:]))
+				var name string
+				if err := decoder.Decode(&name); err != nil {
+					return nil, fmt.Errorf("invalid JSON path %q: %w", path, err)
+				}
+				end = 1 + int(decoder.InputOffset())
+				if end >= len(rest) || rest[end] != ']' {
+					return nil, fmt.Errorf("invalid JSON path %q: missing ]", path)
+				}
+				steps = append(steps, name)
+			} else {
+				if end < 0 {
+					return nil, fmt.Errorf("invalid JSON path %q: missing ]", path)
+				}
+				index, err := strconv.Atoi(rest[1:end])
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("invalid JSON path %q: %q is not an index", path, rest[1:end])
+				}
+				steps = append(steps, rest[1:end])
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q: unexpected %q", path, rest[0])
+		}
+	}
+	return steps, nil
+}
+
+// Valid checks that value is a single JSON value.
+func Valid(value string) error {
+	_, err := decode(value)
+	return err
+}
+
+// Get returns the JSON at path in document.
+func Get(document, path string) (string, error) {
+	steps, err := ParsePath(path)
+	if err != nil {
+		return "", err
+	}
+	doc, err := decode(document)
+	if err != nil {
+		return "", err
+	}
+
+	for _, step := range steps {
+		var ok bool
+		if doc, ok = child(doc, step); !ok {
+			return "", fmt.Errorf("%w: %s", ErrPathNotFound, path)
+		}
+	}
+	return encode(doc)
+}
+
+// Text returns the value at the steps of a parsed path as text, like the
+// #>> operator of Postgres: a string without its quotes, anything else as
+// JSON. It reports false when document is not JSON, or the value is
+// missing or null.
+func Text(document string, steps []string) (string, bool) {
+	doc, err := decode(document)
+	if err != nil {
+		return "", false
+	}
+	value, ok := lookup(doc, steps)
+	switch value := value.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, ok
+	}
+	text, err := encode(value)
+	return text, ok && err == nil
+}
+
+// Set replaces or adds the JSON value at path in document and returns the
+// new document. The parent of path must exist; an array index may be the
+// length of the array, which appends. An empty document is a missing key,
+// which only the path $ creates.
+func Set(document, path, value string) (string, error) {
+	steps, err := ParsePath(path)
+	if err != nil {
+		return "", err
+	}
+	v, err := decode(value)
+	if err != nil {
+		return "", err
+	}
+	if len(steps) == 0 {
+		return encode(v)
+	}
+	if document == "" {
+		return "", fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	}
+	doc, err := decode(document)
+	if err != nil {
+		return "", err
+	}
+
+	last := steps[len(steps)-1]
+	doc, err = update(doc, steps[:len(steps)-1], func(parent any) (any, error) {
+		switch parent := parent.(type) {
+		case map[string]any:
+			parent[last] = v
+			return parent, nil
+		case []any:
+			index, err := strconv.Atoi(last)
+			switch {
+			case err != nil || index < 0 || index > len(parent):
+				return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+			case index == len(parent):
+				return append(parent, v), nil
+			}
+			parent[index] = v
+			return parent, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrPathNotFound, path)
+	})
+	if err != nil {
+		return "", err
+	}
+	return encode(doc)
+}
+
+// MergePatch applies an RFC 7396 merge patch to document, an empty
+// document being a missing key, and returns the new document.
+func MergePatch(document, patch string) (string, error) {
+	p, err := decode(patch)
+	if err != nil {
+		return "", err
+	}
+	var doc any
+	if document != "" {
+		if doc, err = decode(document); err != nil {
+			return "", err
+		}
+	}
+	return encode(merge(doc, p))
+}
+
+func merge(target, patch any) any {
+	members, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]any)
+	if !ok {
+		result = map[string]any{}
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(result, name)
+		} else {
+			result[name] = merge(result[name], value)
+		}
+	}
+	return result
+}
+
+// operation is one step of an RFC 6902 JSON patch.
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Patch applies an RFC 6902 JSON patch, an array of operations, to
+// document and returns the new document. Either every operation applies or
+// an error wrapping ErrPatchFailed tells the first that does not.
+func Patch(document, patch string) (string, error) {
+	var operations []operation
+	if err := json.Unmarshal([]byte(patch), &operations); err != nil {
+		return "", fmt.Errorf("%w: a JSON patch is an array of operations: %s", ErrNotJSON, err)
+	}
+	doc, err := decode(document)
+	if err != nil {
+		return "", err
+	}
+
+	for n, op := range operations {
+		if doc, err = apply(doc, op); err != nil {
+			return "", fmt.Errorf("%w: operation %d: %s", ErrPatchFailed, n, err)
+		}
+	}
+	return encode(doc)
+}
+
+func apply(doc any, op operation) (any, error) {
+	if op.Path == nil {
+		return nil, errors.New("missing path")
+	}
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value of %s", op.Op)
+		}
+		if value, err = decode(string(op.Value)); err != nil {
+			return nil, err
+		}
+	case "move", "copy":
+		if op.From == nil {
+			return nil, fmt.Errorf("missing from of %s", op.Op)
+		}
+		from, err := parsePointer(*op.From)
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if value, ok = lookup(doc, from); !ok {
+			return nil, fmt.Errorf("%s not found", *op.From)
+		}
+		if op.Op == "move" {
+			if len(path) > len(from) && strings.HasPrefix(*op.Path, *op.From+"/") {
+				return nil, errors.New("cannot move a value into itself")
+			}
+			if doc, err = remove(doc, from); err != nil {
+				return nil, err
+			}
+		} else {
+			// The copy must not share maps or slices with the original
+			copied, _ := encode(value)
+			value, _ = decode(copied)
+		}
+	case "remove":
+	default:
+		return nil, fmt.Errorf("unknown op %q", op.Op)
+	}
+
+	switch op.Op {
+	case "remove":
+		return remove(doc, path)
+	case "replace":
+		if _, ok := lookup(doc, path); !ok {
+			return nil, fmt.Errorf("%s not found", *op.Path)
+		}
+		if len(path) == 0 {
+			return value, nil
+		}
+		doc, err = remove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	case "test":
+		current, ok := lookup(doc, path)
+		if !ok {
+			return nil, fmt.Errorf("%s not found", *op.Path)
+		}
+		a, _ := encode(current)
+		b, _ := encode(value)
+		if a != b {
+			return nil, fmt.Errorf("test of %s failed", *op.Path)
+		}
+		return doc, nil
+	}
+	return add(doc, path, value)
+}
+
+// add inserts value at path: a member is added or replaced, an array
+// element is inserted before the index, - appending it.
+func add(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	last := path[len(path)-1]
+	return update(doc, path[:len(path)-1], func(parent any) (any, error) {
+		switch parent := parent.(type) {
+		case map[string]any:
+			parent[last] = value
+			return parent, nil
+		case []any:
+			if last == "-" {
+				return append(parent, value), nil
+			}
+			index, ok := arrayIndex(last, len(parent)+1)
+			if !ok {
+				return nil, fmt.Errorf("index %s out of range", last)
+			}
+			parent = append(parent, nil)
+			copy(parent[index+1:], parent[index:])
+			parent[index] = value
+			return parent, nil
+		}
+		return nil, errors.New("cannot add a member to a scalar")
+	})
+}
+
+func remove(doc any, path []string) (any, error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot remove the whole document")
+	}
+	last := path[len(path)-1]
+	return update(doc, path[:len(path)-1], func(parent any) (any, error) {
+		switch parent := parent.(type) {
+		case map[string]any:
+			if _, ok := parent[last]; !ok {
+				return nil, fmt.Errorf("member %q not found", last)
+			}
+			delete(parent, last)
+			return parent, nil
+		case []any:
+			index, ok := arrayIndex(last, len(parent))
+			if !ok {
+				return nil, fmt.Errorf("index %s out of range", last)
+			}
+			return append(parent[:index], parent[index+1:]...), nil
+		}
+		return nil, errors.New("cannot remove a member of a scalar")
+	})
+}
+
+// update replaces the value at the existing steps of doc with what change
+// makes of it, and returns the new document.
+func update(doc any, steps []string, change func(any) (any, error)) (any, error) {
+	if len(steps) == 0 {
+		return change(doc)
+	}
+	switch node := doc.(type) {
+	case map[string]any:
+		if value, ok := node[steps[0]]; ok {
+			changed, err := update(value, steps[1:], change)
+			if err != nil {
+				return nil, err
+			}
+			node[steps[0]] = changed
+			return node, nil
+		}
+	case []any:
+		if index, ok := arrayIndex(steps[0], len(node)); ok {
+			changed, err := update(node[index], steps[1:], change)
+			if err != nil {
+				return nil, err
+			}
+			node[index] = changed
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s not found", ErrPathNotFound, steps[0])
+}
+
+func lookup(doc any, steps []string) (any, bool) {
+	for _, step := range steps {
+		var ok bool
+		if doc, ok = child(doc, step); !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+func child(doc any, step string) (any, bool) {
+	switch node := doc.(type) {
+	case map[string]any:
+		value, ok := node[step]
+		return value, ok
+	case []any:
+		if index, ok := arrayIndex(step, len(node)); ok {
+			return node[index], true
+		}
+	}
+	return nil, false
+}
+
+// arrayIndex parses an index below length, without sign or leading zeros.
+func arrayIndex(step string, length int) (int, bool) {
+	if step == "" || (len(step) > 1 && step[0] == '0') || strings.TrimLeft(step, "0123456789") != "" {
+		return 0, false
+	}
+	index, err := strconv.Atoi(step)
+	return index, err == nil && index < length
+}
+
+// parsePointer splits an RFC 6901 JSON pointer like /user/name into its
+// steps.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	steps := strings.Split(pointer[1:], "/")
+	for i, step := range steps {
+		steps[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(step)
+	}
+	return steps, nil
+}
+
+// decode parses a single JSON value, keeping numbers as they are written.
+func decode(value string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	var v any
+	if err := decoder.Decode(&v); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNotJSON, err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("%w: data after the value", ErrNotJSON)
+	}
+	return v, nil
+}
+
+func encode(v any) (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
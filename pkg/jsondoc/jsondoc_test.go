@@ -0,0 +1,187 @@
+package jsondoc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "root", path: "$", want: []string{}},
+		{name: "members", path: "$.user.name", want: []string{"user", "name"}},
+		{name: "index", path: "$.items[2].id", want: []string{"items", "2", "id"}},
+		{name: "quoted", path: `$["a.b]"].c`, want: []string{"a.b]", "c"}},
+		{name: "no root", path: "user.name", wantErr: true},
+		{name: "empty member", path: "$..name", wantErr: true},
+		{name: "bad index", path: "$[-1]", wantErr: true},
+		{name: "unclosed", path: "$[1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePath(tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	doc := `{"user":{"name":"Alice","age":30.50},"items":[1,{"id":"x"}]}`
+	tests := []struct {
+		name    string
+		path    string
+		want    string
+		wantErr error
+	}{
+		{name: "root", path: "$", want: `{"items":[1,{"id":"x"}],"user":{"age":30.50,"name":"Alice"}}`},
+		{name: "member", path: "$.user.name", want: `"Alice"`},
+		{name: "number kept as written", path: "$.user.age", want: "30.50"},
+		{name: "index", path: "$.items[1].id", want: `"x"`},
+		{name: "missing member", path: "$.user.email", wantErr: ErrPathNotFound},
+		{name: "index out of range", path: "$.items[2]", wantErr: ErrPathNotFound},
+		{name: "member of a scalar", path: "$.user.name.first", wantErr: ErrPathNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Get(doc, tt.path)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := Get("not json", "$")
+	assert.ErrorIs(t, err, ErrNotJSON)
+}
+
+func TestSet(t *testing.T) {
+	doc := `{"user":{"name":"Alice"},"items":[1,2]}`
+	tests := []struct {
+		name     string
+		document string
+		path     string
+		value    string
+		want     string
+		wantErr  error
+	}{
+		{name: "replace member", document: doc, path: "$.user.name", value: `"Bob"`, want: `{"items":[1,2],"user":{"name":"Bob"}}`},
+		{name: "add member", document: doc, path: "$.user.age", value: "30", want: `{"items":[1,2],"user":{"age":30,"name":"Alice"}}`},
+		{name: "replace element", document: doc, path: "$.items[0]", value: "5", want: `{"items":[5,2],"user":{"name":"Alice"}}`},
+		{name: "append element", document: doc, path: "$.items[2]", value: "3", want: `{"items":[1,2,3],"user":{"name":"Alice"}}`},
+		{name: "root creates", path: "$", value: `{"a":1}`, want: `{"a":1}`},
+		{name: "missing document", path: "$.a", value: "1", wantErr: ErrPathNotFound},
+		{name: "missing parent", document: doc, path: "$.profile.name", value: `"x"`, wantErr: ErrPathNotFound},
+		{name: "index past the end", document: doc, path: "$.items[3]", value: "3", wantErr: ErrPathNotFound},
+		{name: "member of a scalar", document: doc, path: "$.user.name.first", value: `"x"`, wantErr: ErrPathNotFound},
+		{name: "invalid value", document: doc, path: "$.user.name", value: "Bob", wantErr: ErrNotJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Set(tt.document, tt.path, tt.value)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+// The examples of RFC 7396, appendix A
+func TestMergePatch(t *testing.T) {
+	tests := []struct {
+		document string
+		patch    string
+		want     string
+	}{
+		{document: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{document: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{document: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{document: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{document: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{document: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{document: `{"a":{"b":"c"}}`, patch: `{"a":{"b":"d","c":null}}`, want: `{"a":{"b":"d"}}`},
+		{document: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{document: `["a","b"]`, patch: `["c","d"]`, want: `["c","d"]`},
+		{document: `{"a":"b"}`, patch: `["c"]`, want: `["c"]`},
+		{document: `{"a":"foo"}`, patch: `null`, want: `null`},
+		{document: `{"a":"foo"}`, patch: `"bar"`, want: `"bar"`},
+		{document: `{"e":null}`, patch: `{"a":1}`, want: `{"a":1,"e":null}`},
+		{document: `[1,2]`, patch: `{"a":"b","c":null}`, want: `{"a":"b"}`},
+		{document: `{}`, patch: `{"a":{"bb":{"ccc":null}}}`, want: `{"a":{"bb":{}}}`},
+		{document: "", patch: `{"a":1}`, want: `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.patch, func(t *testing.T) {
+			got, err := MergePatch(tt.document, tt.patch)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	doc := `{"foo":"bar","list":[1,2],"a/b":{"~c":1}}`
+	tests := []struct {
+		name    string
+		patch   string
+		want    string
+		wantErr error
+	}{
+		{name: "add member", patch: `[{"op":"add","path":"/baz","value":"qux"}]`, want: `{"a/b":{"~c":1},"baz":"qux","foo":"bar","list":[1,2]}`},
+		{name: "insert element", patch: `[{"op":"add","path":"/list/1","value":9}]`, want: `{"a/b":{"~c":1},"foo":"bar","list":[1,9,2]}`},
+		{name: "append element", patch: `[{"op":"add","path":"/list/-","value":3}]`, want: `{"a/b":{"~c":1},"foo":"bar","list":[1,2,3]}`},
+		{name: "remove escaped", patch: `[{"op":"remove","path":"/a~1b/~0c"}]`, want: `{"a/b":{},"foo":"bar","list":[1,2]}`},
+		{name: "replace", patch: `[{"op":"replace","path":"/list/0","value":0}]`, want: `{"a/b":{"~c":1},"foo":"bar","list":[0,2]}`},
+		{name: "move", patch: `[{"op":"move","from":"/foo","path":"/list/0"}]`, want: `{"a/b":{"~c":1},"list":["bar",1,2]}`},
+		{name: "copy", patch: `[{"op":"copy","from":"/list","path":"/copy"},{"op":"add","path":"/copy/-","value":3}]`, want: `{"a/b":{"~c":1},"copy":[1,2,3],"foo":"bar","list":[1,2]}`},
+		{name: "test then replace", patch: `[{"op":"test","path":"/foo","value":"bar"},{"op":"replace","path":"","value":[]}]`, want: `[]`},
+		{name: "failed test", patch: `[{"op":"remove","path":"/foo"},{"op":"test","path":"/list/0","value":2}]`, wantErr: ErrPatchFailed},
+		{name: "remove missing", patch: `[{"op":"remove","path":"/baz"}]`, wantErr: ErrPatchFailed},
+		{name: "replace missing", patch: `[{"op":"replace","path":"/baz","value":1}]`, wantErr: ErrPatchFailed},
+		{name: "add past the end", patch: `[{"op":"add","path":"/list/3","value":3}]`, wantErr: ErrPatchFailed},
+		{name: "move into itself", patch: `[{"op":"move","from":"/a~1b","path":"/a~1b/x"}]`, wantErr: ErrPatchFailed},
+		{name: "unknown op", patch: `[{"op":"drop","path":"/foo"}]`, wantErr: ErrPatchFailed},
+		{name: "not an array", patch: `{"op":"remove","path":"/foo"}`, wantErr: ErrNotJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Patch(doc, tt.patch)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	doc := `{"status":"active","age":30,"admin":true,"email":null,"tags":["a","b"]}`
+	tests := []struct {
+		name   string
+		doc    string
+		steps  []string
+		want   string
+		wantOk bool
+	}{
+		{name: "string unquoted", doc: doc, steps: []string{"status"}, want: "active", wantOk: true},
+		{name: "number", doc: doc, steps: []string{"age"}, want: "30", wantOk: true},
+		{name: "boolean", doc: doc, steps: []string{"admin"}, want: "true", wantOk: true},
+		{name: "array as JSON", doc: doc, steps: []string{"tags"}, want: `["a","b"]`, wantOk: true},
+		{name: "element", doc: doc, steps: []string{"tags", "1"}, want: "b", wantOk: true},
+		{name: "null", doc: doc, steps: []string{"email"}},
+		{name: "missing", doc: doc, steps: []string{"phone"}},
+		{name: "not JSON", doc: "active", steps: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Text(tt.doc, tt.steps)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+)
+
+// The indexes are expression indexes of the table, see database.Indexer.
+
+func (p *Postgres) CreateIndex(name, path string) error {
+
+	if _, err := database.CheckIndex(name, path); err != nil {
+		return err
+	}
+
+	if err := p.client.CreateIndexPostgresRow(name, path); err != nil {
+		return indexError("create postgres index", err)
+	}
+	return nil
+}
+
+func (p *Postgres) DropIndex(name string) error {
+
+	if err := p.client.DropIndexPostgresRow(name); err != nil {
+		return indexError("drop postgres index", err)
+	}
+	return nil
+}
+
+func (p *Postgres) Indexes() ([]database.Index, error) {
+
+	indexes, err := p.client.IndexesPostgresRows()
+	if err != nil {
+		return nil, indexError("list postgres indexes", err)
+	}
+	converted := make([]database.Index, len(indexes))
+	for i, index := range indexes {
+		converted[i] = database.Index(index)
+	}
+	return converted, nil
+}
+
+func (p *Postgres) Query(index, value string) ([]string, error) {
+
+	keys, err := p.client.QueryPostgresRows(index, value)
+	if err != nil {
+		return nil, indexError("query postgres index", err)
+	}
+	return keys, nil
+}
+
+// indexError converts the index errors of the client to the ones of
+// database.Indexer, keeping their message.
+func indexError(action string, err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrIndexNotFound):
+		return fmt.Errorf("%w%s", database.ErrIndexNotFound, strings.TrimPrefix(err.Error(), postgres.ErrIndexNotFound.Error()))
+	case errors.Is(err, postgres.ErrIndexExists):
+		return fmt.Errorf("%w%s", database.ErrIndexExists, strings.TrimPrefix(err.Error(), postgres.ErrIndexExists.Error()))
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+)
+
+// The documents are changed by JSONB functions in the database, see
+// database.JSONStore.
+
+func (p *Postgres) JSONGet(key, path string) (string, error) {
+
+	if key == "" {
+		return "", fmt.Errorf("key cannot be empty")
+	}
+
+	value, err := p.client.JSONGetPostgresRow(key, path)
+	if err != nil {
+		return "", jsonError("get postgres JSON", err)
+	}
+	return value, nil
+}
+
+func (p *Postgres) JSONSet(key, path, value string) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	if err := p.client.JSONSetPostgresRow(key, path, value); err != nil {
+		return jsonError("set postgres JSON", err)
+	}
+	return nil
+}
+
+func (p *Postgres) JSONMerge(key, patch string) (string, error) {
+
+	if key == "" {
+		return "", fmt.Errorf("key cannot be empty")
+	}
+
+	document, err := p.client.JSONMergePostgresRow(key, patch)
+	if err != nil {
+		return "", jsonError("merge postgres JSON", err)
+	}
+	return document, nil
+}
+
+func (p *Postgres) JSONPatch(key, patch string) (string, error) {
+
+	if key == "" {
+		return "", fmt.Errorf("key cannot be empty")
+	}
+
+	document, err := p.client.JSONPatchPostgresRow(key, patch)
+	if err != nil {
+		return "", jsonError("patch postgres JSON", err)
+	}
+	return document, nil
+}
+
+// jsonError converts a missing key or path to database.ErrNotFound, keeping
+// its message. The client returns the other errors of database.JSONStore.
+func jsonError(action string, err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNotFound):
+		return fmt.Errorf("%w%s", database.ErrNotFound, strings.TrimPrefix(err.Error(), postgres.ErrNotFound.Error()))
+	case errors.Is(err, database.ErrNotJSON), errors.Is(err, database.ErrPatchFailed):
+		return err
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
@@ -0,0 +1,74 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+)
+
+// Every namespace is a table of its own next to the one of the store, see
+// database.NamespaceStore.
+
+func (p *Postgres) Namespace(name string) (database.Database, error) {
+
+	if err := database.CheckNamespace(name); err != nil {
+		return nil, err
+	}
+
+	client, err := p.client.Namespace(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open postgres namespace: %w", err)
+	}
+	return &Postgres{client: client}, nil
+}
+
+func (p *Postgres) Namespaces() ([]database.Namespace, error) {
+
+	namespaces, err := p.client.NamespacesPostgresRows()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list postgres namespaces: %w", err)
+	}
+	converted := make([]database.Namespace, len(namespaces))
+	for i, namespace := range namespaces {
+		converted[i] = database.Namespace(namespace)
+	}
+	return converted, nil
+}
+
+func (p *Postgres) SetNamespaceTTL(name string, ttl time.Duration) error {
+
+	if err := database.CheckNamespace(name); err != nil {
+		return err
+	}
+
+	if err := p.client.SetNamespaceTTLPostgresRow(name, ttl); err != nil {
+		return fmt.Errorf("failed to set postgres namespace TTL: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) DropNamespace(name string) error {
+
+	err := p.client.DropNamespacePostgresRow(name)
+	if errors.Is(err, postgres.ErrNamespaceNotFound) {
+		return fmt.Errorf("%w%s", database.ErrNamespaceNotFound, strings.TrimPrefix(err.Error(), postgres.ErrNamespaceNotFound.Error()))
+	} else if err != nil {
+		return fmt.Errorf("failed to drop postgres namespace: %w", err)
+	}
+	return nil
+}
+
+// Expire deletes the rows last written before the given time, see
+// database.Expirer.
+func (p *Postgres) Expire(before time.Time) (int, error) {
+
+	expired, err := p.client.ExpirePostgresRows(before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire postgres rows: %w", err)
+	}
+	return expired, nil
+}
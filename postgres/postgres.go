@@ -1,9 +1,15 @@
 package postgres
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/imsumedhaa/In-memory-database/database"
 	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
 	_ "github.com/lib/pq"
 )
@@ -12,9 +18,9 @@ type Postgres struct {
 	client postgres.Client
 }
 
-func NewPostgres(host, port, username, password, dbname string) (*Postgres, error) {
+func NewPostgres(cfg postgres.Config) (*Postgres, error) {
 
-	dbClient, err := postgres.NewClient(host, port, username, password, dbname)
+	dbClient, err := postgres.NewClient(cfg)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect %w", err)
@@ -96,3 +102,248 @@ func (p *Postgres) Exit() error {
 	os.Exit(0)
 	return nil
 }
+
+func (p *Postgres) MultiGet(keys []string) (map[string]string, error) {
+
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("key cannot be empty")
+		}
+	}
+
+	store, err := p.client.MultiGetPostgresRows(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get postgres rows: %w", err)
+	}
+	return store, nil
+}
+
+func (p *Postgres) MultiSet(pairs map[string]string) error {
+
+	for key := range pairs {
+		if key == "" {
+			return fmt.Errorf("key cannot be empty")
+		}
+	}
+
+	if err := p.client.MultiSetPostgresRows(pairs); err != nil {
+		return fmt.Errorf("failed to set postgres rows: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) MultiDelete(keys []string) ([]string, error) {
+
+	for _, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("key cannot be empty")
+		}
+	}
+
+	deleted, err := p.client.MultiDeletePostgresRows(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete postgres rows: %w", err)
+	}
+	return deleted, nil
+}
+
+func (p *Postgres) Keys(prefix string) ([]string, error) {
+
+	keys, err := p.client.KeysPostgresRows(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list postgres keys: %w", err)
+	}
+	return keys, nil
+}
+
+// BulkLoad loads the pairs with COPY, which is much faster than MultiSet for
+// imports of thousands of rows.
+func (p *Postgres) BulkLoad(pairs map[string]string) error {
+
+	for key := range pairs {
+		if key == "" {
+			return fmt.Errorf("key cannot be empty")
+		}
+	}
+
+	if err := p.client.CopyPostgresRows(pairs); err != nil {
+		return fmt.Errorf("failed to copy postgres rows: %w", err)
+	}
+	return nil
+}
+
+// Reconnect switches to new credentials, see database.Reconnecter.
+func (p *Postgres) Reconnect(username, password string) error {
+	if err := p.client.Reconnect(username, password); err != nil {
+		return fmt.Errorf("failed to reconnect to postgres: %w", err)
+	}
+	return nil
+}
+
+// Stat returns the metadata of key, see database.MetadataStore.
+func (p *Postgres) Stat(key string) (database.Metadata, error) {
+
+	if key == "" {
+		return database.Metadata{}, fmt.Errorf("key cannot be empty")
+	}
+
+	meta, err := p.client.StatPostgresRow(key)
+	if errors.Is(err, postgres.ErrNotFound) {
+		return database.Metadata{}, database.ErrNotFound
+	} else if err != nil {
+		return database.Metadata{}, fmt.Errorf("failed to stat postgres row: %w", err)
+	}
+	return database.Metadata(meta), nil
+}
+
+func (p *Postgres) SetMetadata(key, contentType string, tags []string) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	err := p.client.SetMetadataPostgresRow(key, contentType, tags)
+	if errors.Is(err, postgres.ErrNotFound) {
+		return database.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to set postgres metadata: %w", err)
+	}
+	return nil
+}
+
+// History returns the versions of key, see database.HistoryStore.
+func (p *Postgres) History(key string, limit int) ([]database.Version, error) {
+
+	if key == "" {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+
+	versions, err := p.client.HistoryPostgresRows(key, limit)
+	if errors.Is(err, postgres.ErrNotFound) {
+		return nil, database.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get postgres history: %w", err)
+	}
+
+	history := make([]database.Version, len(versions))
+	for i, version := range versions {
+		history[i] = database.Version(version)
+	}
+	return history, nil
+}
+
+func (p *Postgres) GetAt(key string, at time.Time) (string, error) {
+
+	if key == "" {
+		return "", fmt.Errorf("key cannot be empty")
+	}
+
+	value, err := p.client.GetAtPostgresRow(key, at)
+	if errors.Is(err, postgres.ErrNotFound) {
+		return "", database.ErrNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get postgres row: %w", err)
+	}
+	return value, nil
+}
+
+// Undelete restores a tombstoned key, see database.Undeleter.
+func (p *Postgres) Undelete(key string) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	err := p.client.UndeletePostgresRow(key)
+	if errors.Is(err, postgres.ErrNotFound) {
+		return database.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to restore postgres row: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) PurgeTombstones(before time.Time) (int, error) {
+
+	purged, err := p.client.PurgeTombstonesPostgresRows(before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge postgres tombstones: %w", err)
+	}
+	return purged, nil
+}
+
+func (p *Postgres) ReadValue(key string, w io.Writer) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	err := p.client.ReadStreamPostgresRow(key, w)
+	if errors.Is(err, postgres.ErrNotFound) {
+		return database.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to read postgres value: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) WriteValue(key string, r io.Reader) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	if err := p.client.WriteStreamPostgresRow(key, r); err != nil {
+		return fmt.Errorf("failed to write postgres value: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) IncrBy(key string, delta int64) (int64, error) {
+
+	if key == "" {
+		return 0, fmt.Errorf("key cannot be empty")
+	}
+
+	result, err := p.client.IncrByPostgresRow(key, delta)
+	if err != nil {
+		return 0, counterError(err)
+	}
+	return result, nil
+}
+
+func (p *Postgres) IncrByFloat(key string, delta float64) (float64, error) {
+
+	if key == "" {
+		return 0, fmt.Errorf("key cannot be empty")
+	}
+
+	result, err := p.client.IncrByFloatPostgresRow(key, delta)
+	if err != nil {
+		return 0, counterError(err)
+	}
+	return result, nil
+}
+
+// counterError converts the counter errors of the client to the ones of
+// database.Counter, keeping their message.
+func counterError(err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNotNumber):
+		return fmt.Errorf("%w%s", database.ErrNotNumber, strings.TrimPrefix(err.Error(), postgres.ErrNotNumber.Error()))
+	case errors.Is(err, postgres.ErrOverflow):
+		return database.ErrOverflow
+	case errors.Is(err, postgres.ErrWrongType):
+		return fmt.Errorf("%w%s", database.ErrWrongType, strings.TrimPrefix(err.Error(), postgres.ErrWrongType.Error()))
+	}
+	return fmt.Errorf("failed to increment postgres row: %w", err)
+}
+
+// ListenChanges reports the keys written by any client of the table, see
+// database.ChangeListener.
+func (p *Postgres) ListenChanges(ctx context.Context, changed func(key string)) error {
+	if err := p.client.ListenPostgresChanges(ctx, changed); err != nil {
+		return fmt.Errorf("failed to listen for postgres changes: %w", err)
+	}
+	return nil
+}
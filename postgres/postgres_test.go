@@ -1,11 +1,20 @@
 package postgres
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
 	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestPostgres_Create(t *testing.T) {
@@ -266,3 +275,1291 @@ func TestPostgres_Show(t *testing.T) {
 		})
 	}
 }
+
+func TestPostgres_MultiGet(t *testing.T) {
+	tests := []struct {
+		name           string
+		keys           []string
+		mockFunc       func(m *mocks.Client)
+		expectedError  string
+		expectedValues map[string]string
+	}{
+		{
+			name:          "Empty Key",
+			keys:          []string{"Hello", ""},
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "MultiGet Failure",
+			keys: []string{"Hello"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("MultiGetPostgresRows", []string{"Hello"}).Return(nil, errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to get postgres rows: db error",
+		},
+		{
+			name: "MultiGet Success",
+			keys: []string{"Hello", "Missing"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("MultiGetPostgresRows", []string{"Hello", "Missing"}).Return(map[string]string{"Hello": "World"}, nil).Times(1)
+			},
+			expectedValues: map[string]string{"Hello": "World"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			values, err := db.MultiGet(tt.keys)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedValues, values)
+
+			mockClient.AssertExpectations(t)
+
+		})
+	}
+}
+
+func TestPostgres_MultiSet(t *testing.T) {
+	tests := []struct {
+		name          string
+		pairs         map[string]string
+		mockFunc      func(m *mocks.Client)
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			pairs:         map[string]string{"": "World"},
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name:  "MultiSet Failure",
+			pairs: map[string]string{"Hello": "World"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("MultiSetPostgresRows", map[string]string{"Hello": "World"}).Return(errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to set postgres rows: db error",
+		},
+		{
+			name:  "MultiSet Success",
+			pairs: map[string]string{"Hello": "World"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("MultiSetPostgresRows", map[string]string{"Hello": "World"}).Return(nil).Times(1)
+			},
+			expectedError: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			err := db.MultiSet(tt.pairs)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+
+		})
+	}
+}
+
+func TestPostgres_MultiDelete(t *testing.T) {
+	tests := []struct {
+		name            string
+		keys            []string
+		mockFunc        func(m *mocks.Client)
+		expectedError   string
+		expectedDeleted []string
+	}{
+		{
+			name:          "Empty Key",
+			keys:          []string{""},
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "MultiDelete Failure",
+			keys: []string{"Hello"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("MultiDeletePostgresRows", []string{"Hello"}).Return(nil, errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to delete postgres rows: db error",
+		},
+		{
+			name: "MultiDelete Success",
+			keys: []string{"Hello", "Missing"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("MultiDeletePostgresRows", []string{"Hello", "Missing"}).Return([]string{"Hello"}, nil).Times(1)
+			},
+			expectedDeleted: []string{"Hello"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			deleted, err := db.MultiDelete(tt.keys)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedDeleted, deleted)
+
+			mockClient.AssertExpectations(t)
+
+		})
+	}
+}
+
+func TestPostgres_Keys(t *testing.T) {
+	tests := []struct {
+		name          string
+		prefix        string
+		mockFunc      func(m *mocks.Client)
+		expectedError string
+		expectedKeys  []string
+	}{
+		{
+			name:   "Keys Failure",
+			prefix: "user:",
+			mockFunc: func(m *mocks.Client) {
+				m.On("KeysPostgresRows", "user:").Return(nil, errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to list postgres keys: db error",
+		},
+		{
+			name:   "Keys Success",
+			prefix: "user:",
+			mockFunc: func(m *mocks.Client) {
+				m.On("KeysPostgresRows", "user:").Return([]string{"user:1", "user:2"}, nil).Times(1)
+			},
+			expectedKeys: []string{"user:1", "user:2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			keys, err := db.Keys(tt.prefix)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedKeys, keys)
+
+			mockClient.AssertExpectations(t)
+
+		})
+	}
+}
+
+func TestPostgres_BulkLoad(t *testing.T) {
+	tests := []struct {
+		name          string
+		pairs         map[string]string
+		mockFunc      func(m *mocks.Client)
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			pairs:         map[string]string{"": "World"},
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name:  "Copy Failure",
+			pairs: map[string]string{"Hello": "World"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("CopyPostgresRows", map[string]string{"Hello": "World"}).Return(errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to copy postgres rows: db error",
+		},
+		{
+			name:  "Copy Success",
+			pairs: map[string]string{"Hello": "World"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("CopyPostgresRows", map[string]string{"Hello": "World"}).Return(nil).Times(1)
+			},
+			expectedError: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			err := db.BulkLoad(tt.pairs)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+
+		})
+	}
+}
+
+func TestPostgres_Reconnect(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockFunc      func(m *mocks.Client)
+		expectedError string
+	}{
+		{
+			name: "Reconnect Failure",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Reconnect", "admin", "rotated").Return(errors.New("password authentication failed")).Times(1)
+			},
+			expectedError: "failed to reconnect to postgres: password authentication failed",
+		},
+		{
+			name: "Reconnect Success",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Reconnect", "admin", "rotated").Return(nil).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			err := db.Reconnect("admin", "rotated")
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostgres_Stat(t *testing.T) {
+	updated := time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expectedMeta  database.Metadata
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Stat Not Found",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "Hello").Return(postgres.Metadata{}, postgres.ErrNotFound).Times(1)
+			},
+			expectedError: "key not found",
+		},
+		{
+			name: "Stat Failure",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "Hello").Return(postgres.Metadata{}, errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to stat postgres row: db error",
+		},
+		{
+			name: "Stat Success",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "Hello").Return(postgres.Metadata{UpdatedAt: updated, Tags: []string{"a"}}, nil).Times(1)
+			},
+			expectedMeta: database.Metadata{UpdatedAt: updated, Tags: []string{"a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			meta, err := db.Stat(tt.key)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedMeta, meta)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostgres_SetMetadata(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "SetMetadata Not Found",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("SetMetadataPostgresRow", "Hello", "text/plain", []string{"a"}).Return(postgres.ErrNotFound).Times(1)
+			},
+			expectedError: "key not found",
+		},
+		{
+			name: "SetMetadata Success",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("SetMetadataPostgresRow", "Hello", "text/plain", []string{"a"}).Return(nil).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			err := db.SetMetadata(tt.key, "text/plain", []string{"a"})
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostgres_History(t *testing.T) {
+	written := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	replaced := time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)
+	tests := []struct {
+		name             string
+		key              string
+		limit            int
+		mockFunc         func(m *mocks.Client)
+		expectedVersions []database.Version
+		expectedError    string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "History Not Found",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("HistoryPostgresRows", "Hello", 0).Return(nil, postgres.ErrNotFound).Times(1)
+			},
+			expectedError: "key not found",
+		},
+		{
+			name: "History Failure",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("HistoryPostgresRows", "Hello", 0).Return(nil, errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to get postgres history: db error",
+		},
+		{
+			name:  "History Success",
+			key:   "Hello",
+			limit: 2,
+			mockFunc: func(m *mocks.Client) {
+				m.On("HistoryPostgresRows", "Hello", 2).Return([]postgres.Version{
+					{Version: 2, Value: "World", WrittenAt: replaced},
+					{Version: 1, Value: "Earth", WrittenAt: written, ReplacedAt: replaced},
+				}, nil).Times(1)
+			},
+			expectedVersions: []database.Version{
+				{Version: 2, Value: "World", WrittenAt: replaced},
+				{Version: 1, Value: "Earth", WrittenAt: written, ReplacedAt: replaced},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			versions, err := db.History(tt.key, tt.limit)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedVersions, versions)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostgres_GetAt(t *testing.T) {
+	at := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expectedValue string
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "GetAt Not Found",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("GetAtPostgresRow", "Hello", at).Return("", postgres.ErrNotFound).Times(1)
+			},
+			expectedError: "key not found",
+		},
+		{
+			name: "GetAt Success",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("GetAtPostgresRow", "Hello", at).Return("Earth", nil).Times(1)
+			},
+			expectedValue: "Earth",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			value, err := db.GetAt(tt.key, at)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedValue, value)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostgres_Undelete(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Undelete Not Found",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("UndeletePostgresRow", "Hello").Return(postgres.ErrNotFound).Times(1)
+			},
+			expectedError: "key not found",
+		},
+		{
+			name: "Undelete Failure",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("UndeletePostgresRow", "Hello").Return(errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to restore postgres row: db error",
+		},
+		{
+			name: "Undelete Success",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("UndeletePostgresRow", "Hello").Return(nil).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			err := db.Undelete(tt.key)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostgres_ReadValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expected      string
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Read Not Found",
+			key:  "logo",
+			mockFunc: func(m *mocks.Client) {
+				m.On("ReadStreamPostgresRow", "logo", mock.Anything).Return(postgres.ErrNotFound).Times(1)
+			},
+			expectedError: "key not found",
+		},
+		{
+			name: "Read Failure",
+			key:  "logo",
+			mockFunc: func(m *mocks.Client) {
+				m.On("ReadStreamPostgresRow", "logo", mock.Anything).Return(errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to read postgres value: db error",
+		},
+		{
+			name: "Read Success",
+			key:  "logo",
+			mockFunc: func(m *mocks.Client) {
+				m.On("ReadStreamPostgresRow", "logo", mock.Anything).Run(func(args mock.Arguments) {
+					io.WriteString(args.Get(1).(io.Writer), "\x89PNG\x00")
+				}).Return(nil).Times(1)
+			},
+			expected: "\x89PNG\x00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			var buf bytes.Buffer
+			err := db.ReadValue(tt.key, &buf)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, buf.String())
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostgres_WriteValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Write Failure",
+			key:  "logo",
+			mockFunc: func(m *mocks.Client) {
+				m.On("WriteStreamPostgresRow", "logo", mock.Anything).Return(errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to write postgres value: db error",
+		},
+		{
+			name: "Write Success",
+			key:  "logo",
+			mockFunc: func(m *mocks.Client) {
+				m.On("WriteStreamPostgresRow", "logo", mock.Anything).Return(nil).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			err := db.WriteValue(tt.key, strings.NewReader("\x89PNG\x00"))
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostgres_IncrBy(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expected      int64
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Not A Number",
+			key:  "hits",
+			mockFunc: func(m *mocks.Client) {
+				err := fmt.Errorf("%w: invalid input syntax for type bigint", postgres.ErrNotNumber)
+				m.On("IncrByPostgresRow", "hits", int64(2)).Return(int64(0), err).Times(1)
+			},
+			expectedError: "value is not a number: invalid input syntax for type bigint",
+		},
+		{
+			name: "Overflow",
+			key:  "hits",
+			mockFunc: func(m *mocks.Client) {
+				m.On("IncrByPostgresRow", "hits", int64(2)).Return(int64(0), postgres.ErrOverflow).Times(1)
+			},
+			expectedError: "increment would overflow",
+		},
+		{
+			name: "Increment Failure",
+			key:  "hits",
+			mockFunc: func(m *mocks.Client) {
+				m.On("IncrByPostgresRow", "hits", int64(2)).Return(int64(0), errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to increment postgres row: db error",
+		},
+		{
+			name: "Increment Success",
+			key:  "hits",
+			mockFunc: func(m *mocks.Client) {
+				m.On("IncrByPostgresRow", "hits", int64(2)).Return(int64(7), nil).Times(1)
+			},
+			expected: 7,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			result, err := db.IncrBy(tt.key, 2)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPostgres_ZAdd(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expected      bool
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Add Failure",
+			key:  "board",
+			mockFunc: func(m *mocks.Client) {
+				m.On("ZAddPostgresRow", "board", "alice", 12.5).Return(false, errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to add to postgres sorted set: db error",
+		},
+		{
+			name: "Key Holds A String",
+			key:  "board",
+			mockFunc: func(m *mocks.Client) {
+				m.On("ZAddPostgresRow", "board", "alice", 12.5).Return(false, fmt.Errorf("%w: board holds a string", postgres.ErrWrongType)).Times(1)
+			},
+			expectedError: "WRONGTYPE operation against a key holding the wrong kind of value: board holds a string",
+		},
+		{
+			name: "Add Success",
+			key:  "board",
+			mockFunc: func(m *mocks.Client) {
+				m.On("ZAddPostgresRow", "board", "alice", 12.5).Return(true, nil).Times(1)
+			},
+			expected: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			added, err := db.ZAdd(tt.key, "alice", 12.5)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, added)
+			}
+		})
+	}
+}
+
+func TestPostgres_Type(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockFunc      func(m *mocks.Client)
+		expected      string
+		expectedError string
+	}{
+		{
+			name: "String",
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "board").Return(postgres.Metadata{Version: 1}, nil).Times(1)
+			},
+			expected: database.TypeString,
+		},
+		{
+			name: "Sorted Set",
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "board").Return(postgres.Metadata{}, postgres.ErrNotFound).Times(1)
+				m.On("ZCardPostgresRows", "board").Return(2, nil).Times(1)
+			},
+			expected: database.TypeZSet,
+		},
+		{
+			name: "Missing",
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "board").Return(postgres.Metadata{}, postgres.ErrNotFound).Times(1)
+				m.On("ZCardPostgresRows", "board").Return(0, nil).Times(1)
+			},
+			expected: database.TypeNone,
+		},
+		{
+			name: "Stat Failure",
+			mockFunc: func(m *mocks.Client) {
+				m.On("StatPostgresRow", "board").Return(postgres.Metadata{}, errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to stat postgres row: db error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			kind, err := db.Type("board")
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, kind)
+			}
+		})
+	}
+}
+
+func TestPostgres_ZRank(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	mockClient.On("ZRankPostgresRow", "board", "alice").Return(3, nil).Times(1)
+	mockClient.On("ZRankPostgresRow", "board", "erin").Return(0, postgres.ErrNotFound).Times(1)
+	db := &Postgres{client: mockClient}
+
+	rank, err := db.ZRank("board", "alice")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, rank)
+
+	_, err = db.ZRank("board", "erin")
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestPostgres_ZRange(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	mockClient.On("ZRangePostgresRows", "board", 0, -1).
+		Return([]postgres.ScoredMember{{Member: "bob", Score: 1}, {Member: "alice", Score: 2}}, nil).Times(1)
+	mockClient.On("ZRangeByScorePostgresRows", "board", 5.0, 10.0).Return([]postgres.ScoredMember{}, nil).Times(1)
+	db := &Postgres{client: mockClient}
+
+	members, err := db.ZRange("board", 0, -1)
+	assert.NoError(t, err)
+	assert.Equal(t, []database.ScoredMember{{Member: "bob", Score: 1}, {Member: "alice", Score: 2}}, members)
+
+	members, err = db.ZRangeByScore("board", 5, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, members)
+}
+
+func TestPostgres_JSONGet(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expected      string
+		expectedErr   error
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Missing Path",
+			key:  "user",
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONGetPostgresRow", "user", "$.name").Return("", fmt.Errorf("%w: JSON path not found: $.name", postgres.ErrNotFound)).Times(1)
+			},
+			expectedErr:   database.ErrNotFound,
+			expectedError: "key not found: JSON path not found: $.name",
+		},
+		{
+			name: "Not JSON",
+			key:  "user",
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONGetPostgresRow", "user", "$.name").Return("", postgres.ErrNotJSON).Times(1)
+			},
+			expectedErr:   database.ErrNotJSON,
+			expectedError: "value is not a JSON document",
+		},
+		{
+			name: "Get Failure",
+			key:  "user",
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONGetPostgresRow", "user", "$.name").Return("", errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to get postgres JSON: db error",
+		},
+		{
+			name: "Get Success",
+			key:  "user",
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONGetPostgresRow", "user", "$.name").Return(`"Alice"`, nil).Times(1)
+			},
+			expected: `"Alice"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			value, err := db.JSONGet(tt.key, "$.name")
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, value)
+			}
+		})
+	}
+}
+
+func TestPostgres_JSONPatch(t *testing.T) {
+	patch := `[{"op":"remove","path":"/email"}]`
+	tests := []struct {
+		name          string
+		mockFunc      func(m *mocks.Client)
+		expected      string
+		expectedErr   error
+		expectedError string
+	}{
+		{
+			name: "Patch Failed",
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONPatchPostgresRow", "user", patch).Return("", fmt.Errorf("%w: operation 0: /email not found", postgres.ErrPatchFailed)).Times(1)
+			},
+			expectedErr:   database.ErrPatchFailed,
+			expectedError: "JSON patch cannot be applied: operation 0: /email not found",
+		},
+		{
+			name: "Missing Key",
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONPatchPostgresRow", "user", patch).Return("", postgres.ErrNotFound).Times(1)
+			},
+			expectedErr:   database.ErrNotFound,
+			expectedError: "key not found",
+		},
+		{
+			name: "Patch Success",
+			mockFunc: func(m *mocks.Client) {
+				m.On("JSONPatchPostgresRow", "user", patch).Return(`{"name": "Alice"}`, nil).Times(1)
+			},
+			expected: `{"name": "Alice"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			document, err := db.JSONPatch("user", patch)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, document)
+			}
+		})
+	}
+}
+
+func TestPostgres_CreateIndex(t *testing.T) {
+	tests := []struct {
+		name          string
+		index         string
+		path          string
+		mockFunc      func(m *mocks.Client)
+		expectedErr   error
+		expectedError string
+	}{
+		{
+			name:          "Invalid Name",
+			index:         "Status",
+			path:          "$.status",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: `invalid index name "Status": use up to 32 lowercase letters, digits and underscores, starting with a letter`,
+		},
+		{
+			name:          "Invalid Path",
+			index:         "status",
+			path:          "status",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: `invalid JSON path "status": it must start with $`,
+		},
+		{
+			name:  "Taken",
+			index: "status",
+			path:  "$.status",
+			mockFunc: func(m *mocks.Client) {
+				m.On("CreateIndexPostgresRow", "status", "$.status").Return(fmt.Errorf("%w: status is on $.state", postgres.ErrIndexExists)).Times(1)
+			},
+			expectedErr:   database.ErrIndexExists,
+			expectedError: "index already exists: status is on $.state",
+		},
+		{
+			name:  "Create Success",
+			index: "status",
+			path:  "$.status",
+			mockFunc: func(m *mocks.Client) {
+				m.On("CreateIndexPostgresRow", "status", "$.status").Return(nil).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			err := db.CreateIndex(tt.index, tt.path)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPostgres_Query(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockFunc      func(m *mocks.Client)
+		expected      []string
+		expectedErr   error
+		expectedError string
+	}{
+		{
+			name: "Missing Index",
+			mockFunc: func(m *mocks.Client) {
+				m.On("QueryPostgresRows", "status", "active").Return(nil, fmt.Errorf("%w: status", postgres.ErrIndexNotFound)).Times(1)
+			},
+			expectedErr:   database.ErrIndexNotFound,
+			expectedError: "index not found: status",
+		},
+		{
+			name: "Query Failure",
+			mockFunc: func(m *mocks.Client) {
+				m.On("QueryPostgresRows", "status", "active").Return(nil, errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to query postgres index: db error",
+		},
+		{
+			name: "Query Success",
+			mockFunc: func(m *mocks.Client) {
+				m.On("QueryPostgresRows", "status", "active").Return([]string{"user:1", "user:2"}, nil).Times(1)
+			},
+			expected: []string{"user:1", "user:2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			keys, err := db.Query("status", "active")
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, keys)
+			}
+		})
+	}
+}
+
+func TestPostgres_Namespace(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	namespaceClient := mocks.NewClient(t)
+	mockClient.On("Namespace", "billing").Return(namespaceClient, nil).Times(1)
+	namespaceClient.On("GetPostgresRow", "invoice").Return("paid", nil).Times(1)
+
+	db := &Postgres{client: mockClient}
+
+	billing, err := db.Namespace("billing")
+	assert.NoError(t, err)
+	assert.NoError(t, billing.Get("invoice"), "the keys are read from the namespace")
+
+	_, err = db.Namespace("Bad-Name")
+	assert.ErrorContains(t, err, "invalid namespace name")
+}
+
+func TestPostgres_DropNamespace(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockFunc      func(m *mocks.Client)
+		expectedErr   error
+		expectedError string
+	}{
+		{
+			name: "Missing Namespace",
+			mockFunc: func(m *mocks.Client) {
+				m.On("DropNamespacePostgresRow", "billing").Return(fmt.Errorf("%w: billing", postgres.ErrNamespaceNotFound)).Times(1)
+			},
+			expectedErr:   database.ErrNamespaceNotFound,
+			expectedError: "namespace not found: billing",
+		},
+		{
+			name: "Drop Failure",
+			mockFunc: func(m *mocks.Client) {
+				m.On("DropNamespacePostgresRow", "billing").Return(errors.New("db error")).Times(1)
+			},
+			expectedError: "failed to drop postgres namespace: db error",
+		},
+		{
+			name: "Drop Success",
+			mockFunc: func(m *mocks.Client) {
+				m.On("DropNamespacePostgresRow", "billing").Return(nil).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			err := db.DropNamespace("billing")
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPostgres_ListenChanges(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockFunc      func(m *mocks.Client)
+		expectedKeys  []string
+		expectedError string
+	}{
+		{
+			name: "Listen Failure",
+			mockFunc: func(m *mocks.Client) {
+				m.On("ListenPostgresChanges", mock.Anything, mock.Anything).Return(errors.New("connection refused")).Times(1)
+			},
+			expectedError: "failed to listen for postgres changes: connection refused",
+		},
+		{
+			name: "Listen Success",
+			mockFunc: func(m *mocks.Client) {
+				m.On("ListenPostgresChanges", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					changed := args.Get(1).(func(string))
+					changed("user:1")
+					changed("")
+				}).Return(nil).Times(1)
+			},
+			expectedKeys: []string{"user:1", ""},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Postgres{client: mockClient}
+
+			var keys []string
+			err := db.ListenChanges(context.Background(), func(key string) { keys = append(keys, key) })
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedKeys, keys)
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/postgres"
+)
+
+// The sorted sets are kept in a table of their own, see
+// database.SortedSetStore.
+
+// Type tells the strings from the sorted sets, see database.Typer.
+func (p *Postgres) Type(key string) (string, error) {
+
+	if key == "" {
+		return "", fmt.Errorf("key cannot be empty")
+	}
+
+	_, err := p.client.StatPostgresRow(key)
+	if err == nil {
+		return database.TypeString, nil
+	} else if !errors.Is(err, postgres.ErrNotFound) {
+		return "", fmt.Errorf("failed to stat postgres row: %w", err)
+	}
+	count, err := p.client.ZCardPostgresRows(key)
+	if err != nil {
+		return "", sortedSetError("count postgres sorted set", err)
+	}
+	if count > 0 {
+		return database.TypeZSet, nil
+	}
+	return database.TypeNone, nil
+}
+
+func (p *Postgres) ZAdd(key, member string, score float64) (bool, error) {
+
+	if key == "" {
+		return false, fmt.Errorf("key cannot be empty")
+	}
+
+	added, err := p.client.ZAddPostgresRow(key, member, score)
+	if err != nil {
+		return false, sortedSetError("add to postgres sorted set", err)
+	}
+	return added, nil
+}
+
+func (p *Postgres) ZIncrBy(key, member string, delta float64) (float64, error) {
+
+	if key == "" {
+		return 0, fmt.Errorf("key cannot be empty")
+	}
+
+	score, err := p.client.ZIncrByPostgresRow(key, member, delta)
+	if err != nil {
+		return 0, sortedSetError("increment postgres sorted set", err)
+	}
+	return score, nil
+}
+
+func (p *Postgres) ZScore(key, member string) (float64, error) {
+
+	if key == "" {
+		return 0, fmt.Errorf("key cannot be empty")
+	}
+
+	score, err := p.client.ZScorePostgresRow(key, member)
+	if err != nil {
+		return 0, sortedSetError("get postgres score", err)
+	}
+	return score, nil
+}
+
+func (p *Postgres) ZRank(key, member string) (int, error) {
+
+	if key == "" {
+		return 0, fmt.Errorf("key cannot be empty")
+	}
+
+	rank, err := p.client.ZRankPostgresRow(key, member)
+	if err != nil {
+		return 0, sortedSetError("get postgres rank", err)
+	}
+	return rank, nil
+}
+
+func (p *Postgres) ZRem(key string, members ...string) (int, error) {
+
+	if key == "" {
+		return 0, fmt.Errorf("key cannot be empty")
+	}
+
+	removed, err := p.client.ZRemPostgresRows(key, members)
+	if err != nil {
+		return 0, sortedSetError("remove from postgres sorted set", err)
+	}
+	return removed, nil
+}
+
+func (p *Postgres) ZCard(key string) (int, error) {
+
+	if key == "" {
+		return 0, fmt.Errorf("key cannot be empty")
+	}
+
+	count, err := p.client.ZCardPostgresRows(key)
+	if err != nil {
+		return 0, sortedSetError("count postgres sorted set", err)
+	}
+	return count, nil
+}
+
+func (p *Postgres) ZRange(key string, start, stop int) ([]database.ScoredMember, error) {
+
+	if key == "" {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+
+	members, err := p.client.ZRangePostgresRows(key, start, stop)
+	if err != nil {
+		return nil, sortedSetError("get postgres sorted set", err)
+	}
+	return scoredMembers(members), nil
+}
+
+func (p *Postgres) ZRangeByScore(key string, min, max float64) ([]database.ScoredMember, error) {
+
+	if key == "" {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+
+	members, err := p.client.ZRangeByScorePostgresRows(key, min, max)
+	if err != nil {
+		return nil, sortedSetError("get postgres sorted set", err)
+	}
+	return scoredMembers(members), nil
+}
+
+// sortedSetError converts the errors of the client to the ones of
+// database.SortedSetStore.
+func sortedSetError(action string, err error) error {
+	switch {
+	case errors.Is(err, postgres.ErrNotFound):
+		return database.ErrNotFound
+	case errors.Is(err, postgres.ErrNotNumber):
+		return counterError(err)
+	case errors.Is(err, postgres.ErrWrongType):
+		return fmt.Errorf("%w%s", database.ErrWrongType, strings.TrimPrefix(err.Error(), postgres.ErrWrongType.Error()))
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+func scoredMembers(members []postgres.ScoredMember) []database.ScoredMember {
+	converted := make([]database.ScoredMember, len(members))
+	for i, member := range members {
+		converted[i] = database.ScoredMember(member)
+	}
+	return converted
+}
@@ -0,0 +1,63 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/remote"
+)
+
+// Every namespace is served by the server under /ns/{namespace}, see
+// database.NamespaceStore. The server expires their keys itself.
+
+func (r *Remote) Namespace(name string) (database.Database, error) {
+
+	if err := database.CheckNamespace(name); err != nil {
+		return nil, err
+	}
+
+	client, err := r.client.Namespace(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open remote namespace: %w", err)
+	}
+	return &Remote{client: client}, nil
+}
+
+func (r *Remote) Namespaces() ([]database.Namespace, error) {
+
+	namespaces, err := r.client.Namespaces(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote namespaces: %w", err)
+	}
+	converted := make([]database.Namespace, len(namespaces))
+	for i, namespace := range namespaces {
+		converted[i] = database.Namespace{Name: namespace.Name, DefaultTTL: namespace.DefaultTTL}
+	}
+	return converted, nil
+}
+
+func (r *Remote) SetNamespaceTTL(name string, ttl time.Duration) error {
+
+	if err := database.CheckNamespace(name); err != nil {
+		return err
+	}
+
+	if err := r.client.SetNamespaceTTL(context.Background(), name, ttl); err != nil {
+		return fmt.Errorf("failed to set remote namespace TTL: %w", err)
+	}
+	return nil
+}
+
+func (r *Remote) DropNamespace(name string) error {
+
+	err := r.client.DropNamespace(context.Background(), name)
+	if errors.Is(err, remote.ErrNotFound) {
+		return fmt.Errorf("%w: %s", database.ErrNamespaceNotFound, name)
+	} else if err != nil {
+		return fmt.Errorf("failed to drop remote namespace: %w", err)
+	}
+	return nil
+}
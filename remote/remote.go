@@ -0,0 +1,345 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/remote"
+)
+
+// Remote is a database backed by a running api server, so the same prompt,
+// one-shot commands and scripts work against production data.
+type Remote struct {
+	client remote.Client
+}
+
+func NewRemote(cfg remote.Config) (*Remote, error) {
+
+	client, err := remote.NewClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remote client: %w", err)
+	}
+	return &Remote{client: client}, nil
+}
+
+func (r *Remote) Create(key, value string) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	if err := r.client.Create(context.Background(), key, value); err != nil {
+		return fmt.Errorf("failed to create remote row: %w", err)
+	}
+
+	fmt.Println("Data inserted successfully.")
+
+	return nil
+}
+
+func (r *Remote) Update(key, value string) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+	if err := r.client.Update(context.Background(), key, value); err != nil {
+		return fmt.Errorf("failed to update remote row: %w", err)
+	}
+	return nil
+}
+
+func (r *Remote) Delete(key string) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+	if err := r.client.Delete(context.Background(), key); err != nil {
+		return fmt.Errorf("failed to delete remote row: %w", err)
+	}
+	return nil
+}
+
+func (r *Remote) Get(key string) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	value, err := r.client.Get(context.Background(), key)
+	if err != nil {
+		return fmt.Errorf("failed to get remote row: %w", err)
+	}
+	fmt.Printf("Value for key '%s': %s\n", key, value)
+
+	return nil
+}
+
+func (r *Remote) Show() error {
+
+	store, err := r.client.Show(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to show remote rows: %w", err)
+	}
+	fmt.Println("Map is", store)
+
+	return nil
+}
+
+func (r *Remote) Exit() error {
+	fmt.Println("Exiting program...")
+	os.Exit(0)
+	return nil
+}
+
+func (r *Remote) MultiGet(keys []string) (map[string]string, error) {
+
+	if len(keys) == 0 {
+		return map[string]string{}, nil
+	}
+
+	ops := make([]remote.BatchOperation, len(keys))
+	for i, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("key cannot be empty")
+		}
+		ops[i] = remote.BatchOperation{Op: "get", Key: key}
+	}
+
+	results, err := r.client.Batch(context.Background(), ops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remote rows: %w", err)
+	}
+
+	store := make(map[string]string, len(keys))
+	for _, result := range results {
+		switch result.Status {
+		case "ok":
+			store[result.Key] = result.Value
+		case "not found":
+		default:
+			return nil, fmt.Errorf("failed to get remote row %q: %s", result.Key, result.Error)
+		}
+	}
+	return store, nil
+}
+
+func (r *Remote) MultiSet(pairs map[string]string) error {
+
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	ops := make([]remote.BatchOperation, 0, len(pairs))
+	for key, value := range pairs {
+		if key == "" {
+			return fmt.Errorf("key cannot be empty")
+		}
+		ops = append(ops, remote.BatchOperation{Op: "set", Key: key, Value: value})
+	}
+
+	results, err := r.client.Batch(context.Background(), ops)
+	if err != nil {
+		return fmt.Errorf("failed to set remote rows: %w", err)
+	}
+	for _, result := range results {
+		if result.Status != "ok" {
+			return fmt.Errorf("failed to set remote row %q: %s", result.Key, result.Error)
+		}
+	}
+	return nil
+}
+
+func (r *Remote) MultiDelete(keys []string) ([]string, error) {
+
+	if len(keys) == 0 {
+		return []string{}, nil
+	}
+
+	ops := make([]remote.BatchOperation, len(keys))
+	for i, key := range keys {
+		if key == "" {
+			return nil, fmt.Errorf("key cannot be empty")
+		}
+		ops[i] = remote.BatchOperation{Op: "delete", Key: key}
+	}
+
+	results, err := r.client.Batch(context.Background(), ops)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete remote rows: %w", err)
+	}
+
+	deleted := []string{}
+	for _, result := range results {
+		switch result.Status {
+		case "ok":
+			deleted = append(deleted, result.Key)
+		case "not found":
+		default:
+			return nil, fmt.Errorf("failed to delete remote row %q: %s", result.Key, result.Error)
+		}
+	}
+	return deleted, nil
+}
+
+// Keys lists the keys with GET /keys, which filters and sorts them on the
+// server.
+func (r *Remote) Keys(prefix string) ([]string, error) {
+
+	keys, err := r.client.Keys(context.Background(), prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Stat returns the metadata of key, see database.MetadataStore.
+func (r *Remote) Stat(key string) (database.Metadata, error) {
+
+	if key == "" {
+		return database.Metadata{}, fmt.Errorf("key cannot be empty")
+	}
+
+	meta, err := r.client.Stat(context.Background(), key)
+	if errors.Is(err, remote.ErrNotFound) {
+		return database.Metadata{}, database.ErrNotFound
+	} else if err != nil {
+		return database.Metadata{}, fmt.Errorf("failed to stat remote row: %w", err)
+	}
+	return database.Metadata(meta), nil
+}
+
+func (r *Remote) SetMetadata(key, contentType string, tags []string) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	err := r.client.SetMetadata(context.Background(), key, contentType, tags)
+	if errors.Is(err, remote.ErrNotFound) {
+		return database.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to set remote metadata: %w", err)
+	}
+	return nil
+}
+
+// History returns the versions of key, see database.HistoryStore.
+func (r *Remote) History(key string, limit int) ([]database.Version, error) {
+
+	if key == "" {
+		return nil, fmt.Errorf("key cannot be empty")
+	}
+
+	versions, err := r.client.History(context.Background(), key, limit)
+	if errors.Is(err, remote.ErrNotFound) {
+		return nil, database.ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get remote history: %w", err)
+	}
+
+	history := make([]database.Version, len(versions))
+	for i, version := range versions {
+		history[i] = database.Version(version)
+	}
+	return history, nil
+}
+
+func (r *Remote) GetAt(key string, at time.Time) (string, error) {
+
+	if key == "" {
+		return "", fmt.Errorf("key cannot be empty")
+	}
+
+	value, err := r.client.GetAt(context.Background(), key, at)
+	if errors.Is(err, remote.ErrNotFound) {
+		return "", database.ErrNotFound
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get remote row: %w", err)
+	}
+	return value, nil
+}
+
+// Undelete restores a key the server tombstoned, see database.Undeleter.
+// The server purges its tombstones itself.
+func (r *Remote) Undelete(key string) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	err := r.client.Undelete(context.Background(), key)
+	if errors.Is(err, remote.ErrNotFound) {
+		return database.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to restore remote row: %w", err)
+	}
+	return nil
+}
+
+func (r *Remote) ReadValue(key string, w io.Writer) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	err := r.client.Download(context.Background(), key, w)
+	if errors.Is(err, remote.ErrNotFound) {
+		return database.ErrNotFound
+	} else if err != nil {
+		return fmt.Errorf("failed to download remote value: %w", err)
+	}
+	return nil
+}
+
+func (r *Remote) WriteValue(key string, body io.Reader) error {
+
+	if key == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+
+	if err := r.client.Upload(context.Background(), key, body); err != nil {
+		return fmt.Errorf("failed to upload remote value: %w", err)
+	}
+	return nil
+}
+
+func (r *Remote) IncrBy(key string, delta int64) (int64, error) {
+
+	if key == "" {
+		return 0, fmt.Errorf("key cannot be empty")
+	}
+
+	result, err := r.client.IncrBy(context.Background(), key, delta)
+	if err != nil {
+		return 0, counterError(err)
+	}
+	return result, nil
+}
+
+func (r *Remote) IncrByFloat(key string, delta float64) (float64, error) {
+
+	if key == "" {
+		return 0, fmt.Errorf("key cannot be empty")
+	}
+
+	result, err := r.client.IncrByFloat(context.Background(), key, delta)
+	if err != nil {
+		return 0, counterError(err)
+	}
+	return result, nil
+}
+
+// counterError converts an answer of the server that the value is not a
+// number, or would overflow, to database.ErrNotNumber.
+func counterError(err error) error {
+	var apiErr *remote.Error
+	if errors.Is(err, remote.ErrNotNumber) && errors.As(err, &apiErr) {
+		return fmt.Errorf("%w: %s", database.ErrNotNumber, apiErr.Message)
+	}
+	return fmt.Errorf("failed to increment remote row: %w", err)
+}
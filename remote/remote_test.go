@@ -0,0 +1,563 @@
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/remote"
+	"github.com/imsumedhaa/In-memory-database/pkg/client/remote/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRemote_Create(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		value         string
+		mockFunc      func(m *mocks.Client)
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			value:         "World",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name:  "Create Failure",
+			key:   "Hello",
+			value: "World",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Create", mock.Anything, "Hello", "World").Return(errors.New("server error")).Times(1)
+			},
+			expectedError: "failed to create remote row: server error",
+		},
+		{
+			name:  "Create Success",
+			key:   "Hello",
+			value: "World",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Create", mock.Anything, "Hello", "World").Return(nil).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Remote{client: mockClient}
+
+			err := db.Create(tt.key, tt.value)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemote_Get(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Get Failure",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Get", mock.Anything, "Hello").Return("", errors.New("server error")).Times(1)
+			},
+			expectedError: "failed to get remote row: server error",
+		},
+		{
+			name: "Get Success",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Get", mock.Anything, "Hello").Return("World", nil).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Remote{client: mockClient}
+
+			err := db.Get(tt.key)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemote_MultiGet(t *testing.T) {
+	tests := []struct {
+		name           string
+		keys           []string
+		mockFunc       func(m *mocks.Client)
+		expectedResult map[string]string
+		expectedError  string
+	}{
+		{
+			name:           "No Keys",
+			keys:           []string{},
+			mockFunc:       func(m *mocks.Client) {},
+			expectedResult: map[string]string{},
+		},
+		{
+			name:          "Empty Key",
+			keys:          []string{"Hello", ""},
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Batch Failure",
+			keys: []string{"Hello"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("Batch", mock.Anything, mock.Anything).Return(nil, errors.New("server error")).Times(1)
+			},
+			expectedError: "failed to get remote rows: server error",
+		},
+		{
+			name: "Item Failure",
+			keys: []string{"Hello"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("Batch", mock.Anything, mock.Anything).Return([]remote.BatchResult{
+					{Op: "get", Key: "Hello", Status: "error", Error: "db error"},
+				}, nil).Times(1)
+			},
+			expectedError: `failed to get remote row "Hello": db error`,
+		},
+		{
+			name: "Missing Keys Are Skipped",
+			keys: []string{"Hello", "Foo"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("Batch", mock.Anything, []remote.BatchOperation{{Op: "get", Key: "Hello"}, {Op: "get", Key: "Foo"}}).Return([]remote.BatchResult{
+					{Op: "get", Key: "Hello", Value: "World", Status: "ok"},
+					{Op: "get", Key: "Foo", Status: "not found"},
+				}, nil).Times(1)
+			},
+			expectedResult: map[string]string{"Hello": "World"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Remote{client: mockClient}
+
+			result, err := db.MultiGet(tt.keys)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemote_MultiSet(t *testing.T) {
+	tests := []struct {
+		name          string
+		pairs         map[string]string
+		mockFunc      func(m *mocks.Client)
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			pairs:         map[string]string{"": "World"},
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name:  "Item Failure",
+			pairs: map[string]string{"Hello": "World"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("Batch", mock.Anything, []remote.BatchOperation{{Op: "set", Key: "Hello", Value: "World"}}).Return([]remote.BatchResult{
+					{Op: "set", Key: "Hello", Status: "error", Error: "db error"},
+				}, nil).Times(1)
+			},
+			expectedError: `failed to set remote row "Hello": db error`,
+		},
+		{
+			name:  "Set Success",
+			pairs: map[string]string{"Hello": "World"},
+			mockFunc: func(m *mocks.Client) {
+				m.On("Batch", mock.Anything, []remote.BatchOperation{{Op: "set", Key: "Hello", Value: "World"}}).Return([]remote.BatchResult{
+					{Op: "set", Key: "Hello", Status: "ok"},
+				}, nil).Times(1)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Remote{client: mockClient}
+
+			err := db.MultiSet(tt.pairs)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemote_MultiDelete(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	mockClient.On("Batch", mock.Anything, []remote.BatchOperation{{Op: "delete", Key: "Hello"}, {Op: "delete", Key: "Foo"}}).Return([]remote.BatchResult{
+		{Op: "delete", Key: "Hello", Status: "ok"},
+		{Op: "delete", Key: "Foo", Status: "not found"},
+	}, nil).Times(1)
+
+	db := &Remote{client: mockClient}
+
+	deleted, err := db.MultiDelete([]string{"Hello", "Foo"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Hello"}, deleted)
+}
+
+func TestRemote_Keys(t *testing.T) {
+	tests := []struct {
+		name           string
+		prefix         string
+		mockFunc       func(m *mocks.Client)
+		expectedResult []string
+		expectedError  string
+	}{
+		{
+			name:   "Keys Failure",
+			prefix: "",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Keys", mock.Anything, "").Return(nil, errors.New("server error")).Times(1)
+			},
+			expectedError: "failed to list remote keys: server error",
+		},
+		{
+			name:   "Filtered On The Server",
+			prefix: "user:",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Keys", mock.Anything, "user:").Return([]string{"user:1", "user:2"}, nil).Times(1)
+			},
+			expectedResult: []string{"user:1", "user:2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Remote{client: mockClient}
+
+			result, err := db.Keys(tt.prefix)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemote_Stat(t *testing.T) {
+	updated := time.Date(2025, 2, 3, 4, 5, 6, 0, time.UTC)
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expectedMeta  database.Metadata
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Stat Not Found",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Stat", mock.Anything, "Hello").Return(remote.Metadata{}, &remote.Error{StatusCode: 404}).Times(1)
+			},
+			expectedError: "key not found",
+		},
+		{
+			name: "Stat Success",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Stat", mock.Anything, "Hello").Return(remote.Metadata{UpdatedAt: updated, ContentType: "text/plain"}, nil).Times(1)
+			},
+			expectedMeta: database.Metadata{UpdatedAt: updated, ContentType: "text/plain"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Remote{client: mockClient}
+
+			meta, err := db.Stat(tt.key)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedMeta, meta)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemote_History(t *testing.T) {
+	written := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name             string
+		key              string
+		mockFunc         func(m *mocks.Client)
+		expectedVersions []database.Version
+		expectedError    string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "History Not Found",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("History", mock.Anything, "Hello", 0).Return(nil, &remote.Error{StatusCode: 404}).Times(1)
+			},
+			expectedError: "key not found",
+		},
+		{
+			name: "History Success",
+			key:  "Hello",
+			mockFunc: func(m *mocks.Client) {
+				m.On("History", mock.Anything, "Hello", 0).Return([]remote.Version{{Version: 1, Value: "World", WrittenAt: written}}, nil).Times(1)
+			},
+			expectedVersions: []database.Version{{Version: 1, Value: "World", WrittenAt: written}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Remote{client: mockClient}
+
+			versions, err := db.History(tt.key, 0)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedVersions, versions)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemote_ReadValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expected      string
+		expectedError string
+	}{
+		{
+			name:          "Empty Key",
+			key:           "",
+			mockFunc:      func(m *mocks.Client) {},
+			expectedError: "key cannot be empty",
+		},
+		{
+			name: "Download Not Found",
+			key:  "logo",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Download", mock.Anything, "logo", mock.Anything).Return(&remote.Error{StatusCode: 404}).Times(1)
+			},
+			expectedError: "key not found",
+		},
+		{
+			name: "Download Failure",
+			key:  "logo",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Download", mock.Anything, "logo", mock.Anything).Return(errors.New("connection refused")).Times(1)
+			},
+			expectedError: "failed to download remote value: connection refused",
+		},
+		{
+			name: "Download Success",
+			key:  "logo",
+			mockFunc: func(m *mocks.Client) {
+				m.On("Download", mock.Anything, "logo", mock.Anything).Run(func(args mock.Arguments) {
+					io.WriteString(args.Get(2).(io.Writer), "\x89PNG\x00")
+				}).Return(nil).Times(1)
+			},
+			expected: "\x89PNG\x00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Remote{client: mockClient}
+
+			var buf bytes.Buffer
+			err := db.ReadValue(tt.key, &buf)
+
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, buf.String())
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemote_IncrBy(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		mockFunc      func(m *mocks.Client)
+		expected      int64
+		expectedError error
+	}{
+		{
+			name: "Not A Number",
+			key:  "hits",
+			mockFunc: func(m *mocks.Client) {
+				m.On("IncrBy", mock.Anything, "hits", int64(1)).Return(int64(0), &remote.Error{StatusCode: 422, Message: "value is not a number"}).Times(1)
+			},
+			expectedError: database.ErrNotNumber,
+		},
+		{
+			name: "Increment Success",
+			key:  "hits",
+			mockFunc: func(m *mocks.Client) {
+				m.On("IncrBy", mock.Anything, "hits", int64(1)).Return(int64(3), nil).Times(1)
+			},
+			expected: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mockClient := mocks.NewClient(t)
+			tt.mockFunc(mockClient)
+
+			db := &Remote{client: mockClient}
+
+			result, err := db.IncrBy(tt.key, 1)
+
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, result)
+			}
+
+			mockClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemote_Namespace(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	namespaceClient := mocks.NewClient(t)
+	mockClient.On("Namespace", "billing").Return(namespaceClient, nil).Times(1)
+	namespaceClient.On("Keys", mock.Anything, "invoice:").Return([]string{"invoice:1"}, nil).Times(1)
+
+	db := &Remote{client: mockClient}
+
+	billing, err := db.Namespace("billing")
+	assert.NoError(t, err)
+	keys, err := billing.Keys("invoice:")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"invoice:1"}, keys, "the keys are listed in the namespace")
+
+	_, err = db.Namespace("Bad-Name")
+	assert.ErrorContains(t, err, "invalid namespace name")
+}
+
+func TestRemote_Namespaces(t *testing.T) {
+	mockClient := mocks.NewClient(t)
+	mockClient.On("Namespaces", mock.Anything).Return([]remote.Namespace{{Name: "cache", DefaultTTL: time.Hour, Keys: 3}}, nil).Times(1)
+	mockClient.On("SetNamespaceTTL", mock.Anything, "cache", 2*time.Hour).Return(nil).Times(1)
+	mockClient.On("DropNamespace", mock.Anything, "billing").Return(&remote.Error{StatusCode: 404}).Times(1)
+
+	db := &Remote{client: mockClient}
+
+	namespaces, err := db.Namespaces()
+	assert.NoError(t, err)
+	assert.Equal(t, []database.Namespace{{Name: "cache", DefaultTTL: time.Hour}}, namespaces)
+
+	assert.NoError(t, db.SetNamespaceTTL("cache", 2*time.Hour))
+
+	err = db.DropNamespace("billing")
+	assert.ErrorIs(t, err, database.ErrNamespaceNotFound)
+	assert.EqualError(t, err, "namespace not found: billing")
+}
@@ -0,0 +1,259 @@
+package transfer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Supported formats for import and export.
+const (
+	FormatJSONL = "jsonl" // one {"key":..,"value":..} object per line
+	FormatCSV   = "csv"   // key,value with a header row
+	FormatJSON  = "json"  // a single {"key": "value"} map, same as the filesystem backend
+)
+
+type Record struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// RecordReader streams records out of an encoded file. Next returns io.EOF
+// once the input is exhausted.
+type RecordReader interface {
+	Next() (Record, error)
+}
+
+// RecordWriter streams records into an encoded file. Close must be called to
+// flush whatever the format keeps buffered.
+type RecordWriter interface {
+	Write(rec Record) error
+	Close() error
+}
+
+// DetectFormat guesses the format from a file extension.
+func DetectFormat(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	case ".csv":
+		return FormatCSV, nil
+	case ".json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("cannot detect format of %q, use --format", filename)
+}
+
+func NewReader(format string, r io.Reader) (RecordReader, error) {
+	switch format {
+	case FormatJSONL:
+		return &jsonlReader{scanner: newLineScanner(r)}, nil
+	case FormatCSV:
+		return &csvReader{reader: csv.NewReader(r)}, nil
+	case FormatJSON:
+		return &jsonReader{decoder: json.NewDecoder(r)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, should be either 'jsonl' or 'csv' or 'json'", format)
+}
+
+func NewWriter(format string, w io.Writer) (RecordWriter, error) {
+	switch format {
+	case FormatJSONL:
+		return &jsonlWriter{writer: bufio.NewWriter(w)}, nil
+	case FormatCSV:
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	case FormatJSON:
+		return &jsonWriter{writer: bufio.NewWriter(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, should be either 'jsonl' or 'csv' or 'json'", format)
+}
+
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	// Values can be much longer than the default 64KB line limit
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	return scanner
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (j *jsonlReader) Next() (Record, error) {
+	for j.scanner.Scan() {
+		j.line++
+		line := strings.TrimSpace(j.scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return Record{}, fmt.Errorf("line %d: %w", j.line, err)
+		}
+		return rec, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return Record{}, fmt.Errorf("line %d: %w", j.line+1, err)
+	}
+	return Record{}, io.EOF
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	header bool
+}
+
+func (c *csvReader) Next() (Record, error) {
+	for {
+		row, err := c.reader.Read()
+		if err != nil {
+			return Record{}, err
+		}
+
+		line, _ := c.reader.FieldPos(0)
+		if len(row) != 2 {
+			return Record{}, fmt.Errorf("line %d: expected 2 columns, got %d", line, len(row))
+		}
+
+		// The header row is optional
+		if !c.header {
+			c.header = true
+			if row[0] == "key" && row[1] == "value" {
+				continue
+			}
+		}
+		return Record{Key: row[0], Value: row[1]}, nil
+	}
+}
+
+type jsonReader struct {
+	decoder *json.Decoder
+	started bool
+	done    bool
+}
+
+func (j *jsonReader) Next() (Record, error) {
+	if j.done {
+		return Record{}, io.EOF
+	}
+
+	// Walk the object token by token so big files never sit in memory whole
+	if !j.started {
+		j.started = true
+		tok, err := j.decoder.Token()
+		if err == io.EOF {
+			// An empty file is what the filesystem backend starts with
+			j.done = true
+			return Record{}, io.EOF
+		}
+		if err != nil {
+			return Record{}, fmt.Errorf("failed to decode JSON: %w", err)
+		}
+		if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+			return Record{}, errors.New("failed to decode JSON: expected an object of key value pairs")
+		}
+	}
+
+	tok, err := j.decoder.Token()
+	if err != nil {
+		return Record{}, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); ok && delim == '}' {
+		j.done = true
+		return Record{}, io.EOF
+	}
+
+	key, ok := tok.(string)
+	if !ok {
+		return Record{}, fmt.Errorf("failed to decode JSON: unexpected %v", tok)
+	}
+
+	var value string
+	if err := j.decoder.Decode(&value); err != nil {
+		return Record{}, fmt.Errorf("failed to decode JSON value of %q: %w", key, err)
+	}
+	return Record{Key: key, Value: value}, nil
+}
+
+type jsonlWriter struct {
+	writer *bufio.Writer
+}
+
+func (j *jsonlWriter) Write(rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("error encoding data: %w", err)
+	}
+	j.writer.Write(data)
+	return j.writer.WriteByte('\n')
+}
+
+func (j *jsonlWriter) Close() error {
+	return j.writer.Flush()
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) Write(rec Record) error {
+	if !c.header {
+		c.header = true
+		if err := c.writer.Write([]string{"key", "value"}); err != nil {
+			return err
+		}
+	}
+	return c.writer.Write([]string{rec.Key, rec.Value})
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// jsonWriter produces the same layout as json.MarshalIndent(store, "", "  ")
+// in the filesystem backend, one pair at a time.
+type jsonWriter struct {
+	writer *bufio.Writer
+	count  int
+}
+
+func (j *jsonWriter) Write(rec Record) error {
+	key, err := json.Marshal(rec.Key)
+	if err != nil {
+		return fmt.Errorf("error encoding data: %w", err)
+	}
+	value, err := json.Marshal(rec.Value)
+	if err != nil {
+		return fmt.Errorf("error encoding data: %w", err)
+	}
+
+	if j.count == 0 {
+		j.writer.WriteString("{\n  ")
+	} else {
+		j.writer.WriteString(",\n  ")
+	}
+	j.count++
+
+	j.writer.Write(key)
+	j.writer.WriteString(": ")
+	_, err = j.writer.Write(value)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.count == 0 {
+		j.writer.WriteString("{}")
+	} else {
+		j.writer.WriteString("\n}")
+	}
+	return j.writer.Flush()
+}
@@ -0,0 +1,309 @@
+package transfer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+type MigrateOptions struct {
+	BatchSize int
+	Progress  io.Writer
+}
+
+// Summary describes the content of a store so two stores can be compared
+// without holding both in memory.
+type Summary struct {
+	Count    int
+	Checksum string
+}
+
+// Copy writes every pair of src into dst and returns how many were copied.
+func Copy(src, dst database.Database, opts MigrateOptions) (int, error) {
+	write := writer(dst)
+
+	count := 0
+	err := walk(src, "", opts.BatchSize, func(keys []string, values map[string]string) error {
+		if err := write(values); err != nil {
+			return fmt.Errorf("failed to write batch ending at key %q: %w", keys[len(keys)-1], err)
+		}
+		count += len(values)
+
+		if opts.Progress != nil {
+			fmt.Fprintf(opts.Progress, "copied %d keys\n", count)
+		}
+		return nil
+	})
+	return count, err
+}
+
+// Summarize counts the pairs of db and hashes them in key order.
+func Summarize(db database.Database, batchSize int) (Summary, error) {
+	hash := sha256.New()
+	count := 0
+
+	err := walk(db, "", batchSize, func(keys []string, values map[string]string) error {
+		for _, key := range keys {
+			value, ok := values[key]
+			if !ok {
+				continue
+			}
+			// Length prefixes keep ("ab","c") and ("a","bc") apart
+			fmt.Fprintf(hash, "%d:%s%d:%s", len(key), key, len(value), value)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return Summary{}, err
+	}
+
+	return Summary{Count: count, Checksum: hex.EncodeToString(hash.Sum(nil))}, nil
+}
+
+// Verify checks that src and dst hold exactly the same pairs.
+func Verify(src, dst database.Database, batchSize int) (Summary, error) {
+	srcSummary, err := Summarize(src, batchSize)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to summarize source: %w", err)
+	}
+	dstSummary, err := Summarize(dst, batchSize)
+	if err != nil {
+		return Summary{}, fmt.Errorf("failed to summarize destination: %w", err)
+	}
+
+	if srcSummary.Count != dstSummary.Count {
+		return srcSummary, fmt.Errorf("count mismatch: source has %d keys, destination has %d", srcSummary.Count, dstSummary.Count)
+	}
+	if srcSummary.Checksum != dstSummary.Checksum {
+		return srcSummary, fmt.Errorf("checksum mismatch: source %s, destination %s", srcSummary.Checksum, dstSummary.Checksum)
+	}
+	return srcSummary, nil
+}
+
+// Follow keeps dst in step with src until ctx is cancelled, which is the
+// cutover signal. Every interval it applies the keys that were added,
+// changed or removed in src. When src announces its writes, see
+// database.ChangeListener, only the announced keys are read again;
+// otherwise the whole of src is compared with dst batch by batch. The first
+// pass always compares the whole store, so writes that landed between Copy
+// and Follow are picked up too, and so does a last pass after cancellation
+// so writes made right before cutover are not lost.
+func Follow(ctx context.Context, src, dst database.Database, interval time.Duration, opts MigrateOptions) error {
+	changes := watch(ctx, src, opts.Progress)
+
+	if err := syncAll(src, dst, opts); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			<-changes.done
+			return syncAll(src, dst, opts)
+		case <-ticker.C:
+			keys, all := changes.take()
+			var err error
+			if all {
+				err = syncAll(src, dst, opts)
+			} else {
+				err = syncKeys(src, dst, keys, opts)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// changeSet collects the keys announced by the source between two passes
+// of Follow.
+type changeSet struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+	// all is set when any key may have changed: notifications were missed
+	// or the source does not send them
+	all     bool
+	stopped bool
+	done    chan struct{}
+}
+
+// watch listens for the writes of db until ctx is done, if it announces
+// them. Once listening fails every pass compares the whole store.
+func watch(ctx context.Context, db database.Database, progress io.Writer) *changeSet {
+	changes := &changeSet{keys: make(map[string]struct{}), done: make(chan struct{})}
+
+	listener, ok := db.(database.ChangeListener)
+	if !ok {
+		changes.stopped = true
+		close(changes.done)
+		return changes
+	}
+
+	go func() {
+		defer close(changes.done)
+
+		err := listener.ListenChanges(ctx, changes.add)
+		if err != nil && progress != nil {
+			fmt.Fprintf(progress, "comparing every key from now on: %v\n", err)
+		}
+
+		changes.mu.Lock()
+		changes.stopped = true
+		changes.mu.Unlock()
+	}()
+	return changes
+}
+
+func (c *changeSet) add(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key == "" {
+		c.all = true
+		return
+	}
+	c.keys[key] = struct{}{}
+}
+
+// take returns the keys announced since the last call, or all when the
+// whole store has to be compared.
+func (c *changeSet) take() (keys []string, all bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	all = c.all || c.stopped
+	if !all {
+		keys = slices.Sorted(maps.Keys(c.keys))
+	}
+	c.keys = make(map[string]struct{})
+	c.all = false
+	return keys, all
+}
+
+// syncAll compares src with dst batch by batch, writing the keys whose
+// values differ, then deletes the keys of dst that are not in src.
+func syncAll(src, dst database.Database, opts MigrateOptions) error {
+	write := writer(dst)
+
+	changed := 0
+	err := walk(src, "", opts.BatchSize, func(keys []string, values map[string]string) error {
+		current, err := dst.MultiGet(keys)
+		if err != nil {
+			return fmt.Errorf("failed to read destination: %w", err)
+		}
+
+		pairs := make(map[string]string)
+		for key, value := range values {
+			if old, ok := current[key]; !ok || old != value {
+				pairs[key] = value
+			}
+		}
+		if len(pairs) == 0 {
+			return nil
+		}
+		if err := write(pairs); err != nil {
+			return fmt.Errorf("failed to apply changes: %w", err)
+		}
+		changed += len(pairs)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sync source: %w", err)
+	}
+
+	// Listed after the walk, so a key written to src in the meantime is
+	// left for the next pass instead of being deleted
+	srcKeys, err := src.Keys("")
+	if err != nil {
+		return fmt.Errorf("failed to list source keys: %w", err)
+	}
+	dstKeys, err := dst.Keys("")
+	if err != nil {
+		return fmt.Errorf("failed to list destination keys: %w", err)
+	}
+	slices.Sort(srcKeys)
+
+	var removed []string
+	for _, key := range dstKeys {
+		if _, found := slices.BinarySearch(srcKeys, key); !found {
+			removed = append(removed, key)
+		}
+	}
+	if err := deleteKeys(dst, removed); err != nil {
+		return err
+	}
+
+	progress(opts, changed, len(removed))
+	return nil
+}
+
+// syncKeys copies the given keys of src to dst batch by batch, deleting
+// the ones src no longer has.
+func syncKeys(src, dst database.Database, keys []string, opts MigrateOptions) error {
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	write := writer(dst)
+
+	changed, deleted := 0, 0
+	for start := 0; start < len(keys); start += batchSize {
+		batch := keys[start:min(start+batchSize, len(keys))]
+
+		values, err := src.MultiGet(batch)
+		if err != nil {
+			return fmt.Errorf("failed to read source: %w", err)
+		}
+		if err := checkTypes(src, batch, values); err != nil {
+			return err
+		}
+
+		var removed []string
+		for _, key := range batch {
+			if _, ok := values[key]; !ok {
+				removed = append(removed, key)
+			}
+		}
+		if len(values) > 0 {
+			if err := write(values); err != nil {
+				return fmt.Errorf("failed to apply changes: %w", err)
+			}
+		}
+		if err := deleteKeys(dst, removed); err != nil {
+			return err
+		}
+		changed += len(values)
+		deleted += len(removed)
+	}
+
+	progress(opts, changed, deleted)
+	return nil
+}
+
+func deleteKeys(db database.Database, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if _, err := db.MultiDelete(keys); err != nil {
+		return fmt.Errorf("failed to apply deletes: %w", err)
+	}
+	return nil
+}
+
+func progress(opts MigrateOptions, changed, deleted int) {
+	if opts.Progress != nil && (changed > 0 || deleted > 0) {
+		fmt.Fprintf(opts.Progress, "synced %d changed and %d deleted keys\n", changed, deleted)
+	}
+}
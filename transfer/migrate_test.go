@@ -0,0 +1,214 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+	"github.com/imsumedhaa/In-memory-database/inmemory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyAndVerify(t *testing.T) {
+	tests := []struct {
+		name          string
+		source        map[string]string
+		destination   map[string]string
+		expectedCount int
+		expectedError string
+	}{
+		{
+			name:          "Copy into an empty store",
+			source:        map[string]string{"a": "1", "b": "2", "c": "3"},
+			destination:   map[string]string{},
+			expectedCount: 3,
+		},
+		{
+			name:          "Copy overwrites stale values",
+			source:        map[string]string{"a": "1", "b": "2"},
+			destination:   map[string]string{"a": "old"},
+			expectedCount: 2,
+		},
+		{
+			name:          "Extra keys in the destination fail verification",
+			source:        map[string]string{"a": "1"},
+			destination:   map[string]string{"z": "26"},
+			expectedCount: 1,
+			expectedError: "count mismatch: source has 1 keys, destination has 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, _ := inmemory.NewInmemory()
+			dst, _ := inmemory.NewInmemory()
+			_ = src.MultiSet(tt.source)
+			_ = dst.MultiSet(tt.destination)
+
+			count, err := Copy(src, dst, MigrateOptions{BatchSize: 2})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCount, count)
+
+			_, err = Verify(src, dst, 2)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	first, _ := inmemory.NewInmemory()
+	second, _ := inmemory.NewInmemory()
+	_ = first.MultiSet(map[string]string{"ab": "c"})
+	_ = second.MultiSet(map[string]string{"a": "bc"})
+
+	firstSummary, err := Summarize(first, 0)
+	assert.NoError(t, err)
+	secondSummary, err := Summarize(second, 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, firstSummary.Count)
+	assert.NotEqual(t, firstSummary.Checksum, secondSummary.Checksum)
+}
+
+// collated lists the keys like Postgres with a collation that ignores the
+// case.
+type collated struct {
+	database.Database
+}
+
+func (c collated) Keys(prefix string) ([]string, error) {
+	return []string{"a", "B", "c"}, nil
+}
+
+func TestSummarize_KeyOrder(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+	_ = db.MultiSet(map[string]string{"a": "1", "B": "2", "c": "3"})
+
+	bytewise, err := Summarize(db, 0)
+	assert.NoError(t, err)
+	collatedSummary, err := Summarize(collated{db}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, bytewise, collatedSummary, "the checksum does not depend on the order of the keys")
+}
+
+func TestFollow(t *testing.T) {
+	src, _ := inmemory.NewInmemory()
+	dst, _ := inmemory.NewInmemory()
+	_ = src.MultiSet(map[string]string{"a": "1", "b": "2"})
+
+	_, err := Copy(src, dst, MigrateOptions{})
+	assert.NoError(t, err)
+
+	// Changes made after the copy have to reach dst once Follow returns
+	_ = src.MultiSet(map[string]string{"a": "10", "c": "3"})
+	_, _ = src.MultiDelete([]string{"b"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = Follow(ctx, src, dst, time.Hour, MigrateOptions{})
+	assert.NoError(t, err)
+
+	_, err = Verify(src, dst, 0)
+	assert.NoError(t, err)
+
+	values, _ := dst.MultiGet([]string{"a", "b", "c"})
+	assert.Equal(t, map[string]string{"a": "10", "c": "3"}, values)
+}
+
+// announcing hands the callback of ListenChanges to the test, like a
+// backend that announces its writes.
+type announcing struct {
+	database.Database
+	listening chan func(key string)
+	err       error
+}
+
+func (a announcing) ListenChanges(ctx context.Context, changed func(key string)) error {
+	if a.err != nil {
+		return a.err
+	}
+	a.listening <- changed
+	<-ctx.Done()
+	return nil
+}
+
+func TestFollow_Changes(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		copies bool
+	}{
+		{
+			name:   "Only the announced keys are copied",
+			copies: false,
+		},
+		{
+			name:   "Every key is compared once listening fails",
+			err:    errors.New("connection refused"),
+			copies: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, _ := inmemory.NewInmemory()
+			dst, _ := inmemory.NewInmemory()
+			_ = store.MultiSet(map[string]string{"a": "1", "b": "2"})
+			src := announcing{Database: store, listening: make(chan func(key string), 1), err: tt.err}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() {
+				done <- Follow(ctx, src, dst, time.Millisecond, MigrateOptions{})
+			}()
+
+			// Written after the first pass, which copies a and b
+			assert.Eventually(t, func() bool {
+				values, _ := dst.MultiGet([]string{"a", "b"})
+				return len(values) == 2
+			}, time.Second, time.Millisecond)
+			_ = store.MultiSet(map[string]string{"b": "20"})
+			_, _ = store.MultiDelete([]string{"a"})
+
+			if tt.err == nil {
+				changed := <-src.listening
+				changed("a")
+			}
+			assert.Eventually(t, func() bool {
+				values, _ := dst.MultiGet([]string{"a"})
+				return len(values) == 0
+			}, time.Second, time.Millisecond)
+
+			values, _ := dst.MultiGet([]string{"b"})
+			if tt.copies {
+				assert.Equal(t, map[string]string{"b": "20"}, values)
+			} else {
+				assert.Equal(t, map[string]string{"b": "2"}, values, "b was not announced")
+			}
+
+			cancel()
+			assert.NoError(t, <-done)
+			_, err := Verify(src, dst, 0)
+			assert.NoError(t, err, "the last pass compares every key")
+		})
+	}
+}
+
+func TestSummarize_OtherTypes(t *testing.T) {
+	db, _ := inmemory.NewInmemory()
+	_ = db.MultiSet(map[string]string{"name": "abc"})
+	_, _ = db.RPush("queue", "a")
+
+	_, err := Summarize(db, 0)
+	assert.ErrorIs(t, err, database.ErrWrongType)
+	assert.ErrorContains(t, err, "queue holds a list")
+
+	dst, _ := inmemory.NewInmemory()
+	_, err = Copy(db, dst, MigrateOptions{})
+	assert.ErrorIs(t, err, database.ErrWrongType, "the other types are not dropped silently")
+}
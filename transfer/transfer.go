@@ -0,0 +1,181 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/imsumedhaa/In-memory-database/database"
+)
+
+const DefaultBatchSize = 1000
+
+type ImportOptions struct {
+	BatchSize int
+	// DryRun decodes and validates the input without writing anything.
+	DryRun bool
+	// Progress receives a line after every batch. Nil disables it.
+	Progress io.Writer
+}
+
+type ExportOptions struct {
+	BatchSize int
+	// Prefix limits the export to keys starting with it.
+	Prefix   string
+	Progress io.Writer
+}
+
+// Import streams records from r into db in batches and returns how many
+// records were read. Backends implementing database.BulkLoader get their
+// fast path, everything else goes through MultiSet.
+func Import(db database.Database, r RecordReader, opts ImportOptions) (int, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	write := writer(db)
+
+	verb := "imported"
+	if opts.DryRun {
+		verb = "validated"
+	}
+
+	count := 0
+	batch := make(map[string]string, opts.BatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if !opts.DryRun {
+			if err := write(batch); err != nil {
+				return fmt.Errorf("failed to write batch ending at record %d: %w", count, err)
+			}
+		}
+		if opts.Progress != nil {
+			fmt.Fprintf(opts.Progress, "%s %d records\n", verb, count)
+		}
+		batch = make(map[string]string, opts.BatchSize)
+		return nil
+	}
+
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return count, fmt.Errorf("failed to read record %d: %w", count+1, err)
+		}
+		if rec.Key == "" {
+			return count, fmt.Errorf("record %d: key cannot be empty", count+1)
+		}
+
+		count++
+		batch[rec.Key] = rec.Value
+		if len(batch) >= opts.BatchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// Export writes every pair of db to w in key order and returns how many
+// records were written. Values are fetched BatchSize keys at a time.
+func Export(db database.Database, w RecordWriter, opts ExportOptions) (int, error) {
+	count := 0
+	err := walk(db, opts.Prefix, opts.BatchSize, func(keys []string, values map[string]string) error {
+		for _, key := range keys {
+			value, ok := values[key]
+			if !ok {
+				// Deleted between listing and reading
+				continue
+			}
+			if err := w.Write(Record{Key: key, Value: value}); err != nil {
+				return fmt.Errorf("failed to write record %q: %w", key, err)
+			}
+			count++
+		}
+
+		if opts.Progress != nil {
+			fmt.Fprintf(opts.Progress, "exported %d records\n", count)
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+
+	if err := w.Close(); err != nil {
+		return count, fmt.Errorf("failed to flush output: %w", err)
+	}
+	return count, nil
+}
+
+// walk lists the keys of db starting with prefix and hands them to fn in
+// sorted batches together with their values. The keys are sorted by their
+// bytes here, since Postgres sorts them by the collation of the database.
+func walk(db database.Database, prefix string, batchSize int, fn func(keys []string, values map[string]string) error) error {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	keys, err := db.Keys(prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list keys: %w", err)
+	}
+	slices.Sort(keys)
+
+	for start := 0; start < len(keys); start += batchSize {
+		end := min(start+batchSize, len(keys))
+
+		values, err := db.MultiGet(keys[start:end])
+		if err != nil {
+			return fmt.Errorf("failed to read keys: %w", err)
+		}
+		if err := checkTypes(db, keys[start:end], values); err != nil {
+			return err
+		}
+		if err := fn(keys[start:end], values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkTypes fails on the keys MultiGet left out because they hold another
+// type than a string, rather than skipping them like the keys deleted since
+// they were listed. Records only hold strings.
+func checkTypes(db database.Database, keys []string, values map[string]string) error {
+	typer, ok := db.(database.Typer)
+	if !ok {
+		return nil
+	}
+	for _, key := range keys {
+		if _, ok := values[key]; ok {
+			continue
+		}
+		kind, err := typer.Type(key)
+		if err != nil {
+			return fmt.Errorf("failed to read the type of %q: %w", key, err)
+		}
+		if kind != database.TypeNone && kind != database.TypeString {
+			return fmt.Errorf("%w: %s holds a %s, only strings can be transferred", database.ErrWrongType, key, kind)
+		}
+	}
+	return nil
+}
+
+// writer picks the fastest way to store a batch in db.
+func writer(db database.Database) func(pairs map[string]string) error {
+	if loader, ok := db.(database.BulkLoader); ok {
+		return loader.BulkLoad
+	}
+	return db.MultiSet
+}
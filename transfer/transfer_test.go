@@ -0,0 +1,198 @@
+package transfer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/imsumedhaa/In-memory-database/inmemory"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImport(t *testing.T) {
+	tests := []struct {
+		name          string
+		format        string
+		input         string
+		dryRun        bool
+		expectedCount int
+		expectedError string
+		expectedStore map[string]string
+	}{
+		{
+			name:          "JSON Lines",
+			format:        FormatJSONL,
+			input:         "{\"key\":\"name\",\"value\":\"abc\"}\n\n{\"key\":\"city\",\"value\":\"xyz\"}\n",
+			expectedCount: 2,
+			expectedStore: map[string]string{"name": "abc", "city": "xyz"},
+		},
+		{
+			name:          "CSV with header",
+			format:        FormatCSV,
+			input:         "key,value\nname,abc\ncity,\"x, y\"\n",
+			expectedCount: 2,
+			expectedStore: map[string]string{"name": "abc", "city": "x, y"},
+		},
+		{
+			name:          "CSV without header",
+			format:        FormatCSV,
+			input:         "name,abc\n",
+			expectedCount: 1,
+			expectedStore: map[string]string{"name": "abc"},
+		},
+		{
+			name:          "Filesystem JSON map",
+			format:        FormatJSON,
+			input:         "{\n  \"city\": \"xyz\",\n  \"name\": \"abc\"\n}",
+			expectedCount: 2,
+			expectedStore: map[string]string{"name": "abc", "city": "xyz"},
+		},
+		{
+			name:          "Empty filesystem file",
+			format:        FormatJSON,
+			input:         "",
+			expectedCount: 0,
+			expectedStore: map[string]string{},
+		},
+		{
+			name:          "Dry run writes nothing",
+			format:        FormatJSONL,
+			input:         "{\"key\":\"name\",\"value\":\"abc\"}\n",
+			dryRun:        true,
+			expectedCount: 1,
+			expectedStore: map[string]string{},
+		},
+		{
+			name:          "Empty key",
+			format:        FormatJSONL,
+			input:         "{\"key\":\"name\",\"value\":\"abc\"}\n{\"key\":\"\",\"value\":\"abc\"}\n",
+			expectedCount: 1,
+			expectedError: "record 2: key cannot be empty",
+			expectedStore: map[string]string{},
+		},
+		{
+			name:          "Broken line",
+			format:        FormatJSONL,
+			input:         "{\"key\":\"name\",\"value\":\"abc\"}\nnot-json\n",
+			expectedCount: 1,
+			expectedError: "failed to read record 2: line 2",
+			expectedStore: map[string]string{},
+		},
+		{
+			name:          "Wrong number of CSV columns",
+			format:        FormatCSV,
+			input:         "name,abc,extra\n",
+			expectedCount: 0,
+			expectedError: "expected 2 columns, got 3",
+			expectedStore: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := inmemory.NewInmemory()
+
+			reader, err := NewReader(tt.format, strings.NewReader(tt.input))
+			assert.NoError(t, err)
+
+			count, err := Import(db, reader, ImportOptions{DryRun: tt.dryRun})
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedCount, count)
+
+			keys, _ := db.Keys("")
+			actual, _ := db.MultiGet(keys)
+			assert.Equal(t, tt.expectedStore, actual)
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	tests := []struct {
+		name           string
+		format         string
+		prefix         string
+		expectedCount  int
+		expectedOutput string
+	}{
+		{
+			name:           "JSON Lines",
+			format:         FormatJSONL,
+			expectedCount:  3,
+			expectedOutput: "{\"key\":\"city\",\"value\":\"x, y\"}\n{\"key\":\"user:1\",\"value\":\"abc\"}\n{\"key\":\"user:2\",\"value\":\"def\"}\n",
+		},
+		{
+			name:           "CSV",
+			format:         FormatCSV,
+			expectedCount:  3,
+			expectedOutput: "key,value\ncity,\"x, y\"\nuser:1,abc\nuser:2,def\n",
+		},
+		{
+			name:           "Filesystem JSON map",
+			format:         FormatJSON,
+			expectedCount:  3,
+			expectedOutput: "{\n  \"city\": \"x, y\",\n  \"user:1\": \"abc\",\n  \"user:2\": \"def\"\n}",
+		},
+		{
+			name:           "Prefix",
+			format:         FormatJSONL,
+			prefix:         "user:",
+			expectedCount:  2,
+			expectedOutput: "{\"key\":\"user:1\",\"value\":\"abc\"}\n{\"key\":\"user:2\",\"value\":\"def\"}\n",
+		},
+		{
+			name:           "Nothing to export",
+			format:         FormatJSON,
+			prefix:         "missing:",
+			expectedCount:  0,
+			expectedOutput: "{}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, _ := inmemory.NewInmemory()
+			_ = db.MultiSet(map[string]string{"user:1": "abc", "user:2": "def", "city": "x, y"})
+
+			var out bytes.Buffer
+			writer, err := NewWriter(tt.format, &out)
+			assert.NoError(t, err)
+
+			// A batch size of 2 makes sure values are fetched over several calls
+			count, err := Export(db, writer, ExportOptions{BatchSize: 2, Prefix: tt.prefix})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCount, count)
+			assert.Equal(t, tt.expectedOutput, out.String())
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		filename       string
+		expectedFormat string
+		expectedError  string
+	}{
+		{filename: "dump.jsonl", expectedFormat: FormatJSONL},
+		{filename: "dump.CSV", expectedFormat: FormatCSV},
+		{filename: "database.json", expectedFormat: FormatJSON},
+		{filename: "dump.txt", expectedError: "cannot detect format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			format, err := DetectFormat(tt.filename)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedFormat, format)
+		})
+	}
+}